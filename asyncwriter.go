@@ -0,0 +1,347 @@
+package golog
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Policies of the async writer when its queue is full
+const (
+	// AsyncDrop drops new entries while the queue is full
+	AsyncDrop = "drop"
+	// AsyncBlock makes logging wait for room in the queue, so no entry is
+	// lost but logging slows down to the pace of the inner writer
+	AsyncBlock = "block"
+	// AsyncSample keeps one in every AsyncSampleRate entries logged while the
+	// queue is full, waiting for room for them, and drops the others
+	AsyncSample = "sample"
+)
+
+const (
+	// defaultAsyncQueueSize is the number of entries queued by default
+	defaultAsyncQueueSize = 1024
+	// defaultAsyncSampleRate is the sample rate of AsyncSample by default
+	defaultAsyncSampleRate = 10
+	// defaultAsyncPriorityQueueSize is the number of priority entries queued
+	// by default
+	defaultAsyncPriorityQueueSize = 256
+)
+
+// AsyncOption configures the writer created by NewAsyncWriter.
+type AsyncOption func(*asyncOptions)
+
+// asyncOptions holds the settings of the async writer.
+type asyncOptions struct {
+	// queueSize is the capacity of the queue
+	queueSize int
+	// policy is AsyncDrop, AsyncBlock, or AsyncSample
+	policy string
+	// sampleRate is the one-in-n rate of AsyncSample
+	sampleRate int
+	// priorityLevel is the minimum level of the entries of the priority lane
+	priorityLevel int
+	// priorityQueueSize is the capacity of the priority lane
+	priorityQueueSize int
+}
+
+// AsyncQueueSize sets the number of entries queued for the inner writer.
+// The default is 1024.
+func AsyncQueueSize(size int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.queueSize = size
+	}
+}
+
+// AsyncFullPolicy sets what happens to entries logged while the queue is
+// full: AsyncDrop (the default), AsyncBlock, or AsyncSample. Dropped entries
+// are counted with RecordDropped as DropQueueFull.
+func AsyncFullPolicy(policy string) AsyncOption {
+	return func(o *asyncOptions) {
+		o.policy = policy
+	}
+}
+
+// AsyncSampleRate sets the rate of AsyncSample: one in every n entries
+// logged while the queue is full is kept. The default is 10.
+func AsyncSampleRate(n int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.sampleRate = n
+	}
+}
+
+// AsyncPriorityLevel sets the minimum level of the entries queued in the
+// priority lane, LevelError by default. Priority entries are written before
+// the entries of the normal queue and are never dropped: when the lane is
+// full, logging waits for room, whatever the AsyncFullPolicy. A level above
+// LevelFatal disables the lane.
+func AsyncPriorityLevel(level int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.priorityLevel = level
+	}
+}
+
+// AsyncPriorityQueueSize sets the number of entries queued in the priority
+// lane. The default is 256.
+func AsyncPriorityQueueSize(size int) AsyncOption {
+	return func(o *asyncOptions) {
+		o.priorityQueueSize = size
+	}
+}
+
+// asyncWriter implements the LogWriter interface by queuing entries for an
+// inner writer that writes them from a background goroutine.
+type asyncWriter struct {
+	inner LogWriter
+	opts  asyncOptions
+	queue chan asyncEntry
+	// priority is the lane of the entries at or above the priority level,
+	// written first
+	priority chan asyncEntry
+	// fields reuses the field maps of queued entries
+	fields fieldsPool
+	// overflow counts the entries logged while the queue was full, for
+	// AsyncSample
+	overflow atomic.Uint64
+
+	// mu guards closed; senders hold it for reading so that Close does not
+	// close the queue under them
+	mu     sync.RWMutex
+	closed bool
+	// stopped is closed when the background goroutine has exited
+	stopped chan struct{}
+}
+
+// asyncEntry is an entry queued for the inner writer, or a flush request.
+type asyncEntry struct {
+	entry Entry
+	// file and line are the caller location, resolved when the entry is
+	// logged since the inner writer runs on another goroutine
+	file string
+	line int
+	// flushed is set for flush requests and closed once the inner writer is
+	// flushed
+	flushed chan struct{}
+	// keepOpen makes a flush request flush the buffers of the inner writer
+	// without closing its output
+	keepOpen bool
+}
+
+// NewAsyncWriter creates a LogWriter that queues entries and writes them to
+// inner from a background goroutine, so logging does not wait for I/O. The
+// queue holds 1024 entries (see AsyncQueueSize); when it is full, new
+// entries are dropped unless AsyncFullPolicy says otherwise.
+//
+// Entries at LevelError and above bypass the queue: they go through a
+// priority lane, written first and never dropped (see AsyncPriorityLevel),
+// so the most important entries survive a burst of debug and info entries.
+// They can therefore be written before entries logged earlier.
+//
+// Flush waits until the entries queued before it are written and then
+// flushes inner. Close flushes and stops the background goroutine; entries
+// written after Close are dropped. Close does not close inner.
+//
+// Example:
+//
+//	writer := golog.NewAsyncWriter(golog.NewJSONWriter(os.Stdout),
+//	    golog.AsyncQueueSize(4096),
+//	    golog.AsyncFullPolicy(golog.AsyncSample),
+//	)
+//	defer writer.Close()
+//	golog.SetWriter(writer)
+func NewAsyncWriter(inner LogWriter, opts ...AsyncOption) *asyncWriter {
+	o := asyncOptions{
+		queueSize:         defaultAsyncQueueSize,
+		policy:            AsyncDrop,
+		sampleRate:        defaultAsyncSampleRate,
+		priorityLevel:     LevelError,
+		priorityQueueSize: defaultAsyncPriorityQueueSize,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	o.queueSize = max(o.queueSize, 1)
+	o.sampleRate = max(o.sampleRate, 1)
+	o.priorityQueueSize = max(o.priorityQueueSize, 1)
+
+	w := &asyncWriter{
+		inner:    inner,
+		opts:     o,
+		queue:    make(chan asyncEntry, o.queueSize),
+		priority: make(chan asyncEntry, o.priorityQueueSize),
+		stopped:  make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write implements LogWriter.
+func (w *asyncWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *asyncWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write queues the entry according to the policy. The fields are copied,
+// since the entry is written after write returns.
+func (w *asyncWriter) write(entry Entry, file string, line int) {
+	copied := w.fields.get()
+	maps.Copy(copied, entry.Fields)
+	entry.Fields = copied
+
+	e := asyncEntry{
+		entry: entry,
+		file:  file,
+		line:  line,
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		w.fields.put(copied)
+		return
+	}
+
+	if entry.Level >= w.opts.priorityLevel {
+		w.priority <- e
+		return
+	}
+
+	if w.opts.policy == AsyncBlock {
+		w.queue <- e
+		return
+	}
+
+	select {
+	case w.queue <- e:
+		return
+	default:
+	}
+
+	if w.opts.policy == AsyncSample && w.overflow.Add(1)%uint64(w.opts.sampleRate) == 0 {
+		w.queue <- e
+		return
+	}
+
+	RecordDropped(DropQueueFull, 1)
+	w.fields.put(copied)
+}
+
+// Flush implements LogWriter by waiting until the queued entries are written
+// and flushing the inner writer.
+func (w *asyncWriter) Flush() {
+	w.flush(false)
+}
+
+// flushBuffers implements bufferFlusher.
+func (w *asyncWriter) flushBuffers() {
+	w.flush(true)
+}
+
+// flush waits until the queued entries are written and flushes the inner
+// writer, keeping its output open if keepOpen is set.
+func (w *asyncWriter) flush(keepOpen bool) {
+	flushed := make(chan struct{})
+
+	w.mu.RLock()
+	if w.closed {
+		w.mu.RUnlock()
+		return
+	}
+
+	w.queue <- asyncEntry{flushed: flushed, keepOpen: keepOpen}
+	w.mu.RUnlock()
+
+	<-flushed
+}
+
+// Close writes the queued entries, flushes the inner writer, and stops the
+// background goroutine.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	<-w.stopped
+	w.inner.Flush()
+
+	return nil
+}
+
+// run writes the queued entries to the inner writer until the queue is
+// closed, taking the entries of the priority lane first.
+func (w *asyncWriter) run() {
+	defer close(w.stopped)
+
+	for {
+		w.drainPriority()
+
+		select {
+		case e := <-w.priority:
+			w.writeQueued(e)
+		case e, ok := <-w.queue:
+			if !ok {
+				// no entry is sent after the queue is closed
+				w.drainPriority()
+				return
+			}
+
+			if e.flushed != nil {
+				// the priority entries logged before Flush are written too
+				w.drainPriority()
+				if e.keepOpen {
+					flushBuffers(w.inner)
+				} else {
+					w.inner.Flush()
+				}
+				close(e.flushed)
+				continue
+			}
+
+			w.writeQueued(e)
+		}
+	}
+}
+
+// drainPriority writes the entries of the priority lane.
+func (w *asyncWriter) drainPriority() {
+	for {
+		select {
+		case e := <-w.priority:
+			w.writeQueued(e)
+		default:
+			return
+		}
+	}
+}
+
+// writeQueued writes a queued entry to the inner writer.
+func (w *asyncWriter) writeQueued(e asyncEntry) {
+	writeLocated(w.inner, e.entry, e.file, e.line)
+	w.fields.put(e.entry.Fields)
+}
+
+// PoolStats returns the statistics of the pool of field maps used for
+// queued entries, for tuning the queue size.
+func (w *asyncWriter) PoolStats() PoolStats {
+	return w.fields.stats()
+}
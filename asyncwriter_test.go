@@ -0,0 +1,234 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// gatedWriter is a captureWriter whose writes wait until the gate is opened.
+type gatedWriter struct {
+	captureWriter
+	gate chan struct{}
+}
+
+func (w *gatedWriter) Write(level int, msg string, fields map[string]any) {
+	<-w.gate
+	w.captureWriter.Write(level, msg, fields)
+}
+
+func TestAsyncWriter(t *testing.T) {
+	inner := &captureWriter{}
+	writer := NewAsyncWriter(inner)
+	defer writer.Close()
+
+	fields := map[string]any{"n": 0}
+	for i := 0; i < 100; i++ {
+		fields["n"] = i
+		writer.Write(LevelInfo, "entry", fields)
+	}
+	writer.Flush()
+
+	inner.mu.Lock()
+	defer inner.mu.Unlock()
+
+	if len(inner.entries) != 100 {
+		t.Fatalf("len(inner.entries) = %d, want 100", len(inner.entries))
+	}
+	for i, entry := range inner.entries {
+		if entry.fields["n"] != i {
+			t.Errorf(`fields are copied when the entry is queued: entry.fields["n"] = %v, want %v`, entry.fields["n"], i)
+		}
+	}
+	if inner.flushes != 1 {
+		t.Errorf("inner.flushes = %v, want 1", inner.flushes)
+	}
+}
+
+func TestAsyncWriter_FullPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		// openGate reports when the gate of the inner writer is opened, while
+		// the entries after the first are written
+		openGate func(w *asyncWriter) bool
+		verify   func(t *testing.T, written, dropped int)
+	}{
+		{
+			name:     "drop",
+			policy:   AsyncDrop,
+			openGate: func(w *asyncWriter) bool { return false },
+			verify: func(t *testing.T, written, dropped int) {
+				// one entry is held by the blocked inner writer and two are
+				// queued
+				if written != 3 {
+					t.Errorf("written = %v, want 3", written)
+				}
+				if dropped != 17 {
+					t.Errorf("dropped = %v, want 17", dropped)
+				}
+			},
+		},
+		{
+			name:     "block",
+			policy:   AsyncBlock,
+			openGate: func(w *asyncWriter) bool { return true },
+			verify: func(t *testing.T, written, dropped int) {
+				if written != 20 {
+					t.Errorf("written = %v, want 20", written)
+				}
+				if dropped != 0 {
+					t.Errorf("dropped = %v, want zero", dropped)
+				}
+			},
+		},
+		{
+			name:   "sample",
+			policy: AsyncSample,
+			// open the gate once the fifth overflowing entry, the first one
+			// sampled, waits for room
+			openGate: func(w *asyncWriter) bool { return w.overflow.Load() >= 5 },
+			verify: func(t *testing.T, written, dropped int) {
+				if got := written + dropped; got != 20 {
+					t.Errorf("written+dropped = %v, want 20", got)
+				}
+				if dropped < 4 {
+					t.Errorf("dropped = %v, want at least 4", dropped)
+				}
+				if dropped >= 17 {
+					t.Errorf("dropped = %v, want less than 17", dropped)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetDrops(t)
+
+			inner := &gatedWriter{gate: make(chan struct{})}
+			writer := NewAsyncWriter(inner, AsyncQueueSize(2), AsyncFullPolicy(tt.policy), AsyncSampleRate(5))
+
+			// the first entry is taken by the background goroutine, which
+			// blocks on the gate; wait for it so the queue is empty
+			writer.Write(LevelInfo, "entry", nil)
+			eventually(t, func() bool { return len(writer.queue) == 0 })
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 1; i < 20; i++ {
+					writer.Write(LevelInfo, "entry", nil)
+				}
+			}()
+
+			opened := false
+			for !opened {
+				select {
+				case <-done:
+					opened = true
+				default:
+					opened = tt.openGate(writer)
+				}
+			}
+			close(inner.gate)
+			<-done
+			if err := writer.Close(); err != nil {
+				t.Fatalf("writer.Close(): %v", err)
+			}
+
+			dropped := 0
+			if fields, ok := drops.take(true); ok {
+				dropped = fields["dropped"].(int)
+			}
+			tt.verify(t, len(inner.entries), dropped)
+		})
+	}
+}
+
+func TestAsyncWriter_Priority(t *testing.T) {
+	resetDrops(t)
+
+	inner := &gatedWriter{gate: make(chan struct{})}
+	writer := NewAsyncWriter(inner, AsyncQueueSize(2))
+
+	// the first entry blocks the background goroutine on the gate
+	writer.Write(LevelInfo, "first", nil)
+	eventually(t, func() bool { return len(writer.queue) == 0 })
+
+	// two entries fill the queue and the others are dropped, but errors
+	// and above take the priority lane
+	for i := 0; i < 5; i++ {
+		writer.Write(LevelInfo, "info", nil)
+	}
+	writer.Write(LevelError, "error", nil)
+	writer.Write(LevelDebug, "debug", nil)
+	writer.Write(LevelFatal, "fatal", nil)
+
+	close(inner.gate)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	var msgs []string
+	for _, entry := range inner.entries {
+		msgs = append(msgs, entry.msg)
+	}
+	want := []string{"first", "error", "fatal", "info", "info"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Errorf("msgs = %v, want %v", msgs, want)
+	}
+
+	fields, ok := drops.take(true)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if fields["dropped"] != 4 {
+		t.Errorf(`fields["dropped"] = %v, want 4`, fields["dropped"])
+	}
+}
+
+func TestAsyncWriter_Close(t *testing.T) {
+	inner := &captureWriter{}
+	writer := NewAsyncWriter(inner)
+
+	writer.Write(LevelInfo, "queued", nil)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	writer.Write(LevelInfo, "after close", nil)
+	writer.Flush()
+
+	if len(inner.entries) != 1 {
+		t.Fatalf("len(inner.entries) = %d, want 1", len(inner.entries))
+	}
+	if inner.entries[0].msg != "queued" {
+		t.Errorf("inner.entries[0].msg = %q, want %q", inner.entries[0].msg, "queued")
+	}
+	if inner.flushes != 1 {
+		t.Errorf("inner.flushes = %v, want 1", inner.flushes)
+	}
+}
+
+func TestAsyncWriter_Caller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewAsyncWriter(NewJSONWriter(buf))
+	defer writer.Close()
+
+	writer.Write(LevelInfo, "entry", nil)
+	writer.Flush()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+	}
+	if got, _ := entry[FieldCaller].(string); !strings.Contains(got, "asyncwriter_test.go") {
+		t.Errorf("the caller is resolved when the entry is logged: entry[FieldCaller] = %q, want it to contain %q", got, "asyncwriter_test.go")
+	}
+}
@@ -0,0 +1,66 @@
+package golog
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvFormat is the environment variable that overrides the format chosen by
+// NewAutoWriter: FormatText or FormatJSON.
+const EnvFormat = "GOLOG_FORMAT"
+
+// NewAutoWriter returns a writer choosing its format from where output goes,
+// so the same binary writes readable lines when run in a terminal and JSON
+// when run in a container or under a process manager:
+//
+//   - the text format of NewDefaultWriter when output is a terminal
+//   - the JSON format of NewJSONWriter otherwise
+//
+// The GOLOG_FORMAT environment variable, set to "text" or "json", overrides
+// the detection. opts apply to either writer. Flush does not close stdout
+// or stderr, so the writer keeps working after the first Flush.
+//
+// Example:
+//
+//	golog.SetWriter(golog.NewAutoWriter(os.Stderr))
+func NewAutoWriter(output io.Writer, opts ...WriterOption) LogWriter {
+	if autoFormat(output, os.Getenv(EnvFormat)) == FormatJSON {
+		return NewJSONWriter(output, opts...)
+	}
+
+	return NewDefaultWriter(output, opts...)
+}
+
+// autoFormat returns the format of NewAutoWriter for output, given the value
+// of GOLOG_FORMAT.
+func autoFormat(output io.Writer, env string) string {
+	switch strings.ToLower(strings.TrimSpace(env)) {
+	case FormatText:
+		return FormatText
+	case FormatJSON:
+		return FormatJSON
+	}
+
+	if isTerminal(output) {
+		return FormatText
+	}
+
+	return FormatJSON
+}
+
+// isTerminal reports whether w is a file attached to a terminal, i.e. a
+// character device, without depending on a terminal package.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
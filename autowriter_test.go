@@ -0,0 +1,114 @@
+package golog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAutoFormat(t *testing.T) {
+	file, err := os.Create(filepath.Join(t.TempDir(), "app.log"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+
+	tests := []struct {
+		name     string
+		output   io.Writer
+		env      string
+		expected string
+	}{
+		{
+			name:     "buffer",
+			output:   &bytes.Buffer{},
+			expected: FormatJSON,
+		},
+		{
+			name:     "regular-file",
+			output:   file,
+			expected: FormatJSON,
+		},
+		{
+			name:     "env-text",
+			output:   file,
+			env:      "TEXT",
+			expected: FormatText,
+		},
+		{
+			name:     "env-json",
+			output:   file,
+			env:      " json ",
+			expected: FormatJSON,
+		},
+		{
+			name:     "env-unknown",
+			output:   &bytes.Buffer{},
+			env:      "pretty",
+			expected: FormatJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := autoFormat(tt.output, tt.env); got != tt.expected {
+				t.Errorf("autoFormat(tt.output, tt.env) = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAutoWriter(t *testing.T) {
+	t.Setenv(EnvFormat, FormatText)
+	if _, ok := NewAutoWriter(&bytes.Buffer{}).(*defaultWriter); !ok {
+		t.Errorf("NewAutoWriter(&bytes.Buffer{}) = %T, want *defaultWriter", NewAutoWriter(&bytes.Buffer{}))
+	}
+
+	t.Setenv(EnvFormat, "")
+	buf := &bytes.Buffer{}
+	writer := NewAutoWriter(buf, SortKeys())
+	if _, ok := writer.(*jsonWriter); !ok {
+		t.Fatalf("writer = %T, want *jsonWriter", writer)
+	}
+
+	writer.Write(LevelInfo, "started", map[string]any{"b": 2, "a": 1})
+	writer.Flush()
+	if got := buf.String(); !strings.Contains(got, `"a":1,"b":2`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `"a":1,"b":2`)
+	}
+}
+
+func TestNewAutoWriter_FlushKeepsStderrOpen(t *testing.T) {
+	stderr, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { stderr.Close() })
+
+	saved := os.Stderr
+	os.Stderr = stderr
+	t.Cleanup(func() { os.Stderr = saved })
+
+	for _, format := range []string{FormatText, FormatJSON} {
+		t.Setenv(EnvFormat, format)
+		writer := NewAutoWriter(os.Stderr)
+
+		writer.Write(LevelInfo, "first "+format, nil)
+		writer.Flush()
+		writer.Write(LevelInfo, "second "+format, nil)
+		writer.Flush()
+	}
+
+	data, err := os.ReadFile(stderr.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, msg := range []string{"first text", "second text", "first json", "second json"} {
+		if !strings.Contains(string(data), msg) {
+			t.Errorf("string(data) = %q, want it to contain %q", string(data), msg)
+		}
+	}
+}
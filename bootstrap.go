@@ -0,0 +1,48 @@
+package golog
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// bootstrapWriter is the global writer used until SetWriter is called.
+// It writes every entry synchronously to its output (os.Stderr) in the
+// default text format, without buffering, so errors logged during early
+// startup (e.g. configuration parse failures) are never lost in an unflushed
+// buffer when the process exits or crash-loops.
+type bootstrapWriter struct {
+	mu   sync.Mutex
+	text *defaultWriter
+}
+
+// newBootstrapWriter creates a bootstrapWriter writing to output.
+func newBootstrapWriter(output io.Writer) *bootstrapWriter {
+	return &bootstrapWriter{text: NewDefaultWriter(output)}
+}
+
+// Write implements LogWriter. The entry is written before Write returns.
+func (w *bootstrapWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter. The entry is written before WriteEntry returns.
+func (w *bootstrapWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write formats the entry and flushes it to the output immediately.
+func (w *bootstrapWriter) write(entry Entry, file string, line int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.text.write(entry, file, line)
+	w.text.buf.Flush()
+}
+
+// Flush implements LogWriter. Entries are never buffered, so there is nothing
+// to flush, and the output (os.Stderr) is intentionally left open.
+func (w *bootstrapWriter) Flush() {}
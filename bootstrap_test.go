@@ -0,0 +1,44 @@
+package golog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// closeRecorder is an io.Writer that records whether it was closed.
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestBootstrapWriter(t *testing.T) {
+	out := &closeRecorder{}
+	useWriter(t, newBootstrapWriter(out))
+
+	With("config", "app.yaml").Error("failed to parse config")
+
+	if got := out.String(); !strings.Contains(got, "[ERROR]") {
+		t.Errorf("entry is written without Flush: out.String() = %q, want it to contain %q", got, "[ERROR]")
+	}
+	if got := out.String(); !strings.Contains(got, `config="app.yaml"`) {
+		t.Errorf("out.String() = %q, want it to contain %q", got, `config="app.yaml"`)
+	}
+
+	Flush()
+	if out.closed {
+		t.Error("Flush must not close the output")
+	}
+}
+
+func TestDefaultInstanceIsBootstrapWriter(t *testing.T) {
+	_, ok := std.Writer().(*bootstrapWriter)
+	if !ok {
+		t.Error("ok = false, want true")
+	}
+}
@@ -0,0 +1,113 @@
+package golog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Classification is the data handling class of a field value.
+type Classification string
+
+// Data classifications
+const (
+	// ClassSensitive marks personal or secret data, such as email addresses
+	// or account numbers. Writers mask it unless configured otherwise.
+	ClassSensitive Classification = "sensitive"
+	// ClassInternal marks data that may be stored internally but must not
+	// leave the organization, such as internal hostnames. Writers show it
+	// unless configured to mask it.
+	ClassInternal Classification = "internal"
+)
+
+// Classified is a field value tagged with a data classification. Create it
+// with Sensitive or Internal.
+//
+// Writers decide from the classification whether to write the value or
+// "[REDACTED]" (see MaskClassified). Writers that do not know about
+// classifications still mask sensitive values, since String and MarshalJSON
+// do. Sinks can inspect Class and Value to route entries.
+type Classified struct {
+	// Class is the data classification of Value
+	Class Classification
+	// Value is the field value
+	Value any
+}
+
+// String returns "[REDACTED]" for sensitive values and the formatted value
+// otherwise.
+func (c Classified) String() string {
+	if c.Class == ClassSensitive {
+		return redactedValue
+	}
+
+	return fmt.Sprint(c.Value)
+}
+
+// MarshalJSON encodes "[REDACTED]" for sensitive values and the value
+// otherwise.
+func (c Classified) MarshalJSON() ([]byte, error) {
+	if c.Class == ClassSensitive {
+		return json.Marshal(redactedValue)
+	}
+
+	return json.Marshal(c.Value)
+}
+
+// Sensitive returns a field, for use with WithFields, whose value is
+// classified as ClassSensitive. Built-in writers write it as "[REDACTED]"
+// unless configured with MaskClassified to reveal it, e.g. for a secure
+// audit sink.
+//
+// Example:
+//
+//	golog.WithFields(golog.Sensitive("email", user.Email)).Info("password reset requested")
+func Sensitive(key string, value any) map[string]any {
+	return map[string]any{key: Classified{Class: ClassSensitive, Value: value}}
+}
+
+// Internal returns a field, for use with WithFields, whose value is
+// classified as ClassInternal. Built-in writers write it as is, unless
+// configured with MaskClassified to mask it, e.g. for an external sink.
+func Internal(key string, value any) map[string]any {
+	return map[string]any{key: Classified{Class: ClassInternal, Value: value}}
+}
+
+// MaskClassified sets the classifications a writer masks as "[REDACTED]",
+// replacing the default of ClassSensitive only. Call it without arguments to
+// reveal all classified values, e.g. in a writer for the secure audit sink,
+// or with both classifications for a writer that ships to an external
+// service.
+//
+// Example:
+//
+//	external := golog.NewJSONWriter(vendorConn, golog.MaskClassified(golog.ClassSensitive, golog.ClassInternal))
+//	audit := golog.NewJSONWriter(auditFile, golog.MaskClassified())
+func MaskClassified(classes ...Classification) WriterOption {
+	return func(o *writerOptions) {
+		o.maskedClasses = make(map[Classification]bool, len(classes))
+		for _, class := range classes {
+			o.maskedClasses[class] = true
+		}
+	}
+}
+
+// classifiedValue returns the value to write for v: the value of a
+// Classified, or "[REDACTED]" when its classification is masked. Other
+// values are returned unchanged.
+func (o writerOptions) classifiedValue(v any) any {
+	c, ok := v.(Classified)
+	if !ok {
+		return v
+	}
+
+	masked := c.Class == ClassSensitive
+	if o.maskedClasses != nil {
+		masked = o.maskedClasses[c.Class]
+	}
+
+	if masked {
+		return redactedValue
+	}
+
+	return c.Value
+}
@@ -0,0 +1,99 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClassification_JSONWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []WriterOption
+		email    any
+		hostname any
+	}{
+		{
+			name:     "default-masks-sensitive",
+			email:    "[REDACTED]",
+			hostname: "db-1.internal",
+		},
+		{
+			name:     "external-sink",
+			opts:     []WriterOption{MaskClassified(ClassSensitive, ClassInternal)},
+			email:    "[REDACTED]",
+			hostname: "[REDACTED]",
+		},
+		{
+			name:     "audit-sink",
+			opts:     []WriterOption{MaskClassified()},
+			email:    "ada@example.com",
+			hostname: "db-1.internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, tt.opts...)
+
+			fields := Sensitive("email", "ada@example.com")
+			fields["host"] = Internal("host", "db-1.internal")["host"]
+			writer.Write(LevelInfo, "password reset requested", fields)
+			if err := writer.flushBuffer(); err != nil {
+				t.Fatalf("writer.flushBuffer(): %v", err)
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			if !reflect.DeepEqual(entry["email"], tt.email) {
+				t.Errorf(`entry["email"] = %v, want %v`, entry["email"], tt.email)
+			}
+			if !reflect.DeepEqual(entry["host"], tt.hostname) {
+				t.Errorf(`entry["host"] = %v, want %v`, entry["host"], tt.hostname)
+			}
+		})
+	}
+}
+
+func TestClassification_DefaultWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewDefaultWriter(buf)
+
+	writer.Write(LevelInfo, "password reset requested", Sensitive("email", "ada@example.com"))
+	if err := writer.flushBuffer(); err != nil {
+		t.Fatalf("writer.flushBuffer(): %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, `email="[REDACTED]"`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `email="[REDACTED]"`)
+	}
+}
+
+func TestClassified_UnawareWriters(t *testing.T) {
+	sensitive := Sensitive("email", "ada@example.com")["email"]
+	internal := Internal("host", "db-1.internal")["host"]
+
+	if got := fmt.Sprintf("%v", sensitive); got != "[REDACTED]" {
+		t.Errorf(`fmt.Sprintf("%%v", sensitive) = %q, want %q`, got, "[REDACTED]")
+	}
+	if got := fmt.Sprintf("%+v", sensitive); got != "[REDACTED]" {
+		t.Errorf(`fmt.Sprintf("%%+v", sensitive) = %q, want %q`, got, "[REDACTED]")
+	}
+	if got := fmt.Sprint(internal); got != "db-1.internal" {
+		t.Errorf("fmt.Sprint(internal) = %q, want %q", got, "db-1.internal")
+	}
+
+	data, err := json.Marshal(map[string]any{"email": sensitive, "host": internal})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := `{"email":"[REDACTED]","host":"db-1.internal"}`; string(data) != want {
+		t.Errorf("json.Marshal = %s, want %s", data, want)
+	}
+}
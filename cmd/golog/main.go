@@ -0,0 +1,139 @@
+// Command golog provides tools for working with golog output.
+//
+// Usage:
+//
+//	golog report [-top n] [-logger-field key] [-json] [file ...]
+//
+// The report subcommand analyzes JSON log lines, from the files or from
+// standard input, and prints the level distribution and the top message
+// templates, callers, and loggers (see package logreport). Files ending in
+// .gz are decompressed.
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jkaveri/golog/logreport"
+)
+
+const usage = `usage: golog <command> [arguments]
+
+commands:
+  report    summarize a JSON log archive to find the noisiest loggers
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line args and returns the exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	switch args[0] {
+	case "report":
+		return runReport(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "golog: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+}
+
+// runReport executes the report subcommand.
+func runReport(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("report", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	top := flags.Int("top", 10, "number of templates, callers, and loggers to report; 0 reports all")
+	loggerField := flags.String("logger-field", logreport.DefaultLoggerField, "field naming the logger of an entry")
+	asJSON := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	input, closeInput, err := openInputs(flags.Args(), stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "golog: %v\n", err)
+		return 1
+	}
+	defer closeInput()
+
+	report, err := logreport.Analyze(input, logreport.Top(*top), logreport.LoggerField(*loggerField))
+	if err != nil {
+		fmt.Fprintf(stderr, "golog: %v\n", err)
+		return 1
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(stdout)
+	}
+
+	if err != nil {
+		fmt.Fprintf(stderr, "golog: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// openInputs returns the concatenation of the files at paths, decompressing
+// .gz files, or stdin when paths is empty or "-". The returned function
+// closes the files.
+func openInputs(paths []string, stdin io.Reader) (io.Reader, func(), error) {
+	if len(paths) == 0 {
+		return stdin, func() {}, nil
+	}
+
+	var readers []io.Reader
+	var closers []io.Closer
+	closeAll := func() {
+		for _, closer := range closers {
+			closer.Close()
+		}
+	}
+
+	for _, path := range paths {
+		if path == "-" {
+			readers = append(readers, stdin)
+			continue
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		closers = append(closers, file)
+
+		var reader io.Reader = file
+		if strings.HasSuffix(path, ".gz") {
+			gz, err := gzip.NewReader(file)
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("%s: %w", path, err)
+			}
+			closers = append(closers, gz)
+			reader = gz
+		}
+
+		// a file without a final newline must not merge with the next one
+		readers = append(readers, reader, strings.NewReader("\n"))
+	}
+
+	return io.MultiReader(readers...), closeAll, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jkaveri/golog/logreport"
+)
+
+func TestRun_Report(t *testing.T) {
+	dir := t.TempDir()
+
+	plain := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(plain, []byte(`{"level":"INFO","msg":"started"}`), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	compressed := filepath.Join(dir, "app.log.1.gz")
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(`{"level":"ERROR","msg":"job 7 failed"}` + "\n"))
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zw.Close(): %v", err)
+	}
+	if err := os.WriteFile(compressed, gz.Bytes(), 0o644); err != nil {
+		t.Fatalf("os.WriteFile(compressed, gz.Bytes(), 0o644): %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		code   int
+		verify func(t *testing.T, stdout, stderr string)
+	}{
+		{
+			name: "files",
+			args: []string{"report", "-json", plain, compressed},
+			verify: func(t *testing.T, stdout, stderr string) {
+				var report logreport.Report
+				if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+					t.Fatalf("json.Unmarshal([]byte(stdout), &report): %v", err)
+				}
+				if report.Entries != 2 {
+					t.Errorf("report.Entries = %v, want 2", report.Entries)
+				}
+				if report.Invalid != 0 {
+					t.Errorf("files are not merged on a missing final newline: report.Invalid = %v, want zero", report.Invalid)
+				}
+				if report.Templates[0].Key != "job <*> failed" {
+					t.Errorf("report.Templates[0].Key = %q, want %q", report.Templates[0].Key, "job <*> failed")
+				}
+			},
+		},
+		{
+			name:  "stdin",
+			args:  []string{"report"},
+			stdin: `{"level":"INFO","msg":"started"}`,
+			verify: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stdout, "entries  1\n") {
+					t.Errorf("stdout = %q, want it to contain %q", stdout, "entries  1\n")
+				}
+			},
+		},
+		{
+			name: "missing file",
+			args: []string{"report", filepath.Join(dir, "missing.log")},
+			code: 1,
+			verify: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stderr, "missing.log") {
+					t.Errorf("stderr = %q, want it to contain %q", stderr, "missing.log")
+				}
+			},
+		},
+		{
+			name: "unknown command",
+			args: []string{"tail"},
+			code: 2,
+			verify: func(t *testing.T, stdout, stderr string) {
+				if !strings.Contains(stderr, `unknown command "tail"`) {
+					t.Errorf("stderr = %q, want it to contain %q", stderr, `unknown command "tail"`)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, strings.NewReader(tt.stdin), &stdout, &stderr)
+
+			if code != tt.code {
+				t.Errorf("%s: code = %v, want %v", stderr.String(), code, tt.code)
+			}
+			tt.verify(t, stdout.String(), stderr.String())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package golog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// CodecGzip is the name of the built-in gzip codec.
+const CodecGzip = "gzip"
+
+// ErrUnknownCodec is returned when a compression codec name is not
+// registered with RegisterCodec.
+var ErrUnknownCodec = errors.New("golog: unknown compression codec")
+
+// Codec compresses log data. Implementations adapt a compression library,
+// such as zstd, lz4, or snappy, so that writers can use it without golog
+// depending on the library.
+type Codec interface {
+	// NewWriter returns a writer compressing the data written to it into w.
+	// Closing it completes the compressed stream without closing w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	// NewReader returns a reader decompressing the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{CodecGzip: gzipCodec{}}
+)
+
+// RegisterCodec makes a compression codec available by name to the writers
+// that compress their output (see FileCompression) and to Config. gzip is
+// registered as "gzip". Registering a name again replaces the codec.
+//
+// Example:
+//
+//	golog.RegisterCodec("zstd", zstdCodec{})
+//	writer, err := golog.NewFileWriter("/var/log/app.log.zst", golog.FileCompression("zstd"))
+func RegisterCodec(name string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+
+	codecs[name] = codec
+}
+
+// LookupCodec returns the codec registered with name.
+func LookupCodec(name string) (Codec, bool) {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	codec, ok := codecs[name]
+
+	return codec, ok
+}
+
+// Codecs returns the names of the registered codecs, sorted.
+func Codecs() []string {
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// lookupCodec returns the codec registered with name, or ErrUnknownCodec.
+func lookupCodec(name string) (Codec, error) {
+	codec, ok := LookupCodec(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %v)", ErrUnknownCodec, name, Codecs())
+	}
+
+	return codec, nil
+}
+
+// compress returns data compressed as one complete stream of codec.
+func compress(codec Codec, data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	w, err := codec.NewWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// gzipCodec is the built-in gzip Codec.
+type gzipCodec struct{}
+
+func (gzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCodec) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
@@ -0,0 +1,140 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// upperCodec is a test Codec that upper-cases data and marks stream ends.
+type upperCodec struct{}
+
+type upperWriter struct{ w io.Writer }
+
+func (u upperWriter) Write(p []byte) (int, error) {
+	return u.w.Write(bytes.ToUpper(p))
+}
+
+func (u upperWriter) Close() error {
+	_, err := u.w.Write([]byte("--\n"))
+	return err
+}
+
+func (upperCodec) NewWriter(w io.Writer) (io.WriteCloser, error) { return upperWriter{w}, nil }
+
+func (upperCodec) NewReader(r io.Reader) (io.ReadCloser, error) { return io.NopCloser(r), nil }
+
+func TestRegisterCodec(t *testing.T) {
+	t.Cleanup(func() {
+		codecsMu.Lock()
+		delete(codecs, "upper")
+		codecsMu.Unlock()
+	})
+
+	_, ok := LookupCodec("upper")
+	if ok {
+		t.Error("ok = true, want false")
+	}
+
+	RegisterCodec("upper", upperCodec{})
+
+	codec, ok := LookupCodec("upper")
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	want := upperCodec{}
+	if !reflect.DeepEqual(codec, want) {
+		t.Errorf("codec = %v, want %v", codec, want)
+	}
+	if got, want := Codecs(), []string{CodecGzip, "upper"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Codecs() = %v, want %v", got, want)
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	writer, err := NewFileWriter(path, FileCompression("upper"), FileTextFormat())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writer.Write(LevelInfo, "first", nil)
+	writer.Write(LevelInfo, "second", nil)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := strings.Count(string(data), "\n"); got != 3 {
+		t.Errorf("entries are compressed in one block: strings.Count(string(data), \"\\n\") = %v, want 3", got)
+	}
+	if !strings.Contains(string(data), "FIRST") {
+		t.Errorf("string(data) = %q, want it to contain %q", string(data), "FIRST")
+	}
+	if !strings.HasSuffix(string(data), "--\n") {
+		t.Error("strings.HasSuffix(string(data), \"--\\n\") = false, want true")
+	}
+}
+
+func TestFileCompression_Gzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log.gz")
+	writer, err := NewFileWriter(path, FileCompression(CodecGzip))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	payload := strings.Repeat("x", compressedBlockSize/4)
+	for i := 0; i < 6; i++ {
+		writer.Write(LevelInfo, "entry", map[string]any{"payload": payload})
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := info.Size(); got == 0 {
+		t.Error("a full block is written without Flush: info.Size() is zero")
+	}
+
+	writer.Write(LevelInfo, "last", nil)
+	writer.Flush()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer file.Close()
+
+	codec, _ := LookupCodec(CodecGzip)
+	reader, err := codec.NewReader(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("concatenated blocks decompress as one stream: unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 7 {
+		t.Fatalf("len(lines) = %d, want 7", len(lines))
+	}
+	if !strings.Contains(lines[6], `"msg":"last"`) {
+		t.Errorf("lines[6] = %q, want it to contain %q", lines[6], `"msg":"last"`)
+	}
+}
+
+func TestFileCompression_UnknownCodec(t *testing.T) {
+	_, err := NewFileWriter(filepath.Join(t.TempDir(), "app.log"), FileCompression("brotli"))
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("err = %v, want %v", err, ErrUnknownCodec)
+	}
+}
@@ -0,0 +1,407 @@
+// Package collector receives entries streamed by other processes and writes
+// them through a golog Logger, making a lightweight, self-hosted aggregation
+// point out of the same primitives as the applications it serves.
+//
+// Processes send entries over TCP, as frames of the wire protocol (see
+// golog.NewWireWriter) or as JSON lines, such as the output of
+// golog.NewJSONWriter, or over HTTP, by POSTing the same streams. The
+// collector detects the format of each stream.
+//
+// Received entries go through the Logger's pipeline, its level, enrichers,
+// silence windows, and retention rules, and fan out to its writer, e.g. the
+// outputs set up with golog.Configure:
+//
+//	c := collector.New(golog.Default())
+//	listener, err := net.Listen("tcp", ":7070")
+//	if err != nil {
+//	    return err
+//	}
+//	go c.Serve(listener)
+//	http.Handle("/ingest", c)
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/netauth"
+)
+
+// ContentTypeWire is the Content-Type of HTTP requests whose body is a
+// stream of wire protocol frames. Other bodies are read as JSON lines.
+const ContentTypeWire = "application/x-golog-wire"
+
+const (
+	// maxLineSize is the size of the largest JSON line accepted.
+	maxLineSize = 1 << 20
+	// maxAuthBodySize is the size of the largest body of an authenticated
+	// request, which is read in full to verify its signature.
+	maxAuthBodySize = 64 << 20
+)
+
+// ErrClosed is returned by Serve after Close.
+var ErrClosed = errors.New("collector: closed")
+
+// Option configures a Collector.
+type Option func(*options)
+
+// options holds the settings of a Collector.
+type options struct {
+	// transform is applied to every entry before it is written
+	transform func(entry *golog.Entry) bool
+	// errorHandler receives invalid entries and stream errors
+	errorHandler func(error)
+	// auth verifies the credentials of HTTP requests
+	auth *netauth.Auth
+}
+
+// Transform sets a function applied to every received entry before it is
+// written. It can modify the entry, e.g. to add the source host or remove
+// fields, and returns false to drop it.
+func Transform(transform func(entry *golog.Entry) bool) Option {
+	return func(o *options) {
+		o.transform = transform
+	}
+}
+
+// Authenticate makes ServeHTTP verify the credentials of every request with
+// auth, answering 401 Unauthorized to requests failing it. Bodies are read in
+// full, up to 64 MiB, before being verified. TCP streams are authenticated
+// with mutual TLS instead: serve a listener created with netauth.Listen.
+func Authenticate(auth netauth.Auth) Option {
+	return func(o *options) {
+		o.auth = &auth
+	}
+}
+
+// OnError sets the handler that receives invalid entries and stream errors.
+// By default they are printed to os.Stderr. They are not logged through the
+// Logger, so that a failing sink cannot feed itself.
+func OnError(handler func(error)) Option {
+	return func(o *options) {
+		o.errorHandler = handler
+	}
+}
+
+// Collector receives entries over TCP and HTTP and writes them through a
+// Logger. It is safe for concurrent use.
+type Collector struct {
+	logger *golog.Logger
+	opts   options
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	// active tracks the connections being served
+	active sync.WaitGroup
+}
+
+// New creates a Collector writing through lg, or through the default Logger
+// when lg is nil.
+func New(lg *golog.Logger, opts ...Option) *Collector {
+	if lg == nil {
+		lg = golog.Default()
+	}
+
+	o := options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return &Collector{
+		logger:    lg,
+		opts:      o,
+		listeners: map[net.Listener]struct{}{},
+		conns:     map[net.Conn]struct{}{},
+	}
+}
+
+// Serve accepts TCP connections on l and reads a stream of entries from
+// each, until l fails or the Collector is closed. Pass a listener created
+// with netauth.Listen to require TLS, and client certificates. It always returns a
+// non-nil error: ErrClosed after Close.
+func (c *Collector) Serve(l net.Listener) error {
+	if !c.addListener(l) {
+		return ErrClosed
+	}
+	defer c.removeListener(l)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if c.isClosed() {
+				return ErrClosed
+			}
+
+			return err
+		}
+
+		if !c.addConn(conn) {
+			conn.Close()
+			return ErrClosed
+		}
+
+		go func() {
+			defer c.removeConn(conn)
+
+			if err := c.readStream(context.Background(), conn); err != nil && !c.isClosed() {
+				c.handleError(fmt.Errorf("collector: read stream from %s: %w", conn.RemoteAddr(), err))
+			}
+		}()
+	}
+}
+
+// ServeHTTP implements http.Handler. It accepts POST requests whose body is
+// a stream of entries, wire protocol frames when the Content-Type is
+// ContentTypeWire, and answers 204 No Content once the entries are written,
+// 400 Bad Request if the stream is invalid, or 401 Unauthorized if the
+// request fails the authentication set with Authenticate.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body io.Reader = r.Body
+	if c.opts.auth != nil {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxAuthBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := c.opts.auth.Verify(r, data); err != nil {
+			c.handleError(fmt.Errorf("collector: request from %s: %w", r.RemoteAddr, err))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body = bytes.NewReader(data)
+	}
+
+	var err error
+	if r.Header.Get("Content-Type") == ContentTypeWire {
+		err = c.readFrames(r.Context(), golog.NewWireReader(body))
+	} else {
+		err = c.readLines(r.Context(), body)
+	}
+
+	if err != nil {
+		c.handleError(fmt.Errorf("collector: read request from %s: %w", r.RemoteAddr, err))
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Close stops the listeners passed to Serve, closes the open connections,
+// waits until they are done, and flushes the Logger.
+func (c *Collector) Close() error {
+	c.mu.Lock()
+	c.closed = true
+	for l := range c.listeners {
+		l.Close()
+	}
+	for conn := range c.conns {
+		conn.Close()
+	}
+	c.mu.Unlock()
+
+	c.active.Wait()
+	c.logger.Flush()
+
+	return nil
+}
+
+// readStream reads entries from a connection, detecting whether it carries
+// wire protocol frames or JSON lines.
+func (c *Collector) readStream(ctx context.Context, r io.Reader) error {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(4)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return c.readLines(ctx, br)
+		}
+
+		return err
+	}
+
+	if string(magic) == "GLOG" {
+		return c.readFrames(ctx, golog.NewWireReader(br))
+	}
+
+	return c.readLines(ctx, br)
+}
+
+// readFrames writes the entries of the frames read from r.
+func (c *Collector) readFrames(ctx context.Context, r *golog.WireReader) error {
+	for {
+		lines, err := r.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := c.readLines(ctx, bytes.NewReader(lines)); err != nil {
+			return err
+		}
+	}
+}
+
+// readLines writes the entries of the JSON lines read from r. Invalid lines
+// are reported to the error handler and skipped.
+func (c *Collector) readLines(ctx context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		entry, err := parseEntry(line)
+		if err != nil {
+			c.handleError(fmt.Errorf("collector: invalid entry: %w", err))
+			continue
+		}
+
+		c.write(ctx, entry)
+	}
+
+	return scanner.Err()
+}
+
+// write writes a received entry through the Logger.
+func (c *Collector) write(ctx context.Context, entry golog.Entry) {
+	if c.opts.transform != nil && !c.opts.transform(&entry) {
+		return
+	}
+
+	scope := c.logger.WithContext(ctx).WithFields(entry.Fields)
+	if !entry.Time.IsZero() {
+		scope.WithTime(entry.Time)
+	}
+
+	scope.Log(entry.Level, entry.Message)
+}
+
+// parseEntry parses a JSON line written by golog's JSON writer. The time,
+// level, and msg keys become the entry's time, level, and message; the other
+// keys, including caller, are kept as fields. Unknown levels are read as
+// info.
+func parseEntry(line []byte) (golog.Entry, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return golog.Entry{}, err
+	}
+
+	entry := golog.Entry{Level: golog.LevelInfo, Fields: fields}
+
+	if msg, ok := fields[golog.FieldMessage].(string); ok {
+		entry.Message = msg
+		delete(fields, golog.FieldMessage)
+	}
+
+	if name, ok := fields[golog.FieldLevel].(string); ok {
+		if level := golog.ParseLevel(name); level >= 0 {
+			entry.Level = level
+		}
+		delete(fields, golog.FieldLevel)
+	}
+
+	if value, ok := fields[golog.FieldTime].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			entry.Time = t
+			delete(fields, golog.FieldTime)
+		}
+	}
+
+	return entry, nil
+}
+
+// addListener tracks l for Close, unless the Collector is closed.
+func (c *Collector) addListener(l net.Listener) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	c.listeners[l] = struct{}{}
+
+	return true
+}
+
+// removeListener stops tracking l.
+func (c *Collector) removeListener(l net.Listener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.listeners, l)
+}
+
+// addConn tracks conn for Close, unless the Collector is closed.
+func (c *Collector) addConn(conn net.Conn) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return false
+	}
+
+	c.conns[conn] = struct{}{}
+	c.active.Add(1)
+
+	return true
+}
+
+// removeConn closes conn and stops tracking it.
+func (c *Collector) removeConn(conn net.Conn) {
+	conn.Close()
+
+	c.mu.Lock()
+	delete(c.conns, conn)
+	c.mu.Unlock()
+
+	c.active.Done()
+}
+
+// handleError reports err to the error handler.
+func (c *Collector) handleError(err error) {
+	if c.opts.errorHandler != nil {
+		c.opts.errorHandler(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%v\n", err)
+}
+
+// isClosed reports whether Close was called.
+func (c *Collector) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closed
+}
@@ -0,0 +1,301 @@
+package collector
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/netauth"
+)
+
+// captureWriter records the entries written to it.
+type captureWriter struct {
+	mu      sync.Mutex
+	entries []golog.Entry
+}
+
+func (w *captureWriter) Write(level int, msg string, fields map[string]any) {
+	w.WriteEntry(golog.Entry{Level: level, Message: msg, Fields: fields})
+}
+
+func (w *captureWriter) WriteEntry(entry golog.Entry) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	fields := make(map[string]any, len(entry.Fields))
+	for k, v := range entry.Fields {
+		fields[k] = v
+	}
+	entry.Fields = fields
+
+	w.entries = append(w.entries, entry)
+}
+
+func (w *captureWriter) Flush() {}
+
+func (w *captureWriter) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return len(w.entries)
+}
+
+// jsonLines returns the entries written by a JSON writer.
+func jsonLines(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := golog.NewJSONWriter(buf)
+	w.WriteEntry(golog.Entry{
+		Time:    time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC),
+		Level:   golog.LevelWarn,
+		Message: "disk 95% full",
+		Fields:  map[string]any{"host": "web-1"},
+	})
+	w.Write(golog.LevelFatal, "out of memory", nil)
+	w.Flush()
+
+	return buf.Bytes()
+}
+
+// wireFrames returns the entries written by a wire writer using gzip.
+func wireFrames(t *testing.T) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w, err := golog.NewWireWriter(buf, golog.WireCodec(golog.CodecGzip))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	w.WriteEntry(golog.Entry{
+		Time:    time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC),
+		Level:   golog.LevelWarn,
+		Message: "disk 95% full",
+		Fields:  map[string]any{"host": "web-1"},
+	})
+	w.Write(golog.LevelFatal, "out of memory", nil)
+	w.Flush()
+
+	return buf.Bytes()
+}
+
+// verifyEntries checks the entries written by jsonLines and wireFrames.
+func verifyEntries(t *testing.T, sink *captureWriter) {
+	t.Helper()
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("len(sink.entries) = %d, want 2", len(sink.entries))
+	}
+	if sink.entries[0].Level != golog.LevelWarn {
+		t.Errorf("sink.entries[0].Level = %v, want %v", sink.entries[0].Level, golog.LevelWarn)
+	}
+	if sink.entries[0].Message != "disk 95% full" {
+		t.Errorf("sink.entries[0].Message = %q, want %q", sink.entries[0].Message, "disk 95% full")
+	}
+	if sink.entries[0].Fields["host"] != "web-1" {
+		t.Errorf(`sink.entries[0].Fields["host"] = %q, want %q`, sink.entries[0].Fields["host"], "web-1")
+	}
+	if got, _ := sink.entries[0].Fields[golog.FieldCaller].(string); !strings.Contains(got, "collector_test.go") {
+		t.Errorf("sink.entries[0].Fields[golog.FieldCaller] = %q, want it to contain %q", got, "collector_test.go")
+	}
+	want := time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC)
+	if got := sink.entries[0].Time.UTC(); !reflect.DeepEqual(got, want) {
+		t.Errorf("sink.entries[0].Time.UTC() = %v, want %v", got, want)
+	}
+	if sink.entries[1].Level != golog.LevelFatal {
+		t.Errorf("forwarded without exiting: sink.entries[1].Level = %v, want %v", sink.entries[1].Level, golog.LevelFatal)
+	}
+}
+
+func TestCollector_Serve(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream func(t *testing.T) []byte
+	}{
+		{name: "json-lines", stream: jsonLines},
+		{name: "wire", stream: wireFrames},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &captureWriter{}
+			c := New(golog.New(golog.LoggerWriter(sink)))
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			served := make(chan error, 1)
+			go func() { served <- c.Serve(listener) }()
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = conn.Write(tt.stream(t))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := conn.Close(); err != nil {
+				t.Fatalf("conn.Close(): %v", err)
+			}
+
+			deadline := time.Now().Add(time.Second)
+			for sink.len() != 2 {
+				if time.Now().After(deadline) {
+					t.Fatalf("sink.len() = %d, want 2", sink.len())
+				}
+				time.Sleep(time.Millisecond)
+			}
+			if err := c.Close(); err != nil {
+				t.Fatalf("c.Close(): %v", err)
+			}
+			if err := <-served; !errors.Is(err, ErrClosed) {
+				t.Errorf("<-served = %v, want %v", err, ErrClosed)
+			}
+
+			verifyEntries(t, sink)
+		})
+	}
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		stream      func(t *testing.T) []byte
+	}{
+		{name: "json-lines", contentType: "application/x-ndjson", stream: jsonLines},
+		{name: "wire", contentType: ContentTypeWire, stream: wireFrames},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &captureWriter{}
+			c := New(golog.New(golog.LoggerWriter(sink)))
+
+			req := httptest.NewRequest(http.MethodPost, "/ingest", bytes.NewReader(tt.stream(t)))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("rec.Code = %v, want %v", rec.Code, http.StatusNoContent)
+			}
+			verifyEntries(t, sink)
+		})
+	}
+}
+
+func TestCollector_ServeHTTP_Invalid(t *testing.T) {
+	var errs []error
+	c := New(golog.New(golog.LoggerWriter(&captureWriter{})), OnError(func(err error) { errs = append(errs, err) }))
+
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ingest", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("rec.Code = %v, want %v", rec.Code, http.StatusMethodNotAllowed)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader("GLOG\x09"))
+	req.Header.Set("Content-Type", ContentTypeWire)
+	rec = httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("rec.Code = %v, want %v", rec.Code, http.StatusBadRequest)
+	}
+	if len(errs) != 1 {
+		t.Errorf("len(errs) = %d, want 1", len(errs))
+	}
+}
+
+func TestCollector_TransformAndInvalidLines(t *testing.T) {
+	sink := &captureWriter{}
+	var errs []error
+	c := New(
+		golog.New(golog.LoggerWriter(sink)),
+		Transform(func(entry *golog.Entry) bool {
+			entry.Fields["source"] = "collector"
+			return entry.Message != "health check"
+		}),
+		OnError(func(err error) { errs = append(errs, err) }),
+	)
+
+	body := `{"level":"INFO","msg":"health check"}
+not json
+{"level":"ERROR","msg":"payment failed","order_id":42}
+{"level":"DEBUG","msg":"below the logger level"}
+`
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/ingest", strings.NewReader(body)))
+
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("rec.Code = %v, want %v", rec.Code, http.StatusNoContent)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("len(errs) = %d, want 1", len(errs))
+	}
+	if len(sink.entries) != 1 {
+		t.Fatalf("len(sink.entries) = %d, want 1", len(sink.entries))
+	}
+	if sink.entries[0].Message != "payment failed" {
+		t.Errorf("sink.entries[0].Message = %q, want %q", sink.entries[0].Message, "payment failed")
+	}
+	if sink.entries[0].Level != golog.LevelError {
+		t.Errorf("sink.entries[0].Level = %v, want %v", sink.entries[0].Level, golog.LevelError)
+	}
+	if sink.entries[0].Fields["order_id"] != float64(42) {
+		t.Errorf(`sink.entries[0].Fields["order_id"] = %v, want %v`, sink.entries[0].Fields["order_id"], float64(42))
+	}
+	if sink.entries[0].Fields["source"] != "collector" {
+		t.Errorf(`sink.entries[0].Fields["source"] = %q, want %q`, sink.entries[0].Fields["source"], "collector")
+	}
+}
+
+func TestCollector_Authenticate(t *testing.T) {
+	auth := netauth.Auth{HMACKey: []byte("shared-key")}
+	body := []byte(`{"level":"INFO","msg":"order placed"}` + "\n")
+
+	tests := []struct {
+		name   string
+		signer netauth.Auth
+		code   int
+		stored int
+	}{
+		{name: "signed", signer: auth, code: http.StatusNoContent, stored: 1},
+		{name: "wrong-key", signer: netauth.Auth{HMACKey: []byte("other")}, code: http.StatusUnauthorized},
+		{name: "unsigned", code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink := &captureWriter{}
+			c := New(golog.New(golog.LoggerWriter(sink)), Authenticate(auth), OnError(func(error) {}))
+
+			req := httptest.NewRequest(http.MethodPost, "/ingest", bytes.NewReader(body))
+			tt.signer.Sign(req, body)
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, req)
+
+			if rec.Code != tt.code {
+				t.Errorf("rec.Code = %v, want %v", rec.Code, tt.code)
+			}
+			if len(sink.entries) != tt.stored {
+				t.Errorf("len(sink.entries) = %d, want %d", len(sink.entries), tt.stored)
+			}
+		})
+	}
+}
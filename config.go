@@ -0,0 +1,315 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Output formats accepted by OutputConfig.Format
+const (
+	// FormatText is the human-readable format of NewDefaultWriter
+	FormatText = "text"
+	// FormatJSON is the JSON lines format of NewJSONWriter
+	FormatJSON = "json"
+)
+
+// Config describes the logging setup applied by Configure.
+type Config struct {
+	// Outputs lists where entries are written. At least one is required.
+	Outputs []OutputConfig
+	// Silence lists the silence windows set with SetSilenceWindows. When it
+	// is empty, the current windows are kept.
+	Silence []SilenceConfig
+}
+
+// SilenceConfig describes a SilenceWindow of Configure.
+type SilenceConfig struct {
+	// Name identifies the window in its summary
+	Name string
+	// Start and End bound the window, in RFC 3339 format. Empty means the
+	// window is open from the start and until it is removed, respectively.
+	Start string
+	End   string
+	// Level restricts the window to entries at this level, as accepted by
+	// ParseLevel. Empty matches every level.
+	Level string
+	// Message restricts the window to entries whose message contains it.
+	// Empty matches every message.
+	Message string
+	// Action is SilenceSuppress (or empty) or SilenceDowngrade.
+	Action string
+}
+
+// OutputConfig describes one output of Configure.
+type OutputConfig struct {
+	// Path is "stdout" (or empty), "stderr", or the path of a file that is
+	// appended to with NewFileWriter. A path with a template action, such as
+	// "/var/log/jobs/{{.Fields.job_name}}.log", partitions the entries into
+	// files with NewPartitionedFileWriter.
+	Path string
+	// Format is FormatText (or empty) or FormatJSON.
+	Format string
+	// Level is the minimum level written to this output, as accepted by
+	// ParseLevel ("debug", "info", "warn", "error", ...). Empty means "info".
+	Level string
+	// Compression is the name of the codec compressing a file output (see
+	// RegisterCodec and FileCompression). Empty writes uncompressed entries.
+	Compression string
+	// TimeZone is the time zone of the timestamps of this output (see
+	// TimeZone): "UTC" (or empty), "Local" for the zone of the host, or an
+	// IANA zone name such as "Europe/Paris".
+	TimeZone string
+	// Optional lets Configure continue without this output when it cannot
+	// be opened, e.g. a file on a network mount that is not available at
+	// startup, instead of failing. A warning entry reports the skipped
+	// output. Invalid settings still fail.
+	Optional bool
+}
+
+// Configure sets up logging in a single call: it opens every output of cfg
+// with its own format and minimum level, joins them with NewMultiWriter and
+// LevelFilter, installs them as the writer of the default Logger, and sets
+// its minimum level to the lowest output level. Every entry is written to
+// each output whose level it meets. The writer replaced is flushed, and
+// closed if it implements io.Closer, such as the files of a previous call.
+// Configure returns the default Logger.
+//
+// Example:
+//
+//	logger, err := golog.Configure(golog.Config{
+//	    Outputs: []golog.OutputConfig{
+//	        {Path: "stdout", Format: golog.FormatText, Level: "info"},
+//	        {Path: "/var/log/app.log", Format: golog.FormatJSON, Level: "debug"},
+//	    },
+//	})
+//
+// Configure returns an error, and leaves the current configuration in place,
+// if cfg has no outputs, an output has an unknown format, level, time zone,
+// or compression codec, a file of an output that is not Optional cannot be
+// opened, no output can be opened, or a silence window is invalid.
+func Configure(cfg Config) (*Logger, error) {
+	if len(cfg.Outputs) == 0 {
+		return nil, errors.New("golog: config has no outputs")
+	}
+
+	windows := make([]SilenceWindow, 0, len(cfg.Silence))
+	for i, sc := range cfg.Silence {
+		w, err := newSilenceWindow(sc)
+		if err != nil {
+			return nil, fmt.Errorf("golog: silence window %d: %w", i, err)
+		}
+
+		windows = append(windows, w)
+	}
+
+	var writers []LogWriter
+	minimum := LevelError
+	skipped := make([]error, len(cfg.Outputs))
+	for i, out := range cfg.Outputs {
+		o, err := newOutput(out)
+
+		var unavailable *unavailableError
+		if err != nil && out.Optional && errors.As(err, &unavailable) {
+			skipped[i] = err
+			continue
+		}
+
+		if err != nil {
+			closeWriters(writers)
+			return nil, fmt.Errorf("golog: output %d: %w", i, err)
+		}
+
+		writers = append(writers, LevelFilter(o.writer, o.level))
+		minimum = min(minimum, o.level)
+	}
+
+	if len(writers) == 0 {
+		return nil, errors.New("golog: no output could be opened")
+	}
+
+	// swap the writer and the level at once, so that no entry is written to
+	// the new writer with the old level
+	var replaced LogWriter
+	std.update(func(cfg *loggerConfig) {
+		replaced = cfg.writer
+		cfg.writer = NewMultiWriter(writers...)
+		cfg.level = minimum
+	})
+
+	if replaced != nil {
+		replaced.Flush()
+		closeWriters([]LogWriter{replaced})
+	}
+
+	for i, err := range skipped {
+		if err == nil {
+			continue
+		}
+
+		WithError(err).WithFields(map[string]any{
+			"output": i,
+			"path":   cfg.Outputs[i].Path,
+		}).Warn("golog skipped optional output")
+	}
+
+	if len(windows) > 0 {
+		SetSilenceWindows(windows...)
+	}
+
+	return std, nil
+}
+
+// closeWriters closes the writers that implement io.Closer.
+func closeWriters(writers []LogWriter) error {
+	var errs []error
+	for _, w := range writers {
+		if closer, ok := w.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// newSilenceWindow builds the SilenceWindow of a SilenceConfig.
+func newSilenceWindow(cfg SilenceConfig) (SilenceWindow, error) {
+	w := SilenceWindow{Name: cfg.Name, Action: strings.ToLower(cfg.Action)}
+
+	if w.Action != "" && w.Action != SilenceSuppress && w.Action != SilenceDowngrade {
+		return SilenceWindow{}, fmt.Errorf("unknown action %q (want %q or %q)", cfg.Action, SilenceSuppress, SilenceDowngrade)
+	}
+
+	for _, bound := range []struct {
+		value string
+		t     *time.Time
+	}{{cfg.Start, &w.Start}, {cfg.End, &w.End}} {
+		if bound.value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, bound.value)
+		if err != nil {
+			return SilenceWindow{}, err
+		}
+
+		*bound.t = t
+	}
+
+	level := -1
+	if cfg.Level != "" {
+		level = ParseLevel(cfg.Level)
+		if level < 0 {
+			return SilenceWindow{}, fmt.Errorf("unknown level %q", cfg.Level)
+		}
+	}
+
+	if level >= 0 || cfg.Message != "" {
+		w.Match = func(l int, msg string, _ map[string]any) bool {
+			return (level < 0 || l == level) && strings.Contains(msg, cfg.Message)
+		}
+	}
+
+	return w, nil
+}
+
+// newOutput builds the writer and level for one OutputConfig.
+func newOutput(cfg OutputConfig) (output, error) {
+	levelName := cfg.Level
+	if levelName == "" {
+		levelName = "info"
+	}
+
+	level := ParseLevel(levelName)
+	if level < 0 {
+		return output{}, fmt.Errorf("unknown level %q", cfg.Level)
+	}
+
+	format := strings.ToLower(cfg.Format)
+	if format != "" && format != FormatText && format != FormatJSON {
+		return output{}, fmt.Errorf("unknown format %q (want %q or %q)", cfg.Format, FormatText, FormatJSON)
+	}
+
+	location := time.UTC
+	if cfg.TimeZone != "" {
+		var err error
+		if location, err = time.LoadLocation(cfg.TimeZone); err != nil {
+			return output{}, fmt.Errorf("unknown time zone %q: %w", cfg.TimeZone, err)
+		}
+	}
+
+	writerOpts := []WriterOption{TimeZone(location)}
+
+	var console io.Writer
+	switch strings.ToLower(strings.TrimSpace(cfg.Path)) {
+	case "", "stdout":
+		console = os.Stdout
+	case "stderr":
+		console = os.Stderr
+	}
+
+	if console == nil {
+		opts := []FileOption{FileWriterOptions(writerOpts...)}
+		if format != FormatJSON {
+			opts = append(opts, FileTextFormat())
+		}
+		if cfg.Compression != "" {
+			if _, err := lookupCodec(cfg.Compression); err != nil {
+				return output{}, err
+			}
+
+			opts = append(opts, FileCompression(cfg.Compression))
+		}
+
+		if strings.Contains(cfg.Path, "{{") {
+			partitioned, err := NewPartitionedFileWriter(cfg.Path, opts...)
+			if err != nil {
+				return output{}, err
+			}
+
+			return output{writer: partitioned, level: level}, nil
+		}
+
+		file, err := NewFileWriter(cfg.Path, opts...)
+		if err != nil {
+			return output{}, &unavailableError{err: err}
+		}
+
+		return output{writer: file, level: level}, nil
+	}
+
+	if cfg.Compression != "" {
+		return output{}, fmt.Errorf("compression is only supported for files, not %q", cfg.Path)
+	}
+
+	// Hide the Close method so that Flush does not close stdout or stderr.
+	console = struct{ io.Writer }{console}
+	if format == FormatJSON {
+		return output{writer: NewJSONWriter(console, writerOpts...), level: level}, nil
+	}
+
+	return output{writer: NewDefaultWriter(console, writerOpts...), level: level}, nil
+}
+
+// unavailableError reports an output that is configured correctly but cannot
+// be opened.
+type unavailableError struct {
+	err error
+}
+
+func (e *unavailableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *unavailableError) Unwrap() error {
+	return e.err
+}
+
+// output is the writer and the minimum level of an OutputConfig.
+type output struct {
+	writer LogWriter
+	level  int
+}
@@ -0,0 +1,230 @@
+package golog
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigure(t *testing.T) {
+	useWriter(t, std.Writer())
+	originalMinLevel := std.Level()
+	t.Cleanup(func() { std.SetLevel(originalMinLevel) })
+
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "debug.json")
+	textPath := filepath.Join(dir, "errors.log")
+
+	logger, err := Configure(Config{
+		Outputs: []OutputConfig{
+			{Path: jsonPath, Format: FormatJSON, Level: "debug"},
+			{Path: textPath, Level: "error"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Default()
+	if logger != want {
+		t.Errorf("logger = %p, want %p", logger, want)
+	}
+	if got := std.Level(); got != LevelDebug {
+		t.Errorf("std.Level() = %v, want %v", got, LevelDebug)
+	}
+
+	Debug("debug entry")
+	Error("error entry")
+	Flush()
+
+	jsonLines := readLines(t, jsonPath)
+	if len(jsonLines) != 2 {
+		t.Fatalf("len(jsonLines) = %d, want 2", len(jsonLines))
+	}
+	if !strings.Contains(jsonLines[0], `"msg":"debug entry"`) {
+		t.Errorf("jsonLines[0] = %q, want it to contain %q", jsonLines[0], `"msg":"debug entry"`)
+	}
+	if !strings.Contains(jsonLines[1], `"msg":"error entry"`) {
+		t.Errorf("jsonLines[1] = %q, want it to contain %q", jsonLines[1], `"msg":"error entry"`)
+	}
+
+	textLines := readLines(t, textPath)
+	if len(textLines) != 1 {
+		t.Fatalf("len(textLines) = %d, want 1", len(textLines))
+	}
+	if !strings.Contains(textLines[0], "[ERROR]") {
+		t.Errorf("textLines[0] = %q, want it to contain %q", textLines[0], "[ERROR]")
+	}
+	if !strings.Contains(textLines[0], "error entry") {
+		t.Errorf("textLines[0] = %q, want it to contain %q", textLines[0], "error entry")
+	}
+}
+
+func TestConfigure_PartitionedFile(t *testing.T) {
+	useWriter(t, std.Writer())
+
+	dir := t.TempDir()
+	_, err := Configure(Config{
+		Outputs: []OutputConfig{{Path: filepath.Join(dir, "{{.Fields.job}}.log"), Format: FormatJSON}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	With("job", "billing").Info("started")
+	With("job", "reports").Info("started")
+	Flush()
+
+	if got := readLines(t, filepath.Join(dir, "billing.log")); len(got) != 1 {
+		t.Errorf(`len(readLines(t, filepath.Join(dir, "billing.log"))) = %d, want 1`, len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, "reports.log")); len(got) != 1 {
+		t.Errorf(`len(readLines(t, filepath.Join(dir, "reports.log"))) = %d, want 1`, len(got))
+	}
+}
+
+func TestConfigure_Optional(t *testing.T) {
+	useWriter(t, std.Writer())
+	originalMinLevel := std.Level()
+	t.Cleanup(func() { std.SetLevel(originalMinLevel) })
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.json")
+	missing := filepath.Join(dir, "missing", "app.log")
+
+	_, err := Configure(Config{
+		Outputs: []OutputConfig{
+			{Path: missing, Optional: true},
+			{Path: path, Format: FormatJSON},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	Info("started")
+	Flush()
+
+	lines := readLines(t, path)
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %d, want 2", len(lines))
+	}
+	if !strings.Contains(lines[0], `"level":"WARN"`) {
+		t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"level":"WARN"`)
+	}
+	if !strings.Contains(lines[0], `"msg":"golog skipped optional output"`) {
+		t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"msg":"golog skipped optional output"`)
+	}
+	if !strings.Contains(lines[0], `"output":0`) {
+		t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"output":0`)
+	}
+	if !strings.Contains(lines[1], `"msg":"started"`) {
+		t.Errorf("lines[1] = %q, want it to contain %q", lines[1], `"msg":"started"`)
+	}
+}
+
+// closingWriter is a captureWriter recording whether it was closed.
+type closingWriter struct {
+	captureWriter
+	closed bool
+}
+
+func (w *closingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestConfigure_ReplacedWriter(t *testing.T) {
+	useWriter(t, std.Writer())
+	originalMinLevel := std.Level()
+	t.Cleanup(func() { std.SetLevel(originalMinLevel) })
+
+	previous := &closingWriter{}
+	SetWriter(previous)
+
+	_, err := Configure(Config{
+		Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "app.log")}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if previous.flushes != 1 {
+		t.Errorf("the replaced writer is flushed: previous.flushes = %v, want 1", previous.flushes)
+	}
+	if !previous.closed {
+		t.Error("the replaced writer is closed")
+	}
+}
+
+func TestConfigure_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "no-outputs",
+			cfg:  Config{},
+		},
+		{
+			name: "unknown-level",
+			cfg:  Config{Outputs: []OutputConfig{{Level: "verbose"}}},
+		},
+		{
+			name: "unknown-format",
+			cfg:  Config{Outputs: []OutputConfig{{Format: "xml"}}},
+		},
+		{
+			name: "unknown-compression",
+			cfg:  Config{Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "app.log"), Compression: "brotli"}}},
+		},
+		{
+			name: "invalid-path-template",
+			cfg:  Config{Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "{{.Fields.job")}}},
+		},
+		{
+			name: "unknown-time-zone",
+			cfg:  Config{Outputs: []OutputConfig{{TimeZone: "Mars/Olympus_Mons"}}},
+		},
+		{
+			name: "compressed-stdout",
+			cfg:  Config{Outputs: []OutputConfig{{Path: "stdout", Compression: CodecGzip}}},
+		},
+		{
+			name: "unknown-silence-action",
+			cfg:  Config{Outputs: []OutputConfig{{}}, Silence: []SilenceConfig{{Action: "mute"}}},
+		},
+		{
+			name: "invalid-silence-end",
+			cfg:  Config{Outputs: []OutputConfig{{}}, Silence: []SilenceConfig{{End: "tonight"}}},
+		},
+		{
+			name: "optional-with-unknown-format",
+			cfg:  Config{Outputs: []OutputConfig{{Format: "xml", Optional: true}, {}}},
+		},
+		{
+			name: "optional-with-unknown-compression",
+			cfg:  Config{Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "app.log"), Compression: "brotli", Optional: true}, {}}},
+		},
+		{
+			name: "no-output-opened",
+			cfg:  Config{Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "missing", "app.log"), Optional: true}}},
+		},
+		{
+			name: "unopenable-file",
+			cfg:  Config{Outputs: []OutputConfig{{Path: filepath.Join(t.TempDir(), "missing", "app.log")}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := std.Writer()
+			_, err := Configure(tt.cfg)
+			if err == nil {
+				t.Error("expected an error")
+			}
+			if got := std.Writer(); got != current {
+				t.Errorf("configuration is unchanged on error: std.Writer() = %p, want %p", got, current)
+			}
+		})
+	}
+}
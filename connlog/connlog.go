@@ -0,0 +1,216 @@
+// Package connlog logs the lifecycle of long-lived network connections, such
+// as raw TCP streams or WebSockets, for services where the connection rather
+// than the request is the unit of work.
+//
+// A wrapped connection logs an entry when it is opened and one when it is
+// closed, with the bytes transferred, the duration, and the error that ended
+// it, if any. Every entry carries the connection's fields (conn_id,
+// remote_addr, local_addr, and any added with Fields), and Conn.Logger
+// returns a scope with the same fields for the application's own entries.
+//
+// WebSocket libraries expose connections as net.Conn for this kind of
+// integration (for example websocket.NetConn), so they can be wrapped too.
+//
+// Example:
+//
+//	listener, err := net.Listen("tcp", ":9000")
+//	if err != nil {
+//	    return err
+//	}
+//
+//	listener = connlog.Listen(listener)
+//	for {
+//	    conn, err := listener.Accept()
+//	    if err != nil {
+//	        return err
+//	    }
+//
+//	    go serve(conn.(*connlog.Conn))
+//	}
+package connlog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"maps"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+// Field names used in connection entries
+const (
+	// FieldConnID is the key of the generated connection ID
+	FieldConnID = "conn_id"
+	// FieldRemoteAddr is the key of the remote address
+	FieldRemoteAddr = "remote_addr"
+	// FieldLocalAddr is the key of the local address
+	FieldLocalAddr = "local_addr"
+)
+
+// Option configures a wrapped connection.
+type Option func(*options)
+
+// options holds the settings of a wrapped connection.
+type options struct {
+	// fields are added to every entry of the connection
+	fields map[string]any
+}
+
+// Fields adds fields to every entry of the connection, such as the protocol
+// or the authenticated user.
+func Fields(fields map[string]any) Option {
+	return func(o *options) {
+		maps.Copy(o.fields, fields)
+	}
+}
+
+// Conn is a net.Conn that logs its lifecycle. It is safe for concurrent use
+// like the connection it wraps.
+type Conn struct {
+	net.Conn
+
+	fields  map[string]any
+	start   time.Time
+	read    atomic.Int64
+	written atomic.Int64
+
+	mu    sync.Mutex
+	cause error
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Wrap returns a Conn that logs "connection opened" right away and
+// "connection closed" when it is closed.
+func Wrap(conn net.Conn, opts ...Option) *Conn {
+	o := options{fields: map[string]any{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	o.fields[FieldConnID] = newConnID()
+	if addr := conn.RemoteAddr(); addr != nil {
+		o.fields[FieldRemoteAddr] = addr.String()
+	}
+	if addr := conn.LocalAddr(); addr != nil {
+		o.fields[FieldLocalAddr] = addr.String()
+	}
+
+	c := &Conn{Conn: conn, fields: o.fields, start: time.Now()}
+	c.Logger().Info("connection opened")
+
+	return c
+}
+
+// Logger returns a new scope carrying the connection's fields. Use it for
+// the application's entries about this connection.
+func (c *Conn) Logger() *golog.LogScope {
+	return golog.WithFields(c.fields)
+}
+
+// Read implements net.Conn, counting the bytes read.
+func (c *Conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.read.Add(int64(n))
+	c.record(err)
+
+	return n, err
+}
+
+// Write implements net.Conn, counting the bytes written.
+func (c *Conn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.written.Add(int64(n))
+	c.record(err)
+
+	return n, err
+}
+
+// Close implements net.Conn. The first call closes the connection and logs
+// "connection closed" with the bytes read and written and the duration; if
+// a read or write failed, the entry is an error entry with the first failure
+// as its "error" field.
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.Conn.Close()
+
+		scope := c.Logger().WithFields(map[string]any{
+			"bytes_read":    c.read.Load(),
+			"bytes_written": c.written.Load(),
+			"duration":      time.Since(c.start).String(),
+		})
+
+		c.mu.Lock()
+		cause := c.cause
+		c.mu.Unlock()
+
+		if cause == nil {
+			scope.Info("connection closed")
+			return
+		}
+
+		_ = scope.With("error", cause.Error()).Error("connection closed")
+	})
+
+	return c.closeErr
+}
+
+// record keeps the first read or write error that indicates a failure
+// rather than the normal end of the connection.
+func (c *Conn) record(err error) {
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+		return
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		// deadlines are routinely used to poll connections
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cause == nil {
+		c.cause = err
+	}
+}
+
+// listener wraps the connections accepted by a net.Listener.
+type listener struct {
+	net.Listener
+	opts []Option
+}
+
+// Listen returns a net.Listener whose accepted connections are wrapped with
+// Wrap and opts, so they are *Conn values.
+func Listen(l net.Listener, opts ...Option) net.Listener {
+	return &listener{Listener: l, opts: opts}
+}
+
+// Accept implements net.Listener.
+func (l *listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return Wrap(conn, l.opts...), nil
+}
+
+// newConnID returns a random 16 character hex connection ID.
+func newConnID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,164 @@
+package connlog
+
+import (
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/sinktest"
+)
+
+// failingConn is a net.Conn whose reads fail.
+type failingConn struct {
+	net.Conn
+}
+
+func (failingConn) Read([]byte) (int, error) { return 0, errors.New("connection reset by peer") }
+
+func TestConn(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+
+	server, client := net.Pipe()
+	conn := Wrap(server, Fields(map[string]any{"protocol": "echo"}))
+
+	go func() {
+		client.Write([]byte("hello"))
+		buf := make([]byte, 5)
+		io.ReadFull(client, buf)
+		client.Close()
+	}()
+
+	buf := make([]byte, 5)
+	_, err := io.ReadFull(conn, buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = conn.Write(buf[:3])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	conn.Write(buf[3:])
+
+	_, err = conn.Read(buf)
+	if !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v, want %v", err, io.EOF)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("conn.Close(): %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("closing twice logs once: conn.Close(): %v", err)
+	}
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	opened := entries[0]
+	if opened.Message != "connection opened" {
+		t.Errorf("opened.Message = %q, want %q", opened.Message, "connection opened")
+	}
+	if opened.Fields["protocol"] != "echo" {
+		t.Errorf(`opened.Fields["protocol"] = %q, want %q`, opened.Fields["protocol"], "echo")
+	}
+	if id, _ := opened.Fields[FieldConnID].(string); len(id) != 16 {
+		t.Errorf("opened.Fields[FieldConnID] = %q, want 16 characters", id)
+	}
+	if opened.Fields[FieldRemoteAddr] != "pipe" {
+		t.Errorf("opened.Fields[FieldRemoteAddr] = %q, want %q", opened.Fields[FieldRemoteAddr], "pipe")
+	}
+
+	closed := entries[1]
+	if closed.Level != golog.LevelInfo {
+		t.Errorf("EOF is a normal end: closed.Level = %v, want %v", closed.Level, golog.LevelInfo)
+	}
+	if closed.Message != "connection closed" {
+		t.Errorf("closed.Message = %q, want %q", closed.Message, "connection closed")
+	}
+	if !reflect.DeepEqual(closed.Fields[FieldConnID], opened.Fields[FieldConnID]) {
+		t.Errorf("closed.Fields[FieldConnID] = %v, want %v", closed.Fields[FieldConnID], opened.Fields[FieldConnID])
+	}
+	if closed.Fields["bytes_read"] != int64(5) {
+		t.Errorf(`closed.Fields["bytes_read"] = %v, want %v`, closed.Fields["bytes_read"], int64(5))
+	}
+	if closed.Fields["bytes_written"] != int64(5) {
+		t.Errorf(`closed.Fields["bytes_written"] = %v, want %v`, closed.Fields["bytes_written"], int64(5))
+	}
+	if got, _ := closed.Fields["duration"].(string); got == "" {
+		t.Error(`closed.Fields["duration"] is empty`)
+	}
+}
+
+func TestConn_ErrorCause(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	conn := Wrap(failingConn{Conn: server})
+	conn.Logger().Info("handshake")
+
+	_, err := conn.Read(make([]byte, 1))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	conn.Close()
+
+	entries := recorder.Entries()
+	if len(entries) != 3 {
+		t.Fatalf("len(entries) = %d, want 3", len(entries))
+	}
+	if entries[1].Message != "handshake" {
+		t.Errorf("entries[1].Message = %q, want %q", entries[1].Message, "handshake")
+	}
+	if !reflect.DeepEqual(entries[1].Fields[FieldConnID], entries[0].Fields[FieldConnID]) {
+		t.Errorf("entries[1].Fields[FieldConnID] = %v, want %v", entries[1].Fields[FieldConnID], entries[0].Fields[FieldConnID])
+	}
+	if entries[2].Level != golog.LevelError {
+		t.Errorf("entries[2].Level = %v, want %v", entries[2].Level, golog.LevelError)
+	}
+	if entries[2].Fields["error"] != "connection reset by peer" {
+		t.Errorf(`entries[2].Fields["error"] = %q, want %q`, entries[2].Fields["error"], "connection reset by peer")
+	}
+}
+
+func TestListen(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	l = Listen(l)
+	defer l.Close()
+
+	go func() {
+		conn, err := net.DialTimeout("tcp", l.Addr().String(), time.Second)
+		if err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := conn.(*Conn); !ok {
+		t.Fatalf("conn = %T, want *Conn", conn)
+	}
+	conn.Close()
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+	want := l.Addr().String()
+	if entries[0].Fields[FieldLocalAddr] != want {
+		t.Errorf("entries[0].Fields[FieldLocalAddr] = %q, want %q", entries[0].Fields[FieldLocalAddr], want)
+	}
+}
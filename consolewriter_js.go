@@ -0,0 +1,99 @@
+//go:build js && wasm
+
+package golog
+
+import (
+	"fmt"
+	"syscall/js"
+	"time"
+)
+
+// consoleWriter implements the LogWriter interface by calling the browser's
+// console API.
+type consoleWriter struct {
+	console js.Value
+	opts    writerOptions
+}
+
+// NewConsoleWriter creates a LogWriter for Go programs compiled to
+// WebAssembly (GOOS=js GOARCH=wasm) that writes entries to the JavaScript
+// console: Debug entries with console.debug, Info entries with console.info,
+// and Error entries with console.error, so browser developer tools can filter
+// them by level. Custom fields and the caller location are passed as a
+// separate object argument, which the console shows as an expandable value.
+//
+// Fields that cannot be encoded as JSON are left out and reported to the
+// error handler (see OnError).
+//
+// Example:
+//
+//	golog.SetWriter(golog.NewConsoleWriter())
+func NewConsoleWriter(opts ...WriterOption) *consoleWriter {
+	return &consoleWriter{
+		console: js.Global().Get("console"),
+		opts:    newWriterOptions(opts),
+	}
+}
+
+// Write implements LogWriter.
+func (w *consoleWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *consoleWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write calls the console method of the entry's level with the message and
+// an object holding the fields.
+func (w *consoleWriter) write(entry Entry, file string, line int) {
+	values := make(map[string]any, len(entry.Fields)+2)
+	for k, v := range entry.Fields {
+		v = encodeField(w.opts.classifiedValue(v))
+		if err, ok := v.(error); ok {
+			values[k] = fmt.Sprintf("%+v", err)
+			continue
+		}
+
+		values[k] = w.opts.fieldValue(v)
+	}
+
+	values[FieldTime] = w.opts.timestamp(entry.Time)
+	values[FieldCaller] = fmt.Sprintf("%s:%d", file, line)
+
+	data, err := w.opts.marshal(values)
+	if err != nil {
+		w.opts.handleError(fmt.Errorf("golog: failed to marshal log entry: %w", err))
+		w.console.Call(consoleMethod(entry.Level), entry.Message)
+		return
+	}
+
+	w.console.Call(consoleMethod(entry.Level), entry.Message, js.Global().Get("JSON").Call("parse", string(data)))
+}
+
+// Flush implements LogWriter. The console is written synchronously, so there
+// is nothing to flush.
+func (w *consoleWriter) Flush() {}
+
+// Describe implements Describer.
+func (w *consoleWriter) Describe() WriterDescription {
+	return WriterDescription{Type: "console", Settings: w.opts.describe()}
+}
+
+// consoleMethod returns the console method used for level.
+func consoleMethod(level int) string {
+	switch level {
+	case LevelTrace, LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError, LevelPanic, LevelFatal:
+		return "error"
+	default:
+		return "info"
+	}
+}
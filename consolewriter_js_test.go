@@ -0,0 +1,56 @@
+//go:build js && wasm
+
+package golog
+
+import (
+	"reflect"
+	"strings"
+	"syscall/js"
+	"testing"
+)
+
+func TestConsoleWriter(t *testing.T) {
+	var calls []string
+	var args [][]js.Value
+
+	console := js.Global().Get("Object").New()
+	for _, method := range []string{"debug", "info", "error"} {
+		fn := js.FuncOf(func(_ js.Value, a []js.Value) any {
+			calls = append(calls, method)
+			args = append(args, a)
+			return nil
+		})
+		t.Cleanup(fn.Release)
+		console.Set(method, fn)
+	}
+
+	writer := NewConsoleWriter()
+	writer.console = console
+
+	writer.Write(LevelDebug, "cache miss", map[string]any{"key": "user:1", "size": 3})
+	writer.Write(LevelInfo, "page loaded", nil)
+	writer.Write(LevelError, "request failed", map[string]any{"invalid": make(chan int)})
+
+	want := []string{"debug", "info", "error"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if len(args[0]) != 2 {
+		t.Fatalf("len(args[0]) = %d, want 2", len(args[0]))
+	}
+	if got := args[0][0].String(); got != "cache miss" {
+		t.Errorf("args[0][0].String() = %q, want %q", got, "cache miss")
+	}
+	if got := args[0][1].Get("key").String(); got != "user:1" {
+		t.Errorf(`args[0][1].Get("key").String() = %q, want %q`, got, "user:1")
+	}
+	if got := args[0][1].Get("size").Int(); got != 3 {
+		t.Errorf(`args[0][1].Get("size").Int() = %v, want 3`, got)
+	}
+	if got := args[0][1].Get(FieldCaller).String(); !strings.Contains(got, ".go:") {
+		t.Errorf("args[0][1].Get(FieldCaller).String() = %q, want it to contain %q", got, ".go:")
+	}
+	if len(args[2]) != 1 {
+		t.Errorf("fields that fail to marshal are left out: len(args[2]) = %d, want 1", len(args[2]))
+	}
+}
@@ -0,0 +1,220 @@
+package golog
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// redactedValue replaces the values of redacted HTTP headers.
+const redactedValue = "[REDACTED]"
+
+// defaultRedactedHeaders lists the HTTP headers whose values are masked unless
+// RedactHeaders is set.
+var defaultRedactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// stringSlicesType is the underlying type of url.Values and http.Header.
+var stringSlicesType = reflect.TypeOf(map[string][]string(nil))
+
+// RedactHeaders sets the HTTP headers (case-insensitive) whose values are
+// written as "[REDACTED]" when an http.Header is logged as a field. It
+// replaces the default list: Authorization, Proxy-Authorization, Cookie,
+// Set-Cookie, X-Api-Key, and X-Auth-Token. Call it without names to log all
+// header values.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.RedactHeaders("Authorization", "X-Session"))
+func RedactHeaders(names ...string) WriterOption {
+	return func(o *writerOptions) {
+		o.redactedHeaders = make(map[string]bool, len(names))
+		for _, name := range names {
+			o.redactedHeaders[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// isRedactedHeader reports whether the value of the header name is masked.
+func (o writerOptions) isRedactedHeader(name string) bool {
+	if o.redactedHeaders != nil {
+		return o.redactedHeaders[strings.ToLower(name)]
+	}
+
+	for _, redacted := range defaultRedactedHeaders {
+		if strings.EqualFold(name, redacted) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// marshalContainer encodes the common string container types directly,
+// without reflection: map[string]string as an object of strings, and
+// url.Values, http.Header, and map[string][]string as objects of string
+// arrays. Header values listed by RedactHeaders are masked. Keys are sorted.
+// It reports false for other types.
+func (o writerOptions) marshalContainer(v any) ([]byte, bool) {
+	switch v := v.(type) {
+	case map[string]string:
+		return appendStringMap(nil, v), true
+	case url.Values:
+		return appendStringSlices(nil, v, nil), true
+	case map[string][]string:
+		return appendStringSlices(nil, v, nil), true
+	}
+
+	// http.Header is matched by name so that golog does not import net/http
+	t := reflect.TypeOf(v)
+	if t != nil && t.Name() == "Header" && t.PkgPath() == "net/http" && t.ConvertibleTo(stringSlicesType) {
+		header := reflect.ValueOf(v).Convert(stringSlicesType).Interface().(map[string][]string)
+		return appendStringSlices(nil, header, o.isRedactedHeader), true
+	}
+
+	return nil, false
+}
+
+// appendStringMap appends m to b as a JSON object with sorted keys.
+func appendStringMap(b []byte, m map[string]string) []byte {
+	if m == nil {
+		return append(b, "null"...)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		b = appendJSONString(b, k)
+		b = append(b, ':')
+		b = appendJSONString(b, m[k])
+	}
+
+	return append(b, '}')
+}
+
+// appendStringSlices appends m to b as a JSON object of string arrays with
+// sorted keys. The values of keys for which redact returns true are masked.
+func appendStringSlices(b []byte, m map[string][]string, redact func(string) bool) []byte {
+	if m == nil {
+		return append(b, "null"...)
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b = append(b, '{')
+	for i, k := range keys {
+		if i > 0 {
+			b = append(b, ',')
+		}
+
+		b = appendJSONString(b, k)
+		b = append(b, ':', '[')
+
+		masked := redact != nil && redact(k)
+		for j, value := range m[k] {
+			if j > 0 {
+				b = append(b, ',')
+			}
+
+			if masked {
+				value = redactedValue
+			}
+
+			b = appendJSONString(b, value)
+		}
+
+		b = append(b, ']')
+	}
+
+	return append(b, '}')
+}
+
+// hexDigits are used to escape control characters.
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString appends s to b as a JSON string, escaped like
+// encoding/json without HTML escaping; invalid UTF-8 becomes U+FFFD.
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+
+			i++
+			start = i
+
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+
+			continue
+		}
+
+		// U+2028 and U+2029 are escaped like encoding/json does
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+
+			continue
+		}
+
+		i += size
+	}
+
+	b = append(b, s[start:]...)
+
+	return append(b, '"')
+}
@@ -0,0 +1,109 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestJSONWriter_Containers(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer secret-token")
+	header.Set("Content-Type", "application/json")
+	header.Add("Cookie", "a=1")
+	header.Add("Cookie", "b=2")
+
+	tests := []struct {
+		name     string
+		opts     []WriterOption
+		value    any
+		expected string
+	}{
+		{
+			name:     "string-map",
+			value:    map[string]string{"region": "eu-west-1", "az": "b"},
+			expected: `{"az":"b","region":"eu-west-1"}`,
+		},
+		{
+			name:     "url-values",
+			value:    url.Values{"q": {"golog"}, "page": {"1", "2"}},
+			expected: `{"page":["1","2"],"q":["golog"]}`,
+		},
+		{
+			name:     "http-header-redacted-by-default",
+			value:    header,
+			expected: `{"Authorization":["[REDACTED]"],"Content-Type":["application/json"],"Cookie":["[REDACTED]","[REDACTED]"]}`,
+		},
+		{
+			name:     "http-header-custom-redaction",
+			opts:     []WriterOption{RedactHeaders("content-type")},
+			value:    header,
+			expected: `{"Authorization":["Bearer secret-token"],"Content-Type":["[REDACTED]"],"Cookie":["a=1","b=2"]}`,
+		},
+		{
+			name:     "nil-map",
+			value:    map[string]string(nil),
+			expected: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, tt.opts...)
+
+			writer.Write(LevelInfo, "containers", map[string]any{"value": tt.value})
+			if err := writer.flushBuffer(); err != nil {
+				t.Fatalf("writer.flushBuffer(): %v", err)
+			}
+
+			var entry map[string]json.RawMessage
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			if string(entry["value"]) != tt.expected {
+				t.Errorf(`string(entry["value"]) = %q, want %q`, string(entry["value"]), tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultWriter_Containers(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewDefaultWriter(buf)
+
+	writer.Write(LevelInfo, "request", map[string]any{"header": http.Header{"Authorization": {"Bearer secret-token"}}})
+	if err := writer.flushBuffer(); err != nil {
+		t.Fatalf("writer.flushBuffer(): %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, `header="{"Authorization":["[REDACTED]"]}"`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `header="{"Authorization":["[REDACTED]"]}"`)
+	}
+	if got := buf.String(); strings.Contains(got, "secret-token") {
+		t.Errorf("buf.String() = %q, want it not to contain %q", got, "secret-token")
+	}
+}
+
+func FuzzAppendJSONString(f *testing.F) {
+	f.Add("plain")
+	f.Add("quote\" backslash\\ newline\n tab\t nul\x00")
+	f.Add("invalid \xff utf-8 and   separators   <html>&")
+
+	f.Fuzz(func(t *testing.T, s string) {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(s); err != nil {
+			t.Fatalf("encoder.Encode(s): %v", err)
+		}
+
+		want := bytes.TrimSuffix(buf.Bytes(), []byte{'\n'})
+		if got := appendJSONString(nil, s); !bytes.Equal(got, want) {
+			t.Errorf("appendJSONString(nil, s) = %v, want %v", got, want)
+		}
+	})
+}
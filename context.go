@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"context"
+	"maps"
+)
+
+// scopeKey is the context key of the scope stored by IntoContext.
+type scopeKey struct{}
+
+// contextScope is the part of a LogScope stored by IntoContext.
+type contextScope struct {
+	logger *Logger
+	fields map[string]any
+	writer LogWriter
+	err    error
+	// level and levelSet are the level set with WithLevel, if any
+	level    int
+	levelSet bool
+}
+
+// IntoContext returns a copy of ctx that carries scope, so that code further
+// down the call chain logs with its fields using FromContext, instead of
+// threading them manually. The context keeps the Logger, the fields, the
+// error (see WithError), the writer (see WithWriter), and the level (see
+// WithLevel) of scope, not its context, time, or Once and Every gates. Later
+// changes to scope do not affect the context.
+//
+// Example, in a request handler:
+//
+//	ctx = golog.IntoContext(ctx, golog.With("request_id", id).With("user_id", user.ID))
+//	...
+//	// in a function the handler calls
+//	golog.FromContext(ctx).Info("order created") // request_id=... user_id=...
+//
+// To add fields for the rest of the chain, derive a scope and store it again:
+//
+//	ctx = golog.IntoContext(ctx, golog.FromContext(ctx).With("order_id", order.ID))
+func IntoContext(ctx context.Context, scope *LogScope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, contextScope{
+		logger:   scope.logger,
+		fields:   maps.Clone(scope.fields),
+		writer:   scope.writer,
+		err:      scope.err,
+		level:    scope.level,
+		levelSet: scope.levelSet,
+	})
+}
+
+// NewContext returns a copy of ctx that carries scope. It is the same as
+// IntoContext.
+func NewContext(ctx context.Context, scope *LogScope) context.Context {
+	return IntoContext(ctx, scope)
+}
+
+// FromContext returns a new LogScope with the scope stored in ctx by
+// IntoContext, if any, and ctx as its context, so that enrichers see it.
+// Without a stored scope, it is the same as WithContext. Each call returns a
+// separate child scope, so the result can be extended and shared freely.
+func FromContext(ctx context.Context) *LogScope {
+	stored, ok := ctx.Value(scopeKey{}).(contextScope)
+	if !ok {
+		return WithContext(ctx)
+	}
+
+	lg := stored.logger
+	if lg == nil {
+		lg = std
+	}
+
+	scope := lg.WithContext(ctx).WithFields(stored.fields)
+	scope.writer = stored.writer
+	scope.err = stored.err
+	scope.level, scope.levelSet = stored.level, stored.levelSet
+
+	return scope
+}
@@ -7,8 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/bytedance/sonic"
+	"unicode/utf8"
 )
 
 // defaultWriter implements the LogWriter interface with buffered writing and efficient JSON serialization.
@@ -16,19 +15,22 @@ import (
 type defaultWriter struct {
 	output io.Writer
 	buf    *bufio.Writer
+	opts   writerOptions
 }
 
 // NewDefaultWriter creates a new defaultWriter instance with the given io.Writer.
 // The writer is wrapped in a buffer for better performance.
 // Unsupported field types (complex64, complex128, channels, functions) will cause a panic.
+// Pass WriterOption values such as SortKeys to customize the output.
 //
 // Example:
 //
 //	writer := NewDefaultWriter(os.Stdout)
-func NewDefaultWriter(output io.Writer) *defaultWriter {
+func NewDefaultWriter(output io.Writer, opts ...WriterOption) *defaultWriter {
 	return &defaultWriter{
 		output: output,
 		buf:    bufio.NewWriter(output),
+		opts:   newWriterOptions(opts),
 	}
 }
 
@@ -37,49 +39,99 @@ func NewDefaultWriter(output io.Writer) *defaultWriter {
 //	file:line [level][timestamp] message field1="value1" field2="value2"
 //
 // The fields are automatically converted to strings and properly escaped.
+// Continuation lines of a multi-line message or value, such as a stack trace,
+// start with a tab (see ContinuationPrefix), so line-based collectors can
+// fold them into the entry.
 // The caller information (file and line) is automatically captured.
+// Numbers are formatted independently of the host locale (a "." decimal
+// point and no digit grouping), and the line is always valid UTF-8 without a
+// byte order mark (see validText).
 // Panics on unsupported field types (complex numbers, channels, functions).
 func (l *defaultWriter) Write(level int, msg string, fields map[string]any) {
 	file, line := getCallerInfo(skipFrames)
-	fmt.Fprintf(
+	l.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter. It writes entry in the same format as
+// Write, using entry.Time as the timestamp.
+func (l *defaultWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	l.write(entry, file, line)
+}
+
+// write formats one log line with the given time and caller location.
+func (l *defaultWriter) write(entry Entry, file string, line int) {
+	n, _ := fmt.Fprintf(
 		l.buf,
-		"%s [%s][%s] %s %s\n",
+		"%s%s [%s][%s] %s %s%s",
+		l.opts.recordStart(),
 		fmt.Sprintf("%s:%d", file, line),
-		LevelString(level),
-		time.Now().Format(time.RFC3339),
-		msg,
-		l.fieldsToString(fields),
+		LevelString(entry.Level),
+		l.opts.timestamp(entry.Time),
+		l.opts.foldLines(validText(entry.Message)),
+		l.fieldsToString(entry.Fields),
+		l.opts.recordEnd(),
 	)
+
+	if report := l.opts.metrics.record(entry.Level, entry.Message, n); report != nil {
+		l.write(Entry{Time: time.Now(), Level: LevelInfo, Message: SelfMetricsMessage, Fields: report}, file, line)
+		l.opts.metrics.reported()
+	}
 }
 
-// Flush writes any buffered data to the underlying writer and closes it if it implements io.Closer.
+// Flush writes any buffered data to the underlying writer and closes it if it implements io.Closer,
+// unless it is stdout or stderr.
 // This should be called when you want to ensure all buffered logs are written.
 // It's typically called when shutting down the application or when immediate flushing is needed.
 func (l *defaultWriter) Flush() {
 	l.buf.Flush()
-	if flusher, ok := l.output.(io.Closer); ok {
-		flusher.Close()
+	closeOutput(l.output)
+}
+
+// flushBuffer writes buffered data to the output without closing it.
+func (l *defaultWriter) flushBuffer() error {
+	return l.buf.Flush()
+}
+
+// flushBuffers implements bufferFlusher.
+func (l *defaultWriter) flushBuffers() {
+	l.buf.Flush()
+}
+
+// validText returns s as valid UTF-8 without a leading byte order mark.
+// Invalid bytes, such as text in a Windows code page read from a file or a
+// console, are replaced with U+FFFD, so the output can always be parsed as
+// UTF-8.
+func validText(s string) string {
+	s = strings.TrimPrefix(s, "\ufeff")
+	if utf8.ValidString(s) {
+		return s
 	}
+
+	return strings.ToValidUTF8(s, "\ufffd")
 }
 
 // fieldsToString converts a map of fields to a space-separated string of key-value pairs.
 // Each value is wrapped in quotes and properly escaped.
+// Keys are sorted when the SortKeys option is set.
 // Example: map[string]any{"user": "john", "age": 30} -> user="john" age="30"
 func (l *defaultWriter) fieldsToString(fields map[string]any) string {
 	var sb strings.Builder
 
 	started := false
-	for key, value := range fields {
+	for _, key := range l.opts.fieldKeys(fields) {
+		value := encodeField(l.opts.classifiedValue(fields[key]))
 		if started {
 			sb.WriteRune(' ')
 		} else {
 			started = true
 		}
 
-		sb.WriteString(key)
+		sb.WriteString(validText(key))
 		sb.WriteRune('=')
 		sb.WriteRune('"')
-		sb.WriteString(l.valToString(value))
+		sb.WriteString(l.opts.foldLines(validText(l.valToString(value))))
 		sb.WriteRune('"')
 	}
 
@@ -87,14 +139,17 @@ func (l *defaultWriter) fieldsToString(fields map[string]any) string {
 }
 
 // valToString converts any value to its string representation.
-// It handles: strings, bools, numbers, time.Time, error, and other types via Sonic JSON.
-// Panics on complex64, complex128, and other types not supported by Sonic.
+// It handles: strings, bools, numbers, time.Time, error, and other types via JSON.
+// Panics on complex64, complex128, and other types that cannot be encoded as JSON,
+// unless TolerantEncoding is set.
 func (l *defaultWriter) valToString(value any) string {
 	var sb strings.Builder
 
 	switch v := value.(type) {
 	case string:
 		sb.WriteString(v)
+	case Pseudonym:
+		sb.WriteString(string(v))
 	case bool:
 		sb.WriteString(strconv.FormatBool(v))
 	case float64:
@@ -118,9 +173,17 @@ func (l *defaultWriter) valToString(value any) string {
 	case uint16:
 		sb.WriteString(strconv.FormatUint(uint64(v), 10))
 	case complex64:
-		panic("complex64 is not supported")
+		if !l.opts.tolerant {
+			panic("complex64 is not supported")
+		}
+
+		sb.WriteString(fmt.Sprint(v))
 	case complex128:
-		panic("complex128 is not supported")
+		if !l.opts.tolerant {
+			panic("complex128 is not supported")
+		}
+
+		sb.WriteString(fmt.Sprint(v))
 	case time.Time:
 		sb.WriteString(v.Format(time.RFC3339))
 	case error:
@@ -132,12 +195,12 @@ func (l *defaultWriter) valToString(value any) string {
 	return sb.String()
 }
 
-// reflectToString uses Sonic to convert any value to its JSON string representation.
+// reflectToString converts any value to its JSON string representation, using
+// encoding/json or the function set with MarshalFunc.
 // This is used as a fallback for types that aren't handled by valToString.
-// Sonic is used instead of the standard json package for better performance.
 // Returns an empty string if serialization fails.
 func (l *defaultWriter) reflectToString(v any) string {
-	jstr, err := sonic.Marshal(v)
+	jstr, err := l.opts.marshal(v)
 	if err != nil {
 		panic(err)
 	}
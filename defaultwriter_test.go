@@ -5,14 +5,15 @@ import (
 	"strings"
 	"testing"
 	"time"
-
-	"github.com/stretchr/testify/assert"
+	"unicode/utf8"
 )
 
 func TestNewDefaultWriter(t *testing.T) {
 	buf := &bytes.Buffer{}
 	writer := NewDefaultWriter(buf)
-	assert.NotNil(t, writer, "NewDefaultWriter should not return nil")
+	if writer == nil {
+		t.Error("NewDefaultWriter should not return nil: writer is nil")
+	}
 }
 
 func TestDefaultWriter_Write(t *testing.T) {
@@ -36,6 +37,12 @@ func TestDefaultWriter_Write(t *testing.T) {
 				"defaultwriter_test.go",
 			},
 		},
+		{
+			name:     "warn-entry",
+			level:    LevelWarn,
+			message:  "retrying",
+			contains: []string{"[WARN]", "retrying"},
+		},
 		{
 			name:    "log-entry-with-fields",
 			level:   LevelDebug,
@@ -60,12 +67,16 @@ func TestDefaultWriter_Write(t *testing.T) {
 				// Extract timestamp from the log entry
 				// Format: file:line [level][timestamp] message
 				parts := strings.Split(output, "]")
-				assert.Greater(t, len(parts), 2, "Log entry should contain timestamp")
+				if len(parts) <= 2 {
+					t.Errorf("Log entry should contain timestamp: len(parts) = %v, want greater than 2", len(parts))
+				}
 
 				// The timestamp is in the second part, between [ and ]
 				timestamp := strings.TrimPrefix(parts[1], "[")
 				_, err := time.Parse(time.RFC3339, timestamp)
-				assert.NoError(t, err, "Timestamp should be in RFC3339 format log=%s", output)
+				if err != nil {
+					t.Errorf("Timestamp should be in RFC3339 format log=%s: unexpected error: %v", output, err)
+				}
 			},
 		},
 		{
@@ -119,9 +130,9 @@ func TestDefaultWriter_Write(t *testing.T) {
 			writer := NewDefaultWriter(buf)
 
 			if tt.shouldPanic {
-				assert.Panics(t, func() {
-					writer.Write(tt.level, tt.message, tt.fields)
-				}, "Write should panic for unsupported types")
+				if recovered(func() { writer.Write(tt.level, tt.message, tt.fields) }) == nil {
+					t.Error("Write should panic for unsupported types")
+				}
 				return
 			}
 
@@ -132,7 +143,9 @@ func TestDefaultWriter_Write(t *testing.T) {
 
 			// Check for required string contents
 			for _, contain := range tt.contains {
-				assert.Contains(t, output, contain)
+				if !strings.Contains(output, contain) {
+					t.Errorf("output = %q, want it to contain %q", output, contain)
+				}
 			}
 
 			// Run additional validation if specified
@@ -165,9 +178,7 @@ func TestDefaultWriter_Flush(t *testing.T) {
 
 			writer.Write(tt.level, tt.message, tt.fields)
 
-			assert.NotPanics(t, func() {
-				writer.Flush()
-			}, "Flush should not panic")
+			writer.Flush()
 		})
 	}
 }
@@ -205,15 +216,151 @@ func TestDefaultWriter_FieldsToString(t *testing.T) {
 				"float": 3.14,
 				"bool":  true,
 			},
-			expected: `int="42" float="3.14" bool="true"`,
+			expected: `bool="true" float="3.14" int="42"`,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			writer := NewDefaultWriter(&bytes.Buffer{})
+			writer := NewDefaultWriter(&bytes.Buffer{}, SortKeys())
 			result := writer.fieldsToString(tt.fields)
-			assert.Equal(t, tt.expected, result)
+			if result != tt.expected {
+				t.Errorf("result = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultWriter_SortKeys(t *testing.T) {
+	writer := NewDefaultWriter(&bytes.Buffer{}, SortKeys())
+
+	for i := 0; i < 10; i++ {
+		result := writer.fieldsToString(map[string]any{
+			"int":   42,
+			"float": 3.14,
+			"bool":  true,
+			"map":   map[string]any{"b": 2, "a": 1},
+		})
+		want := `bool="true" float="3.14" int="42" map="{"a":1,"b":2}"`
+		if result != want {
+			t.Errorf("result = %q, want %q", result, want)
+		}
+	}
+}
+
+func TestDefaultWriter_ValidText(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		fields   map[string]any
+		expected string
+	}{
+		{
+			name:     "windows-1252 bytes",
+			msg:      "caf\xe9 opened",
+			fields:   map[string]any{"user": "Jos\xe9"},
+			expected: "caf\ufffd opened user=\"Jos\ufffd\"",
+		},
+		{
+			name:     "code page 437 bytes",
+			msg:      "r\x82sum\x82",
+			expected: "r\ufffdsum\ufffd ",
+		},
+		{
+			name:     "byte order mark",
+			msg:      "\ufeffimported",
+			fields:   map[string]any{"line": "\ufeffid,name"},
+			expected: `imported line="id,name"`,
+		},
+		{
+			name:     "valid utf-8 is kept",
+			msg:      "héllo 世界",
+			expected: "héllo 世界 ",
+		},
+		{
+			name: "locale-independent numbers",
+			msg:  "totals",
+			fields: map[string]any{
+				"amount": 1234567.891,
+				"ratio":  float32(0.5),
+				"count":  int64(1234567890),
+			},
+			expected: `totals amount="1234567.891" count="1234567890" ratio="0.5"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewDefaultWriter(buf, SortKeys())
+			writer.Write(LevelInfo, tt.msg, tt.fields)
+			writer.Flush()
+
+			output := buf.String()
+			if !utf8.ValidString(output) {
+				t.Errorf("output %q is valid UTF-8", output)
+			}
+			if strings.Contains(output, "\ufeff") {
+				t.Errorf("output = %q, want it not to contain %q", output, "\ufeff")
+			}
+			if !strings.HasSuffix(output, "] "+tt.expected+"\n") {
+				t.Errorf("output %q", output)
+			}
+		})
+	}
+}
+
+func TestDefaultWriter_ContinuationLines(t *testing.T) {
+	stack := "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:12 +0x1d\n"
+
+	tests := []struct {
+		name     string
+		opts     []WriterOption
+		msg      string
+		fields   map[string]any
+		expected string
+	}{
+		{
+			name:     "single-line",
+			msg:      "started",
+			expected: "started \n",
+		},
+		{
+			name:     "multi-line-message",
+			msg:      "request failed\ncaused by: timeout",
+			expected: "request failed\n\tcaused by: timeout \n",
+		},
+		{
+			name:     "stack-field",
+			msg:      "panic",
+			fields:   map[string]any{"stack": stack},
+			expected: "panic stack=\"goroutine 1 [running]:\n\tmain.main()\n\t\t/app/main.go:12 +0x1d\"\n",
+		},
+		{
+			name:     "custom-prefix",
+			opts:     []WriterOption{ContinuationPrefix("  | ")},
+			msg:      "line 1\r\nline 2",
+			expected: "line 1\n  | line 2 \n",
+		},
+		{
+			name:     "crlf-framing",
+			opts:     []WriterOption{RecordFraming(FramingCRLF)},
+			msg:      "line 1\nline 2",
+			expected: "line 1\r\n\tline 2 \r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewDefaultWriter(buf, tt.opts...)
+			writer.Write(LevelError, tt.msg, tt.fields)
+			writer.Flush()
+
+			output := buf.String()
+			if !strings.HasSuffix(output, "] "+tt.expected) {
+				t.Errorf("output %q", output)
+			}
 		})
 	}
 }
@@ -0,0 +1,152 @@
+package golog
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// Degradation profiles
+const (
+	// ProfileFull writes every entry the Loggers enable
+	ProfileFull = "full"
+	// ProfileReduced drops debug and trace entries and keeps one in every
+	// DegradationSampleRate info entries
+	ProfileReduced = "reduced"
+	// ProfileMinimal writes only error entries and above, to a local writer
+	// (see DegradationWriter) instead of the Loggers' writers
+	ProfileMinimal = "minimal"
+)
+
+// defaultDegradationSampleRate is the info sample rate of ProfileReduced by
+// default
+const defaultDegradationSampleRate = 10
+
+// DegradationOption configures the profile set by SetDegradationProfile.
+type DegradationOption func(*degradationProfile)
+
+// DegradationSampleRate sets the rate of ProfileReduced: one in every n info
+// entries is written. The default is 10.
+func DegradationSampleRate(n int) DegradationOption {
+	return func(p *degradationProfile) {
+		p.sampleRate = n
+	}
+}
+
+// DegradationWriter sets the writer replacing the Loggers' writers under the
+// profile, typically a file on local disk created with NewFileWriter, so
+// that errors are kept while the remote sinks are down. ProfileMinimal
+// writes the text format to os.Stderr by default; the other profiles keep
+// the Loggers' writers.
+func DegradationWriter(w LogWriter) DegradationOption {
+	return func(p *degradationProfile) {
+		p.writer = w
+	}
+}
+
+// degradationProfile is a profile set with SetDegradationProfile.
+type degradationProfile struct {
+	name string
+	// level is the minimum level written
+	level int
+	// sampleRate is the one-in-n rate of info entries, or 0 to keep them all
+	sampleRate int
+	// writer replaces the Loggers' writers when set
+	writer LogWriter
+	// infos counts the info entries, for sampling
+	infos atomic.Uint64
+}
+
+// degradation holds the profile set with SetDegradationProfile, nil for
+// ProfileFull, so that the write path only pays for an atomic load.
+var degradation atomic.Pointer[degradationProfile]
+
+// SetDegradationProfile switches every Logger to a degradation profile:
+// ProfileFull, the default, ProfileReduced, or ProfileMinimal, e.g. from
+// incident automation when the logging pipeline itself is overloaded. The
+// switch is atomic: each entry is written under either the previous profile
+// or the new one. The profile applies on top of the Loggers' levels, and the
+// entries it sheds are not counted as dropped (see RecordDropped).
+//
+// Switching away from ProfileMinimal flushes its writer, but does not close
+// it.
+//
+// Example:
+//
+//	fallback, err := golog.NewFileWriter("/var/log/app/degraded.log")
+//	if err != nil {
+//	    return err
+//	}
+//	err = golog.SetDegradationProfile(golog.ProfileMinimal, golog.DegradationWriter(fallback))
+func SetDegradationProfile(profile string, opts ...DegradationOption) error {
+	p := &degradationProfile{name: profile}
+
+	switch profile {
+	case ProfileFull:
+		p = nil
+	case ProfileReduced:
+		p.level = LevelInfo
+		p.sampleRate = defaultDegradationSampleRate
+	case ProfileMinimal:
+		p.level = LevelError
+		p.writer = NewDefaultWriter(os.Stderr)
+	default:
+		return fmt.Errorf("golog: unknown degradation profile %q", profile)
+	}
+
+	if p != nil {
+		for _, opt := range opts {
+			if opt != nil {
+				opt(p)
+			}
+		}
+
+		if profile == ProfileReduced {
+			p.sampleRate = max(p.sampleRate, 1)
+		}
+	}
+
+	if old := degradation.Swap(p); old != nil && old.writer != nil && (p == nil || old.writer != p.writer) {
+		old.writer.Flush()
+	}
+
+	return nil
+}
+
+// DegradationProfile returns the current degradation profile.
+func DegradationProfile() string {
+	if p := degradation.Load(); p != nil {
+		return p.name
+	}
+
+	return ProfileFull
+}
+
+// degrade applies the degradation profile to an entry at level. It reports
+// whether the entry is written, and returns the writer of the profile, if
+// any, which replaces the Logger's writer.
+func degrade(level int) (LogWriter, bool) {
+	p := degradation.Load()
+	if p == nil {
+		return nil, true
+	}
+
+	if level < p.level {
+		return nil, false
+	}
+
+	if level == LevelInfo && p.sampleRate > 1 && (p.infos.Add(1)-1)%uint64(p.sampleRate) != 0 {
+		return nil, false
+	}
+
+	return p.writer, true
+}
+
+// degradationWriter returns the writer of the degradation profile, if any.
+func degradationWriter() LogWriter {
+	if p := degradation.Load(); p != nil {
+		return p.writer
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetDegradationProfile(t *testing.T) {
+	t.Cleanup(func() { SetDegradationProfile(ProfileFull) })
+
+	fallback := &captureWriter{}
+	tests := []struct {
+		name     string
+		profile  string
+		fallback *captureWriter
+		opts     []DegradationOption
+		written  map[int]int
+		// fellBack counts the entries written to the fallback writer
+		fellBack map[int]int
+	}{
+		{
+			name:    "full",
+			profile: ProfileFull,
+			written: map[int]int{LevelDebug: 10, LevelInfo: 10, LevelWarn: 10, LevelError: 10},
+		},
+		{
+			name:    "reduced",
+			profile: ProfileReduced,
+			opts:    []DegradationOption{DegradationSampleRate(5)},
+			written: map[int]int{LevelInfo: 2, LevelWarn: 10, LevelError: 10},
+		},
+		{
+			name:     "minimal",
+			profile:  ProfileMinimal,
+			fallback: fallback,
+			opts:     []DegradationOption{DegradationWriter(fallback)},
+			written:  map[int]int{},
+			fellBack: map[int]int{LevelError: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			useWriter(t, w)
+			SetLevel(LevelDebug)
+			t.Cleanup(func() { SetLevel(LevelInfo) })
+
+			if err := SetDegradationProfile(tt.profile, tt.opts...); err != nil {
+				t.Fatalf("SetDegradationProfile(tt.profile, tt.opts...): %v", err)
+			}
+			if got := DegradationProfile(); got != tt.profile {
+				t.Errorf("DegradationProfile() = %q, want %q", got, tt.profile)
+			}
+
+			for _, level := range []int{LevelDebug, LevelInfo, LevelWarn, LevelError} {
+				for i := 0; i < 10; i++ {
+					std.newScope().Log(level, "entry")
+				}
+			}
+
+			if got := countLevels(w.entries); !reflect.DeepEqual(got, tt.written) {
+				t.Errorf("countLevels(w.entries) = %v, want %v", got, tt.written)
+			}
+			if tt.fallback != nil {
+				if got := countLevels(tt.fallback.entries); !reflect.DeepEqual(got, tt.fellBack) {
+					t.Errorf("countLevels(tt.fallback.entries) = %v, want %v", got, tt.fellBack)
+				}
+			}
+		})
+	}
+}
+
+func TestSetDegradationProfile_Switch(t *testing.T) {
+	t.Cleanup(func() { SetDegradationProfile(ProfileFull) })
+
+	w := &captureWriter{}
+	useWriter(t, w)
+	fallback := &captureWriter{}
+
+	if err := SetDegradationProfile(ProfileMinimal, DegradationWriter(fallback)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	Error("during the incident")
+
+	if err := SetDegradationProfile(ProfileFull); err != nil {
+		t.Fatalf("SetDegradationProfile(ProfileFull): %v", err)
+	}
+	Error("after the incident")
+
+	if len(fallback.entries) != 1 {
+		t.Fatalf("len(fallback.entries) = %d, want 1", len(fallback.entries))
+	}
+	if fallback.entries[0].msg != "during the incident" {
+		t.Errorf("fallback.entries[0].msg = %q, want %q", fallback.entries[0].msg, "during the incident")
+	}
+	if fallback.flushes != 1 {
+		t.Errorf("switching away from the profile flushes its writer: fallback.flushes = %v, want 1", fallback.flushes)
+	}
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if w.entries[0].msg != "after the incident" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "after the incident")
+	}
+
+	if err := SetDegradationProfile("off"); err == nil {
+		t.Error(`SetDegradationProfile("off") = nil, want an error`)
+	}
+	if got := DegradationProfile(); got != ProfileFull {
+		t.Errorf("DegradationProfile() = %q, want %q", got, ProfileFull)
+	}
+}
+
+// countLevels returns the number of entries by level.
+func countLevels(entries []capturedEntry) map[int]int {
+	counts := map[int]int{}
+	for _, entry := range entries {
+		counts[entry.level]++
+	}
+
+	return counts
+}
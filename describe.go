@@ -0,0 +1,393 @@
+package golog
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pipeline describes the logging setup of a Logger: its writer and everything
+// entries go through before reaching it. Log it at startup, e.g.
+// golog.Infof("logging configured\n%s", golog.Describe()), to debug
+// misconfigurations.
+type Pipeline struct {
+	// Level is the minimum level of the Logger (see SetLevel)
+	Level string
+	// CompiledLevel is the most verbose level compiled in (see the build
+	// tags in the package documentation)
+	CompiledLevel string
+	// Enrichers lists the types of the registered enrichers, in order
+	Enrichers []string
+	// Hooks lists the types of the hooks added with AddHook, in order
+	Hooks []string
+	// RetentionRules is the number of rules set with SetRetentionRules
+	RetentionRules int
+	// SilenceWindows lists the names of the windows set with
+	// SetSilenceWindows, in order
+	SilenceWindows []string
+	// ErrorKinds lists the kinds of the taxonomy set with SetErrorKinds, in
+	// order; it is empty when error classification is disabled
+	ErrorKinds []string
+	// FieldEncoders lists the types with an encoder registered with
+	// RegisterFieldEncoder, sorted
+	FieldEncoders []string
+	// RedactedFields lists the field names redacted by SetRedaction, sorted
+	RedactedFields []string
+	// RedactionPatterns lists the patterns redacted by SetRedaction, in
+	// order
+	RedactionPatterns []string
+	// Writer describes the writer of the Logger (see SetWriter)
+	Writer WriterDescription
+}
+
+// WriterDescription describes a writer of the pipeline.
+type WriterDescription struct {
+	// Type is the kind of writer, such as "json", "text", or "file", or the
+	// Go type of writers that do not implement Describer
+	Type string
+	// Level is the minimum level of entries written, when the writer
+	// filters entries by level
+	Level string
+	// Settings holds the settings of the writer, such as "path"
+	Settings map[string]string
+	// Writers describes the writers that this writer writes to, in order
+	Writers []WriterDescription
+}
+
+// Describer is implemented by writers that describe themselves in Describe.
+// The built-in writers implement it; implement it in writers that wrap other
+// writers, filter, or sample entries so that Describe shows them.
+type Describer interface {
+	// Describe returns the description of the writer.
+	Describe() WriterDescription
+}
+
+// Describe returns the description of the current logging pipeline of the
+// default Logger.
+func Describe() Pipeline {
+	return std.Describe()
+}
+
+// Describe returns the description of the current logging pipeline of the
+// Logger.
+func (lg *Logger) Describe() Pipeline {
+	cfg := lg.load()
+	p := Pipeline{
+		Level:         LevelString(cfg.level),
+		CompiledLevel: LevelString(maxLevel),
+		Writer:        DescribeWriter(cfg.writer),
+	}
+
+	for _, enricher := range cfg.enrichers {
+		p.Enrichers = append(p.Enrichers, fmt.Sprintf("%T", enricher))
+	}
+
+	for _, h := range cfg.hooks {
+		p.Hooks = append(p.Hooks, fmt.Sprintf("%T", h.hook))
+	}
+
+	if rules := retentionRules.Load(); rules != nil {
+		p.RetentionRules = len(*rules)
+	}
+
+	silences.mu.Lock()
+	for _, w := range silences.windows {
+		p.SilenceWindows = append(p.SilenceWindows, w.Name)
+	}
+	silences.mu.Unlock()
+
+	if kinds := errorKinds.Load(); kinds != nil {
+		for _, kind := range *kinds {
+			if !slices.Contains(p.ErrorKinds, kind.Kind) {
+				p.ErrorKinds = append(p.ErrorKinds, kind.Kind)
+			}
+		}
+	}
+
+	if encoders := fieldEncoders.Load(); encoders != nil {
+		for t := range *encoders {
+			p.FieldEncoders = append(p.FieldEncoders, t.String())
+		}
+		slices.Sort(p.FieldEncoders)
+	}
+
+	if r := redaction.Load(); r != nil {
+		p.RedactedFields = slices.Sorted(maps.Keys(r.fields))
+		for _, pattern := range r.patterns {
+			p.RedactionPatterns = append(p.RedactionPatterns, pattern.String())
+		}
+	}
+
+	return p
+}
+
+// DescribeWriter returns the description of w: the result of its Describe
+// method if it implements Describer, or its Go type otherwise.
+func DescribeWriter(w LogWriter) WriterDescription {
+	if d, ok := w.(Describer); ok {
+		return d.Describe()
+	}
+
+	return WriterDescription{Type: fmt.Sprintf("%T", w)}
+}
+
+// String returns the description as indented, human-readable lines.
+func (p Pipeline) String() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "level: %s (compiled: %s)\n", p.Level, p.CompiledLevel)
+	writeList(&sb, "enrichers", p.Enrichers)
+	writeList(&sb, "hooks", p.Hooks)
+	fmt.Fprintf(&sb, "retention rules: %d\n", p.RetentionRules)
+	writeList(&sb, "silence windows", p.SilenceWindows)
+	writeList(&sb, "error kinds", p.ErrorKinds)
+	writeList(&sb, "field encoders", p.FieldEncoders)
+	writeList(&sb, "redacted fields", p.RedactedFields)
+	writeList(&sb, "redaction patterns", p.RedactionPatterns)
+	sb.WriteString("writer: ")
+	p.Writer.write(&sb, "")
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// String returns the description as indented, human-readable lines.
+func (d WriterDescription) String() string {
+	var sb strings.Builder
+	d.write(&sb, "")
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// write writes the description of the writer and, indented, of the writers
+// it writes to.
+func (d WriterDescription) write(sb *strings.Builder, indent string) {
+	sb.WriteString(d.Type)
+	if d.Level != "" {
+		fmt.Fprintf(sb, " (level %s)", d.Level)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(d.Settings)) {
+		fmt.Fprintf(sb, " %s=%s", key, d.Settings[key])
+	}
+	sb.WriteByte('\n')
+
+	for _, w := range d.Writers {
+		sb.WriteString(indent + "  - ")
+		w.write(sb, indent+"    ")
+	}
+}
+
+// writeList writes a "name: a, b" line, with "none" for an empty list.
+func writeList(sb *strings.Builder, name string, values []string) {
+	list := "none"
+	if len(values) > 0 {
+		list = strings.Join(values, ", ")
+	}
+
+	fmt.Fprintf(sb, "%s: %s\n", name, list)
+}
+
+// describe returns the settings of the writer options that differ from the
+// defaults.
+func (o writerOptions) describe() map[string]string {
+	settings := map[string]string{}
+
+	if o.sortKeys {
+		settings["sort_keys"] = "true"
+	}
+
+	if o.int64AsString {
+		settings["int64_as_string"] = "true"
+	}
+
+	if o.tolerant {
+		settings["tolerant"] = "true"
+	}
+
+	if o.metrics != nil {
+		settings["self_metrics"] = o.metrics.interval.String()
+	}
+
+	if o.structuredErrors {
+		settings["structured_errors"] = "true"
+
+		if o.errorChainDepth != defaultErrorChainDepth {
+			settings["error_chain_depth"] = strconv.Itoa(o.errorChainDepth)
+		}
+	}
+
+	if o.location != nil && o.location != time.UTC {
+		settings["time_zone"] = o.location.String()
+	}
+
+	switch o.framing {
+	case FramingCRLF:
+		settings["framing"] = "crlf"
+	case FramingJSONSeq:
+		settings["framing"] = "json-seq"
+	}
+
+	return settings
+}
+
+// Describe implements Describer.
+func (l *jsonWriter) Describe() WriterDescription {
+	settings := l.opts.describe()
+	settings["output"] = fmt.Sprintf("%T", l.output)
+
+	return WriterDescription{Type: FormatJSON, Settings: settings}
+}
+
+// Describe implements Describer.
+func (l *defaultWriter) Describe() WriterDescription {
+	settings := l.opts.describe()
+	settings["output"] = fmt.Sprintf("%T", l.output)
+
+	return WriterDescription{Type: FormatText, Settings: settings}
+}
+
+// Describe implements Describer.
+func (w *bootstrapWriter) Describe() WriterDescription {
+	return WriterDescription{Type: "bootstrap", Writers: []WriterDescription{w.text.Describe()}}
+}
+
+// Describe implements Describer.
+func (w *fileWriter) Describe() WriterDescription {
+	format := FormatJSON
+	if w.opts.text {
+		format = FormatText
+	}
+
+	settings := map[string]string{"path": w.path, "format": format}
+	if w.opts.codec != "" {
+		settings["compression"] = w.opts.codec
+	}
+
+	if w.opts.lock {
+		settings["lock"] = "true"
+	}
+
+	if w.opts.minFreeSpace > 0 {
+		settings["min_free_space"] = strconv.FormatUint(w.opts.minFreeSpace, 10)
+	}
+
+	if w.opts.maxSize > 0 {
+		settings["max_size"] = strconv.FormatInt(w.opts.maxSize, 10)
+	}
+
+	if w.opts.maxAge > 0 {
+		settings["max_age"] = w.opts.maxAge.String()
+	}
+
+	if w.opts.maxBackups > 0 {
+		settings["max_backups"] = strconv.Itoa(w.opts.maxBackups)
+	}
+
+	if w.opts.compressBackups {
+		settings["compress_backups"] = "true"
+	}
+
+	if w.opts.checksums {
+		settings["segment_checksums"] = "true"
+	}
+
+	return WriterDescription{Type: "file", Settings: settings}
+}
+
+// Describe implements Describer.
+func (w *partitionedFileWriter) Describe() WriterDescription {
+	format := FormatJSON
+	if w.settings.text {
+		format = FormatText
+	}
+
+	return WriterDescription{Type: "partitioned_file", Settings: map[string]string{
+		"path_template":  w.text,
+		"format":         format,
+		"max_open_files": strconv.Itoa(w.settings.maxOpenFiles),
+	}}
+}
+
+// Describe implements Describer.
+func (w *exportWriter) Describe() WriterDescription {
+	return WriterDescription{
+		Type: "export",
+		Settings: map[string]string{
+			"exporter":   fmt.Sprintf("%T", w.exporter),
+			"batch_size": strconv.Itoa(w.opts.batchSize),
+			"interval":   w.opts.interval.String(),
+			"queue_size": strconv.Itoa(w.opts.queueSize),
+		},
+	}
+}
+
+// Describe implements Describer.
+func (w *slogWriter) Describe() WriterDescription {
+	return WriterDescription{
+		Type:     "slog",
+		Settings: map[string]string{"handler": fmt.Sprintf("%T", w.handler)},
+	}
+}
+
+// Describe implements Describer.
+func (w *wireWriter) Describe() WriterDescription {
+	return WriterDescription{
+		Type: "wire",
+		Settings: map[string]string{
+			"output":     fmt.Sprintf("%T", w.output),
+			"codec":      w.opts.codec,
+			"frame_size": strconv.Itoa(w.opts.frameSize),
+		},
+	}
+}
+
+// Describe implements Describer.
+func (w *asyncWriter) Describe() WriterDescription {
+	settings := map[string]string{
+		"queue_size": strconv.Itoa(w.opts.queueSize),
+		"policy":     w.opts.policy,
+	}
+	if w.opts.policy == AsyncSample {
+		settings["sample_rate"] = strconv.Itoa(w.opts.sampleRate)
+	}
+	if w.opts.priorityLevel <= LevelFatal {
+		settings["priority_level"] = LevelString(w.opts.priorityLevel)
+		settings["priority_queue_size"] = strconv.Itoa(w.opts.priorityQueueSize)
+	}
+
+	return WriterDescription{Type: "async", Settings: settings, Writers: []WriterDescription{DescribeWriter(w.inner)}}
+}
+
+// Describe implements Describer.
+func (w *samplerWriter) Describe() WriterDescription {
+	settings := map[string]string{"tick": w.opts.tick.String()}
+	if w.opts.perMessage {
+		settings["per_message"] = "true"
+	}
+	for level, rule := range w.opts.rules {
+		settings[strings.ToLower(LevelString(level))] = fmt.Sprintf("first %d, thereafter %d", rule.first, rule.thereafter)
+	}
+
+	return WriterDescription{Type: "sampler", Settings: settings, Writers: []WriterDescription{DescribeWriter(w.writer)}}
+}
+
+// Describe implements Describer.
+func (w *multiWriter) Describe() WriterDescription {
+	d := WriterDescription{Type: "multi"}
+	for _, writer := range w.writers {
+		d.Writers = append(d.Writers, DescribeWriter(writer))
+	}
+
+	return d
+}
+
+// Describe implements Describer.
+func (w *levelFilter) Describe() WriterDescription {
+	d := DescribeWriter(w.writer)
+	d.Level = LevelString(w.level)
+
+	return d
+}
@@ -0,0 +1,112 @@
+package golog
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	useWriter(t, std.Writer())
+	resetEnrichers(t)
+	originalMinLevel := std.Level()
+	t.Cleanup(func() {
+		std.SetLevel(originalMinLevel)
+		SetSilenceWindows()
+		SetErrorKinds(nil)
+		RegisterFieldEncoder(reflect.TypeOf(money{}), nil)
+		DisableRedaction()
+	})
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	_, err := Configure(Config{
+		Outputs: []OutputConfig{
+			{Path: "stderr"},
+			{Path: path, Format: FormatJSON, Level: "debug", Compression: CodecGzip},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	RegisterEnricher(NewNoveltyEnricher(0))
+	SetSilenceWindows(SilenceWindow{Name: "nightly"})
+	SetErrorKinds(DefaultErrorKinds())
+	RegisterFieldEncoder(reflect.TypeOf(money{}), func(v any) any { return v })
+	SetRedaction(RedactFields("Token", "password"), RedactPatterns(regexp.MustCompile(`\d{16}`)))
+
+	p := Describe()
+
+	if p.Level != "DEBUG" {
+		t.Errorf("p.Level = %q, want %q", p.Level, "DEBUG")
+	}
+	want := []string{"*golog.NoveltyEnricher"}
+	if !reflect.DeepEqual(p.Enrichers, want) {
+		t.Errorf("p.Enrichers = %v, want %v", p.Enrichers, want)
+	}
+	if want := []string{"nightly"}; !reflect.DeepEqual(p.SilenceWindows, want) {
+		t.Errorf("p.SilenceWindows = %v, want %v", p.SilenceWindows, want)
+	}
+	if want := []string{"timeout", "canceled", "not_found", "conflict", "io"}; !reflect.DeepEqual(p.ErrorKinds, want) {
+		t.Errorf("p.ErrorKinds = %v, want %v", p.ErrorKinds, want)
+	}
+	if want := []string{"golog.money"}; !reflect.DeepEqual(p.FieldEncoders, want) {
+		t.Errorf("p.FieldEncoders = %v, want %v", p.FieldEncoders, want)
+	}
+	if want := []string{"password", "token"}; !reflect.DeepEqual(p.RedactedFields, want) {
+		t.Errorf("p.RedactedFields = %v, want %v", p.RedactedFields, want)
+	}
+	if want := []string{`\d{16}`}; !reflect.DeepEqual(p.RedactionPatterns, want) {
+		t.Errorf("p.RedactionPatterns = %v, want %v", p.RedactionPatterns, want)
+	}
+	if want := (WriterDescription{
+		Type: "multi",
+		Writers: []WriterDescription{
+			{Type: "text", Level: "INFO", Settings: map[string]string{"output": "struct { io.Writer }"}},
+			{Type: "file", Level: "DEBUG", Settings: map[string]string{"path": path, "format": "json", "compression": "gzip"}},
+		},
+	}); !reflect.DeepEqual(p.Writer, want) {
+		t.Errorf("p.Writer = %v, want %v", p.Writer, want)
+	}
+
+	if got := p.String(); !strings.Contains(got, "level: DEBUG (compiled: ") {
+		t.Errorf("p.String() = %q, want it to contain %q", got, "level: DEBUG (compiled: ")
+	}
+	if got, want := p.String(), "\nwriter: multi\n  - text (level INFO) output=struct { io.Writer }\n  - file (level DEBUG) compression=gzip format=json path="+path; !strings.Contains(got, want) {
+		t.Errorf("p.String() = %q, want it to contain %q", got, want)
+	}
+}
+
+func TestDescribeWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		writer   LogWriter
+		expected string
+	}{
+		{
+			name:     "json",
+			writer:   NewJSONWriter(&bytes.Buffer{}, SortKeys(), RecordFraming(FramingJSONSeq)),
+			expected: "json framing=json-seq output=*bytes.Buffer sort_keys=true",
+		},
+		{
+			name:     "bootstrap",
+			writer:   newBootstrapWriter(&bytes.Buffer{}),
+			expected: "bootstrap\n  - text output=*bytes.Buffer",
+		},
+		{
+			name:     "undescribed",
+			writer:   &captureWriter{},
+			expected: "*golog.captureWriter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeWriter(tt.writer).String(); got != tt.expected {
+				t.Errorf("DescribeWriter(tt.writer).String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package golog
+
+// freeDiskSpace is not supported on this platform; the disk space check of
+// the file writer is skipped.
+func freeDiskSpace(string) (uint64, bool) {
+	return 0, false
+}
@@ -4,22 +4,51 @@
 // # Architecture
 //
 // Golog uses three main concepts:
-//   - Writers: Implement LogWriter to control where and how logs are formatted (e.g., JSON, human-readable).
-//   - Scopes: LogScope holds fields and context; create scopes with With, WithFields, WithContext, WithError, or WithPairs.
-//   - Enrichers: Implement Enricher to add fields to log entries; register globally with RegisterEnricher.
+//   - Writers: Implement LogWriter to control where and how logs are
+//     formatted (e.g., JSON, human-readable).
+//   - Scopes: LogScope holds fields and context; create scopes with With,
+//     WithFields, WithContext, WithError, or WithPairs.
+//   - Enrichers: Implement Enricher to add fields to log entries; register
+//     globally with RegisterEnricher.
 //
 // # Features
 //
 //   - Structured JSON or text logging
 //   - Context support for request-scoped fields
 //   - Field enrichment via Enricher
-//   - Multiple log levels (Debug, Info, Error)
+//   - Multiple log levels (Trace, Debug, Info, Warn, Error, Panic, Fatal)
 //   - Flushable output
 //
+// # Startup
+//
+// Until SetWriter is called, entries are written synchronously to os.Stderr in
+// the text format without buffering, so errors logged before logging is
+// configured (e.g. while parsing configuration) are not lost if the process
+// exits early. Every package-level function works before any configuration,
+// including from init functions.
+//
+// Tests that configure the global state restore it with ResetForTesting, so
+// that a writer or a rule set by one test does not leak into the next ones.
+//
+// # Compile-time levels
+//
+// Build tags remove levels from the binary: with golog_max_level_debug, Trace
+// calls compile to nothing, with golog_max_level_info, Trace and Debug calls
+// do, with golog_max_level_warn, Trace, Debug, and Info calls do, and with
+// golog_max_level_error, Trace, Debug, Info, and Warn calls do. Use them for
+// release builds that need the smallest binary and no hot-path overhead:
+//
+//	go build -tags golog_max_level_info ./cmd/server
+//
+// The arguments of a removed call are still evaluated, so avoid expensive
+// expressions in Debug arguments.
+//
 // # Thread Safety
 //
-// LogScope is not safe for concurrent use; create a new scope per goroutine or operation.
-// LogWriter implementations (NewDefaultWriter, NewJSONWriter) are thread-safe.
+// The package-level functions and Loggers are safe for concurrent use. A
+// LogScope can be shared once configured: the methods adding fields return a
+// child scope. LogWriter implementations (NewDefaultWriter, NewJSONWriter)
+// are thread-safe.
 //
 // # Example
 //
@@ -31,6 +60,7 @@
 //	golog.WithError(err).Error("Operation failed")
 //	golog.WithContext(ctx).WithFields(map[string]any{"request_id": "abc"}).Info("Request processed")
 //	golog.WithPairs("user_id", 123, "action", "login").Info("User logged in")
+//	golog.WithTime(event.OccurredAt).Info("Event replayed")
 //	golog.SetLevel(golog.LevelDebug)
 //	golog.SetSkipFrames(2)
 package golog
@@ -0,0 +1,159 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DroppedEntriesMessage is the message of the entries reporting dropped
+// entries.
+const DroppedEntriesMessage = "golog dropped entries"
+
+// Reasons passed to RecordDropped by the built-in writers
+const (
+	// DropQueueFull counts entries dropped because a writer's queue was full
+	DropQueueFull = "queue_full"
+	// DropExportFailed counts entries dropped after their export failed
+	DropExportFailed = "export_failed"
+	// DropLowDiskSpace counts entries dropped by a file writer in emergency
+	// mode (see MinFreeDiskSpace)
+	DropLowDiskSpace = "low_disk_space"
+	// DropHandlerFailed counts entries the slog.Handler of a writer created
+	// with NewSlogWriter failed to handle
+	DropHandlerFailed = "handler_failed"
+	// DropWriterFailed counts entries a writer behind NewMultiWriter failed
+	// to write because it panicked
+	DropWriterFailed = "writer_failed"
+	// DropSampled counts entries dropped by a writer created with
+	// NewSampler
+	DropSampled = "sampled"
+)
+
+// defaultDropReportInterval is the minimum time between two drop reports.
+const defaultDropReportInterval = 10 * time.Second
+
+// drops counts the entries dropped since the last report.
+var drops = &dropCounter{interval: defaultDropReportInterval}
+
+// RecordDropped counts n entries dropped for reason. The built-in writers
+// call it when they drop entries; samplers, rate limiters, and filters
+// outside golog call it so that their data loss is reported too.
+//
+// The counts are reported as a single Error entry with the message
+// "golog dropped entries", written to the current writer with the next
+// entry logged once the report interval has passed (see
+// SetDropReportInterval), and by Flush. Its fields are:
+//
+//   - dropped: the number of entries dropped since the last report
+//   - dropped_by_reason: the counts by reason
+//   - interval: the time since the last report, or since the start
+func RecordDropped(reason string, n int) {
+	drops.record(reason, n)
+}
+
+// SetDropReportInterval sets the minimum time between two reports of
+// dropped entries. The default is 10 seconds. Zero disables the reports.
+func SetDropReportInterval(interval time.Duration) {
+	drops.mu.Lock()
+	defer drops.mu.Unlock()
+
+	drops.interval = interval
+}
+
+// dropCounter accumulates dropped entries by reason between reports.
+type dropCounter struct {
+	// pending is set when drops were recorded since the last report, so that
+	// the write path only pays for an atomic load
+	pending atomic.Bool
+
+	mu       sync.Mutex
+	interval time.Duration
+	counts   map[string]int
+	// lastReport is when the counts were last reported
+	lastReport time.Time
+	// start is when the first drop since the last report was recorded
+	start time.Time
+}
+
+// reset discards the counts and restores the default report interval.
+func (d *dropCounter) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.interval = defaultDropReportInterval
+	d.counts = nil
+	d.lastReport = time.Time{}
+	d.start = time.Time{}
+	d.pending.Store(false)
+}
+
+// record counts n entries dropped for reason.
+func (d *dropCounter) record(reason string, n int) {
+	if n <= 0 {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.interval <= 0 {
+		return
+	}
+
+	if d.counts == nil {
+		d.counts = make(map[string]int)
+		if d.lastReport.IsZero() {
+			d.start = time.Now()
+		} else {
+			d.start = d.lastReport
+		}
+	}
+
+	d.counts[reason] += n
+	d.pending.Store(true)
+}
+
+// take returns the fields of the drop report and resets the counts, if drops
+// are pending and the report interval has passed since the last report or
+// force is set.
+func (d *dropCounter) take(force bool) (map[string]any, bool) {
+	if !d.pending.Load() {
+		return nil, false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	if d.counts == nil || (!force && now.Sub(d.lastReport) < d.interval) {
+		return nil, false
+	}
+
+	total := 0
+	for _, n := range d.counts {
+		total += n
+	}
+
+	fields := map[string]any{
+		"dropped":           total,
+		"dropped_by_reason": d.counts,
+		"interval":          now.Sub(d.start).Round(time.Millisecond).String(),
+	}
+
+	d.counts = nil
+	d.lastReport = now
+	d.pending.Store(false)
+
+	return fields, true
+}
+
+// reportDropped writes the drop report to writer when one is due.
+func reportDropped(writer LogWriter, force bool) {
+	fields, ok := drops.take(force)
+	if !ok {
+		return
+	}
+
+	writer.Write(LevelError, DroppedEntriesMessage, fields)
+}
@@ -0,0 +1,97 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// resetDrops replaces the drop counter for the duration of the test.
+func resetDrops(t *testing.T) {
+	t.Helper()
+
+	old := drops
+	drops = &dropCounter{interval: defaultDropReportInterval}
+	t.Cleanup(func() { drops = old })
+}
+
+func TestRecordDropped(t *testing.T) {
+	resetDrops(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	Info("nothing dropped yet")
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+
+	RecordDropped("sampled", 3)
+	RecordDropped(DropQueueFull, 1)
+	RecordDropped("sampled", 2)
+	RecordDropped("ignored", 0)
+
+	Info("first entry after drops")
+	if len(w.entries) != 3 {
+		t.Fatalf("the report is written before the entry: len(w.entries) = %d, want 3", len(w.entries))
+	}
+
+	report := w.entries[1]
+	if report.level != LevelError {
+		t.Errorf("report.level = %v, want %v", report.level, LevelError)
+	}
+	if report.msg != DroppedEntriesMessage {
+		t.Errorf("report.msg = %q, want %q", report.msg, DroppedEntriesMessage)
+	}
+	if report.fields["dropped"] != 6 {
+		t.Errorf(`report.fields["dropped"] = %v, want 6`, report.fields["dropped"])
+	}
+	want := map[string]int{"sampled": 5, DropQueueFull: 1}
+	if !reflect.DeepEqual(report.fields["dropped_by_reason"], want) {
+		t.Errorf(`report.fields["dropped_by_reason"] = %v, want %v`, report.fields["dropped_by_reason"], want)
+	}
+	if _, ok := report.fields["interval"]; !ok {
+		t.Errorf("report.fields has no key %q", "interval")
+	}
+
+	RecordDropped("sampled", 1)
+	Info("within the interval")
+	if len(w.entries) != 4 {
+		t.Errorf("reports are throttled by the interval: len(w.entries) = %d, want 4", len(w.entries))
+	}
+
+	Flush()
+	if len(w.entries) != 5 {
+		t.Fatalf("Flush writes pending reports: len(w.entries) = %d, want 5", len(w.entries))
+	}
+	if got, want := w.last().fields["dropped_by_reason"], map[string]int{"sampled": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`w.last().fields["dropped_by_reason"] = %v, want %v`, got, want)
+	}
+
+	Flush()
+	if len(w.entries) != 5 {
+		t.Errorf("nothing left to report: len(w.entries) = %d, want 5", len(w.entries))
+	}
+}
+
+func TestSetDropReportInterval(t *testing.T) {
+	resetDrops(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	SetDropReportInterval(time.Nanosecond)
+	RecordDropped("sampled", 1)
+	Info("first")
+	RecordDropped("sampled", 1)
+	Info("second")
+	if len(w.entries) != 4 {
+		t.Errorf("a report precedes each entry: len(w.entries) = %d, want 4", len(w.entries))
+	}
+
+	SetDropReportInterval(0)
+	RecordDropped("sampled", 1)
+	Info("disabled")
+	Flush()
+	if len(w.entries) != 5 {
+		t.Errorf("reports are disabled: len(w.entries) = %d, want 5", len(w.entries))
+	}
+}
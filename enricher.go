@@ -5,6 +5,8 @@ import "context"
 // Enricher defines the interface for log entry enrichment.
 // Enrichers add additional fields to log entries based on context.
 // The fields map is modified in place; add keys to enrich the log entry.
+// Enrich may be called from multiple goroutines at once, so implementations
+// must be safe for concurrent use.
 type Enricher interface {
 	// Enrich adds additional fields to a log entry based on the context.
 	// Modify the fields map in place; do not replace it.
@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"context"
+	"time"
+)
+
+// Entry is a single log event as delivered to an EntryWriter.
+type Entry struct {
+	// Time is when the event happened. It is the write time unless the scope
+	// overrides it with WithTime.
+	Time time.Time
+	// Level is the severity of the event (LevelDebug, LevelInfo, ...).
+	Level int
+	// Message is the formatted log message.
+	Message string
+	// Fields contains the custom fields of the event.
+	Fields map[string]any
+	// Err is the error of the event, passed to WithError, WithReturnedError,
+	// or ErrorIf, for writers and hooks that report errors, e.g. with their
+	// stack trace (see ErrorStack). It is nil for other events.
+	Err error
+	// Context is the context of the scope that logged the event (see
+	// LogScope.WithContext), for writers that read request-scoped values
+	// such as the active span. It may be nil for entries built elsewhere.
+	Context context.Context
+}
+
+// EntryWriter is an optional interface for LogWriter implementations that
+// want the complete Entry, including an explicit event time set with
+// LogScope.WithTime. The built-in writers implement it.
+// LogWriters that only implement Write stamp entries with the time they
+// are written.
+type EntryWriter interface {
+	// WriteEntry writes a log entry. The entry must not be retained after
+	// WriteEntry returns.
+	WriteEntry(entry Entry)
+}
+
+// locatedWriter is implemented by the built-in writers. Writers that wrap
+// them use it to pass along the caller location they resolved, since the
+// extra stack frame would otherwise shift the location the inner writer
+// reports.
+type locatedWriter interface {
+	// write writes entry with the given caller location. The time of entry
+	// is set.
+	write(entry Entry, file string, line int)
+}
+
+// entryTime returns the time to render for entry, defaulting to now when unset.
+func entryTime(entry Entry) time.Time {
+	if entry.Time.IsZero() {
+		return time.Now()
+	}
+
+	return entry.Time
+}
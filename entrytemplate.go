@@ -0,0 +1,90 @@
+package golog
+
+import (
+	"strings"
+	"text/template"
+	"time"
+)
+
+// EntryTemplate formats entries as text, such as the message of an alert
+// sent to Slack, email, or PagerDuty, from a template configured per rule
+// rather than from code.
+//
+// Templates use the text/template syntax, with the entry as:
+//
+//   - .Time: the time of the entry
+//   - .Level: the level name, e.g. "ERROR"
+//   - .Msg: the message
+//   - .Fields: the fields, e.g. {{.Fields.user_id}}
+//
+// and the function default, which replaces a missing or empty value:
+// {{.Fields.user_id | default "anonymous"}}.
+type EntryTemplate struct {
+	tmpl *template.Template
+}
+
+// entryTemplateData is the data an EntryTemplate is executed with.
+type entryTemplateData struct {
+	Time   time.Time
+	Level  string
+	Msg    string
+	Fields map[string]any
+}
+
+// entryTemplateFuncs are the functions available to entry templates.
+var entryTemplateFuncs = template.FuncMap{
+	"default": func(fallback, value any) any {
+		if value == nil || value == "" {
+			return fallback
+		}
+
+		return value
+	},
+}
+
+// ParseEntryTemplate parses text as an EntryTemplate.
+//
+// Example:
+//
+//	tmpl, err := golog.ParseEntryTemplate("{{.Fields.user_id}} failed {{.Msg}}")
+//	if err != nil {
+//	    return err
+//	}
+//	text, err := tmpl.Execute(entry)
+func ParseEntryTemplate(text string) (*EntryTemplate, error) {
+	tmpl, err := template.New("entry").Funcs(entryTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EntryTemplate{tmpl: tmpl}, nil
+}
+
+// MustParseEntryTemplate is like ParseEntryTemplate but panics if text
+// cannot be parsed. Use it for templates defined in code.
+func MustParseEntryTemplate(text string) *EntryTemplate {
+	tmpl, err := ParseEntryTemplate(text)
+	if err != nil {
+		panic(err)
+	}
+
+	return tmpl
+}
+
+// Execute returns the text of entry. Missing fields are written as
+// "<no value>" unless the template gives them a default.
+func (t *EntryTemplate) Execute(entry Entry) (string, error) {
+	var sb strings.Builder
+
+	err := t.tmpl.Execute(&sb, entryTemplateData{
+		Time:   entry.Time,
+		Level:  LevelString(entry.Level),
+		Msg:    entry.Message,
+		Fields: entry.Fields,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,80 @@
+package golog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEntryTemplate(t *testing.T) {
+	entry := Entry{
+		Time:    time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC),
+		Level:   LevelError,
+		Message: "checkout",
+		Fields:  map[string]any{"user_id": 42, "cart": map[string]any{"items": 3}},
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{
+			name:     "fields-and-message",
+			text:     "{{.Fields.user_id}} failed {{.Msg}}",
+			expected: "42 failed checkout",
+		},
+		{
+			name:     "level-and-time",
+			text:     `[{{.Level}}] {{.Time.Format "15:04:05"}}`,
+			expected: "[ERROR] 12:34:56",
+		},
+		{
+			name:     "nested-field",
+			text:     "{{.Fields.cart.items}} items",
+			expected: "3 items",
+		},
+		{
+			name:     "missing-field",
+			text:     "{{.Fields.tenant}}",
+			expected: "<no value>",
+		},
+		{
+			name:     "default",
+			text:     `{{.Fields.tenant | default "unknown"}} / {{.Fields.user_id | default "anonymous"}}`,
+			expected: "unknown / 42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseEntryTemplate(tt.text)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			text, err := tmpl.Execute(entry)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text != tt.expected {
+				t.Errorf("text = %q, want %q", text, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEntryTemplate_Invalid(t *testing.T) {
+	_, err := ParseEntryTemplate("{{.Msg")
+	if err == nil {
+		t.Error("expected an error")
+	}
+	if recovered(func() { MustParseEntryTemplate("{{.Msg") }) == nil {
+		t.Error("MustParseEntryTemplate should panic on an invalid template")
+	}
+
+	tmpl := MustParseEntryTemplate("{{.Msg.Length}}")
+	_, err = tmpl.Execute(Entry{Message: "failed"})
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,155 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"sync/atomic"
+)
+
+// FieldErrorKind is the key of the field holding the class of an error (see
+// SetErrorKinds).
+const FieldErrorKind = "error_kind"
+
+// Error kinds of the default taxonomy
+const (
+	// ErrorKindTimeout is a deadline that was exceeded
+	ErrorKindTimeout = "timeout"
+	// ErrorKindCanceled is an operation that was canceled
+	ErrorKindCanceled = "canceled"
+	// ErrorKindNotFound is a resource that does not exist
+	ErrorKindNotFound = "not_found"
+	// ErrorKindConflict is a resource that already exists
+	ErrorKindConflict = "conflict"
+	// ErrorKindIO is a failed read, write, or closed stream
+	ErrorKindIO = "io"
+	// ErrorKindUnknown is an error that matches no kind of the taxonomy
+	ErrorKindUnknown = "unknown"
+)
+
+// ErrorKind is a class of errors in an error taxonomy.
+type ErrorKind struct {
+	// Kind is the value of the error_kind field for matching errors
+	Kind string
+	// Match reports whether err belongs to the class
+	Match func(err error) bool
+}
+
+// ErrorIs returns an ErrorKind matching errors for which errors.Is reports
+// true with any of targets.
+func ErrorIs(kind string, targets ...error) ErrorKind {
+	return ErrorKind{
+		Kind: kind,
+		Match: func(err error) bool {
+			for _, target := range targets {
+				if errors.Is(err, target) {
+					return true
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// ErrorAs returns an ErrorKind matching errors for which errors.As finds an
+// error of type T in the chain.
+func ErrorAs[T error](kind string) ErrorKind {
+	return ErrorKind{
+		Kind: kind,
+		Match: func(err error) bool {
+			var target T
+			return errors.As(err, &target)
+		},
+	}
+}
+
+// DefaultErrorKinds returns the default error taxonomy. It classifies the
+// errors of the standard library:
+//
+//   - timeout: context.DeadlineExceeded, os.ErrDeadlineExceeded, and errors
+//     with a Timeout method returning true, such as net.Error
+//   - canceled: context.Canceled
+//   - not_found: fs.ErrNotExist
+//   - conflict: fs.ErrExist
+//   - io: io.EOF, io.ErrUnexpectedEOF, io.ErrClosedPipe, fs.ErrClosed, and
+//     *fs.PathError
+func DefaultErrorKinds() []ErrorKind {
+	return []ErrorKind{
+		ErrorIs(ErrorKindTimeout, context.DeadlineExceeded, os.ErrDeadlineExceeded),
+		{Kind: ErrorKindTimeout, Match: isTimeout},
+		ErrorIs(ErrorKindCanceled, context.Canceled),
+		ErrorIs(ErrorKindNotFound, fs.ErrNotExist),
+		ErrorIs(ErrorKindConflict, fs.ErrExist),
+		ErrorIs(ErrorKindIO, io.EOF, io.ErrUnexpectedEOF, io.ErrClosedPipe, fs.ErrClosed),
+		ErrorAs[*fs.PathError](ErrorKindIO),
+	}
+}
+
+// errorKinds holds the taxonomy set with SetErrorKinds; nil disables the
+// classification.
+var errorKinds atomic.Pointer[[]ErrorKind]
+
+// SetErrorKinds enables the error_kind field: WithError classifies its error
+// with kinds and adds the kind of the first match, or "unknown", so error
+// dashboards can be sliced by cause without matching messages. Pass
+// DefaultErrorKinds, extended with the errors of your domain as needed.
+// A nil taxonomy disables the field, which is the default.
+//
+// Example:
+//
+//	golog.SetErrorKinds(append(golog.DefaultErrorKinds(),
+//	    golog.ErrorIs("not_found", sql.ErrNoRows),
+//	    golog.ErrorAs[*pgconn.PgError]("database"),
+//	))
+func SetErrorKinds(kinds []ErrorKind) {
+	if kinds == nil {
+		errorKinds.Store(nil)
+		return
+	}
+
+	kinds = append([]ErrorKind(nil), kinds...)
+	errorKinds.Store(&kinds)
+}
+
+// ClassifyError returns the kind of err in the taxonomy set with
+// SetErrorKinds, or in the default taxonomy when none is set. It returns
+// "unknown" when no kind matches.
+func ClassifyError(err error) string {
+	kinds := DefaultErrorKinds()
+	if set := errorKinds.Load(); set != nil {
+		kinds = *set
+	}
+
+	return classifyError(kinds, err)
+}
+
+// classifyError returns the kind of the first of kinds matching err.
+func classifyError(kinds []ErrorKind, err error) string {
+	for _, kind := range kinds {
+		if kind.Match(err) {
+			return kind.Kind
+		}
+	}
+
+	return ErrorKindUnknown
+}
+
+// errorKind returns the error_kind field value for err, and false when the
+// classification is disabled.
+func errorKind(err error) (string, bool) {
+	kinds := errorKinds.Load()
+	if kinds == nil {
+		return "", false
+	}
+
+	return classifyError(*kinds, err), true
+}
+
+// isTimeout reports whether err has a Timeout method returning true.
+func isTimeout(err error) bool {
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
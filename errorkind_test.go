@@ -0,0 +1,126 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"testing"
+)
+
+// timeoutError is an error with a Timeout method, like net.Error.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// conflictError is a domain error of a custom taxonomy.
+type conflictError struct{ id string }
+
+func (e *conflictError) Error() string { return "version conflict on " + e.id }
+
+func TestClassifyError(t *testing.T) {
+	errStale := errors.New("stale read")
+
+	tests := []struct {
+		name     string
+		kinds    []ErrorKind
+		err      error
+		expected string
+	}{
+		{
+			name:     "deadline",
+			err:      fmt.Errorf("query: %w", context.DeadlineExceeded),
+			expected: ErrorKindTimeout,
+		},
+		{
+			name:     "timeout-method",
+			err:      fmt.Errorf("dial: %w", timeoutError{}),
+			expected: ErrorKindTimeout,
+		},
+		{
+			name:     "canceled",
+			err:      context.Canceled,
+			expected: ErrorKindCanceled,
+		},
+		{
+			name:     "not-found",
+			err:      &fs.PathError{Op: "open", Path: "/etc/app.yaml", Err: fs.ErrNotExist},
+			expected: ErrorKindNotFound,
+		},
+		{
+			name:     "conflict",
+			err:      fmt.Errorf("create: %w", os.ErrExist),
+			expected: ErrorKindConflict,
+		},
+		{
+			name:     "io",
+			err:      fmt.Errorf("read body: %w", io.ErrUnexpectedEOF),
+			expected: ErrorKindIO,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("boom"),
+			expected: ErrorKindUnknown,
+		},
+		{
+			name:     "custom-is",
+			kinds:    append(DefaultErrorKinds(), ErrorIs("stale", errStale)),
+			err:      fmt.Errorf("get: %w", errStale),
+			expected: "stale",
+		},
+		{
+			name:     "custom-as",
+			kinds:    []ErrorKind{ErrorAs[*conflictError](ErrorKindConflict)},
+			err:      fmt.Errorf("save: %w", &conflictError{id: "order-1"}),
+			expected: ErrorKindConflict,
+		},
+		{
+			name:     "custom-replaces-default",
+			kinds:    []ErrorKind{ErrorIs("stale", errStale)},
+			err:      context.Canceled,
+			expected: ErrorKindUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetErrorKinds(tt.kinds)
+			t.Cleanup(func() { SetErrorKinds(nil) })
+
+			if got := ClassifyError(tt.err); got != tt.expected {
+				t.Errorf("ClassifyError(tt.err) = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWithError_ErrorKind(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	WithError(context.Canceled).Info("request aborted")
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if _, ok := w.last().fields[FieldErrorKind]; ok {
+		t.Errorf("classification is disabled by default: w.last().fields has key %q, want none", FieldErrorKind)
+	}
+
+	SetErrorKinds(DefaultErrorKinds())
+	t.Cleanup(func() { SetErrorKinds(nil) })
+
+	WithError(fmt.Errorf("fetch: %w", context.DeadlineExceeded)).Info("request aborted")
+	if len(w.entries) != 2 {
+		t.Fatalf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	if got := w.last().fields[FieldErrorKind]; got != ErrorKindTimeout {
+		t.Errorf("w.last().fields[FieldErrorKind] = %q, want %q", got, ErrorKindTimeout)
+	}
+	if got := w.last().fields["error"]; got != "fetch: context deadline exceeded" {
+		t.Errorf(`w.last().fields["error"] = %q, want %q`, got, "fetch: context deadline exceeded")
+	}
+}
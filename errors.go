@@ -0,0 +1,355 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxStackDepth is the maximum number of frames recorded by StackErrors.
+const maxStackDepth = 32
+
+// ErrorWrapper creates the errors returned by LogScope.Error and Error.
+// Implement it to return errors from your preferred error library; see
+// SetErrorWrapper.
+type ErrorWrapper interface {
+	// New returns a new error with the message msg.
+	New(msg string) error
+	// Wrap returns an error with the message msg that wraps err, so that
+	// errors.Is and errors.As still match err.
+	Wrap(err error, msg string) error
+}
+
+// errorWrapper creates the errors returned by LogScope.Error.
+var errorWrapper ErrorWrapper = StdErrors()
+
+// SetErrorWrapper sets the ErrorWrapper used to create the errors returned
+// by LogScope.Error and Error. The default is StdErrors. Call it at startup,
+// before logging starts.
+//
+// Example using github.com/pkg/errors:
+//
+//	type pkgErrors struct{}
+//
+//	func (pkgErrors) New(msg string) error            { return errors.New(msg) }
+//	func (pkgErrors) Wrap(err error, msg string) error { return errors.Wrap(err, msg) }
+//
+//	golog.SetErrorWrapper(pkgErrors{})
+func SetErrorWrapper(wrapper ErrorWrapper) {
+	if wrapper == nil {
+		wrapper = StdErrors()
+	}
+
+	errorWrapper = wrapper
+}
+
+// StdErrors returns an ErrorWrapper based on the standard library: New uses
+// errors.New and Wrap uses fmt.Errorf with %w, producing "msg: err".
+func StdErrors() ErrorWrapper {
+	return stdErrors{}
+}
+
+// stdErrors implements ErrorWrapper with the standard library.
+type stdErrors struct{}
+
+// New implements ErrorWrapper.
+func (stdErrors) New(msg string) error {
+	return errors.New(msg)
+}
+
+// Wrap implements ErrorWrapper.
+func (stdErrors) Wrap(err error, msg string) error {
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// StackErrors returns an ErrorWrapper that behaves like StdErrors, and also
+// records the stack trace of the logging call in each error. Formatting the
+// error with %+v prints the message followed by the stack trace, as the JSON
+// writer does for error fields.
+//
+// Example:
+//
+//	golog.SetErrorWrapper(golog.StackErrors())
+func StackErrors() ErrorWrapper {
+	return stackErrors{}
+}
+
+// scopedError is an error returned by LogScope.Error, with a snapshot of the
+// scope's fields for WithReturnedError.
+type scopedError struct {
+	err    error
+	fields map[string]any
+}
+
+// Error implements error.
+func (e *scopedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the error created by the ErrorWrapper.
+func (e *scopedError) Unwrap() error {
+	return e.err
+}
+
+// Format implements fmt.Formatter, formatting the error created by the
+// ErrorWrapper, so that verbs such as %+v keep their meaning.
+func (e *scopedError) Format(s fmt.State, verb rune) {
+	if f, ok := e.err.(fmt.Formatter); ok {
+		f.Format(s, verb)
+		return
+	}
+
+	fmt.Fprintf(s, fmt.FormatString(s, verb), e.err)
+}
+
+// stackErrors implements ErrorWrapper with errors that record a stack trace.
+type stackErrors struct{}
+
+// New implements ErrorWrapper.
+func (stackErrors) New(msg string) error {
+	return &stackError{msg: msg, stack: callers()}
+}
+
+// Wrap implements ErrorWrapper.
+func (stackErrors) Wrap(err error, msg string) error {
+	return &stackError{msg: msg + ": " + err.Error(), err: err, stack: callers()}
+}
+
+// stackError is an error with the stack trace of where it was created.
+type stackError struct {
+	msg   string
+	err   error
+	stack []uintptr
+}
+
+// Error implements error.
+func (e *stackError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the wrapped error, if any.
+func (e *stackError) Unwrap() error {
+	return e.err
+}
+
+// StackTrace returns the program counters of the stack trace, the method
+// error trackers such as Sentry look for to report it.
+func (e *stackError) StackTrace() []uintptr {
+	return e.stack
+}
+
+// Format implements fmt.Formatter. The %+v verb adds the stack trace, one
+// "function\n\tfile:line" pair per frame.
+func (e *stackError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.msg)
+		if s.Flag('+') {
+			frames := runtime.CallersFrames(e.stack)
+			for {
+				frame, more := frames.Next()
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+				if !more {
+					break
+				}
+			}
+		}
+	case 's':
+		io.WriteString(s, e.msg)
+	case 'q':
+		fmt.Fprintf(s, "%q", e.msg)
+	}
+}
+
+// ErrorStack returns the stack trace recorded in err or in an error it
+// wraps, by StackErrors or by another package with a StackTrace method
+// returning program counters, such as github.com/pkg/errors, whose frames
+// are program counters of a named type, or nil when there is none.
+func ErrorStack(err error) []uintptr {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if stack := ownStack(e); stack != nil {
+			return stack
+		}
+	}
+
+	return nil
+}
+
+// ownStack returns the stack trace recorded in err itself, not in the
+// errors it wraps, or nil when there is none.
+func ownStack(err error) []uintptr {
+	if st, ok := err.(interface{ StackTrace() []uintptr }); ok {
+		return st.StackTrace()
+	}
+
+	return reflectedStack(err)
+}
+
+// reflectedStack returns the stack trace of err when it has a StackTrace
+// method returning a slice of program counters of another type, e.g. the
+// errors.StackTrace of github.com/pkg/errors, without depending on the
+// package.
+func reflectedStack(err error) []uintptr {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() {
+		return nil
+	}
+
+	typ := method.Type()
+	if typ.NumIn() != 0 || typ.NumOut() != 1 || typ.Out(0).Kind() != reflect.Slice || typ.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+
+	frames := method.Call(nil)[0]
+	stack := make([]uintptr, frames.Len())
+	for i := range stack {
+		stack[i] = uintptr(frames.Index(i).Uint())
+	}
+
+	return stack
+}
+
+// structuredError returns the object rendering err with StructuredErrors:
+// its message and type, its stack trace, and the errors it wraps, as set
+// with ErrorChainDepth and ErrorCauseFields.
+func (o writerOptions) structuredError(err error) map[string]any {
+	value := map[string]any{
+		"message": err.Error(),
+		"type":    fmt.Sprintf("%T", err),
+	}
+
+	if stack := ErrorStack(err); len(stack) > 0 {
+		value["stack"] = stackFrames(stack)
+	}
+
+	causes, truncated := errorChain(err, nil, o.errorChainDepth)
+
+	var chain []any
+	for _, cause := range causes {
+		chain = append(chain, o.causeValue(cause))
+	}
+
+	if len(chain) > 0 {
+		value["chain"] = chain
+	}
+
+	if truncated {
+		value["chain_truncated"] = true
+	}
+
+	return value
+}
+
+// causeValue returns the object rendering a cause in the chain of a
+// structured error, with the fields set with ErrorCauseFields.
+func (o writerOptions) causeValue(cause error) map[string]any {
+	fields := o.causeFields
+	if fields == nil {
+		fields = defaultCauseFields
+	}
+
+	value := make(map[string]any, len(fields))
+	for _, field := range fields {
+		switch field {
+		case CauseType:
+			value["type"] = fmt.Sprintf("%T", cause)
+		case CauseMessage:
+			value["message"] = cause.Error()
+		case CauseFrame:
+			// the frame of the cause itself, since the errors it wraps are
+			// rendered after it
+			if frames := stackFrames(ownStack(cause)); len(frames) > 0 {
+				value["frame"] = frames[0]
+			}
+		}
+	}
+
+	return value
+}
+
+// errorChain appends the errors wrapped by err to chain, depth first, in the
+// order errors.Is visits them, up to limit errors. It reports whether errors
+// were left out.
+func errorChain(err error, chain []error, limit int) ([]error, bool) {
+	var causes []error
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		causes = []error{e.Unwrap()}
+	case interface{ Unwrap() []error }:
+		causes = e.Unwrap()
+	}
+
+	truncated := false
+	for _, cause := range causes {
+		if cause == nil {
+			continue
+		}
+
+		if len(chain) >= limit {
+			return chain, true
+		}
+
+		chain, truncated = errorChain(cause, append(chain, cause), limit)
+		if truncated {
+			return chain, true
+		}
+	}
+
+	return chain, false
+}
+
+// stackFrames renders the frames of stack as "function file:line".
+func stackFrames(stack []uintptr) []string {
+	var rendered []string
+
+	frames := runtime.CallersFrames(stack)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" || frame.File != "" {
+			rendered = append(rendered, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		}
+
+		if !more {
+			return rendered
+		}
+	}
+}
+
+// callers returns the stack of the logging call that creates an error,
+// without the frames of golog's Error functions.
+func callers() []uintptr {
+	var pcs [maxStackDepth]uintptr
+	// skip runtime.Callers, callers, and the stackErrors method
+	n := runtime.Callers(3, pcs[:])
+	stack := pcs[:n]
+
+	for len(stack) > 0 {
+		fn := runtime.FuncForPC(stack[0] - 1)
+		if fn == nil || !isErrorFunc(fn.Name()) {
+			break
+		}
+
+		stack = stack[1:]
+	}
+
+	return append([]uintptr(nil), stack...)
+}
+
+// isErrorFunc reports whether name is one of golog's Error functions.
+func isErrorFunc(name string) bool {
+	pkg, fn, ok := strings.Cut(name, "golog.")
+	if !ok || !strings.HasSuffix(pkg, "jkaveri/") {
+		return false
+	}
+
+	switch fn {
+	case "Error", "(*LogScope).Error", "(*Logger).Error", "ErrorIf", "(*LogScope).ErrorIf", "(*Logger).ErrorIf",
+		"Errorf", "(*LogScope).Errorf", "(*Logger).Errorf", "(*LogScope).newError":
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,137 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// prefixErrors is an ErrorWrapper standing in for a third-party error library.
+type prefixErrors struct{}
+
+func (prefixErrors) New(msg string) error { return errors.New("custom: " + msg) }
+
+func (prefixErrors) Wrap(err error, msg string) error { return fmt.Errorf("custom: %s: %w", msg, err) }
+
+func TestLogScope_Error(t *testing.T) {
+	useWriter(t, &captureWriter{})
+	t.Cleanup(func() { SetErrorWrapper(nil) })
+
+	cause := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		wrapper   ErrorWrapper
+		cause     error
+		withError bool
+		expected  string
+	}{
+		{
+			name:     "std-new",
+			wrapper:  StdErrors(),
+			expected: "query failed",
+		},
+		{
+			name:     "std-wrap",
+			wrapper:  StdErrors(),
+			cause:    cause,
+			expected: "query failed: connection refused",
+		},
+		{
+			name:      "std-wrap-with-error",
+			wrapper:   StdErrors(),
+			cause:     cause,
+			withError: true,
+			expected:  "query failed: connection refused",
+		},
+		{
+			name:     "stack-wrap",
+			wrapper:  StackErrors(),
+			cause:    cause,
+			expected: "query failed: connection refused",
+		},
+		{
+			name:     "custom-wrap",
+			wrapper:  prefixErrors{},
+			cause:    cause,
+			expected: "custom: query failed: connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetErrorWrapper(tt.wrapper)
+
+			scope := With("table", "users")
+			switch {
+			case tt.withError:
+				scope = scope.WithError(tt.cause)
+			case tt.cause != nil:
+				scope = scope.With("error", tt.cause)
+			}
+
+			err := scope.Errorf("query %s", "failed")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := err.Error(); got != tt.expected {
+				t.Errorf("err.Error() = %q, want %q", got, tt.expected)
+			}
+			if tt.cause != nil {
+				if !errors.Is(err, tt.cause) {
+					t.Errorf("err = %v, want %v", err, tt.cause)
+				}
+			}
+		})
+	}
+}
+
+func TestStackErrors(t *testing.T) {
+	useWriter(t, &captureWriter{})
+	SetErrorWrapper(StackErrors())
+	t.Cleanup(func() { SetErrorWrapper(nil) })
+
+	err := Error("disk full")
+
+	if got := fmt.Sprintf("%v", err); got != "disk full" {
+		t.Errorf(`fmt.Sprintf("%%v", err) = %q, want %q`, got, "disk full")
+	}
+	trace := fmt.Sprintf("%+v", err)
+	start := regexp.MustCompile(`^disk full\ngithub.com/jkaveri/golog.TestStackErrors\n\t.*errors_test.go:\d+`)
+	if !start.MatchString(trace) {
+		t.Errorf("the stack starts at the logging call: trace = %q, want a match of %q", trace, start)
+	}
+}
+
+func TestWithReturnedError(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	save := func() error {
+		return With("order_id", "o-1").With("table", "orders").Error("insert failed")
+	}
+
+	err := fmt.Errorf("save order: %w", save())
+	With("table", "checkout").WithReturnedError(err).Warn("order not saved")
+
+	if len(w.entries) != 2 {
+		t.Fatalf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	want := map[string]any{
+		"order_id": "o-1",
+		"table":    "checkout",
+		"error":    "save order: insert failed",
+	}
+	if got := w.last().fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("the caller's fields win over the carried ones: w.last().fields = %v, want %v", got, want)
+	}
+
+	if got := fmt.Sprintf("%v", errors.Unwrap(err)); got != "insert failed" {
+		t.Errorf(`fmt.Sprintf("%%v", errors.Unwrap(err)) = %q, want %q`, got, "insert failed")
+	}
+	if got, want := WithReturnedError(errors.New("boom")).fields, map[string]any{"error": "boom"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`WithReturnedError(errors.New("boom")).fields = %v, want %v`, got, want)
+	}
+}
@@ -0,0 +1,429 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultExportBatchSize is the number of entries sent per Export call
+	defaultExportBatchSize = 100
+	// defaultExportInterval is how often pending entries are exported
+	defaultExportInterval = time.Second
+	// defaultExportTimeout bounds a single Export call
+	defaultExportTimeout = 10 * time.Second
+	// defaultExportRetries is the number of retries of a failed batch
+	defaultExportRetries = 3
+	// defaultExportBackoff is the delay before the first retry
+	defaultExportBackoff = 100 * time.Millisecond
+	// defaultExportQueueSize is the number of entries kept while exports lag
+	defaultExportQueueSize = 10000
+	// defaultClockDriftThreshold is the clock drift reported as ErrClockDrift
+	defaultClockDriftThreshold = 5 * time.Second
+	// httpDateLayout is the layout of HTTP Date headers (http.TimeFormat)
+	httpDateLayout = "Mon, 02 Jan 2006 15:04:05 GMT"
+)
+
+// FieldSentAt is the key of the time an entry was passed to the Exporter,
+// added by the export writer next to the event time. A custom field of the
+// same name is kept with the "fields_" prefix, as the JSON writer does under
+// ReservedFieldPrefix.
+const FieldSentAt = "sent_at"
+
+// ErrClockDrift is reported to the export writer's error handler when the
+// local clock differs from the server time observed with ObserveServerTime
+// by more than the threshold set with ExportClockDriftThreshold. It explains
+// entries that collectors see as coming from the future or the past.
+var ErrClockDrift = errors.New("golog: local clock drift")
+
+// Exporter sends batches of entries to an external service, such as a
+// vendor's logs API. Implementations only translate and send the entries;
+// the writer returned by NewExportWriter handles batching, retries, and
+// backpressure.
+//
+// Export is never called concurrently. It must honour ctx cancellation and
+// must not retain entries, or their Fields maps, after it returns: the writer
+// reuses them for later entries.
+type Exporter interface {
+	// Export sends entries. A non-nil error makes the batch be retried.
+	Export(ctx context.Context, entries []Entry) error
+}
+
+// ExportOption configures the writer created by NewExportWriter.
+type ExportOption func(*exportOptions)
+
+// exportOptions holds the settings of the export writer.
+type exportOptions struct {
+	// batchSize is the maximum number of entries per Export call
+	batchSize int
+	// interval is the maximum time an entry waits before being exported
+	interval time.Duration
+	// timeout bounds each Export call
+	timeout time.Duration
+	// retries is the number of times a failed batch is retried
+	retries int
+	// backoff is the delay before the first retry; it doubles per retry
+	backoff time.Duration
+	// queueSize is the maximum number of pending entries
+	queueSize int
+	// driftThreshold is the clock drift reported as ErrClockDrift
+	driftThreshold time.Duration
+	// errors reports dropped batches and entries
+	errors writerOptions
+}
+
+// ExportBatchSize sets the maximum number of entries passed to one Export
+// call. A full batch is exported without waiting for the interval.
+// The default is 100.
+func ExportBatchSize(size int) ExportOption {
+	return func(o *exportOptions) {
+		o.batchSize = size
+	}
+}
+
+// ExportInterval sets how often pending entries are exported.
+// The default is one second.
+func ExportInterval(interval time.Duration) ExportOption {
+	return func(o *exportOptions) {
+		o.interval = interval
+	}
+}
+
+// ExportTimeout bounds each Export call. The default is 10 seconds.
+func ExportTimeout(timeout time.Duration) ExportOption {
+	return func(o *exportOptions) {
+		o.timeout = timeout
+	}
+}
+
+// ExportRetries sets how many times a failed batch is retried, and the delay
+// before the first retry, which doubles on every further retry. A batch that
+// still fails is dropped and reported to the error handler.
+// The default is 3 retries starting at 100ms.
+func ExportRetries(retries int, backoff time.Duration) ExportOption {
+	return func(o *exportOptions) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// ExportQueueSize sets the maximum number of entries waiting to be exported.
+// While the queue is full, new entries are dropped and reported to the error
+// handler, so a slow or unreachable service never grows memory without bound.
+// The default is 10000.
+func ExportQueueSize(size int) ExportOption {
+	return func(o *exportOptions) {
+		o.queueSize = size
+	}
+}
+
+// ExportClockDriftThreshold sets how far the local clock may differ from the
+// server time observed by the Exporter (see ObserveServerTime) before
+// ErrClockDrift is reported. The default is 5 seconds.
+func ExportClockDriftThreshold(threshold time.Duration) ExportOption {
+	return func(o *exportOptions) {
+		o.driftThreshold = threshold
+	}
+}
+
+// ExportOnError sets the handler that receives export failures and dropped
+// entries. By default they are printed to os.Stderr.
+func ExportOnError(handler func(error)) ExportOption {
+	return func(o *exportOptions) {
+		o.errors.errorHandler = handler
+	}
+}
+
+// exportWriter implements the LogWriter interface by exporting batches of
+// entries with an Exporter from a background goroutine.
+type exportWriter struct {
+	exporter Exporter
+	opts     exportOptions
+
+	mu      sync.Mutex
+	pending []Entry
+	dropped int
+	closed  bool
+	// drifting is set while the observed clock drift exceeds the threshold
+	drifting bool
+
+	// exportMu serializes Export calls
+	exportMu sync.Mutex
+	// batch holds the entries being exported; guarded by exportMu
+	batch []Entry
+	// fields reuses the field maps of exported entries
+	fields fieldsPool
+	// wake is signalled when a full batch is pending
+	wake chan struct{}
+	// done is closed by Close to stop the background goroutine
+	done chan struct{}
+	// stopped is closed when the background goroutine has exited
+	stopped chan struct{}
+}
+
+// NewExportWriter creates a LogWriter that sends entries to exporter in
+// batches. Entries are queued in memory and exported from a background
+// goroutine every ExportInterval, or as soon as ExportBatchSize entries are
+// pending. Failed batches are retried with exponential backoff.
+//
+// Each exported entry carries its event time in Entry.Time and the time it
+// was passed to the Exporter in the "sent_at" field, so collectors can tell
+// delivery delays from clock skew. Exporters that learn the server time, for
+// example from an HTTP Date header, report it with ObserveServerDate or
+// ObserveServerTime to have local clock drift detected.
+//
+// Vendor integrations only need to implement Exporter:
+//
+//	writer := golog.NewExportWriter(honeycomb.NewExporter(apiKey))
+//	defer writer.Close()
+//	golog.SetWriter(writer)
+//
+// Flush exports all pending entries before returning. Close flushes and stops
+// the background goroutine; entries written after Close are dropped.
+func NewExportWriter(exporter Exporter, opts ...ExportOption) *exportWriter {
+	o := exportOptions{
+		batchSize: defaultExportBatchSize,
+		interval:  defaultExportInterval,
+		timeout:   defaultExportTimeout,
+		retries:   defaultExportRetries,
+		backoff:   defaultExportBackoff,
+		queueSize: defaultExportQueueSize,
+
+		driftThreshold: defaultClockDriftThreshold,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	o.batchSize = max(o.batchSize, 1)
+	o.queueSize = max(o.queueSize, 1)
+
+	w := &exportWriter{
+		exporter: exporter,
+		opts:     o,
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Write implements LogWriter.
+func (w *exportWriter) Write(level int, msg string, fields map[string]any) {
+	w.WriteEntry(Entry{Level: level, Message: msg, Fields: fields})
+}
+
+// WriteEntry implements EntryWriter. The entry's fields are copied, since the
+// entry is exported after WriteEntry returns. The copies are pooled and
+// reused once their batch is exported, so a steady flow of entries does not
+// allocate field maps (see PoolStats).
+func (w *exportWriter) WriteEntry(entry Entry) {
+	fields := w.fields.get()
+	maps.Copy(fields, entry.Fields)
+	if v, ok := fields[FieldSentAt]; ok {
+		delete(fields, FieldSentAt)
+		fields[reservedFieldPrefix+FieldSentAt] = v
+	}
+	entry.Time = entryTime(entry)
+	entry.Fields = fields
+
+	w.mu.Lock()
+	if w.closed || len(w.pending) >= w.opts.queueSize {
+		if !w.closed {
+			RecordDropped(DropQueueFull, 1)
+		}
+
+		w.dropped++
+		w.mu.Unlock()
+		w.fields.put(fields)
+		return
+	}
+
+	w.pending = append(w.pending, entry)
+	full := len(w.pending) >= w.opts.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush implements LogWriter by exporting all pending entries.
+func (w *exportWriter) Flush() {
+	w.exportPending(true)
+}
+
+// Close exports all pending entries and stops the background goroutine.
+func (w *exportWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.done)
+	<-w.stopped
+	w.exportPending(true)
+
+	return nil
+}
+
+// run exports pending entries every interval and whenever a batch is full.
+func (w *exportWriter) run() {
+	defer close(w.stopped)
+
+	ticker := time.NewTicker(w.opts.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.exportPending(true)
+		case <-w.wake:
+			w.exportPending(false)
+		}
+	}
+}
+
+// exportPending exports pending entries in batches. Unless all is set, only
+// full batches are exported.
+func (w *exportWriter) exportPending(all bool) {
+	w.exportMu.Lock()
+	defer w.exportMu.Unlock()
+
+	for {
+		w.mu.Lock()
+		if dropped := w.dropped; dropped > 0 {
+			w.dropped = 0
+			w.opts.errors.handleError(fmt.Errorf("golog: export queue full, dropped %d entries", dropped))
+		}
+
+		n := min(len(w.pending), w.opts.batchSize)
+		if n == 0 || (!all && n < w.opts.batchSize) {
+			w.mu.Unlock()
+			return
+		}
+
+		// the entries are moved to the batch buffer, so that pending keeps
+		// its backing array and neither slice grows in the steady state
+		w.batch = append(w.batch[:0], w.pending[:n]...)
+		w.pending = append(w.pending[:0], w.pending[n:]...)
+		w.mu.Unlock()
+
+		if err := w.export(w.batch); err != nil {
+			RecordDropped(DropExportFailed, len(w.batch))
+			w.opts.errors.handleError(fmt.Errorf("golog: export %d entries: %w", len(w.batch), err))
+		}
+
+		for i := range w.batch {
+			w.fields.put(w.batch[i].Fields)
+			w.batch[i] = Entry{}
+		}
+	}
+}
+
+// PoolStats returns the statistics of the pool of field maps used for
+// queued entries, for tuning the batch and queue sizes.
+func (w *exportWriter) PoolStats() PoolStats {
+	return w.fields.stats()
+}
+
+// export sends one batch, retrying failures with exponential backoff.
+func (w *exportWriter) export(batch []Entry) error {
+	backoff := w.opts.backoff
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		sentAt := time.Now()
+		for i := range batch {
+			batch[i].Fields[FieldSentAt] = sentAt
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), w.opts.timeout)
+		ctx = context.WithValue(ctx, clockObserverKey{}, w)
+		err = w.exporter.Export(ctx, batch)
+		cancel()
+
+		if err == nil || attempt >= w.opts.retries {
+			return err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// clockObserverKey is the context key of the export writer observing the
+// server time during an Export call.
+type clockObserverKey struct{}
+
+// ObserveServerTime reports the current server time, as learned by an
+// Exporter while exporting, to the export writer that called it with ctx.
+// The writer reports ErrClockDrift once when the local clock drifts from
+// the server time beyond the threshold, and again after it recovers and
+// drifts anew. It does nothing for other contexts.
+func ObserveServerTime(ctx context.Context, serverTime time.Time) {
+	w, ok := ctx.Value(clockObserverKey{}).(*exportWriter)
+	if !ok {
+		return
+	}
+
+	w.observeDrift(time.Now().Sub(serverTime), 0)
+}
+
+// ObserveServerDate is like ObserveServerTime for the value of an HTTP Date
+// header, e.g. ObserveServerDate(ctx, resp.Header.Get("Date")). The header's
+// one second resolution is taken into account. Invalid values are ignored.
+func ObserveServerDate(ctx context.Context, date string) {
+	serverTime, err := time.Parse(httpDateLayout, date)
+	if err != nil {
+		return
+	}
+
+	w, ok := ctx.Value(clockObserverKey{}).(*exportWriter)
+	if !ok {
+		return
+	}
+
+	w.observeDrift(time.Now().Sub(serverTime), time.Second)
+}
+
+// observeDrift updates the drift state from the difference between the
+// local and server clocks, where the server time may be up to resolution
+// behind, and reports ErrClockDrift when the drift starts.
+func (w *exportWriter) observeDrift(drift, resolution time.Duration) {
+	drifting := drift > w.opts.driftThreshold+resolution || drift < -w.opts.driftThreshold
+
+	w.mu.Lock()
+	started := drifting && !w.drifting
+	w.drifting = drifting
+	w.mu.Unlock()
+
+	if !started {
+		return
+	}
+
+	direction := "ahead of"
+	if drift < 0 {
+		direction, drift = "behind", -drift
+	}
+
+	w.opts.errors.handleError(fmt.Errorf("%w: local clock is %s %s the server", ErrClockDrift, drift.Round(time.Millisecond), direction))
+}
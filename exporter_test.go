@@ -0,0 +1,309 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordExporter records exported batches and fails the first failures calls.
+type recordExporter struct {
+	mu       sync.Mutex
+	batches  [][]Entry
+	calls    int
+	failures int
+}
+
+func (e *recordExporter) Export(_ context.Context, entries []Entry) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls++
+	if e.calls <= e.failures {
+		return errors.New("service unavailable")
+	}
+
+	// entries must not be retained, so they are copied
+	batch := make([]Entry, len(entries))
+	for i, entry := range entries {
+		entry.Fields = maps.Clone(entry.Fields)
+		batch[i] = entry
+	}
+
+	e.batches = append(e.batches, batch)
+
+	return nil
+}
+
+func (e *recordExporter) exported() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := 0
+	for _, batch := range e.batches {
+		n += len(batch)
+	}
+
+	return n
+}
+
+func TestExportWriter_Batches(t *testing.T) {
+	exporter := &recordExporter{}
+	writer := NewExportWriter(exporter, ExportBatchSize(2), ExportInterval(time.Hour))
+	defer writer.Close()
+
+	fields := map[string]any{"n": 1}
+	writer.Write(LevelInfo, "first", fields)
+	fields["n"] = 2
+	writer.Write(LevelError, "second", nil)
+	writer.Write(LevelInfo, "third", nil)
+
+	// a full batch is exported without waiting for the interval
+	eventually(t, func() bool { return exporter.exported() >= 2 })
+
+	writer.Flush()
+
+	if len(exporter.batches) != 2 {
+		t.Fatalf("len(exporter.batches) = %d, want 2", len(exporter.batches))
+	}
+	if len(exporter.batches[0]) != 2 {
+		t.Errorf("len(exporter.batches[0]) = %d, want 2", len(exporter.batches[0]))
+	}
+	if exporter.batches[0][0].Message != "first" {
+		t.Errorf("exporter.batches[0][0].Message = %q, want %q", exporter.batches[0][0].Message, "first")
+	}
+	if exporter.batches[0][0].Fields["n"] != 1 {
+		t.Errorf(`fields are copied: exporter.batches[0][0].Fields["n"] = %v, want 1`, exporter.batches[0][0].Fields["n"])
+	}
+	if exporter.batches[0][0].Time.IsZero() {
+		t.Error("exporter.batches[0][0].Time.IsZero() = true, want false")
+	}
+	if _, ok := exporter.batches[0][0].Fields[FieldSentAt].(time.Time); !ok {
+		t.Errorf("exporter.batches[0][0].Fields[FieldSentAt] = %T, want time.Time", exporter.batches[0][0].Fields[FieldSentAt])
+	}
+	if exporter.batches[0][1].Level != LevelError {
+		t.Errorf("exporter.batches[0][1].Level = %v, want %v", exporter.batches[0][1].Level, LevelError)
+	}
+	if exporter.batches[1][0].Message != "third" {
+		t.Errorf("exporter.batches[1][0].Message = %q, want %q", exporter.batches[1][0].Message, "third")
+	}
+}
+
+func TestExportWriter_SentAtCollision(t *testing.T) {
+	exporter := &recordExporter{}
+	writer := NewExportWriter(exporter, ExportInterval(time.Hour))
+	defer writer.Close()
+
+	writer.Write(LevelInfo, "notification sent", map[string]any{FieldSentAt: "2024-03-30T12:00:00Z"})
+	writer.Flush()
+
+	if exporter.exported() != 1 {
+		t.Fatalf("exporter.exported() = %d, want 1", exporter.exported())
+	}
+	fields := exporter.batches[0][0].Fields
+	if _, ok := fields[FieldSentAt].(time.Time); !ok {
+		t.Errorf("fields[FieldSentAt] = %T, want time.Time", fields[FieldSentAt])
+	}
+	if got := fields["fields_sent_at"]; got != "2024-03-30T12:00:00Z" {
+		t.Errorf(`the custom field is kept: fields["fields_sent_at"] = %v, want %q`, got, "2024-03-30T12:00:00Z")
+	}
+}
+
+func TestExportWriter_Retries(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		exported int
+		errors   int
+	}{
+		{
+			name:     "recovers",
+			failures: 2,
+			exported: 1,
+		},
+		{
+			name:     "gives-up",
+			failures: 10,
+			errors:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetDrops(t)
+
+			var errs []error
+			exporter := &recordExporter{failures: tt.failures}
+			writer := NewExportWriter(
+				exporter,
+				ExportInterval(time.Hour),
+				ExportRetries(2, time.Millisecond),
+				ExportOnError(func(err error) { errs = append(errs, err) }),
+			)
+
+			writer.Write(LevelInfo, "entry", nil)
+			if err := writer.Close(); err != nil {
+				t.Fatalf("writer.Close(): %v", err)
+			}
+
+			if got := exporter.exported(); got != tt.exported {
+				t.Errorf("exporter.exported() = %v, want %v", got, tt.exported)
+			}
+			if len(errs) != tt.errors {
+				t.Errorf("len(errs) = %d, want %d", len(errs), tt.errors)
+			}
+			if exporter.calls != 3 {
+				t.Errorf("one attempt plus two retries: exporter.calls = %v, want 3", exporter.calls)
+			}
+		})
+	}
+}
+
+func TestExportWriter_QueueFull(t *testing.T) {
+	resetDrops(t)
+
+	var errs []error
+	exporter := &recordExporter{}
+	writer := NewExportWriter(
+		exporter,
+		ExportBatchSize(10),
+		ExportQueueSize(2),
+		ExportInterval(time.Hour),
+		ExportOnError(func(err error) { errs = append(errs, err) }),
+	)
+
+	for range 5 {
+		writer.Write(LevelInfo, "entry", nil)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+	writer.Write(LevelInfo, "after close", nil)
+
+	if got := exporter.exported(); got != 2 {
+		t.Errorf("exporter.exported() = %v, want 2", got)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("len(errs) = %d, want 1", len(errs))
+	}
+	if got := errs[0].Error(); !strings.Contains(got, "dropped 3 entries") {
+		t.Errorf("errs[0].Error() = %q, want it to contain %q", got, "dropped 3 entries")
+	}
+	want := map[string]int{DropQueueFull: 3}
+	if !reflect.DeepEqual(drops.counts, want) {
+		t.Errorf("entries dropped after Close are not from a full queue: drops.counts = %v, want %v", drops.counts, want)
+	}
+}
+
+// dateExporter reports a server clock offset from the local clock.
+type dateExporter struct {
+	offset time.Duration
+	sentAt []any
+}
+
+func (e *dateExporter) Export(ctx context.Context, entries []Entry) error {
+	for _, entry := range entries {
+		e.sentAt = append(e.sentAt, entry.Fields[FieldSentAt])
+	}
+
+	ObserveServerDate(ctx, time.Now().Add(e.offset).UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"))
+
+	return nil
+}
+
+func TestExportWriter_ClockDrift(t *testing.T) {
+	var errs []error
+	exporter := &dateExporter{}
+	writer := NewExportWriter(
+		exporter,
+		ExportInterval(time.Hour),
+		ExportClockDriftThreshold(10*time.Second),
+		ExportOnError(func(err error) { errs = append(errs, err) }),
+	)
+	defer writer.Close()
+
+	eventTime := time.Now().Add(-time.Minute)
+	steps := []struct {
+		offset time.Duration
+		errors int
+	}{
+		{offset: 0, errors: 0},
+		{offset: -time.Minute, errors: 1},
+		{offset: -2 * time.Minute, errors: 1},
+		{offset: 0, errors: 1},
+		{offset: time.Minute, errors: 2},
+	}
+
+	for _, step := range steps {
+		exporter.offset = step.offset
+		writer.WriteEntry(Entry{Time: eventTime, Level: LevelInfo, Message: "tick"})
+		writer.Flush()
+
+		if len(errs) != step.errors {
+			t.Errorf("offset %s: len(errs) = %d, want %d", step.offset, len(errs), step.errors)
+		}
+	}
+
+	if len(errs) != 2 {
+		t.Fatalf("len(errs) = %d, want 2", len(errs))
+	}
+	if !errors.Is(errs[0], ErrClockDrift) {
+		t.Errorf("errs[0] = %v, want %v", errs[0], ErrClockDrift)
+	}
+	if got := errs[0].Error(); !strings.Contains(got, "ahead of the server") {
+		t.Errorf("errs[0].Error() = %q, want it to contain %q", got, "ahead of the server")
+	}
+	if got := errs[1].Error(); !strings.Contains(got, "behind the server") {
+		t.Errorf("errs[1].Error() = %q, want it to contain %q", got, "behind the server")
+	}
+
+	if len(exporter.sentAt) != len(steps) {
+		t.Fatalf("len(exporter.sentAt) = %d, want %d", len(exporter.sentAt), len(steps))
+	}
+	sentAt, ok := exporter.sentAt[0].(time.Time)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if !sentAt.After(eventTime) {
+		t.Error("send time is kept next to the event time")
+	}
+}
+
+func TestExportWriter_PoolsFields(t *testing.T) {
+	exporter := &recordExporter{}
+	writer := NewExportWriter(exporter, ExportBatchSize(10), ExportInterval(time.Hour))
+	defer writer.Close()
+
+	for i := 0; i < 20; i++ {
+		writer.Write(LevelInfo, "first", map[string]any{"first": i})
+		writer.Write(LevelInfo, "second", map[string]any{"second": i})
+		writer.Flush()
+	}
+
+	stats := writer.PoolStats()
+	if stats.Gets != uint64(40) {
+		t.Errorf("stats.Gets = %v, want %v", stats.Gets, uint64(40))
+	}
+	if stats.Puts != uint64(40) {
+		t.Errorf("fields are returned once exported: stats.Puts = %v, want %v", stats.Puts, uint64(40))
+	}
+	if stats.Misses >= stats.Gets {
+		t.Errorf("fields are reused: stats.Misses = %v, want less than %v", stats.Misses, stats.Gets)
+	}
+
+	if got := exporter.exported(); got != 40 {
+		t.Fatalf("exporter.exported() = %v, want 40", got)
+	}
+	last := exporter.batches[len(exporter.batches)-1]
+	if _, ok := last[1].Fields["first"]; ok {
+		t.Errorf("reused fields are cleared: last[1].Fields has key %q, want none", "first")
+	}
+	if last[1].Fields["second"] != 19 {
+		t.Errorf(`last[1].Fields["second"] = %v, want 19`, last[1].Fields["second"])
+	}
+}
@@ -0,0 +1,96 @@
+package golog
+
+import (
+	"maps"
+	"reflect"
+	"sync/atomic"
+)
+
+// fieldEncoders holds the registered field encoders as an immutable map.
+var fieldEncoders atomic.Pointer[map[reflect.Type]func(any) any]
+
+// RegisterFieldEncoder sets how the built-in writers render field values of
+// type t, so domain types such as decimals, UUIDs, or protobuf messages are
+// rendered the same way everywhere instead of being converted at each call
+// site. encode receives a value of type t and returns the value to write in
+// its place, typically a string, a map[string]any, or a json.RawMessage.
+// Encoders also apply to values nested in map[string]any and []any fields.
+// Registering a type again replaces its encoder; a nil encode removes it.
+//
+// When t is an interface type, such as proto.Message, the encoder applies to
+// every value implementing it. Encoders registered for a concrete type take
+// precedence.
+//
+// Register encoders at startup. RegisterFieldEncoder is safe to call
+// concurrently with logging.
+//
+// Example:
+//
+//	golog.RegisterFieldEncoder(reflect.TypeOf(decimal.Decimal{}), func(v any) any {
+//	    return v.(decimal.Decimal).String()
+//	})
+func RegisterFieldEncoder(t reflect.Type, encode func(any) any) {
+	for {
+		old := fieldEncoders.Load()
+
+		next := make(map[reflect.Type]func(any) any)
+		if old != nil {
+			maps.Copy(next, *old)
+		}
+
+		if encode == nil {
+			delete(next, t)
+		} else {
+			next[t] = encode
+		}
+
+		if fieldEncoders.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// encodeField applies the registered field encoders to v.
+func encodeField(v any) any {
+	encoders := fieldEncoders.Load()
+	if encoders == nil || len(*encoders) == 0 || v == nil {
+		return v
+	}
+
+	return encodeWith(*encoders, v)
+}
+
+// encodeWith applies encoders to v and to the values nested in it.
+func encodeWith(encoders map[reflect.Type]func(any) any, v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for k, item := range v {
+			converted[k] = encodeWith(encoders, item)
+		}
+
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = encodeWith(encoders, item)
+		}
+
+		return converted
+	}
+
+	t := reflect.TypeOf(v)
+	if encode, ok := encoders[t]; ok {
+		return encode(v)
+	}
+
+	for iface, encode := range encoders {
+		if iface.Kind() == reflect.Interface && t.Implements(iface) {
+			return encode(v)
+		}
+	}
+
+	return v
+}
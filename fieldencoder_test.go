@@ -0,0 +1,125 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// money is a domain type rendered by a field encoder.
+type money struct {
+	cents    int64
+	currency string
+}
+
+func TestRegisterFieldEncoder(t *testing.T) {
+	old := fieldEncoders.Load()
+	t.Cleanup(func() { fieldEncoders.Store(old) })
+
+	RegisterFieldEncoder(reflect.TypeOf(money{}), func(v any) any {
+		m := v.(money)
+		return fmt.Sprintf("%d.%02d %s", m.cents/100, m.cents%100, m.currency)
+	})
+
+	fields := map[string]any{
+		"total": money{cents: 1250, currency: "EUR"},
+		"lines": []any{money{cents: 250, currency: "EUR"}, "shipping"},
+		"order": map[string]any{"tax": money{cents: 99, currency: "EUR"}},
+		"n":     1,
+	}
+
+	t.Run("json-writer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer := NewJSONWriter(buf)
+		writer.Write(LevelInfo, "paid", fields)
+		writer.Flush()
+
+		var entry map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+		}
+		if entry["total"] != "12.50 EUR" {
+			t.Errorf(`entry["total"] = %q, want %q`, entry["total"], "12.50 EUR")
+		}
+		want := []any{"2.50 EUR", "shipping"}
+		if !reflect.DeepEqual(entry["lines"], want) {
+			t.Errorf(`entry["lines"] = %v, want %v`, entry["lines"], want)
+		}
+		if want := map[string]any{"tax": "0.99 EUR"}; !reflect.DeepEqual(entry["order"], want) {
+			t.Errorf(`entry["order"] = %v, want %v`, entry["order"], want)
+		}
+		if entry["n"] != float64(1) {
+			t.Errorf(`entry["n"] = %v, want %v`, entry["n"], float64(1))
+		}
+	})
+
+	t.Run("default-writer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer := NewDefaultWriter(buf, SortKeys())
+		writer.Write(LevelInfo, "paid", fields)
+		writer.Flush()
+
+		if !strings.HasSuffix(buf.String(),
+			`lines="["2.50 EUR","shipping"]" n="1" order="{"tax":"0.99 EUR"}" total="12.50 EUR"`+"\n") {
+			t.Errorf("%s", buf.String())
+		}
+	})
+
+	t.Run("removed", func(t *testing.T) {
+		RegisterFieldEncoder(reflect.TypeOf(money{}), nil)
+
+		want := money{cents: 1}
+		if got := encodeField(money{cents: 1}); !reflect.DeepEqual(got, want) {
+			t.Errorf("encodeField(money{cents: 1}) = %v, want %v", got, want)
+		}
+		if got := *fieldEncoders.Load(); len(got) != 0 {
+			t.Errorf("*fieldEncoders.Load() = %v, want empty", got)
+		}
+	})
+}
+
+// labeled is implemented by types rendered by an interface field encoder.
+type labeled interface{ label() string }
+
+type status int
+
+func (s status) label() string { return [...]string{"pending", "paid"}[s] }
+
+func TestRegisterFieldEncoder_Interface(t *testing.T) {
+	old := fieldEncoders.Load()
+	t.Cleanup(func() { fieldEncoders.Store(old) })
+
+	RegisterFieldEncoder(reflect.TypeOf((*labeled)(nil)).Elem(), func(v any) any {
+		return v.(labeled).label()
+	})
+
+	if got := encodeField(status(1)); got != "paid" {
+		t.Errorf("encodeField(status(1)) = %q, want %q", got, "paid")
+	}
+	want := []any{"pending", 2}
+	if got := encodeField([]any{status(0), 2}); !reflect.DeepEqual(got, want) {
+		t.Errorf("encodeField([]any{status(0), 2}) = %v, want %v", got, want)
+	}
+
+	RegisterFieldEncoder(reflect.TypeOf(status(0)), func(v any) any { return int(v.(status)) })
+	if got := encodeField(status(1)); got != 1 {
+		t.Errorf("concrete types take precedence: encodeField(status(1)) = %v, want 1", got)
+	}
+}
+
+func TestEncodeField_NoEncoders(t *testing.T) {
+	old := fieldEncoders.Load()
+	fieldEncoders.Store(nil)
+	t.Cleanup(func() { fieldEncoders.Store(old) })
+
+	nested := map[string]any{"a": 1}
+	if got := encodeField(nested); !reflect.DeepEqual(got, nested) {
+		t.Errorf("encodeField(nested) = %v, want %v", got, nested)
+	}
+	if got := encodeField(nil); got != nil {
+		t.Errorf("encodeField(nil) = %v, want nil", got)
+	}
+}
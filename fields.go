@@ -0,0 +1,256 @@
+package golog
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// HexDump returns a field, for use with WithFields, that renders data as a
+// hex+ASCII dump in the format of encoding/hex.Dump (offset, hex bytes, and
+// printable characters). At most max bytes are dumped; when data is longer,
+// a final line reports the total size. A max of zero or less dumps all of
+// data.
+//
+// It is intended for protocol debugging at the debug level:
+//
+//	golog.WithFields(golog.HexDump("frame", frame, 256)).Debug("Frame received")
+func HexDump(key string, data []byte, max int) map[string]any {
+	total := len(data)
+	truncated := max > 0 && total > max
+	if truncated {
+		data = data[:max]
+	}
+
+	dump := strings.TrimSuffix(hex.Dump(data), "\n")
+	if truncated {
+		dump += fmt.Sprintf("\n... truncated, %d of %d bytes shown", max, total)
+	}
+
+	return map[string]any{key: dump}
+}
+
+// defaultSliceLimit is the number of elements written by Slice by default.
+const defaultSliceLimit = 10
+
+// SliceOption configures Slice.
+type SliceOption func(*sliceOptions)
+
+// sliceOptions holds the settings of Slice.
+type sliceOptions struct {
+	limit   int
+	summary bool
+	redact  *redactor
+}
+
+// SliceLimit sets the maximum number of elements Slice writes. The default
+// is 10; zero or less writes all the elements.
+func SliceLimit(n int) SliceOption {
+	return func(o *sliceOptions) {
+		o.limit = n
+	}
+}
+
+// SliceSummary makes Slice write only the length and the first and last
+// elements.
+func SliceSummary() SliceOption {
+	return func(o *sliceOptions) {
+		o.summary = true
+	}
+}
+
+// SliceRedact masks the fields named names, compared case-insensitively, in
+// the elements Slice writes: the keys of map elements and the fields of
+// struct elements, by their JSON name, at any depth.
+func SliceRedact(names ...string) SliceOption {
+	return func(o *sliceOptions) {
+		o.redact = &redactor{fields: map[string]bool{}}
+		RedactFields(names...)(o.redact)
+	}
+}
+
+// Slice returns a field, for use with WithFields, that bounds the size of a
+// slice of records, such as a batch, so that logging it does not explode
+// the entry:
+//
+//   - a slice within the limit (see SliceLimit) is written as is, as a list
+//   - a longer slice is written as {"len": 5021, "items": [...], "omitted":
+//     5011}, with the first elements within the limit
+//   - with SliceSummary, the slice is written as {"len": 5021, "first": ...,
+//     "last": ...}
+//
+// Example:
+//
+//	golog.WithFields(golog.Slice("orders", batch, golog.SliceLimit(5), golog.SliceRedact("card"))).Info("batch imported")
+func Slice[T any](key string, items []T, opts ...SliceOption) map[string]any {
+	o := sliceOptions{limit: defaultSliceLimit}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	element := func(i int) any {
+		if o.redact == nil {
+			return items[i]
+		}
+
+		v, _ := o.redact.redactValue(items[i], 0)
+		return v
+	}
+
+	total := len(items)
+	if o.summary {
+		summary := map[string]any{"len": total}
+		if total > 0 {
+			summary["first"] = element(0)
+			summary["last"] = element(total - 1)
+		}
+
+		return map[string]any{key: summary}
+	}
+
+	shown := total
+	if o.limit > 0 {
+		shown = min(total, o.limit)
+	}
+
+	list := make([]any, shown)
+	for i := range list {
+		list[i] = element(i)
+	}
+
+	if shown == total {
+		return map[string]any{key: list}
+	}
+
+	return map[string]any{key: map[string]any{"len": total, "items": list, "omitted": total - shown}}
+}
+
+// UnitConventions sets the keys and formats of the fields returned by Dur
+// and Bytes.
+type UnitConventions struct {
+	// DurationUnit is the unit of the numeric value of Dur: time.Nanosecond,
+	// time.Microsecond, time.Millisecond, or time.Second, which set the key
+	// suffix to "_ns", "_us", "_ms", or "_s". The default is
+	// time.Millisecond.
+	DurationUnit time.Duration
+	// BytesSuffix is appended to the key of the numeric value of Bytes. The
+	// default is "_bytes".
+	BytesSuffix string
+	// HumanSuffix is appended to the key of the human-readable values. The
+	// default is "_h".
+	HumanSuffix string
+	// DecimalBytes formats sizes in decimal units (kB, MB) instead of
+	// binary units (KiB, MiB).
+	DecimalBytes bool
+}
+
+// durationSuffixes holds the key suffix of each duration unit.
+var durationSuffixes = map[time.Duration]string{
+	time.Nanosecond:  "_ns",
+	time.Microsecond: "_us",
+	time.Millisecond: "_ms",
+	time.Second:      "_s",
+}
+
+// unitConventions holds the conventions set with SetUnitConventions.
+var unitConventions atomic.Pointer[UnitConventions]
+
+// SetUnitConventions sets the conventions of Dur and Bytes for the whole
+// process, so that every service writes durations and sizes under the same
+// keys. Zero settings keep their default; an unknown DurationUnit is
+// replaced by time.Millisecond.
+func SetUnitConventions(c UnitConventions) {
+	if _, ok := durationSuffixes[c.DurationUnit]; !ok {
+		c.DurationUnit = time.Millisecond
+	}
+
+	if c.BytesSuffix == "" {
+		c.BytesSuffix = "_bytes"
+	}
+
+	if c.HumanSuffix == "" {
+		c.HumanSuffix = "_h"
+	}
+
+	unitConventions.Store(&c)
+}
+
+// units returns the current unit conventions.
+func units() UnitConventions {
+	if c := unitConventions.Load(); c != nil {
+		return *c
+	}
+
+	return UnitConventions{DurationUnit: time.Millisecond, BytesSuffix: "_bytes", HumanSuffix: "_h"}
+}
+
+// Dur returns fields, for use with WithFields, holding d both as a number,
+// in milliseconds by default, for queries and dashboards, and as a
+// human-readable string:
+//
+//	golog.WithFields(golog.Dur("duration", elapsed)).Info("export done")
+//	// duration_ms=1520 duration_h="1.52s"
+//
+// See SetUnitConventions to change the unit and the key suffixes.
+func Dur(key string, d time.Duration) map[string]any {
+	c := units()
+
+	return map[string]any{
+		key + durationSuffixes[c.DurationUnit]: int64(d / c.DurationUnit),
+		key + c.HumanSuffix:                    d.String(),
+	}
+}
+
+// integer is the constraint of the sizes accepted by Bytes.
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Bytes returns fields, for use with WithFields, holding the size n both as
+// a number of bytes and as a human-readable string, in binary units by
+// default:
+//
+//	golog.WithFields(golog.Bytes("size", len(body))).Info("upload received")
+//	// size_bytes=1572864 size_h="1.5 MiB"
+//
+// See SetUnitConventions to change the units and the key suffixes.
+func Bytes[N integer](key string, n N) map[string]any {
+	c := units()
+
+	return map[string]any{
+		key + c.BytesSuffix: n,
+		key + c.HumanSuffix: humanBytes(float64(n), c.DecimalBytes),
+	}
+}
+
+// humanBytes formats a size in binary or decimal units.
+func humanBytes(n float64, decimal bool) string {
+	base, units := 1024.0, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	if decimal {
+		base, units = 1000.0, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+	}
+
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+
+	unit := 0
+	for n >= base && unit < len(units)-1 {
+		n /= base
+		unit++
+	}
+
+	if unit == 0 {
+		return fmt.Sprintf("%s%d %s", sign, int64(n), units[0])
+	}
+
+	value := strings.TrimRight(strings.TrimRight(strconv.FormatFloat(n, 'f', 2, 64), "0"), ".")
+
+	return fmt.Sprintf("%s%s %s", sign, value, units[unit])
+}
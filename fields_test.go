@@ -0,0 +1,146 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		max      int
+		expected string
+	}{
+		{
+			name:     "empty",
+			data:     nil,
+			max:      16,
+			expected: "",
+		},
+		{
+			name:     "full",
+			data:     []byte("GET / HTTP/1.1\r\n"),
+			max:      0,
+			expected: "00000000  47 45 54 20 2f 20 48 54  54 50 2f 31 2e 31 0d 0a  |GET / HTTP/1.1..|",
+		},
+		{
+			name: "truncated",
+			data: []byte("0123456789abcdefXYZ"),
+			max:  4,
+			expected: "00000000  30 31 32 33                                       |0123|\n" +
+				"... truncated, 4 of 19 bytes shown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := map[string]any{"payload": tt.expected}
+			if got := HexDump("payload", tt.data, tt.max); !reflect.DeepEqual(got, want) {
+				t.Errorf(`HexDump("payload", tt.data, tt.max) = %v, want %v`, got, want)
+			}
+		})
+	}
+}
+
+func TestSlice(t *testing.T) {
+	type record struct {
+		ID   int    `json:"id"`
+		Card string `json:"card"`
+	}
+
+	records := []record{{1, "4111"}, {2, "4222"}, {3, "4333"}}
+
+	tests := []struct {
+		name     string
+		items    []record
+		opts     []SliceOption
+		expected any
+	}{
+		{
+			name:     "within-limit",
+			items:    records,
+			expected: []any{records[0], records[1], records[2]},
+		},
+		{
+			name:     "over-limit",
+			items:    records,
+			opts:     []SliceOption{SliceLimit(2)},
+			expected: map[string]any{"len": 3, "items": []any{records[0], records[1]}, "omitted": 1},
+		},
+		{
+			name:     "summary",
+			items:    records,
+			opts:     []SliceOption{SliceSummary()},
+			expected: map[string]any{"len": 3, "first": records[0], "last": records[2]},
+		},
+		{
+			name:     "empty-summary",
+			opts:     []SliceOption{SliceSummary()},
+			expected: map[string]any{"len": 0},
+		},
+		{
+			name:  "redacted-elements",
+			items: records[:2],
+			opts:  []SliceOption{SliceRedact("Card")},
+			expected: []any{
+				map[string]any{"id": 1, "card": "[REDACTED]"},
+				map[string]any{"id": 2, "card": "[REDACTED]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := map[string]any{"records": tt.expected}
+			if got := Slice("records", tt.items, tt.opts...); !reflect.DeepEqual(got, want) {
+				t.Errorf(`Slice("records", tt.items, tt.opts...) = %v, want %v`, got, want)
+			}
+		})
+	}
+}
+
+func TestDurAndBytes(t *testing.T) {
+	t.Cleanup(func() { unitConventions.Store(nil) })
+
+	want := map[string]any{"duration_ms": int64(1520), "duration_h": "1.52s"}
+	if got := Dur("duration", 1520*time.Millisecond); !reflect.DeepEqual(got, want) {
+		t.Errorf(`Dur("duration", 1520*time.Millisecond) = %v, want %v`, got, want)
+	}
+	if got, want := Bytes("size", 1572864), map[string]any{"size_bytes": 1572864, "size_h": "1.5 MiB"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`Bytes("size", 1572864) = %v, want %v`, got, want)
+	}
+
+	SetUnitConventions(UnitConventions{DurationUnit: time.Second, HumanSuffix: "_human", DecimalBytes: true})
+	if got, want := Dur("duration", 90*time.Second), map[string]any{"duration_s": int64(90), "duration_human": "1m30s"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`Dur("duration", 90*time.Second) = %v, want %v`, got, want)
+	}
+	if got, want := Bytes("size", uint64(1500)), map[string]any{"size_bytes": uint64(1500), "size_human": "1.5 kB"}; !reflect.DeepEqual(got, want) {
+		t.Errorf(`Bytes("size", uint64(1500)) = %v, want %v`, got, want)
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	tests := []struct {
+		n        float64
+		decimal  bool
+		expected string
+	}{
+		{0, false, "0 B"},
+		{1023, false, "1023 B"},
+		{1024, false, "1 KiB"},
+		{1536, false, "1.5 KiB"},
+		{5 << 30, false, "5 GiB"},
+		{-2048, false, "-2 KiB"},
+		{1_234_567, true, "1.23 MB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if got := humanBytes(tt.n, tt.decimal); got != tt.expected {
+				t.Errorf("humanBytes(tt.n, tt.decimal) = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
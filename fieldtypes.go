@@ -0,0 +1,168 @@
+package golog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// FieldTypeChangedMessage is the message of the warning entries of
+// SetFieldTypeChecks.
+const FieldTypeChangedMessage = "field type changed"
+
+// fieldTypeMaxKeys is the maximum number of field keys whose type is
+// tracked by SetFieldTypeChecks.
+const fieldTypeMaxKeys = 10000
+
+// fieldTypeChecksEnabled reports whether the types of the fields are
+// checked.
+var fieldTypeChecksEnabled atomic.Bool
+
+// fieldTypes holds the types of the fields seen by SetFieldTypeChecks.
+var fieldTypes = &fieldTypeRegistry{}
+
+// SetFieldTypeChecks enables a development mode that warns when a field key
+// is logged with values of different Go types, such as user_id as a string
+// at one call site and as an int at another. Log stores such as
+// Elasticsearch map a field to the type of its first value, and reject or
+// fail to index the entries whose value does not fit, so type flapping loses
+// entries in production.
+//
+// The first type of each key is remembered with the call site that logged
+// it. An entry with another type is logged as is, after a warning entry
+// with the message FieldTypeChangedMessage, written to the writer of the
+// Logger, naming the field, both types, and both call sites, once per key
+// and type. Finding the call sites walks the stack of every entry, so
+// keep it disabled, the default, in production.
+//
+// Example:
+//
+//	golog.SetFieldTypeChecks(true)
+//	golog.With("user_id", "42").Info("profile viewed")
+//	golog.With("user_id", 42).Info("profile updated")
+//	// WARN field type changed field=user_id type=int first_type=string caller=... first_caller=...
+func SetFieldTypeChecks(enabled bool) {
+	fieldTypeChecksEnabled.Store(enabled)
+}
+
+// fieldTypeRegistry remembers the first type and call site of each field
+// key.
+type fieldTypeRegistry struct {
+	mu    sync.Mutex
+	first map[string]fieldTypeSite
+	// reported holds the keys and types already warned about
+	reported map[fieldTypeKey]bool
+}
+
+// fieldTypeKey is a field key and a type of its values.
+type fieldTypeKey struct {
+	key string
+	typ string
+}
+
+// fieldTypeSite is a type of a field and the call site that logged it.
+type fieldTypeSite struct {
+	typ    string
+	caller string
+}
+
+// checkFieldTypes writes a warning to writer for each field of fields whose
+// type differs from the first type logged for its key.
+func checkFieldTypes(writer LogWriter, fields map[string]any) {
+	if !fieldTypeChecksEnabled.Load() || len(fields) == 0 {
+		return
+	}
+
+	caller := callSite()
+	for _, warning := range fieldTypes.check(fields, caller) {
+		writer.Write(LevelWarn, FieldTypeChangedMessage, warning)
+	}
+}
+
+// check records the types of fields logged at caller, and returns the
+// fields of the warnings to write.
+func (r *fieldTypeRegistry) check(fields map[string]any, caller string) []map[string]any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.first == nil {
+		r.first = make(map[string]fieldTypeSite)
+		r.reported = make(map[fieldTypeKey]bool)
+	}
+
+	var warnings []map[string]any
+	for key, value := range fields {
+		if value == nil {
+			continue
+		}
+
+		typ := fmt.Sprintf("%T", value)
+
+		first, ok := r.first[key]
+		if !ok {
+			if len(r.first) < fieldTypeMaxKeys {
+				r.first[key] = fieldTypeSite{typ: typ, caller: caller}
+			}
+
+			continue
+		}
+
+		reported := fieldTypeKey{key: key, typ: typ}
+		if first.typ == typ || r.reported[reported] {
+			continue
+		}
+
+		r.reported[reported] = true
+		warnings = append(warnings, map[string]any{
+			"field":        key,
+			"type":         typ,
+			"caller":       caller,
+			"first_type":   first.typ,
+			"first_caller": first.caller,
+		})
+	}
+
+	return warnings
+}
+
+// reset forgets the types seen.
+func (r *fieldTypeRegistry) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.first = nil
+	r.reported = nil
+}
+
+// callSite returns the location, as "function file:line", of the first
+// caller outside of golog, which logged the entry being written.
+func callSite() string {
+	var pcs [32]uintptr
+	// skip runtime.Callers and callSite
+	n := runtime.Callers(2, pcs[:])
+
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !isGologFrame(frame) {
+			return fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// isGologFrame reports whether frame is in the golog package itself, other
+// than in its tests.
+func isGologFrame(frame runtime.Frame) bool {
+	pkg, _, ok := strings.Cut(frame.Function, "golog.")
+	if !ok || !strings.HasSuffix(pkg, "jkaveri/") {
+		return false
+	}
+
+	return !strings.HasSuffix(frame.File, "_test.go")
+}
@@ -0,0 +1,91 @@
+package golog
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// useFieldTypeChecks enables the field type checks for the test.
+func useFieldTypeChecks(t *testing.T) {
+	SetFieldTypeChecks(true)
+	t.Cleanup(func() {
+		SetFieldTypeChecks(false)
+		fieldTypes.reset()
+	})
+}
+
+func TestSetFieldTypeChecks(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	useFieldTypeChecks(t)
+
+	With("user_id", "42").Info("profile viewed")
+	With("user_id", 42).Info("profile updated")
+	std.With("user_id", 7).Info("profile deleted")
+	With("user_id", "43").With("plan", nil).Info("profile viewed")
+
+	if len(w.entries) != 5 {
+		t.Fatalf("len(w.entries) = %d, want 5", len(w.entries))
+	}
+	if w.entries[2].msg != "profile updated" {
+		t.Errorf("the entry is logged as is: w.entries[2].msg = %q, want %q", w.entries[2].msg, "profile updated")
+	}
+
+	warning := w.entries[1]
+	if warning.level != LevelWarn {
+		t.Errorf("warning.level = %v, want %v", warning.level, LevelWarn)
+	}
+	if warning.msg != FieldTypeChangedMessage {
+		t.Errorf("warning.msg = %q, want %q", warning.msg, FieldTypeChangedMessage)
+	}
+	if warning.fields["field"] != "user_id" {
+		t.Errorf(`warning.fields["field"] = %q, want %q`, warning.fields["field"], "user_id")
+	}
+	if warning.fields["type"] != "int" {
+		t.Errorf(`warning.fields["type"] = %q, want %q`, warning.fields["type"], "int")
+	}
+	if warning.fields["first_type"] != "string" {
+		t.Errorf(`warning.fields["first_type"] = %q, want %q`, warning.fields["first_type"], "string")
+	}
+	caller := regexp.MustCompile(`^github\.com/jkaveri/golog\.TestSetFieldTypeChecks .*fieldtypes_test\.go:25$`)
+	if got, _ := warning.fields["caller"].(string); !caller.MatchString(got) {
+		t.Errorf(`warning.fields["caller"] = %q, want a match of %q`, got, caller)
+	}
+	if got, _ := warning.fields["first_caller"].(string); !strings.HasSuffix(got, "fieldtypes_test.go:24") {
+		t.Errorf(`warning.fields["first_caller"] = %q, want the suffix %q`, got, "fieldtypes_test.go:24")
+	}
+
+	if w.entries[3].msg != "profile deleted" {
+		t.Errorf("a changed type is reported once: w.entries[3].msg = %q, want %q", w.entries[3].msg, "profile deleted")
+	}
+	if w.entries[4].msg != "profile viewed" {
+		t.Errorf("the first type is not reported: w.entries[4].msg = %q, want %q", w.entries[4].msg, "profile viewed")
+	}
+}
+
+func TestSetFieldTypeChecks_Disabled(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	With("user_id", "42").Info("profile viewed")
+	With("user_id", 42).Info("profile updated")
+
+	if len(w.entries) != 2 {
+		t.Errorf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+}
+
+func TestSetFieldTypeChecks_ErrorIf(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	useFieldTypeChecks(t)
+
+	_ = WithError(errors.New("disk full")).Error("write failed")
+	_ = ErrorIf(errors.New("disk full"), "write failed")
+
+	if len(w.entries) != 2 {
+		t.Errorf("ErrorIf sets the error field with the type WithError sets: len(w.entries) = %d, want 2", len(w.entries))
+	}
+}
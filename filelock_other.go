@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly && !windows
+
+package golog
+
+import "os"
+
+// lockFile is not supported on this platform; entries are appended unlocked.
+func lockFile(*os.File) error {
+	return nil
+}
+
+// unlockFile is not supported on this platform.
+func unlockFile(*os.File) error {
+	return nil
+}
@@ -0,0 +1,53 @@
+//go:build windows
+
+package golog
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// lockfileExclusiveLock is LOCKFILE_EXCLUSIVE_LOCK from the Windows API.
+const lockfileExclusiveLock = 0x00000002
+
+var (
+	procLockFileEx   = syscall.NewLazyDLL("kernel32.dll").NewProc("LockFileEx")
+	procUnlockFileEx = syscall.NewLazyDLL("kernel32.dll").NewProc("UnlockFileEx")
+)
+
+// lockFile takes an exclusive lock on the whole of f, blocking until it is
+// granted.
+func lockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	ret, _, err := procLockFileEx.Call(
+		f.Fd(),
+		lockfileExclusiveLock,
+		0,
+		0xFFFFFFFF,
+		0xFFFFFFFF,
+		uintptr(unsafe.Pointer(&ol)),
+	)
+	if ret == 0 {
+		return err
+	}
+
+	return nil
+}
+
+// unlockFile releases the lock taken by lockFile.
+func unlockFile(f *os.File) error {
+	var ol syscall.Overlapped
+	ret, _, err := procUnlockFileEx.Call(
+		f.Fd(),
+		0,
+		0xFFFFFFFF,
+		0xFFFFFFFF,
+		uintptr(unsafe.Pointer(&ol)),
+	)
+	if ret == 0 {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package golog
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultMaxOpenFiles is the number of files a partitioned file writer keeps
+// open by default.
+const defaultMaxOpenFiles = 32
+
+// MaxOpenFiles sets the number of partition files the writer created by
+// NewPartitionedFileWriter keeps open. When an entry goes to another
+// partition, the least recently written file is closed, and reopened when an
+// entry goes to it again. The default is 32; it has no effect on
+// NewFileWriter.
+func MaxOpenFiles(n int) FileOption {
+	return func(o *fileOptions) {
+		o.maxOpenFiles = n
+	}
+}
+
+// partitionedFileWriter appends entries to the file of their partition.
+type partitionedFileWriter struct {
+	text     string
+	tmpl     *EntryTemplate
+	opts     []FileOption
+	settings fileOptions
+	errors   writerOptions
+
+	mu sync.Mutex
+	// files holds the elements of lru by path
+	files map[string]*list.Element
+	// lru holds the open partitions, the most recently written first
+	lru *list.List
+}
+
+// partition is an open partition file.
+type partition struct {
+	path   string
+	writer *fileWriter
+}
+
+// NewPartitionedFileWriter creates a LogWriter that appends each entry to
+// the file whose path is pathTemplate executed for the entry, as an
+// EntryTemplate, such as one file per job or per day, for batch platforms
+// that archive logs per job. Each partition file is written as with
+// NewFileWriter, with opts, and its directory is created if needed.
+//
+// The field values are rendered with their path separators replaced by "_",
+// so that a value cannot write outside of the directory of the template.
+// Give fields that can be missing a default, or their entries go to a file
+// named "<no value>".
+//
+// At most 32 partition files are kept open (see MaxOpenFiles). Errors
+// rendering the path, or opening or writing a file, are reported to the
+// error handler set with FileWriterOptions(OnError(...)), and the entry is
+// dropped.
+//
+// Example:
+//
+//	writer, err := golog.NewPartitionedFileWriter(
+//	    `/var/log/jobs/{{.Fields.job_name | default "unknown"}}/{{.Time.Format "2006-01-02"}}.log`,
+//	    golog.MaxOpenFiles(64),
+//	)
+//	if err != nil {
+//	    return err
+//	}
+//	golog.SetWriter(writer)
+func NewPartitionedFileWriter(pathTemplate string, opts ...FileOption) (*partitionedFileWriter, error) {
+	tmpl, err := ParseEntryTemplate(pathTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("golog: parse partition path template: %w", err)
+	}
+
+	o := fileOptions{maxOpenFiles: defaultMaxOpenFiles}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	o.maxOpenFiles = max(o.maxOpenFiles, 1)
+
+	if o.codec != "" {
+		if _, err := lookupCodec(o.codec); err != nil {
+			return nil, err
+		}
+	}
+
+	return &partitionedFileWriter{
+		text:     pathTemplate,
+		tmpl:     tmpl,
+		opts:     opts,
+		settings: o,
+		errors:   newWriterOptions(o.writerOptions),
+		files:    make(map[string]*list.Element),
+		lru:      list.New(),
+	}, nil
+}
+
+// Write implements LogWriter.
+func (w *partitionedFileWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *partitionedFileWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write appends the entry to the file of its partition.
+func (w *partitionedFileWriter) write(entry Entry, file string, line int) {
+	path, err := w.path(entry)
+	if err != nil {
+		w.errors.handleError(err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	writer, err := w.partition(path)
+	if err != nil {
+		w.errors.handleError(err)
+		return
+	}
+
+	writer.write(entry, file, line)
+}
+
+// path returns the path of the partition file of entry.
+func (w *partitionedFileWriter) path(entry Entry) (string, error) {
+	safe := make(map[string]any, len(entry.Fields))
+	for key, value := range entry.Fields {
+		safe[key] = pathValue(value)
+	}
+
+	entry.Message = pathValue(entry.Message)
+	entry.Fields = safe
+
+	path, err := w.tmpl.Execute(entry)
+	if err != nil {
+		return "", fmt.Errorf("golog: render partition path: %w", err)
+	}
+
+	if path == "" {
+		return "", errors.New("golog: render partition path: empty path")
+	}
+
+	return filepath.Clean(path), nil
+}
+
+// pathValue renders v as a single path element, with the path separators
+// and the control characters replaced by "_".
+func pathValue(v any) string {
+	s, ok := v.(string)
+	if !ok {
+		if v == nil {
+			return ""
+		}
+
+		s = fmt.Sprint(v)
+	}
+
+	if s == "." || s == ".." {
+		return "_"
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r < ' ' || r == 0x7f {
+			return '_'
+		}
+
+		return r
+	}, s)
+}
+
+// partition returns the writer of the partition file at path, opening it,
+// and closing the least recently written file when too many are open.
+func (w *partitionedFileWriter) partition(path string) (*fileWriter, error) {
+	if elem, ok := w.files[path]; ok {
+		w.lru.MoveToFront(elem)
+		return elem.Value.(*partition).writer, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("golog: create partition directory: %w", err)
+	}
+
+	writer, err := NewFileWriter(path, w.opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	w.files[path] = w.lru.PushFront(&partition{path: path, writer: writer})
+
+	for w.lru.Len() > w.settings.maxOpenFiles {
+		w.evict(w.lru.Back())
+	}
+
+	return writer, nil
+}
+
+// evict closes the partition file of elem.
+func (w *partitionedFileWriter) evict(elem *list.Element) {
+	p := w.lru.Remove(elem).(*partition)
+	delete(w.files, p.path)
+
+	if err := p.writer.Close(); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: close log file %q: %w", p.path, err))
+	}
+}
+
+// Flush implements LogWriter, flushing the open partition files.
+func (w *partitionedFileWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for elem := w.lru.Front(); elem != nil; elem = elem.Next() {
+		elem.Value.(*partition).writer.Flush()
+	}
+}
+
+// Close closes the open partition files. A later entry reopens the file of
+// its partition.
+func (w *partitionedFileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var errs []error
+	for w.lru.Len() > 0 {
+		p := w.lru.Remove(w.lru.Front()).(*partition)
+		delete(w.files, p.path)
+		errs = append(errs, p.writer.Close())
+	}
+
+	return errors.Join(errs...)
+}
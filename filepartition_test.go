@@ -0,0 +1,112 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPartitionedFileWriter(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, err := NewPartitionedFileWriter(filepath.Join(dir, `{{.Fields.job_name | default "unknown"}}`, `{{.Time.Format "2006-01-02"}}.log`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	day := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	writer.WriteEntry(Entry{Time: day, Level: LevelInfo, Message: "started", Fields: map[string]any{"job_name": "billing"}})
+	writer.WriteEntry(Entry{Time: day, Level: LevelInfo, Message: "started", Fields: map[string]any{"job_name": "reports"}})
+	writer.WriteEntry(Entry{Time: day, Level: LevelInfo, Message: "done", Fields: map[string]any{"job_name": "billing"}})
+	writer.WriteEntry(Entry{Time: day.AddDate(0, 0, 1), Level: LevelInfo, Message: "started", Fields: map[string]any{"job_name": "billing"}})
+	writer.WriteEntry(Entry{Time: day, Level: LevelInfo, Message: "orphan"})
+	writer.WriteEntry(Entry{Time: day, Level: LevelInfo, Message: "escape", Fields: map[string]any{"job_name": "../../etc"}})
+	writer.Flush()
+
+	if got := readLines(t, filepath.Join(dir, "billing", "2026-10-17.log")); len(got) != 2 {
+		t.Errorf("got %d entries, want 2", len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, "billing", "2026-10-18.log")); len(got) != 1 {
+		t.Errorf("got %d entries, want 1", len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, "reports", "2026-10-17.log")); len(got) != 1 {
+		t.Errorf("got %d entries, want 1", len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, "unknown", "2026-10-17.log")); len(got) != 1 {
+		t.Errorf("a missing field uses the default: got %d entries, want 1", len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, ".._.._etc", "2026-10-17.log")); len(got) != 1 {
+		t.Errorf("the path separators of values are replaced: got %d entries, want 1", len(got))
+	}
+}
+
+func TestPartitionedFileWriter_MaxOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, err := NewPartitionedFileWriter(filepath.Join(dir, "{{.Fields.job}}.log"), MaxOpenFiles(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	for _, job := range []string{"a", "b", "c", "a"} {
+		writer.Write(LevelInfo, "tick", map[string]any{"job": job})
+	}
+
+	if got := writer.lru.Len(); got != 2 {
+		t.Errorf("writer.lru.Len() = %v, want 2", got)
+	}
+	if _, ok := writer.files[filepath.Join(dir, "b.log")]; ok {
+		t.Errorf("the least recently written file is closed: writer.files has %q", filepath.Join(dir, "b.log"))
+	}
+	if got := readLines(t, filepath.Join(dir, "a.log")); len(got) != 2 {
+		t.Errorf(`a closed file is reopened for appending: len(readLines(t, filepath.Join(dir, "a.log"))) = %d, want 2`, len(got))
+	}
+	if got := readLines(t, filepath.Join(dir, "b.log")); len(got) != 1 {
+		t.Errorf(`len(readLines(t, filepath.Join(dir, "b.log"))) = %d, want 1`, len(got))
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+	if got := writer.lru.Len(); got != 0 {
+		t.Errorf("writer.lru.Len() = %v, want zero", got)
+	}
+}
+
+func TestPartitionedFileWriter_Errors(t *testing.T) {
+	_, err := NewPartitionedFileWriter("{{.Fields.job")
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	_, err = NewPartitionedFileWriter("{{.Fields.job}}.log", FileCompression("unknown"))
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("err = %v, want %v", err, ErrUnknownCodec)
+	}
+
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatalf("os.WriteFile(blocker, nil, 0o644): %v", err)
+	}
+
+	var errs []error
+	writer, err := NewPartitionedFileWriter(filepath.Join(blocker, "{{.Fields.job}}.log"),
+		FileWriterOptions(OnError(func(err error) { errs = append(errs, err) })))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writer.Write(LevelInfo, "tick", map[string]any{"job": "a"})
+	if len(errs) != 1 {
+		t.Fatalf("%s: len(errs) = %d, want 1", fmt.Sprint(errs), len(errs))
+	}
+	if got := errs[0].Error(); !strings.Contains(got, "create partition directory") {
+		t.Errorf("errs[0].Error() = %q, want it to contain %q", got, "create partition directory")
+	}
+}
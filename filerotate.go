@@ -0,0 +1,421 @@
+package golog
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupTimeFormat is the layout of the time in the names of rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// MaxFileSize makes the file writer rotate the file before an append would
+// make it larger than maxSize bytes. Rotating renames the file by inserting
+// the time before its extension, e.g. app-2024-03-30T12-34-56.000.log, and
+// opens a new file at the path. A single entry larger than maxSize is still
+// written, to a file of its own.
+//
+// The writer rotates the file itself, so do not combine it with an external
+// tool rotating the same path, or with LockFile: several processes sharing a
+// path would each rotate it.
+func MaxFileSize(maxSize int64) FileOption {
+	return func(o *fileOptions) {
+		o.maxSize = maxSize
+	}
+}
+
+// MaxFileAge makes the file writer rotate the file once it has been open for
+// maxAge, e.g. 24 * time.Hour for a file per day (see MaxFileSize). The file
+// is rotated with the first entry written after that time.
+func MaxFileAge(maxAge time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.maxAge = maxAge
+	}
+}
+
+// MaxBackups sets the number of rotated files kept next to the log file;
+// the oldest are removed after each rotation. The default, zero, keeps them
+// all.
+func MaxBackups(n int) FileOption {
+	return func(o *fileOptions) {
+		o.maxBackups = n
+	}
+}
+
+// CompressBackups makes the file writer gzip the rotated files, adding a .gz
+// extension. Compression runs in the background after each rotation, so it
+// does not delay logging; Close waits for it.
+func CompressBackups() FileOption {
+	return func(o *fileOptions) {
+		o.compressBackups = true
+	}
+}
+
+// SegmentSummarySuffix is appended to the name of a rotated file to name its
+// summary (see SegmentChecksums).
+const SegmentSummarySuffix = ".sum"
+
+// ErrSegmentMismatch is returned by VerifySegment when a rotated file does
+// not match its summary.
+var ErrSegmentMismatch = errors.New("golog: log segment does not match its summary")
+
+// SegmentChecksums makes the file writer write a summary next to each
+// rotated file, named after it with the .sum suffix, e.g.
+// app-2024-03-30T12-34-56.000.log.sum, so that shippers and archives can
+// verify the integrity of the files they receive (see VerifySegment). The
+// summary is a JSON object with the fields of SegmentSummary. It describes
+// the file as the writer closed it, before CompressBackups compresses it.
+func SegmentChecksums() FileOption {
+	return func(o *fileOptions) {
+		o.checksums = true
+	}
+}
+
+// SegmentSummary describes a rotated log file, as written by
+// SegmentChecksums.
+type SegmentSummary struct {
+	// Segment is the name of the rotated file, without its directory
+	Segment string `json:"segment"`
+	// Entries is the number of entries the writer appended to the file
+	Entries int64 `json:"entries"`
+	// Bytes is the size of the file
+	Bytes int64 `json:"bytes"`
+	// SHA256 is the hex SHA-256 of the contents of the file
+	SHA256 string `json:"sha256"`
+	// InitialBytes is the size of the file when the writer opened it, when
+	// it already existed; Entries only counts the entries written after it
+	InitialBytes int64 `json:"initial_bytes,omitempty"`
+}
+
+// writeSegmentSummary writes the summary of the rotated file at path, with
+// the entry counts of summary.
+func writeSegmentSummary(path string, summary SegmentSummary) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	summary.Segment = filepath.Base(path)
+	if summary.Bytes, summary.SHA256, err = checksum(f); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path+SegmentSummarySuffix, append(data, '\n'), info.Mode().Perm())
+}
+
+// checksum returns the size and the hex SHA-256 of the contents of r.
+func checksum(r io.Reader) (int64, string, error) {
+	h := sha256.New()
+	n, err := io.Copy(h, r)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifySegment checks the rotated file at path against the summary written
+// by SegmentChecksums. path is the rotated file, or its .gz copy made by
+// CompressBackups, which is decompressed to be checked. It returns the
+// summary, and an error wrapping ErrSegmentMismatch when the size or the
+// checksum differ.
+func VerifySegment(path string) (SegmentSummary, error) {
+	var summary SegmentSummary
+
+	data, err := os.ReadFile(strings.TrimSuffix(path, ".gz") + SegmentSummarySuffix)
+	if err != nil {
+		return summary, fmt.Errorf("golog: read segment summary: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return summary, fmt.Errorf("golog: parse segment summary: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return summary, fmt.Errorf("golog: open segment: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			return summary, fmt.Errorf("golog: open segment: %w", err)
+		}
+		defer zr.Close()
+
+		r = zr
+	}
+
+	n, sum, err := checksum(r)
+	if err != nil {
+		return summary, fmt.Errorf("golog: read segment: %w", err)
+	}
+
+	if n != summary.Bytes || sum != summary.SHA256 {
+		return summary, fmt.Errorf("%w: %s has %d bytes with SHA-256 %s, expected %d bytes with SHA-256 %s",
+			ErrSegmentMismatch, filepath.Base(path), n, sum, summary.Bytes, summary.SHA256)
+	}
+
+	return summary, nil
+}
+
+// shouldRotate reports whether the file must be rotated before appending n
+// bytes.
+func (w *fileWriter) shouldRotate(n int) bool {
+	if w.opts.maxSize > 0 && w.size > 0 && w.size+int64(n) > w.opts.maxSize {
+		return true
+	}
+
+	return w.opts.maxAge > 0 && w.size > 0 && time.Since(w.opened) >= w.opts.maxAge
+}
+
+// rotatedFile is a backup waiting to be processed.
+type rotatedFile struct {
+	path    string
+	summary SegmentSummary
+}
+
+// rotate renames the file to a backup, opens a new file at the path, and
+// queues the backup for processing.
+func (w *fileWriter) rotate() error {
+	w.file.Close()
+	w.file = nil
+
+	summary := SegmentSummary{Entries: w.entries, InitialBytes: w.initialSize}
+	backup := w.backupName(time.Now())
+	renameErr := os.Rename(w.path, backup)
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.lastCheck = time.Now()
+	if renameErr != nil {
+		return fmt.Errorf("golog: rotate log file %q: %w", w.path, renameErr)
+	}
+
+	w.queueBackup(rotatedFile{path: backup, summary: summary})
+
+	return nil
+}
+
+// queueBackup queues a rotated file for processing, starting the worker
+// unless it is running. A single worker processes the backups in rotation
+// order, so that removing the oldest backups never races with the
+// processing of another one.
+func (w *fileWriter) queueBackup(backup rotatedFile) {
+	w.backupMu.Lock()
+	defer w.backupMu.Unlock()
+
+	w.pendingBackups = append(w.pendingBackups, backup)
+	if w.processingBackups {
+		return
+	}
+
+	w.processingBackups = true
+	w.backups.Add(1)
+	go w.processBackups()
+}
+
+// processBackups processes the queued backups until the queue is empty.
+func (w *fileWriter) processBackups() {
+	defer w.backups.Done()
+
+	for {
+		w.backupMu.Lock()
+		pending := w.pendingBackups
+		w.pendingBackups = nil
+		if len(pending) == 0 {
+			w.processingBackups = false
+			w.backupMu.Unlock()
+			return
+		}
+		w.backupMu.Unlock()
+
+		w.processRotated(pending)
+	}
+}
+
+// backupName returns the name of the backup of the file rotated at t,
+// moving t forward while a backup of that name exists. While the worker
+// compresses a backup, its .gz copy is created before it is removed, so one
+// of the two names always exists.
+func (w *fileWriter) backupName(t time.Time) string {
+	ext := filepath.Ext(w.path)
+	for {
+		name := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(w.path, ext), t.Format(backupTimeFormat), ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// fileExists reports whether a file exists at path.
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+// processRotated removes the oldest backups beyond MaxBackups, then writes
+// the summary of each of the rotated files left, when SegmentChecksums is
+// set, and compresses it, when CompressBackups is set. Rotated files
+// already removed, when newer ones were rotated before they were processed,
+// are skipped. Errors are reported to the error handler.
+func (w *fileWriter) processRotated(rotated []rotatedFile) {
+	w.removeOldBackups()
+
+	for _, backup := range rotated {
+		// only the worker removes backups, so the file cannot be removed
+		// once it is found
+		if !fileExists(backup.path) {
+			continue
+		}
+
+		if w.opts.checksums {
+			if err := writeSegmentSummary(backup.path, backup.summary); err != nil {
+				w.errors.handleError(fmt.Errorf("golog: write summary of rotated log file %q: %w", backup.path, err))
+			}
+		}
+
+		if w.opts.compressBackups {
+			if err := gzipFile(backup.path); err != nil {
+				w.errors.handleError(fmt.Errorf("golog: compress rotated log file %q: %w", backup.path, err))
+			}
+		}
+	}
+}
+
+// removeOldBackups removes the oldest backups beyond MaxBackups, with their
+// summaries.
+func (w *fileWriter) removeOldBackups() {
+	if w.opts.maxBackups <= 0 {
+		return
+	}
+
+	backups, err := w.listBackups()
+	if err != nil {
+		w.errors.handleError(fmt.Errorf("golog: list rotated log files of %q: %w", w.path, err))
+		return
+	}
+
+	for len(backups) > w.opts.maxBackups {
+		if err := os.Remove(backups[0]); err != nil && !os.IsNotExist(err) {
+			w.errors.handleError(fmt.Errorf("golog: remove rotated log file %q: %w", backups[0], err))
+		}
+
+		summary := strings.TrimSuffix(backups[0], ".gz") + SegmentSummarySuffix
+		if err := os.Remove(summary); err != nil && !os.IsNotExist(err) {
+			w.errors.handleError(fmt.Errorf("golog: remove rotated log file summary %q: %w", summary, err))
+		}
+
+		backups = backups[1:]
+	}
+}
+
+// listBackups returns the rotated files of the path, compressed or not,
+// oldest first.
+func (w *fileWriter) listBackups() ([]string, error) {
+	ext := filepath.Ext(w.path)
+	prefix := strings.TrimSuffix(filepath.Base(w.path), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(w.path))
+	if err != nil {
+		return nil, err
+	}
+
+	type backup struct {
+		name string
+		time time.Time
+	}
+
+	var backups []backup
+	for _, entry := range entries {
+		stamp, ok := strings.CutPrefix(strings.TrimSuffix(entry.Name(), ".gz"), prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+
+		stamp, ok = strings.CutSuffix(stamp, ext)
+		if !ok {
+			continue
+		}
+
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backup{name: filepath.Join(filepath.Dir(w.path), entry.Name()), time: t})
+	}
+
+	slices.SortFunc(backups, func(a, b backup) int { return a.time.Compare(b.time) })
+
+	names := make([]string, len(backups))
+	for i, b := range backups {
+		names[i] = b.name
+	}
+
+	return names, nil
+}
+
+// gzipFile replaces the file at path with its gzip-compressed copy, path.gz.
+func gzipFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := src.Stat()
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		src.Close()
+		return err
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	// close the source before removing it, which fails on Windows otherwise
+	src.Close()
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,286 @@
+package golog
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// backupFiles returns the names of the files in dir other than the log file.
+func backupFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Name() != "app.log" {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func TestFileWriter_Rotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []FileOption
+		backups int
+		ext     string
+	}{
+		{name: "max-size", opts: []FileOption{MaxFileSize(150)}, backups: 4, ext: ".log"},
+		{name: "max-backups", opts: []FileOption{MaxFileSize(150), MaxBackups(2)}, backups: 2, ext: ".log"},
+		{name: "max-age", opts: []FileOption{MaxFileAge(time.Nanosecond)}, backups: 4, ext: ".log"},
+		{
+			name:    "compress-backups",
+			opts:    []FileOption{MaxFileSize(150), MaxBackups(3), CompressBackups()},
+			backups: 3,
+			ext:     ".log.gz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+
+			writer, err := NewFileWriter(path, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// each entry is about 100 bytes, so every entry after the first
+			// triggers a rotation
+			for i := 0; i < 5; i++ {
+				writer.Write(LevelInfo, "entry", map[string]any{"n": i})
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("writer.Close(): %v", err)
+			}
+
+			lines := readLines(t, path)
+			if len(lines) != 1 {
+				t.Fatalf("len(lines) = %d, want 1", len(lines))
+			}
+			if !strings.Contains(lines[0], `"n":4`) {
+				t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"n":4`)
+			}
+
+			backups := backupFiles(t, dir)
+			if len(backups) != tt.backups {
+				t.Fatalf("len(backups) = %d, want %d", len(backups), tt.backups)
+			}
+			for _, name := range backups {
+				if !strings.HasPrefix(name, "app-") {
+					t.Errorf("%s", name)
+				}
+				if !strings.HasSuffix(name, tt.ext) {
+					t.Errorf("%s", name)
+				}
+			}
+
+			// the newest backup holds the entry before the last
+			newest := filepath.Join(dir, backups[len(backups)-1])
+			var data []byte
+			if tt.ext == ".log.gz" {
+				f, err := os.Open(newest)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				defer f.Close()
+
+				zr, err := gzip.NewReader(f)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				data, err = io.ReadAll(zr)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			} else {
+				data, err = os.ReadFile(newest)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if !strings.Contains(string(data), `"n":3`) {
+				t.Errorf("string(data) = %q, want it to contain %q", string(data), `"n":3`)
+			}
+		})
+	}
+}
+
+func TestFileWriter_RapidRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	var errs []error
+	writer, err := NewFileWriter(path, MaxFileSize(150), MaxBackups(3), CompressBackups(), SegmentChecksums(),
+		FileWriterOptions(OnError(func(err error) { errs = append(errs, err) })))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// every entry after the first rotates the file, faster than the backups
+	// are compressed
+	for i := 0; i < 50; i++ {
+		writer.Write(LevelInfo, "entry", map[string]any{"n": i})
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	if len(errs) != 0 {
+		t.Errorf("backups are processed in rotation order: errs = %v, want empty", errs)
+	}
+
+	var segments, summaries []string
+	for _, name := range backupFiles(t, dir) {
+		if strings.HasSuffix(name, SegmentSummarySuffix) {
+			summaries = append(summaries, name)
+		} else {
+			segments = append(segments, name)
+		}
+	}
+	if len(segments) != 3 {
+		t.Fatalf("len(segments) = %d, want 3", len(segments))
+	}
+	if len(summaries) != 3 {
+		t.Errorf("no summary is left for a removed backup: len(summaries) = %d, want 3", len(summaries))
+	}
+	for _, name := range segments {
+		if !strings.HasSuffix(name, ".log.gz") {
+			t.Errorf("every backup kept is compressed: %s", name)
+		}
+	}
+}
+
+func TestFileWriter_NoRotationBelowLimits(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	writer, err := NewFileWriter(path, MaxFileSize(1<<20), MaxFileAge(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		writer.Write(LevelInfo, "entry", map[string]any{"n": i})
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	if got := readLines(t, path); len(got) != 5 {
+		t.Errorf("len(readLines(t, path)) = %d, want 5", len(got))
+	}
+	if got := backupFiles(t, dir); len(got) != 0 {
+		t.Errorf("backupFiles(t, dir) = %v, want empty", got)
+	}
+}
+
+func TestFileWriter_SegmentChecksums(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []FileOption
+		ext  string
+	}{
+		{name: "plain", opts: []FileOption{MaxFileSize(250), SegmentChecksums()}, ext: ".log"},
+		{name: "compressed", opts: []FileOption{MaxFileSize(250), SegmentChecksums(), CompressBackups()}, ext: ".log.gz"},
+		{name: "max-backups", opts: []FileOption{MaxFileSize(250), SegmentChecksums(), MaxBackups(1)}, ext: ".log"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+
+			writer, err := NewFileWriter(path, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// each entry is about 100 bytes, so segments hold two entries
+			for i := 0; i < 5; i++ {
+				writer.Write(LevelInfo, "entry", map[string]any{"n": i})
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("writer.Close(): %v", err)
+			}
+
+			var segments, summaries []string
+			for _, name := range backupFiles(t, dir) {
+				if strings.HasSuffix(name, SegmentSummarySuffix) {
+					summaries = append(summaries, name)
+				} else {
+					segments = append(segments, name)
+				}
+			}
+			if len(segments) == 0 {
+				t.Fatal("segments is empty")
+			}
+			if len(summaries) != len(segments) {
+				t.Fatalf("one summary per segment: len(summaries) = %d, want %d", len(summaries), len(segments))
+			}
+
+			for _, name := range segments {
+				if !strings.HasSuffix(name, tt.ext) {
+					t.Errorf("%s", name)
+				}
+
+				summary, err := VerifySegment(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				want := strings.TrimSuffix(name, ".gz")
+				if summary.Segment != want {
+					t.Errorf("summary.Segment = %q, want %q", summary.Segment, want)
+				}
+				if summary.Entries != int64(2) {
+					t.Errorf("summary.Entries = %v, want %v", summary.Entries, int64(2))
+				}
+				if summary.Bytes <= 0 {
+					t.Errorf("summary.Bytes = %v, want positive", summary.Bytes)
+				}
+				if len(summary.SHA256) != 64 {
+					t.Errorf("len(summary.SHA256) = %d, want 64", len(summary.SHA256))
+				}
+			}
+
+			// tampering is detected
+			tampered := filepath.Join(dir, segments[0])
+			f, err := os.Create(tampered)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.ext == ".log.gz" {
+				zw := gzip.NewWriter(f)
+				zw.Write([]byte("tampered\n"))
+				if err := zw.Close(); err != nil {
+					t.Fatalf("zw.Close(): %v", err)
+				}
+			} else {
+				f.WriteString("tampered\n")
+			}
+			if err := f.Close(); err != nil {
+				t.Fatalf("f.Close(): %v", err)
+			}
+
+			_, err = VerifySegment(tampered)
+			if !errors.Is(err, ErrSegmentMismatch) {
+				t.Errorf("err = %v, want %v", err, ErrSegmentMismatch)
+			}
+		})
+	}
+}
@@ -0,0 +1,497 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultReopenInterval is how often the file writer checks whether its
+	// file was rotated away.
+	defaultReopenInterval = time.Second
+	// defaultDiskCheckInterval is how often the file writer checks free disk
+	// space when MinFreeDiskSpace is set.
+	defaultDiskCheckInterval = 10 * time.Second
+	// compressedBlockSize is the amount of entries compressed together with
+	// FileCompression.
+	compressedBlockSize = 64 * 1024
+)
+
+// ErrLowDiskSpace is reported to the error handler when the file writer
+// enters emergency mode because free disk space fell below the threshold set
+// with MinFreeDiskSpace.
+var ErrLowDiskSpace = errors.New("golog: low disk space")
+
+// diskFree returns the free disk space for a directory; replaced in tests.
+var diskFree = freeDiskSpace
+
+// entryEncoder formats entries into an internal buffer. It is implemented by
+// the built-in writers so other writers can reuse their formats.
+type entryEncoder interface {
+	locatedWriter
+	// flushBuffer writes formatted data to the encoder's output
+	flushBuffer() error
+}
+
+// FileOption configures the writer created by NewFileWriter.
+type FileOption func(*fileOptions)
+
+// fileOptions holds the settings of the file writer.
+type fileOptions struct {
+	// text selects the default text format instead of JSON
+	text bool
+	// writerOptions configure the entry format
+	writerOptions []WriterOption
+	// reopenInterval is the minimum time between two rotation checks
+	reopenInterval time.Duration
+	// perm is the permission used when creating the file
+	perm os.FileMode
+	// minFreeSpace is the free disk space threshold in bytes; zero disables it
+	minFreeSpace uint64
+	// diskCheckInterval is the minimum time between two disk space checks
+	diskCheckInterval time.Duration
+	// lock takes an advisory file lock around each append
+	lock bool
+	// codec is the name of the compression codec; empty disables compression
+	codec string
+	// maxSize is the size in bytes that triggers a rotation; zero disables it
+	maxSize int64
+	// maxAge is the age of the file that triggers a rotation; zero disables it
+	maxAge time.Duration
+	// maxBackups is the number of rotated files kept; zero keeps them all
+	maxBackups int
+	// compressBackups gzips the rotated files
+	compressBackups bool
+	// checksums writes a summary file next to each rotated file
+	checksums bool
+	// maxOpenFiles is the number of partition files kept open
+	maxOpenFiles int
+}
+
+// FileTextFormat makes the file writer use the human-readable format of
+// NewDefaultWriter instead of JSON lines.
+func FileTextFormat() FileOption {
+	return func(o *fileOptions) {
+		o.text = true
+	}
+}
+
+// FileWriterOptions passes WriterOption values (SortKeys, OnError, ...) to the
+// format used by the file writer.
+func FileWriterOptions(opts ...WriterOption) FileOption {
+	return func(o *fileOptions) {
+		o.writerOptions = append(o.writerOptions, opts...)
+	}
+}
+
+// ReopenInterval sets how often the file writer checks whether the file at
+// its path was rotated (renamed, replaced, or deleted) by an external tool
+// such as logrotate. The default is one second; zero checks on every entry.
+func ReopenInterval(interval time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.reopenInterval = interval
+	}
+}
+
+// FilePermissions sets the permissions used when the log file is created.
+// The default is 0644.
+func FilePermissions(perm os.FileMode) FileOption {
+	return func(o *fileOptions) {
+		o.perm = perm
+	}
+}
+
+// MinFreeDiskSpace makes the file writer monitor the free space of the
+// filesystem holding the log file. When it falls below minFree bytes, the
+// writer enters emergency mode: Trace, Debug, and Info entries are dropped
+// and only Warn entries and above are written, so logging cannot fill the disk and take down
+// the host service. Entering emergency mode reports ErrLowDiskSpace to the
+// error handler; the writer leaves it once space is available again.
+//
+// Free space is checked every 10 seconds (see DiskCheckInterval). The check
+// is supported on Linux, macOS, FreeBSD, and Windows and skipped elsewhere.
+func MinFreeDiskSpace(minFree uint64) FileOption {
+	return func(o *fileOptions) {
+		o.minFreeSpace = minFree
+	}
+}
+
+// DiskCheckInterval sets how often the file writer checks free disk space
+// when MinFreeDiskSpace is set.
+func DiskCheckInterval(interval time.Duration) FileOption {
+	return func(o *fileOptions) {
+		o.diskCheckInterval = interval
+	}
+}
+
+// LockFile makes the file writer hold an exclusive advisory lock on the file
+// (flock on Unix, LockFileEx on Windows) while appending each entry, so that
+// several processes sharing the same log path never interleave partial
+// lines. All processes writing the file must enable it. It has no effect on
+// platforms without file locking support.
+func LockFile() FileOption {
+	return func(o *fileOptions) {
+		o.lock = true
+	}
+}
+
+// FileCompression makes the file writer compress its output with the codec
+// registered under name (see RegisterCodec), such as "gzip". Entries are
+// buffered and compressed in blocks of 64 KiB; each block is a complete
+// compressed stream appended to the file, so the file can be decompressed as
+// a whole (gzip and zstd read concatenated streams) even if the process
+// stops. Entries still buffered are written by Flush and Close, and lost if
+// the process exits without them.
+//
+// NewFileWriter returns ErrUnknownCodec if name is not registered.
+func FileCompression(name string) FileOption {
+	return func(o *fileOptions) {
+		o.codec = name
+	}
+}
+
+// fileWriter implements the LogWriter interface by appending entries to a file.
+type fileWriter struct {
+	mu        sync.Mutex
+	path      string
+	opts      fileOptions
+	file      *os.File
+	lastCheck time.Time
+	pending   bytes.Buffer
+	encoder   entryEncoder
+	errors    writerOptions
+	// lastDiskCheck is the time free disk space was last checked
+	lastDiskCheck time.Time
+	// emergency is set while free disk space is below the threshold
+	emergency bool
+	// codec compresses blocks of entries when FileCompression is set
+	codec Codec
+	// block holds the entries waiting to be compressed
+	block bytes.Buffer
+	// size is the size of the open file, tracked when MaxFileSize is set
+	size int64
+	// opened is when the open file was opened, for MaxFileAge
+	opened time.Time
+	// entries is the number of entries appended to the open file
+	entries int64
+	// initialSize is the size of the open file when it was opened
+	initialSize int64
+	// blockEntries is the number of entries waiting to be compressed
+	blockEntries int64
+	// backups runs the worker processing the rotated files
+	backups sync.WaitGroup
+	// backupMu guards pendingBackups and processingBackups
+	backupMu sync.Mutex
+	// pendingBackups holds the rotated files not processed yet, oldest first
+	pendingBackups []rotatedFile
+	// processingBackups reports whether the worker is running
+	processingBackups bool
+}
+
+// NewFileWriter creates a LogWriter that appends entries to the file at path,
+// creating it if needed. Entries are JSON lines unless FileTextFormat is set.
+//
+// Each entry is written to the file with a single write call, so entries are
+// never split across partial writes. The writer periodically checks whether
+// the file was rotated by an external tool (the path is missing or points to a
+// different file, as after logrotate renames it) and transparently reopens
+// the path, so it never keeps writing to a deleted file.
+//
+// With MaxFileSize or MaxFileAge, the writer rotates the file itself (see
+// MaxBackups, CompressBackups, and SegmentChecksums).
+// With LockFile, several processes can safely append to the same path.
+// With MinFreeDiskSpace, the writer stops writing Debug and Info entries when
+// the disk is almost full. With FileCompression, the file is compressed.
+//
+// Errors opening or writing the file are reported to the error handler set
+// with FileWriterOptions(OnError(...)).
+//
+// Example:
+//
+//	writer, err := golog.NewFileWriter("/var/log/app.log")
+//	if err != nil {
+//	    return err
+//	}
+//	golog.SetWriter(writer)
+func NewFileWriter(path string, opts ...FileOption) (*fileWriter, error) {
+	o := fileOptions{
+		reopenInterval:    defaultReopenInterval,
+		perm:              0o644,
+		diskCheckInterval: defaultDiskCheckInterval,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	w := &fileWriter{
+		path:   path,
+		opts:   o,
+		errors: newWriterOptions(o.writerOptions),
+	}
+
+	if o.codec != "" {
+		codec, err := lookupCodec(o.codec)
+		if err != nil {
+			return nil, err
+		}
+
+		w.codec = codec
+	}
+
+	if o.text {
+		w.encoder = NewDefaultWriter(&w.pending, o.writerOptions...)
+	} else {
+		w.encoder = NewJSONWriter(&w.pending, o.writerOptions...)
+	}
+
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+
+	w.lastCheck = time.Now()
+
+	return w, nil
+}
+
+// Write implements LogWriter.
+func (w *fileWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *fileWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write formats the entry and appends it to the file with a single write.
+func (w *fileWriter) write(entry Entry, file string, line int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.checkDiskSpace()
+	if w.emergency && entry.Level < LevelWarn {
+		RecordDropped(DropLowDiskSpace, 1)
+		return
+	}
+
+	w.pending.Reset()
+	w.encoder.write(entry, file, line)
+	if err := w.encoder.flushBuffer(); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: format log entry: %w", err))
+		return
+	}
+
+	if w.codec != nil {
+		w.block.Write(w.pending.Bytes())
+		w.blockEntries++
+		if w.block.Len() >= compressedBlockSize {
+			w.writeBlock()
+		}
+
+		return
+	}
+
+	w.appendEntries(w.pending.Bytes(), 1)
+}
+
+// writeBlock compresses the buffered entries and appends them to the file.
+func (w *fileWriter) writeBlock() {
+	if w.block.Len() == 0 {
+		return
+	}
+
+	data, err := compress(w.codec, w.block.Bytes())
+	entries := w.blockEntries
+	w.block.Reset()
+	w.blockEntries = 0
+	if err != nil {
+		w.errors.handleError(fmt.Errorf("golog: compress log entries: %w", err))
+		return
+	}
+
+	w.appendEntries(data, entries)
+}
+
+// appendEntries appends n formatted entries to the file, reopening it first
+// if it was rotated.
+func (w *fileWriter) appendEntries(data []byte, n int64) {
+	if err := w.reopenIfRotated(); err != nil {
+		w.errors.handleError(err)
+		return
+	}
+
+	if w.shouldRotate(len(data)) {
+		if err := w.rotate(); err != nil {
+			w.errors.handleError(err)
+			if w.file == nil {
+				return
+			}
+		}
+	}
+
+	if err := w.append(data); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: write log file %q: %w", w.path, err))
+		return
+	}
+
+	w.size += int64(len(data))
+	w.entries += n
+}
+
+// append writes data to the file, holding the file lock when LockFile is set.
+func (w *fileWriter) append(data []byte) error {
+	if !w.opts.lock {
+		_, err := w.file.Write(data)
+		return err
+	}
+
+	if err := lockFile(w.file); err != nil {
+		return err
+	}
+
+	_, err := w.file.Write(data)
+	if unlockErr := unlockFile(w.file); err == nil {
+		err = unlockErr
+	}
+
+	return err
+}
+
+// Flush implements LogWriter. Entries are written as they are logged, so
+// Flush only commits the file contents to stable storage, after writing the
+// entries buffered for compression. The file stays open; use Close to
+// release it.
+func (w *fileWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.codec != nil {
+		w.writeBlock()
+	}
+
+	if w.file == nil {
+		return
+	}
+
+	if err := w.file.Sync(); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: sync log file %q: %w", w.path, err))
+	}
+}
+
+// Close writes the entries buffered for compression and closes the log
+// file, after the compression and removal of rotated files complete. A later
+// entry reopens it.
+func (w *fileWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer w.backups.Wait()
+
+	if w.codec != nil {
+		w.writeBlock()
+	}
+
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// open opens the file at the writer's path for appending.
+func (w *fileWriter) open() error {
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, w.opts.perm)
+	if err != nil {
+		return fmt.Errorf("golog: open log file %q: %w", w.path, err)
+	}
+
+	w.file = f
+	w.opened = time.Now()
+	w.size = 0
+	if info, err := f.Stat(); err == nil {
+		w.size = info.Size()
+	}
+	w.entries = 0
+	w.initialSize = w.size
+
+	return nil
+}
+
+// reopenIfRotated reopens the path when the file is closed or, at most once
+// per reopen interval, when the path no longer refers to the open file.
+func (w *fileWriter) reopenIfRotated() error {
+	if w.file != nil {
+		now := time.Now()
+		if now.Sub(w.lastCheck) < w.opts.reopenInterval {
+			return nil
+		}
+
+		w.lastCheck = now
+		if !w.rotated() {
+			return nil
+		}
+
+		w.file.Close()
+		w.file = nil
+	}
+
+	return w.open()
+}
+
+// rotated reports whether the path was removed or now refers to another file.
+func (w *fileWriter) rotated() bool {
+	pathInfo, err := os.Stat(w.path)
+	if err != nil {
+		return true
+	}
+
+	fileInfo, err := w.file.Stat()
+	if err != nil {
+		return true
+	}
+
+	return !os.SameFile(pathInfo, fileInfo)
+}
+
+// checkDiskSpace updates the emergency mode from the free disk space, at most
+// once per disk check interval.
+func (w *fileWriter) checkDiskSpace() {
+	if w.opts.minFreeSpace == 0 {
+		return
+	}
+
+	now := time.Now()
+	if !w.lastDiskCheck.IsZero() && now.Sub(w.lastDiskCheck) < w.opts.diskCheckInterval {
+		return
+	}
+
+	w.lastDiskCheck = now
+
+	dir := filepath.Dir(w.path)
+	free, ok := diskFree(dir)
+	if !ok {
+		return
+	}
+
+	low := free < w.opts.minFreeSpace
+	if low && !w.emergency {
+		w.errors.handleError(fmt.Errorf(
+			"%w: %d bytes free in %q, below %d; dropping debug and info entries",
+			ErrLowDiskSpace, free, dir, w.opts.minFreeSpace,
+		))
+	}
+
+	w.emergency = low
+}
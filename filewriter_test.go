@@ -0,0 +1,277 @@
+package golog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// readLines returns the non-empty lines of the file at path.
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return nil
+	}
+
+	return strings.Split(trimmed, "\n")
+}
+
+func TestNewFileWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewFileWriter(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	writer.Write(LevelInfo, "first", map[string]any{"n": 1})
+	writer.Write(LevelError, "second", nil)
+	writer.Flush()
+
+	lines := readLines(t, path)
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %d, want 2", len(lines))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("json.Unmarshal([]byte(lines[0]), &entry): %v", err)
+	}
+	if entry[FieldMessage] != "first" {
+		t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "first")
+	}
+	if entry["n"] != float64(1) {
+		t.Errorf(`entry["n"] = %v, want %v`, entry["n"], float64(1))
+	}
+}
+
+func TestNewFileWriter_TextFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewFileWriter(path, FileTextFormat(), FileWriterOptions(SortKeys()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	writer.Write(LevelInfo, "hello", map[string]any{"b": 2, "a": 1})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), `[INFO]`) {
+		t.Errorf("string(data) = %q, want it to contain %q", string(data), `[INFO]`)
+	}
+	if !strings.Contains(string(data), `hello a="1" b="2"`) {
+		t.Errorf("string(data) = %q, want it to contain %q", string(data), `hello a="1" b="2"`)
+	}
+}
+
+func TestNewFileWriter_InvalidPath(t *testing.T) {
+	_, err := NewFileWriter(filepath.Join(t.TempDir(), "missing", "app.log"))
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestFileWriter_ReopensAfterRotation(t *testing.T) {
+	tests := []struct {
+		name   string
+		rotate func(t *testing.T, path string)
+		// rotatedPath holds the entries written before rotation, if kept
+		rotatedPath string
+	}{
+		{
+			name: "renamed",
+			rotate: func(t *testing.T, path string) {
+				if err := os.Rename(path, path+".1"); err != nil {
+					t.Fatalf(`os.Rename(path, path+".1"): %v`, err)
+				}
+			},
+			rotatedPath: ".1",
+		},
+		{
+			name: "deleted",
+			rotate: func(t *testing.T, path string) {
+				if err := os.Remove(path); err != nil {
+					t.Fatalf("os.Remove(path): %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+
+			writer, err := NewFileWriter(path, ReopenInterval(0))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer writer.Close()
+
+			writer.Write(LevelInfo, "before", nil)
+			tt.rotate(t, path)
+			writer.Write(LevelInfo, "after", nil)
+
+			lines := readLines(t, path)
+			if len(lines) != 1 {
+				t.Fatalf("len(lines) = %d, want 1", len(lines))
+			}
+			if !strings.Contains(lines[0], `"msg":"after"`) {
+				t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"msg":"after"`)
+			}
+
+			if tt.rotatedPath != "" {
+				rotated := readLines(t, path+tt.rotatedPath)
+				if len(rotated) != 1 {
+					t.Fatalf("len(rotated) = %d, want 1", len(rotated))
+				}
+				if !strings.Contains(rotated[0], `"msg":"before"`) {
+					t.Errorf("rotated[0] = %q, want it to contain %q", rotated[0], `"msg":"before"`)
+				}
+			}
+		})
+	}
+}
+
+func TestFileWriter_WriteAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	writer, err := NewFileWriter(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writer.Write(LevelInfo, "before", nil)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+	writer.Write(LevelInfo, "after", nil)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("writer.Close(): %v", err)
+	}
+
+	if got := readLines(t, path); len(got) != 2 {
+		t.Errorf("len(readLines(t, path)) = %d, want 2", len(got))
+	}
+}
+
+func TestFileWriter_MinFreeDiskSpace(t *testing.T) {
+	resetDrops(t)
+
+	free := uint64(50)
+	oldDiskFree := diskFree
+	diskFree = func(string) (uint64, bool) { return free, true }
+	t.Cleanup(func() { diskFree = oldDiskFree })
+
+	var errs []error
+	path := filepath.Join(t.TempDir(), "app.log")
+	writer, err := NewFileWriter(
+		path,
+		MinFreeDiskSpace(100),
+		DiskCheckInterval(0),
+		FileWriterOptions(OnError(func(err error) { errs = append(errs, err) })),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer writer.Close()
+
+	writer.Write(LevelInfo, "dropped-info", nil)
+	writer.Write(LevelDebug, "dropped-debug", nil)
+	writer.Write(LevelWarn, "kept-warn", nil)
+	writer.Write(LevelError, "kept-error", nil)
+
+	if len(errs) != 1 {
+		t.Fatalf("entering emergency mode is reported once: len(errs) = %d, want 1", len(errs))
+	}
+	if !errors.Is(errs[0], ErrLowDiskSpace) {
+		t.Errorf("errs[0] = %v, want %v", errs[0], ErrLowDiskSpace)
+	}
+	want := map[string]int{DropLowDiskSpace: 2}
+	if !reflect.DeepEqual(drops.counts, want) {
+		t.Errorf("drops.counts = %v, want %v", drops.counts, want)
+	}
+
+	free = 500
+	writer.Write(LevelInfo, "recovered", nil)
+
+	lines := readLines(t, path)
+	if len(lines) != 3 {
+		t.Fatalf("len(lines) = %d, want 3", len(lines))
+	}
+	if !strings.Contains(lines[0], `"msg":"kept-warn"`) {
+		t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"msg":"kept-warn"`)
+	}
+	if !strings.Contains(lines[1], `"msg":"kept-error"`) {
+		t.Errorf("lines[1] = %q, want it to contain %q", lines[1], `"msg":"kept-error"`)
+	}
+	if !strings.Contains(lines[2], `"msg":"recovered"`) {
+		t.Errorf("lines[2] = %q, want it to contain %q", lines[2], `"msg":"recovered"`)
+	}
+}
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, ok := freeDiskSpace(t.TempDir())
+	if !ok {
+		t.Skip("free disk space is not supported on this platform")
+	}
+
+	if free == 0 {
+		t.Error("free is zero")
+	}
+}
+
+func TestFileWriter_LockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "shared.log")
+	payload := strings.Repeat("x", 64*1024)
+
+	// Two writers with separate file handles stand in for two processes.
+	writers := make([]*fileWriter, 2)
+	for i := range writers {
+		writer, err := NewFileWriter(path, LockFile())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer writer.Close()
+		writers[i] = writer
+	}
+
+	var wg sync.WaitGroup
+	for i, writer := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				writer.Write(LevelInfo, "large entry", map[string]any{"writer": i, "payload": payload})
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines := readLines(t, path)
+	if len(lines) != 40 {
+		t.Fatalf("len(lines) = %d, want 40", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("every line is a complete JSON entry: json.Unmarshal([]byte(line), &entry): %v", err)
+		}
+	}
+}
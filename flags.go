@@ -0,0 +1,252 @@
+package golog
+
+import (
+	"context"
+	"maps"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// defaultFlagInterval is the time between two evaluations of the flags by
+// default
+const defaultFlagInterval = 30 * time.Second
+
+// FlagProvider returns the value of the feature flag key, and false when it
+// is not set, e.g. by evaluating it with an OpenFeature client. ctx is the
+// context passed to BindFlags, which can carry the evaluation context, such
+// as the environment or the tenant.
+type FlagProvider func(ctx context.Context, key string) (value any, ok bool)
+
+// FlagOption configures BindFlags.
+type FlagOption func(*flagBinding)
+
+// flagBinding holds the flags bound to the settings of a Logger.
+type flagBinding struct {
+	logger      *Logger
+	interval    time.Duration
+	levelKey    string
+	sampleKey   string
+	debugKey    string
+	debugFields []string
+}
+
+// flagSettings holds the settings of a Logger overridden by flags.
+type flagSettings struct {
+	// level is the minimum level, or -1 to keep the Logger's
+	level int
+	// sampleRate is the one-in-n rate of entries below LevelWarn, or 0 to
+	// keep them all
+	sampleRate int
+	// sampled counts the entries below LevelWarn, for sampling
+	sampled *atomic.Uint64
+	// hiddenFields holds the debug fields removed from entries
+	hiddenFields []string
+}
+
+// FlagLevel binds the minimum level of the Logger to the flag key, whose
+// value is a level name, such as "debug", or number. It replaces the level
+// set with SetLevel while the flag is set.
+func FlagLevel(key string) FlagOption {
+	return func(b *flagBinding) {
+		b.levelKey = key
+	}
+}
+
+// FlagSampleRate binds a sample rate to the flag key, whose value is a
+// number n: one in every n entries below LevelWarn is written. Warnings and
+// errors are always written.
+func FlagSampleRate(key string) FlagOption {
+	return func(b *flagBinding) {
+		b.sampleKey = key
+	}
+}
+
+// FlagDebugFields binds the debug fields, fields only useful while
+// debugging, such as request bodies, to the boolean flag key: they are
+// removed from entries unless the flag is true.
+func FlagDebugFields(key string, fields ...string) FlagOption {
+	return func(b *flagBinding) {
+		b.debugKey = key
+		b.debugFields = fields
+	}
+}
+
+// FlagInterval sets the time between two evaluations of the flags. The
+// default is 30 seconds.
+func FlagInterval(interval time.Duration) FlagOption {
+	return func(b *flagBinding) {
+		b.interval = interval
+	}
+}
+
+// FlagLogger binds the flags to lg instead of the default Logger.
+func FlagLogger(lg *Logger) FlagOption {
+	return func(b *flagBinding) {
+		b.logger = lg
+	}
+}
+
+// BindFlags binds the level, sample rate, and debug fields of the default
+// Logger to feature flags, so that verbosity can be tuned per environment or
+// tenant from the flag system. The flags are evaluated before BindFlags
+// returns, then every 30 seconds (see FlagInterval), until stop is called or
+// ctx is done; stop restores the Logger's own settings. Flags that are not
+// set, or whose value is invalid, leave the setting alone. Bind a Logger
+// once at a time.
+//
+// Example:
+//
+//	stop := golog.BindFlags(ctx, func(ctx context.Context, key string) (any, bool) {
+//	    value, err := flags.StringValue(ctx, key, "", openfeature.EvaluationContext{})
+//	    return value, err == nil && value != ""
+//	},
+//	    golog.FlagLevel("log-level"),
+//	    golog.FlagDebugFields("log-debug-fields", "request_body"),
+//	)
+//	defer stop()
+func BindFlags(ctx context.Context, provider FlagProvider, opts ...FlagOption) (stop func()) {
+	b := &flagBinding{logger: std, interval: defaultFlagInterval}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(b)
+		}
+	}
+
+	sampled := &atomic.Uint64{}
+	b.logger.flags.Store(b.evaluate(ctx, provider, sampled))
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(max(b.interval, time.Millisecond))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				b.logger.flags.Store(b.evaluate(ctx, provider, sampled))
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+		b.logger.flags.Store(nil)
+	}
+}
+
+// evaluate returns the settings of the flags.
+func (b *flagBinding) evaluate(ctx context.Context, provider FlagProvider, sampled *atomic.Uint64) *flagSettings {
+	s := &flagSettings{level: -1, sampled: sampled, hiddenFields: b.debugFields}
+
+	if b.levelKey != "" {
+		if value, ok := provider(ctx, b.levelKey); ok {
+			s.level = flagLevel(value)
+		}
+	}
+
+	if b.sampleKey != "" {
+		if value, ok := provider(ctx, b.sampleKey); ok {
+			if n, ok := flagInt(value); ok && n > 0 {
+				s.sampleRate = n
+			}
+		}
+	}
+
+	if b.debugKey != "" {
+		if value, ok := provider(ctx, b.debugKey); ok && flagBool(value) {
+			s.hiddenFields = nil
+		}
+	}
+
+	return s
+}
+
+// flagLevel returns the level of a flag value, or -1 if it is invalid.
+func flagLevel(value any) int {
+	if name, ok := value.(string); ok {
+		if level := ParseLevel(name); level >= 0 {
+			return level
+		}
+	}
+
+	if n, ok := flagInt(value); ok {
+		if _, known := levelNames[n]; known {
+			return n
+		}
+	}
+
+	return -1
+}
+
+// flagBool reports whether a flag value is true or "true".
+func flagBool(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, _ := strconv.ParseBool(v)
+		return b
+	}
+
+	return false
+}
+
+// flagInt returns the integer of a flag value: an integer, a whole float, or
+// a numeric string.
+func flagInt(value any) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	case string:
+		if n, err := strconv.Atoi(v); err == nil {
+			return n, true
+		}
+	}
+
+	return 0, false
+}
+
+// applyFlags applies the settings of the flags of lg to an entry at level.
+// It reports whether the entry is written, and returns its fields without
+// the hidden debug fields.
+func (lg *Logger) applyFlags(level int, fields map[string]any) (map[string]any, bool) {
+	s := lg.flags.Load()
+	if s == nil {
+		return fields, true
+	}
+
+	if s.sampleRate > 1 && level < LevelWarn && (s.sampled.Add(1)-1)%uint64(s.sampleRate) != 0 {
+		return nil, false
+	}
+
+	cloned := false
+	for _, key := range s.hiddenFields {
+		if _, ok := fields[key]; !ok {
+			continue
+		}
+
+		if !cloned {
+			fields = maps.Clone(fields)
+			cloned = true
+		}
+
+		delete(fields, key)
+	}
+
+	return fields, true
+}
@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flagValues is a FlagProvider backed by a map.
+type flagValues struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func (f *flagValues) get(_ context.Context, key string) (any, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.values[key]
+	return value, ok
+}
+
+func (f *flagValues) set(key string, value any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.values[key] = value
+}
+
+func TestBindFlags(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]any
+		// written counts the entries written by level
+		written map[int]int
+		// debugFields reports whether the debug fields are kept
+		debugFields bool
+	}{
+		{
+			name:    "unset",
+			values:  map[string]any{},
+			written: map[int]int{LevelInfo: 10, LevelWarn: 10},
+		},
+		{
+			name:        "debug",
+			values:      map[string]any{"log-level": "debug", "log-debug-fields": true},
+			written:     map[int]int{LevelDebug: 10, LevelInfo: 10, LevelWarn: 10},
+			debugFields: true,
+		},
+		{
+			name:    "sampled",
+			values:  map[string]any{"log-level": float64(LevelDebug), "log-sample-rate": "5"},
+			written: map[int]int{LevelDebug: 2, LevelInfo: 2, LevelWarn: 10},
+		},
+		{
+			name:    "invalid",
+			values:  map[string]any{"log-level": "verbose", "log-sample-rate": -1, "log-debug-fields": "no"},
+			written: map[int]int{LevelInfo: 10, LevelWarn: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			lg := New(LoggerWriter(w))
+			flags := &flagValues{values: tt.values}
+
+			stop := BindFlags(context.Background(), flags.get,
+				FlagLogger(lg),
+				FlagLevel("log-level"),
+				FlagSampleRate("log-sample-rate"),
+				FlagDebugFields("log-debug-fields", "request_body"),
+			)
+			defer stop()
+
+			for _, level := range []int{LevelDebug, LevelInfo, LevelWarn} {
+				for i := 0; i < 10; i++ {
+					lg.With("request_body", "{}").Log(level, "entry")
+				}
+			}
+
+			if got := countLevels(w.entries); !reflect.DeepEqual(got, tt.written) {
+				t.Errorf("countLevels(w.entries) = %v, want %v", got, tt.written)
+			}
+			_, kept := w.last().fields["request_body"]
+			if kept != tt.debugFields {
+				t.Errorf("kept = %v, want %v", kept, tt.debugFields)
+			}
+		})
+	}
+}
+
+func TestBindFlags_Reevaluate(t *testing.T) {
+	lg := New(LoggerWriter(&captureWriter{}))
+	flags := &flagValues{values: map[string]any{"log-level": "error"}}
+
+	stop := BindFlags(context.Background(), flags.get, FlagLogger(lg), FlagLevel("log-level"), FlagInterval(time.Millisecond))
+	if got := lg.Level(); got != LevelError {
+		t.Errorf("lg.Level() = %v, want %v", got, LevelError)
+	}
+
+	flags.set("log-level", "debug")
+	eventually(t, func() bool { return lg.Level() == LevelDebug })
+
+	stop()
+	if got := lg.Level(); got != LevelInfo {
+		t.Errorf("stop restores the level of the Logger: lg.Level() = %v, want %v", got, LevelInfo)
+	}
+}
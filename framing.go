@@ -0,0 +1,88 @@
+package golog
+
+import "strings"
+
+// Framing selects how the built-in writers delimit entries.
+type Framing int
+
+const (
+	// FramingNewline ends each entry with "\n". This is the default.
+	FramingNewline Framing = iota
+	// FramingCRLF ends each entry with "\r\n", for Windows-native tools that
+	// expect CRLF line endings.
+	FramingCRLF
+	// FramingJSONSeq writes JSON text sequences (RFC 7464, media type
+	// application/json-seq): each entry starts with the ASCII record
+	// separator (0x1E) and ends with "\n". Collectors reading such sequences
+	// can recover from a truncated entry at the next separator.
+	FramingJSONSeq
+)
+
+// recordSeparator starts each entry with FramingJSONSeq.
+const recordSeparator = "\x1e"
+
+// RecordFraming sets how the JSON and default writers delimit entries.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(conn, golog.RecordFraming(golog.FramingJSONSeq))
+func RecordFraming(framing Framing) WriterOption {
+	return func(o *writerOptions) {
+		o.framing = framing
+	}
+}
+
+// recordStart returns the bytes written before each entry.
+func (o writerOptions) recordStart() string {
+	if o.framing == FramingJSONSeq {
+		return recordSeparator
+	}
+
+	return ""
+}
+
+// recordEnd returns the bytes written after each entry.
+func (o writerOptions) recordEnd() string {
+	if o.framing == FramingCRLF {
+		return "\r\n"
+	}
+
+	return "\n"
+}
+
+// defaultContinuationPrefix starts the continuation lines of multi-line text
+// unless ContinuationPrefix sets another prefix.
+const defaultContinuationPrefix = "\t"
+
+// ContinuationPrefix sets the prefix of the continuation lines of a
+// multi-line message or field value, such as a stack trace, in the default
+// writer. Line-based collectors, such as Filebeat multiline or Fluent Bit
+// multiline parsers, fold lines starting with the prefix into the previous
+// event. The default is a tab; an empty prefix restores it.
+//
+// Example:
+//
+//	writer := golog.NewDefaultWriter(os.Stdout, golog.ContinuationPrefix("  | "))
+func ContinuationPrefix(prefix string) WriterOption {
+	return func(o *writerOptions) {
+		o.continuation = prefix
+	}
+}
+
+// foldLines returns s with every line after the first starting with the
+// continuation prefix. Trailing line breaks are removed.
+func (o writerOptions) foldLines(s string) string {
+	if !strings.ContainsAny(s, "\r\n") {
+		return s
+	}
+
+	prefix := o.continuation
+	if prefix == "" {
+		prefix = defaultContinuationPrefix
+	}
+
+	s = strings.TrimRight(s, "\r\n")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return strings.ReplaceAll(s, "\n", o.recordEnd()+prefix)
+}
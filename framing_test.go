@@ -0,0 +1,69 @@
+package golog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRecordFraming(t *testing.T) {
+	tests := []struct {
+		name    string
+		framing Framing
+		start   string
+		end     string
+	}{
+		{
+			name:    "newline",
+			framing: FramingNewline,
+			end:     "\n",
+		},
+		{
+			name:    "crlf",
+			framing: FramingCRLF,
+			end:     "\r\n",
+		},
+		{
+			name:    "json-seq",
+			framing: FramingJSONSeq,
+			start:   "\x1e",
+			end:     "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonBuf := &bytes.Buffer{}
+			jsonWriter := NewJSONWriter(jsonBuf, RecordFraming(tt.framing))
+			jsonWriter.Write(LevelInfo, "first", map[string]any{"a": 1})
+			jsonWriter.Write(LevelInfo, "second", map[string]any{"a": 1})
+			jsonWriter.Flush()
+
+			records := strings.SplitAfter(jsonBuf.String(), "\n")
+			if len(records) != 3 {
+				t.Errorf("two records and an empty tail: len(records) = %d, want 3", len(records))
+			}
+			for _, record := range records[:2] {
+				if !strings.HasPrefix(record, tt.start+"{") {
+					t.Errorf("record %q", record)
+				}
+				if !strings.HasSuffix(record, `"a":1}`+tt.end) {
+					t.Errorf("record %q", record)
+				}
+			}
+
+			textBuf := &bytes.Buffer{}
+			textWriter := NewDefaultWriter(textBuf, RecordFraming(tt.framing))
+			textWriter.Write(LevelInfo, "first", map[string]any{"a": 1})
+			textWriter.Flush()
+
+			text := textBuf.String()
+			if !strings.HasPrefix(text, tt.start+"framing_test.go:") {
+				t.Errorf("entry %q", text)
+			}
+			if !strings.HasSuffix(text, `a="1"`+tt.end) {
+				t.Errorf("entry %q", text)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+)
+
+// FieldGoroutineID is the key for the goroutine ID added by GoroutineIDEnricher
+const FieldGoroutineID = "goroutine_id"
+
+// GoroutineIDEnricher returns an Enricher that adds the ID of the goroutine
+// writing the entry under the "goroutine_id" key. It helps to tell apart
+// interleaved entries when debugging concurrency issues where no request
+// context is available.
+//
+// The ID is parsed from the runtime stack header, which costs a small stack
+// capture per entry, so register it only when needed:
+//
+//	golog.RegisterEnricher(golog.GoroutineIDEnricher())
+func GoroutineIDEnricher() Enricher {
+	return EnricherFunc(func(_ context.Context, _, _ string, fields map[string]any) {
+		if id, ok := goroutineID(); ok {
+			fields[FieldGoroutineID] = id
+		}
+	})
+}
+
+// PprofLabelsEnricher returns an Enricher that adds the pprof labels carried
+// by the scope's context as fields, one field per label. Labels are attached
+// with pprof.Do or pprof.WithLabels and are typically used to name workers
+// or pools:
+//
+//	golog.RegisterEnricher(golog.PprofLabelsEnricher())
+//
+//	pprof.Do(ctx, pprof.Labels("worker", "indexer-3"), func(ctx context.Context) {
+//	    golog.WithContext(ctx).Info("Batch indexed") // worker="indexer-3"
+//	})
+//
+// Labels never override fields already set on the entry.
+func PprofLabelsEnricher() Enricher {
+	return EnricherFunc(func(ctx context.Context, _, _ string, fields map[string]any) {
+		if ctx == nil {
+			return
+		}
+
+		pprof.ForLabels(ctx, func(key, value string) bool {
+			if _, exists := fields[key]; !exists {
+				fields[key] = value
+			}
+
+			return true
+		})
+	})
+}
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [status]:" header written by runtime.Stack.
+func goroutineID() (uint64, bool) {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+
+	header := bytes.TrimPrefix(buf[:n], []byte("goroutine "))
+	end := bytes.IndexByte(header, ' ')
+	if end < 0 {
+		return 0, false
+	}
+
+	id, err := strconv.ParseUint(string(header[:end]), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return id, true
+}
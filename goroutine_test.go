@@ -0,0 +1,70 @@
+package golog
+
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+	"testing"
+)
+
+func TestGoroutineIDEnricher(t *testing.T) {
+	enricher := GoroutineIDEnricher()
+
+	main := map[string]any{}
+	enricher.Enrich(context.Background(), "INFO", "msg", main)
+	if reflect.ValueOf(main[FieldGoroutineID]).IsZero() {
+		t.Error("main[FieldGoroutineID] is zero")
+	}
+
+	other := map[string]any{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		enricher.Enrich(context.Background(), "INFO", "msg", other)
+	}()
+	<-done
+
+	if reflect.ValueOf(other[FieldGoroutineID]).IsZero() {
+		t.Error("other[FieldGoroutineID] is zero")
+	}
+	if reflect.DeepEqual(other[FieldGoroutineID], main[FieldGoroutineID]) {
+		t.Errorf("other[FieldGoroutineID] = %v, want a different value", other[FieldGoroutineID])
+	}
+}
+
+func TestPprofLabelsEnricher(t *testing.T) {
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		fields   map[string]any
+		expected map[string]any
+	}{
+		{
+			name:     "no-labels",
+			ctx:      context.Background(),
+			fields:   map[string]any{},
+			expected: map[string]any{},
+		},
+		{
+			name:     "labels-become-fields",
+			ctx:      pprof.WithLabels(context.Background(), pprof.Labels("worker", "indexer-3", "pool", "io")),
+			fields:   map[string]any{},
+			expected: map[string]any{"worker": "indexer-3", "pool": "io"},
+		},
+		{
+			name:     "existing-fields-win",
+			ctx:      pprof.WithLabels(context.Background(), pprof.Labels("worker", "indexer-3")),
+			fields:   map[string]any{"worker": "explicit"},
+			expected: map[string]any{"worker": "explicit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			PprofLabelsEnricher().Enrich(tt.ctx, "INFO", "msg", tt.fields)
+			if !reflect.DeepEqual(tt.fields, tt.expected) {
+				t.Errorf("tt.fields = %v, want %v", tt.fields, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package golog
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// GoroutineDumpMessage is the message of the entries carrying a goroutine
+// dump.
+const GoroutineDumpMessage = "golog goroutine dump"
+
+// goroutineDumpChunkSize is the maximum size in bytes of the dump carried by
+// one entry, so that entries stay below the size limits of log pipelines.
+const goroutineDumpChunkSize = 32 * 1024
+
+// LogGoroutineDump writes the stacks of all goroutines as Warn entries with
+// the message "golog goroutine dump", so the diagnostics of a stuck process
+// end up in the central log store rather than on a terminal. Call it from an
+// admin endpoint, or see DumpGoroutinesOnSignal.
+//
+// Large dumps are split, between goroutines where possible, into entries of
+// at most 32KiB of stack text. Their fields are:
+//
+//   - reason: the reason passed to LogGoroutineDump
+//   - dump_id: an ID shared by the entries of one dump
+//   - goroutines: the number of goroutines
+//   - chunk, chunks: the position of the entry in the dump and its length
+//   - stack: the stacks, in the format of runtime.Stack
+func LogGoroutineDump(reason string) {
+	dump := goroutineStacks()
+	chunks := splitDump(dump, goroutineDumpChunkSize)
+	id := newRunID()
+	goroutines := runtime.NumGoroutine()
+
+	for i, chunk := range chunks {
+		WithFields(map[string]any{
+			"reason":     reason,
+			"dump_id":    id,
+			"goroutines": goroutines,
+			"chunk":      i + 1,
+			"chunks":     len(chunks),
+			"stack":      string(chunk),
+		}).Warn(GoroutineDumpMessage)
+	}
+}
+
+// goroutineStacks returns the stacks of all goroutines.
+func goroutineStacks() []byte {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// splitDump splits dump into chunks of at most size bytes, preferably at the
+// blank lines between goroutines.
+func splitDump(dump []byte, size int) [][]byte {
+	var chunks [][]byte
+
+	for len(dump) > size {
+		end := bytes.LastIndex(dump[:size], []byte("\n\n"))
+		if end <= 0 {
+			end = size
+		}
+
+		chunks = append(chunks, dump[:end])
+		dump = bytes.TrimLeft(dump[end:], "\n")
+	}
+
+	return append(chunks, dump)
+}
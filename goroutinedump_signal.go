@@ -0,0 +1,52 @@
+//go:build !js && !wasip1 && !plan9
+
+package golog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// DumpGoroutinesOnSignal logs a goroutine dump (see LogGoroutineDump) each
+// time the process receives one of sigs, or SIGQUIT when none is given,
+// until stop is called. Calling stop more than once has no effect.
+//
+// Handling SIGQUIT replaces the default behavior of the Go runtime, which
+// prints the stacks to stderr and exits: the process keeps running, so a
+// stuck process can be inspected with kill -QUIT without being restarted.
+//
+// Example:
+//
+//	stop := golog.DumpGoroutinesOnSignal()
+//	defer stop()
+func DumpGoroutinesOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGQUIT}
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+
+	go func() {
+		for {
+			select {
+			case sig := <-ch:
+				LogGoroutineDump(sig.String())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
+}
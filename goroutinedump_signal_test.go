@@ -0,0 +1,29 @@
+//go:build unix
+
+package golog
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestDumpGoroutinesOnSignal(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	stop := DumpGoroutinesOnSignal(syscall.SIGUSR1)
+	t.Cleanup(stop)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Errorf("syscall.Kill(syscall.Getpid(), syscall.SIGUSR1): %v", err)
+	}
+	eventually(t, func() bool {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		return len(w.entries) > 0 && w.entries[0].fields["reason"] == "user defined signal 1"
+	})
+
+	stop()
+	stop()
+}
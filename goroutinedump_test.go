@@ -0,0 +1,84 @@
+package golog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogGoroutineDump(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	LogGoroutineDump("admin request")
+
+	if len(w.entries) == 0 {
+		t.Fatal("w.entries is empty")
+	}
+	first := w.entries[0]
+	if first.msg != GoroutineDumpMessage {
+		t.Errorf("first.msg = %q, want %q", first.msg, GoroutineDumpMessage)
+	}
+	if first.level != LevelWarn {
+		t.Errorf("first.level = %v, want %v", first.level, LevelWarn)
+	}
+	if first.fields["reason"] != "admin request" {
+		t.Errorf(`first.fields["reason"] = %q, want %q`, first.fields["reason"], "admin request")
+	}
+	if first.fields["chunk"] != 1 {
+		t.Errorf(`first.fields["chunk"] = %v, want 1`, first.fields["chunk"])
+	}
+	if first.fields["chunks"] != len(w.entries) {
+		t.Errorf(`first.fields["chunks"] = %v, want %v`, first.fields["chunks"], len(w.entries))
+	}
+	if n, _ := first.fields["goroutines"].(int); n <= 0 {
+		t.Errorf(`first.fields["goroutines"] = %v, want positive`, first.fields["goroutines"])
+	}
+	if got, _ := first.fields["stack"].(string); !strings.Contains(got, "TestLogGoroutineDump") {
+		t.Errorf(`first.fields["stack"] = %q, want it to contain %q`, got, "TestLogGoroutineDump")
+	}
+}
+
+func TestSplitDump(t *testing.T) {
+	g1 := "goroutine 1 [running]:\nmain.main()"
+	g2 := "goroutine 2 [select]:\nmain.worker()"
+
+	tests := []struct {
+		name     string
+		dump     string
+		size     int
+		expected []string
+	}{
+		{
+			name:     "fits",
+			dump:     g1 + "\n\n" + g2,
+			size:     1024,
+			expected: []string{g1 + "\n\n" + g2},
+		},
+		{
+			name:     "between-goroutines",
+			dump:     g1 + "\n\n" + g2,
+			size:     len(g1) + 10,
+			expected: []string{g1, g2},
+		},
+		{
+			name:     "single-large-goroutine",
+			dump:     strings.Repeat("x", 10),
+			size:     4,
+			expected: []string{"xxxx", "xxxx", "xx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var chunks []string
+			for _, chunk := range splitDump([]byte(tt.dump), tt.size) {
+				chunks = append(chunks, string(chunk))
+			}
+
+			if !reflect.DeepEqual(chunks, tt.expected) {
+				t.Errorf("chunks = %v, want %v", chunks, tt.expected)
+			}
+		})
+	}
+}
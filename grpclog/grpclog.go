@@ -0,0 +1,369 @@
+// Package grpclog logs the RPCs of gRPC servers and clients. Its
+// interceptors log one entry per RPC, with the method, status code,
+// latency, and peer, and give each server RPC a scoped logger carrying its
+// request ID, which handlers get with golog.FromContext.
+//
+// The request ID is taken from the x-request-id metadata of the RPC, when
+// the client set it to a short printable value, or generated. The client
+// interceptors send the request ID of the scope stored in the context of the
+// call (see golog.IntoContext), so the ID follows a request across
+// services. Extract adds the IDs of other propagation schemes, with the
+// extractors of the httplog package.
+//
+// Example:
+//
+//	server := grpc.NewServer(
+//	    grpc.ChainUnaryInterceptor(grpclog.UnaryServerInterceptor()),
+//	    grpc.ChainStreamInterceptor(grpclog.StreamServerInterceptor()),
+//	)
+//
+//	conn, err := grpc.NewClient(target,
+//	    grpc.WithUnaryInterceptor(grpclog.UnaryClientInterceptor()),
+//	    grpc.WithStreamInterceptor(grpclog.StreamClientInterceptor()),
+//	)
+package grpclog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/httplog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Field names used in RPC entries
+const (
+	// FieldRequestID is the key of the request ID
+	FieldRequestID = httplog.FieldRequestID
+	// FieldMethod is the key of the full method name, e.g.
+	// "/orders.v1.Orders/CreateOrder"
+	FieldMethod = "grpc_method"
+	// FieldCode is the key of the status code, e.g. "NotFound"
+	FieldCode = "grpc_code"
+	// FieldLatency is the key of the duration of the RPC
+	FieldLatency = httplog.FieldLatency
+	// FieldPeer is the key of the address of the client, for servers, or
+	// of the target, for clients
+	FieldPeer = "peer"
+)
+
+// Messages of the RPC entries
+const (
+	// ServerMessage is the message of the entries of the server interceptors
+	ServerMessage = "rpc completed"
+	// ClientMessage is the message of the entries of the client interceptors
+	ClientMessage = "rpc call completed"
+)
+
+// DefaultRequestIDKey is the metadata key carrying the request ID by
+// default.
+const DefaultRequestIDKey = "x-request-id"
+
+// maxRequestIDLen is the length of the longest request ID accepted from
+// metadata.
+const maxRequestIDLen = 128
+
+// Option configures the interceptors.
+type Option func(*options)
+
+// options holds the settings of the interceptors.
+type options struct {
+	// requestIDKey is the metadata key carrying the request ID
+	requestIDKey string
+	// fields are added to every entry of the RPCs
+	fields map[string]any
+	// extractors return the fields of the metadata of a server RPC
+	extractors []httplog.Extractor
+}
+
+// RequestIDKey sets the metadata key carrying the request ID. The default
+// is x-request-id.
+func RequestIDKey(key string) Option {
+	return func(o *options) {
+		o.requestIDKey = key
+	}
+}
+
+// Fields adds fields to every entry of the RPCs, including the entries of
+// the scoped loggers of server RPCs, such as the name of the service.
+func Fields(fields map[string]any) Option {
+	return func(o *options) {
+		maps.Copy(o.fields, fields)
+	}
+}
+
+// Extract adds the fields returned by extractors for the incoming metadata
+// to every entry of the server RPCs, including the entries of their scoped
+// loggers, e.g. httplog.B3 or httplog.Header("x-tenant-id", "tenant").
+func Extract(extractors ...httplog.Extractor) Option {
+	return func(o *options) {
+		o.extractors = append(o.extractors, extractors...)
+	}
+}
+
+// newOptions applies opts on top of the default settings.
+func newOptions(opts []Option) options {
+	o := options{requestIDKey: DefaultRequestIDKey, fields: map[string]any{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return o
+}
+
+// UnaryServerInterceptor returns an interceptor that logs the unary RPCs of
+// a server and serves them with a context carrying a scope with the request
+// ID (see golog.IntoContext).
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, scope := o.serverScope(ctx)
+
+		resp, err := handler(ctx, req)
+		o.logServer(ctx, scope, info.FullMethod, start, err)
+
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns an interceptor that logs the streaming
+// RPCs of a server, when they end, and serves them with a stream whose
+// context carries a scope with the request ID (see golog.IntoContext).
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
+	o := newOptions(opts)
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, scope := o.serverScope(ss.Context())
+
+		err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+		o.logServer(ctx, scope, info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+// serverStream is a grpc.ServerStream with the context of the scoped
+// logger.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// serverScope returns ctx with the scope of a server RPC, and the scope.
+func (o options) serverScope(ctx context.Context) (context.Context, *golog.LogScope) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	get := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+
+		return ""
+	}
+
+	id := get(o.requestIDKey)
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+
+	fields := maps.Clone(o.fields)
+	for _, extractor := range o.extractors {
+		if extractor != nil {
+			maps.Copy(fields, extractor(func(key string) string {
+				if value := get(key); validRequestID(value) {
+					return value
+				}
+
+				return ""
+			}))
+		}
+	}
+	fields[FieldRequestID] = id
+
+	scope := golog.FromContext(ctx).WithFields(fields)
+
+	return golog.IntoContext(ctx, scope), scope
+}
+
+// logServer logs the entry of a server RPC.
+func (o options) logServer(ctx context.Context, scope *golog.LogScope, method string, start time.Time, err error) {
+	fields := map[string]any{
+		FieldMethod:  method,
+		FieldLatency: time.Since(start).String(),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		fields[FieldPeer] = p.Addr.String()
+	}
+
+	logRPC(scope.WithContext(ctx).WithFields(fields), ServerMessage, err)
+}
+
+// UnaryClientInterceptor returns an interceptor that logs the unary calls
+// of a client, and sends the request ID of the scope stored in the context
+// of the call, if any.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, callOpts ...grpc.CallOption) error {
+		start := time.Now()
+		ctx, scope := o.clientScope(ctx, method, cc)
+
+		err := invoker(ctx, method, req, reply, cc, callOpts...)
+		logRPC(scope.With(FieldLatency, time.Since(start).String()), ClientMessage, err)
+
+		return err
+	}
+}
+
+// StreamClientInterceptor returns an interceptor that logs the streaming
+// calls of a client, when the stream ends or fails, and sends the request
+// ID of the scope stored in the context of the call, if any.
+func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
+	o := newOptions(opts)
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+		start := time.Now()
+		ctx, scope := o.clientScope(ctx, method, cc)
+
+		cs, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			logRPC(scope.With(FieldLatency, time.Since(start).String()), ClientMessage, err)
+			return nil, err
+		}
+
+		return &clientStream{ClientStream: cs, scope: scope, start: start}, nil
+	}
+}
+
+// clientStream is a grpc.ClientStream that logs its end.
+type clientStream struct {
+	grpc.ClientStream
+
+	scope *golog.LogScope
+	start time.Time
+	once  sync.Once
+}
+
+// SendMsg implements grpc.ClientStream, logging the failures that end the
+// stream.
+func (s *clientStream) SendMsg(m any) error {
+	err := s.ClientStream.SendMsg(m)
+	if err != nil && !errors.Is(err, io.EOF) {
+		// io.EOF reports a stream ended by the server, whose status RecvMsg
+		// returns
+		s.end(err)
+	}
+
+	return err
+}
+
+// RecvMsg implements grpc.ClientStream, logging the end of the stream.
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if errors.Is(err, io.EOF) {
+		s.end(nil)
+	} else if err != nil {
+		s.end(err)
+	}
+
+	return err
+}
+
+// end logs the end of the stream, once.
+func (s *clientStream) end(err error) {
+	s.once.Do(func() {
+		logRPC(s.scope.With(FieldLatency, time.Since(s.start).String()), ClientMessage, err)
+	})
+}
+
+// clientScope returns ctx with the request ID of its scope in the outgoing
+// metadata, and the scope of the entry of a client call.
+func (o options) clientScope(ctx context.Context, method string, cc *grpc.ClientConn) (context.Context, *golog.LogScope) {
+	scope := golog.FromContext(ctx).WithFields(o.fields).WithFields(map[string]any{
+		FieldMethod: method,
+		FieldPeer:   cc.Target(),
+	})
+
+	if id, ok := scope.Field(FieldRequestID); ok {
+		if id, ok := id.(string); ok && id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, o.requestIDKey, id)
+		}
+	}
+
+	return ctx, scope
+}
+
+// logRPC logs the entry of an RPC with its status code, at error level for
+// the codes that report a failure of the server or of the network, and at
+// info level otherwise, since the other codes, such as NotFound, are part
+// of the normal operation of a service.
+func logRPC(scope *golog.LogScope, msg string, err error) {
+	code := status.Code(err)
+	scope = scope.With(FieldCode, code.String())
+
+	if err != nil {
+		scope = scope.WithError(err)
+	}
+
+	if serverFailure(code) {
+		_ = scope.Error(msg)
+		return
+	}
+
+	scope.Info(msg)
+}
+
+// serverFailure reports whether code reports a failure of the server or of
+// the network rather than of the request.
+func serverFailure(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.DeadlineExceeded, codes.Unimplemented, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// validRequestID reports whether id, received from a peer, is short and
+// printable, so that it cannot forge or bloat the entries.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for i := range len(id) {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID returns a random 16 character hex request ID.
+func newRequestID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
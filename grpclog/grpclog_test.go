@@ -0,0 +1,146 @@
+package grpclog
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/httplog"
+	"github.com/jkaveri/golog/logtest"
+	"github.com/jkaveri/golog/sinktest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// handled logs an entry with the scoped logger of the RPC.
+func handled(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	golog.FromContext(ctx).Info("handled")
+	return handler(ctx, req)
+}
+
+// newClient serves the health service through the server interceptors and
+// returns a client calling it through the client interceptors.
+func newClient(t *testing.T, opts ...Option) healthpb.HealthClient {
+	listener := bufconn.Listen(1 << 20)
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor(opts...), handled),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor(opts...)),
+	)
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(opts...)),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(opts...)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return healthpb.NewHealthClient(conn)
+}
+
+// byMessage returns the entries with message msg.
+func byMessage(recorder *logtest.Recorder, msg string) []golog.Entry {
+	var entries []golog.Entry
+	for _, entry := range recorder.Entries() {
+		if entry.Message == msg {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func TestUnaryInterceptors(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+	client := newClient(t, Fields(map[string]any{"service": "orders"}), Extract(httplog.Header("x-tenant-id", "tenant")))
+
+	ctx := golog.IntoContext(context.Background(), golog.With(FieldRequestID, "req-1"))
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-tenant-id", "acme")
+	_, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	require.NoError(t, err)
+
+	scoped := byMessage(recorder, "handled")
+	require.Len(t, scoped, 1)
+	assert.Equal(t, map[string]any{FieldRequestID: "req-1", "service": "orders", "tenant": "acme"}, scoped[0].Fields,
+		"the request ID is propagated to the scoped logger of the server")
+
+	server := byMessage(recorder, ServerMessage)
+	require.Len(t, server, 1)
+	assert.Equal(t, golog.LevelInfo, server[0].Level)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", server[0].Fields[FieldMethod])
+	assert.Equal(t, "OK", server[0].Fields[FieldCode])
+	assert.Equal(t, "req-1", server[0].Fields[FieldRequestID])
+	assert.Equal(t, "bufconn", server[0].Fields[FieldPeer])
+	assert.NotEmpty(t, server[0].Fields[FieldLatency])
+
+	calls := byMessage(recorder, ClientMessage)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", calls[0].Fields[FieldMethod])
+	assert.Equal(t, "OK", calls[0].Fields[FieldCode])
+	assert.Equal(t, "req-1", calls[0].Fields[FieldRequestID])
+	assert.Equal(t, "passthrough:///bufnet", calls[0].Fields[FieldPeer])
+}
+
+func TestUnaryInterceptors_Codes(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+	client := newClient(t)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "payments"})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.List(context.Background(), &healthpb.HealthListRequest{})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	server := byMessage(recorder, ServerMessage)
+	require.Len(t, server, 2)
+	assert.Equal(t, "NotFound", server[0].Fields[FieldCode])
+	assert.Equal(t, golog.LevelInfo, server[0].Level, "a request error is not a failure of the server")
+	assert.Contains(t, server[0].Fields["error"], "unknown service")
+	assert.Len(t, server[0].Fields[FieldRequestID], 16, "a request ID is generated")
+	assert.Equal(t, golog.LevelError, server[1].Level)
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+	client := newClient(t)
+
+	ctx, cancel := context.WithCancel(golog.IntoContext(context.Background(), golog.With(FieldRequestID, "req-2")))
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "orders"})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	cancel()
+	_, err = stream.Recv()
+	require.Equal(t, codes.Canceled, status.Code(err))
+
+	calls := byMessage(recorder, ClientMessage)
+	require.Len(t, calls, 1)
+	assert.Equal(t, "/grpc.health.v1.Health/Watch", calls[0].Fields[FieldMethod])
+	assert.Equal(t, "Canceled", calls[0].Fields[FieldCode])
+
+	require.Eventually(t, func() bool { return len(byMessage(recorder, ServerMessage)) == 1 }, time.Second, time.Millisecond)
+	server := byMessage(recorder, ServerMessage)[0]
+	assert.Equal(t, "/grpc.health.v1.Health/Watch", server.Fields[FieldMethod])
+	assert.Equal(t, "req-2", server.Fields[FieldRequestID])
+}
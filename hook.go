@@ -0,0 +1,96 @@
+package golog
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Hook receives the entries of a Logger once they are written, for side
+// effects that are not part of the entry, such as incrementing a metric,
+// paging on errors, or forwarding to an error tracker. Unlike an Enricher,
+// which runs before the level of the entry is final and may change its
+// fields, a hook sees the entry exactly as the writer received it:
+// enriched, redacted, and past the level filters.
+//
+// Fire is called synchronously by the goroutine that logged the entry, so
+// hand slow work, such as network calls, to another goroutine. It may be
+// called from several goroutines at once. The entry must not be modified,
+// nor retained after Fire returns; copy its fields to keep them.
+type Hook interface {
+	// Fire handles a written entry.
+	Fire(entry Entry)
+}
+
+// HookFunc is a function type that implements the Hook interface.
+type HookFunc func(entry Entry)
+
+// Fire implements the Hook interface for HookFunc.
+func (f HookFunc) Fire(entry Entry) {
+	f(entry)
+}
+
+// registeredHook is a hook with the levels it receives.
+type registeredHook struct {
+	hook Hook
+	// levels holds the levels of the entries fired, all levels when empty
+	levels []int
+}
+
+// AddHook adds a hook to the default Logger, fired with the entries at
+// levels, or at every level when none is given. See Logger.AddHook.
+//
+// Example:
+//
+//	golog.AddHook(golog.HookFunc(func(entry golog.Entry) {
+//	    errorsTotal.WithLabelValues(golog.LevelString(entry.Level)).Inc()
+//	}), golog.LevelError, golog.LevelPanic, golog.LevelFatal)
+func AddHook(hook Hook, levels ...int) {
+	std.AddHook(hook, levels...)
+}
+
+// AddHook adds a hook to the Logger, fired with the entries at levels, or at
+// every level when none is given. Hooks are fired after the entry is handed
+// to the writer, in the order they are added, so a hook always sees the
+// entries in the order they were written. Entries dropped before the writer,
+// e.g. by the minimum level or silence windows, are not fired. Entries the
+// writer drops itself, such as those sampled out by NewSampler or discarded
+// by a full NewAsyncWriter queue, are still fired.
+// A hook that panics is reported to os.Stderr and does not keep the hooks
+// after it from firing.
+//
+// It is safe to call concurrently with logging: each entry is fired to the
+// hooks added when it was logged.
+func (lg *Logger) AddHook(hook Hook, levels ...int) {
+	if hook == nil {
+		return
+	}
+
+	registered := registeredHook{hook: hook, levels: slices.Clone(levels)}
+	lg.update(func(cfg *loggerConfig) {
+		next := make([]registeredHook, 0, len(cfg.hooks)+1)
+		next = append(next, cfg.hooks...)
+		cfg.hooks = append(next, registered)
+	})
+}
+
+// fireHooks fires entry to the hooks of its level, in order.
+func fireHooks(hooks []registeredHook, entry Entry) {
+	for _, h := range hooks {
+		if len(h.levels) > 0 && !slices.Contains(h.levels, entry.Level) {
+			continue
+		}
+
+		fireHook(h.hook, entry)
+	}
+}
+
+// fireHook fires entry to hook, reporting a panic of the hook.
+func fireHook(hook Hook, entry Entry) {
+	defer func() {
+		if r := recover(); r != nil {
+			writerOptions{}.handleError(fmt.Errorf("golog: hook %T panicked: %v", hook, r))
+		}
+	}()
+
+	hook.Fire(entry)
+}
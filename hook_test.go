@@ -0,0 +1,129 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestLogger_AddHook(t *testing.T) {
+	t.Cleanup(DisableRedaction)
+	SetRedaction(RedactFields("password"))
+
+	w := &captureWriter{}
+	lg := New(
+		LoggerWriter(w),
+		LoggerEnrichers(EnricherFunc(func(_ context.Context, _, _ string, fields map[string]any) {
+			fields["service"] = "api"
+		})),
+	)
+
+	var fired []string
+	record := func(name string) Hook {
+		return HookFunc(func(entry Entry) {
+			// the writer has the entry before the hooks
+			if got := w.last().msg; got != entry.Message {
+				t.Fatalf("w.last().msg = %q, want %q", got, entry.Message)
+			}
+			fired = append(fired, name+":"+entry.Message)
+		})
+	}
+
+	lg.AddHook(record("all"))
+	lg.AddHook(HookFunc(func(Entry) { panic("broken hook") }))
+	lg.AddHook(record("errors"), LevelError)
+	lg.AddHook(nil)
+
+	var last Entry
+	lg.AddHook(HookFunc(func(entry Entry) { last = entry }))
+
+	lg.Debug("below the level")
+	lg.Info("started")
+	lg.With("password", "hunter2").Error("login failed")
+
+	want := []string{"all:started", "all:login failed", "errors:login failed"}
+	if !reflect.DeepEqual(fired, want) {
+		t.Errorf("fired = %v, want %v", fired, want)
+	}
+	if last.Level != LevelError {
+		t.Errorf("last.Level = %v, want %v", last.Level, LevelError)
+	}
+	if want := map[string]any{"password": "[REDACTED]", "service": "api"}; !reflect.DeepEqual(last.Fields, want) {
+		t.Errorf("hooks see the rendered entry: last.Fields = %v, want %v", last.Fields, want)
+	}
+	if last.Time.IsZero() {
+		t.Error("last.Time.IsZero() = true, want false")
+	}
+	if got := lg.Describe().Hooks; len(got) != 4 {
+		t.Errorf("len(lg.Describe().Hooks) = %d, want 4", len(got))
+	}
+}
+
+func TestLogger_AddHook_Sampler(t *testing.T) {
+	resetDrops(t)
+
+	w := &captureWriter{}
+	lg := New(LoggerWriter(NewSampler(w, SampleLevel(LevelInfo, 1, 0))))
+
+	fired := 0
+	lg.AddHook(HookFunc(func(Entry) { fired++ }))
+
+	for i := 0; i < 5; i++ {
+		lg.Info("polled")
+	}
+
+	written := 0
+	for _, entry := range w.entries {
+		if entry.msg == "polled" {
+			written++
+		}
+	}
+	if written != 1 {
+		t.Errorf("written = %d, want 1", written)
+	}
+	if fired != 5 {
+		t.Errorf("entries sampled out by the writer are fired: fired = %d, want 5", fired)
+	}
+}
+
+func TestLogger_AddHook_Err(t *testing.T) {
+	t.Cleanup(DisableRedaction)
+
+	lg := New(LoggerWriter(&captureWriter{}))
+
+	var last Entry
+	lg.AddHook(HookFunc(func(entry Entry) { last = entry }))
+
+	cause := StackErrors().New("dial tcp: refused")
+	lg.WithError(cause).With("order_id", 7).Error("charge failed")
+	if last.Err != cause {
+		t.Errorf("last.Err = %p, want %p", last.Err, cause)
+	}
+	if got := ErrorStack(last.Err); len(got) == 0 {
+		t.Error("ErrorStack(last.Err) is empty")
+	}
+
+	lg.Info("no error")
+	if last.Err != nil {
+		t.Errorf("last.Err: %v", last.Err)
+	}
+
+	SetRedaction(RedactPatterns(regexp.MustCompile(`token=\w+`)))
+	lg.WithError(fmt.Errorf("get /orders?token=s3cr3t: %w", cause)).Error("fetch failed")
+	if last.Err == nil {
+		t.Fatal("last.Err = nil, want an error")
+	}
+	if got := last.Err.Error(); got != "get /orders?[REDACTED]: dial tcp: refused" {
+		t.Errorf("last.Err.Error() = %q, want %q", got, "get /orders?[REDACTED]: dial tcp: refused")
+	}
+	if err := errors.Unwrap(last.Err); err != nil {
+		t.Errorf("the redacted error does not expose the original: errors.Unwrap(last.Err): %v", err)
+	}
+	want := ErrorStack(cause)
+	if got := ErrorStack(last.Err); !reflect.DeepEqual(got, want) {
+		t.Errorf("ErrorStack(last.Err) = %v, want %v", got, want)
+	}
+}
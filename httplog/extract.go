@@ -0,0 +1,136 @@
+package httplog
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/jkaveri/golog"
+)
+
+// Extractor returns the fields that a request adds to its entries, from its
+// headers, such as the IDs of an organization's propagation scheme. header
+// returns the first value of the named header, compared case-insensitively,
+// or "" when the request does not have it. Values longer than 128 bytes or
+// with control characters are dropped, since clients can set them.
+//
+// Extractors only see header values, so the same extractor can serve other
+// transports that carry string metadata, such as gRPC.
+type Extractor func(header func(name string) string) map[string]any
+
+// Extract adds the fields returned by extractors to every entry of the
+// requests, including the entries of the request-scoped loggers. Extractors
+// run in order, so a later one overrides the fields of an earlier one.
+//
+// Example:
+//
+//	handler := httplog.Middleware(mux, httplog.Extract(
+//	    httplog.B3(),
+//	    httplog.Header("X-Tenant-ID", "tenant"),
+//	))
+func Extract(extractors ...Extractor) Option {
+	return func(o *options) {
+		o.extractors = append(o.extractors, extractors...)
+	}
+}
+
+// Header returns an Extractor setting field to the value of the header
+// name, when the request has it.
+func Header(name, field string) Extractor {
+	return func(header func(string) string) map[string]any {
+		value := header(name)
+		if value == "" {
+			return nil
+		}
+
+		return map[string]any{field: value}
+	}
+}
+
+// B3 returns an Extractor of the Zipkin B3 headers, X-B3-TraceId,
+// X-B3-SpanId, and X-B3-Sampled, or the single b3 header, setting the trace
+// correlation fields golog.FieldTraceID, golog.FieldSpanID, and
+// golog.FieldTraceSampled.
+func B3() Extractor {
+	return func(header func(string) string) map[string]any {
+		traceID, spanID, sampled := header("X-B3-TraceId"), header("X-B3-SpanId"), header("X-B3-Sampled")
+
+		// b3: {TraceId}-{SpanId}[-{SamplingState}[-{ParentSpanId}]]
+		if single := header("b3"); traceID == "" && single != "" {
+			parts := strings.Split(single, "-")
+			if len(parts) >= 2 {
+				traceID, spanID = parts[0], parts[1]
+			}
+			if len(parts) >= 3 {
+				sampled = parts[2]
+			}
+		}
+
+		return traceFields(traceID, spanID, sampled)
+	}
+}
+
+// AmazonTraceID returns an Extractor of the X-Amzn-Trace-Id header of AWS
+// load balancers and X-Ray, setting golog.FieldTraceID to its root trace ID,
+// golog.FieldSpanID to its parent segment ID, and golog.FieldTraceSampled to
+// its sampling decision, e.g. for
+// "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1".
+func AmazonTraceID() Extractor {
+	return func(header func(string) string) map[string]any {
+		var traceID, spanID, sampled string
+		for _, part := range strings.Split(header("X-Amzn-Trace-Id"), ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch key {
+			case "Root":
+				traceID = value
+			case "Parent":
+				spanID = value
+			case "Sampled":
+				sampled = value
+			}
+		}
+
+		return traceFields(traceID, spanID, sampled)
+	}
+}
+
+// traceFields returns the trace correlation fields of a trace propagated in
+// headers, or nil without a trace ID.
+func traceFields(traceID, spanID, sampled string) map[string]any {
+	if traceID == "" {
+		return nil
+	}
+
+	fields := map[string]any{golog.FieldTraceID: traceID}
+	if spanID != "" {
+		fields[golog.FieldSpanID] = spanID
+	}
+
+	switch sampled {
+	case "1", "d", "true":
+		fields[golog.FieldTraceSampled] = true
+	case "0", "false":
+		fields[golog.FieldTraceSampled] = false
+	}
+
+	return fields
+}
+
+// extract returns the fields of the extractors for the headers of a request.
+func extract(extractors []Extractor, header func(string) string) map[string]any {
+	get := func(name string) string {
+		if value := header(name); validHeaderValue(value) {
+			return value
+		}
+
+		return ""
+	}
+
+	fields := map[string]any{}
+	for _, extractor := range extractors {
+		if extractor != nil {
+			maps.Copy(fields, extractor(get))
+		}
+	}
+
+	return fields
+}
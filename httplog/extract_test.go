@@ -0,0 +1,110 @@
+package httplog
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/sinktest"
+)
+
+func TestMiddleware_Extract(t *testing.T) {
+	tests := []struct {
+		name      string
+		extractor Extractor
+		headers   map[string]string
+		want      map[string]any
+	}{
+		{
+			name:      "header",
+			extractor: Header("X-Tenant-ID", "tenant"),
+			headers:   map[string]string{"x-tenant-id": "acme"},
+			want:      map[string]any{"tenant": "acme"},
+		},
+		{
+			name:      "header-missing",
+			extractor: Header("X-Tenant-ID", "tenant"),
+			want:      map[string]any{},
+		},
+		{
+			name:      "header-control-characters",
+			extractor: Header("X-Tenant-ID", "tenant"),
+			headers:   map[string]string{"X-Tenant-ID": "acme\x1b[2J"},
+			want:      map[string]any{},
+		},
+		{
+			name:      "b3-multi",
+			extractor: B3(),
+			headers: map[string]string{
+				"x-b3-traceid": "80f198ee56343ba864fe8b2a57d3eff7",
+				"x-b3-spanid":  "e457b5a2e4d86bd1",
+				"x-b3-sampled": "1",
+			},
+			want: map[string]any{
+				golog.FieldTraceID:      "80f198ee56343ba864fe8b2a57d3eff7",
+				golog.FieldSpanID:       "e457b5a2e4d86bd1",
+				golog.FieldTraceSampled: true,
+			},
+		},
+		{
+			name:      "b3-single",
+			extractor: B3(),
+			headers:   map[string]string{"b3": "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-0-05e3ac9a4f6e3b90"},
+			want: map[string]any{
+				golog.FieldTraceID:      "80f198ee56343ba864fe8b2a57d3eff7",
+				golog.FieldSpanID:       "e457b5a2e4d86bd1",
+				golog.FieldTraceSampled: false,
+			},
+		},
+		{
+			name:      "amazon",
+			extractor: AmazonTraceID(),
+			headers:   map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793;Parent=53995c3f42cd8ad8;Sampled=1"},
+			want: map[string]any{
+				golog.FieldTraceID:      "1-5759e988-bd862e3fe1be46a994272793",
+				golog.FieldSpanID:       "53995c3f42cd8ad8",
+				golog.FieldTraceSampled: true,
+			},
+		},
+		{
+			name:      "amazon-root-only",
+			extractor: AmazonTraceID(),
+			headers:   map[string]string{"X-Amzn-Trace-Id": "Root=1-5759e988-bd862e3fe1be46a994272793"},
+			want:      map[string]any{golog.FieldTraceID: "1-5759e988-bd862e3fe1be46a994272793"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			handler := Middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				golog.FromContext(r.Context()).Info("handled")
+			}), Extract(tt.extractor))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := recorder.Entries()
+			if len(entries) != 2 {
+				t.Fatalf("len(entries) = %d, want 2", len(entries))
+			}
+			for _, entry := range entries {
+				delete(entry.Fields, FieldRequestID)
+			}
+			if !reflect.DeepEqual(entries[0].Fields, tt.want) {
+				t.Errorf("the request-scoped logger has the fields: entries[0].Fields = %v, want %v", entries[0].Fields, tt.want)
+			}
+			for key, value := range tt.want {
+				if !reflect.DeepEqual(entries[1].Fields[key], value) {
+					t.Errorf("entries[1].Fields[key] = %v, want %v", entries[1].Fields[key], value)
+				}
+			}
+		})
+	}
+}
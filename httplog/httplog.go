@@ -0,0 +1,344 @@
+// Package httplog logs the requests served by a net/http server. Its
+// middleware logs one entry per request, with the method, path, status,
+// latency, response bytes, and remote address, and gives each request a
+// request-scoped logger carrying its request ID, which handlers get with
+// golog.FromContext.
+//
+// The request ID is taken from the X-Request-ID header of the request, when
+// a proxy or the client set it to a short printable value, or generated, as
+// a ULID by default (see RequestIDGenerator), and is echoed in the same
+// header of the response so that clients can quote it.
+// Extract adds the IDs of other propagation schemes, such as Zipkin B3
+// headers or a tenant header, to the fields of the request.
+//
+// Example:
+//
+//	mux := http.NewServeMux()
+//	mux.HandleFunc("POST /orders", func(w http.ResponseWriter, r *http.Request) {
+//	    golog.FromContext(r.Context()).With("order_id", id).Info("order created")
+//	    ...
+//	})
+//
+//	http.ListenAndServe(":8080", httplog.Middleware(mux))
+package httplog
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+// Field names used in request entries
+const (
+	// FieldRequestID is the key of the request ID
+	FieldRequestID = "request_id"
+	// FieldMethod is the key of the request method
+	FieldMethod = "method"
+	// FieldPath is the key of the URL path, without the query, which may
+	// carry secrets
+	FieldPath = "path"
+	// FieldStatus is the key of the response status code
+	FieldStatus = "status"
+	// FieldLatency is the key of the time taken to serve the request
+	FieldLatency = "latency"
+	// FieldBytes is the key of the number of bytes of the response body
+	FieldBytes = "bytes"
+	// FieldRemoteAddr is the key of the address of the client
+	FieldRemoteAddr = "remote_addr"
+	// FieldPanic is the key of the value a handler panicked with
+	FieldPanic = "panic"
+	// FieldStack is the key of the stack trace of a handler panic
+	FieldStack = "stack"
+)
+
+// DefaultRequestIDHeader is the header carrying the request ID by default.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxHeaderValueLen is the length of the longest header value logged, such
+// as a request ID.
+const maxHeaderValueLen = 128
+
+// Option configures Middleware.
+type Option func(*options)
+
+// options holds the settings of Middleware.
+type options struct {
+	// header is the header carrying the request ID
+	header string
+	// newID generates the IDs of the requests without one
+	newID func() string
+	// fields are added to every entry of the requests
+	fields map[string]any
+	// extractors return the fields of the headers of a request
+	extractors []Extractor
+	// routes configure the entries of the requests by path
+	routes []RouteRule
+	// repanic makes Middleware panic again after logging a handler panic
+	repanic bool
+}
+
+// RequestIDHeader sets the header carrying the request ID, in the requests
+// and the responses. The default is X-Request-ID.
+func RequestIDHeader(name string) Option {
+	return func(o *options) {
+		o.header = name
+	}
+}
+
+// RequestIDGenerator sets the function generating the ID of the requests
+// without a valid one, such as a UUID generator. The default is NewULID.
+func RequestIDGenerator(generate func() string) Option {
+	return func(o *options) {
+		o.newID = generate
+	}
+}
+
+// Fields adds fields to every entry of the requests, including the entries
+// of the request-scoped loggers, such as the name of the server.
+func Fields(fields map[string]any) Option {
+	return func(o *options) {
+		maps.Copy(o.fields, fields)
+	}
+}
+
+// Repanic makes Middleware panic again with the value a handler panicked
+// with, once the panic is logged, for an outer middleware that recovers
+// panics itself. The 500 response is then left to that middleware.
+func Repanic() Option {
+	return func(o *options) {
+		o.repanic = true
+	}
+}
+
+// Middleware returns a handler that serves requests with next and logs
+// "request completed" when next returns, at info level, or at error level
+// when the status is 500 or above. The context of the request carries a
+// scope with the request ID (see golog.IntoContext), so golog.FromContext
+// returns a logger for the request.
+//
+// A panic of next is recovered and logged in the "request completed" entry,
+// at error level, with the panic and stack fields, and the client gets a 500
+// response unless the handler already wrote its response header (see
+// Repanic). A panic with http.ErrAbortHandler, which aborts the response on
+// purpose, is not recovered.
+//
+// The path is logged without the query string, and no header or body is
+// logged, since they may hold credentials or personal data, unless a route
+// rule logs the body (see Routes).
+func Middleware(next http.Handler, opts ...Option) http.Handler {
+	o := options{header: DefaultRequestIDHeader, newID: NewULID, fields: map[string]any{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	routes := newRoutes(o.routes)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		id := r.Header.Get(o.header)
+		if !validHeaderValue(id) {
+			id = o.newID()
+		}
+		w.Header().Set(o.header, id)
+
+		fields := maps.Clone(o.fields)
+		maps.Copy(fields, extract(o.extractors, r.Header.Get))
+		fields[FieldRequestID] = id
+		scope := golog.WithFields(fields)
+
+		rt := matchRoute(routes, r.URL.Path)
+		req := r.WithContext(golog.IntoContext(r.Context(), scope))
+
+		var body *bodyRecorder
+		if rt != nil && rt.BodyBytes > 0 && req.Body != nil {
+			body = &bodyRecorder{ReadCloser: req.Body, limit: rt.BodyBytes}
+			req.Body = body
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		recovered, stack := serve(next, recorder, req)
+		if recovered != nil && !o.repanic && !recorder.wroteHeader {
+			status := http.StatusInternalServerError
+			http.Error(recorder, http.StatusText(status), status)
+		}
+
+		failed := recovered != nil || recorder.status >= http.StatusInternalServerError
+		if !failed && rt != nil && !rt.logged() {
+			return
+		}
+
+		scope = scope.WithContext(r.Context()).WithFields(map[string]any{
+			FieldMethod:     r.Method,
+			FieldPath:       r.URL.Path,
+			FieldStatus:     recorder.status,
+			FieldLatency:    time.Since(start).String(),
+			FieldBytes:      recorder.bytes,
+			FieldRemoteAddr: r.RemoteAddr,
+		})
+
+		if body != nil {
+			scope = scope.With(FieldRequestBody, body.String())
+		}
+
+		if recovered != nil {
+			_ = scope.WithFields(map[string]any{
+				FieldPanic: fmt.Sprint(recovered),
+				FieldStack: string(stack),
+			}).Error("request completed")
+
+			if o.repanic {
+				panic(recovered)
+			}
+
+			return
+		}
+
+		if recorder.status >= http.StatusInternalServerError {
+			_ = scope.Error("request completed")
+			return
+		}
+
+		if rt != nil {
+			scope.Log(rt.level, "request completed")
+			return
+		}
+
+		scope.Info("request completed")
+	})
+}
+
+// serve serves r with next, and returns the value next panicked with and
+// its stack trace. A panic with http.ErrAbortHandler is not recovered.
+func serve(next http.Handler, w http.ResponseWriter, r *http.Request) (v any, stack []byte) {
+	defer func() {
+		if v = recover(); v == nil {
+			return
+		}
+
+		if v == http.ErrAbortHandler {
+			panic(v)
+		}
+
+		stack = debug.Stack()
+	}()
+
+	next.ServeHTTP(w, r)
+
+	return nil, nil
+}
+
+// responseRecorder is an http.ResponseWriter recording the status and the
+// size of the response.
+type responseRecorder struct {
+	http.ResponseWriter
+
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter, recording the status.
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		// informational responses precede the final one
+		r.wroteHeader = status >= http.StatusOK
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter, counting the bytes written.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+
+	return n, err
+}
+
+// Flush implements http.Flusher, for streaming handlers.
+func (r *responseRecorder) Flush() {
+	r.wroteHeader = true
+
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker, for WebSocket upgrades.
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("httplog: the response writer does not support hijacking")
+	}
+
+	r.status = http.StatusSwitchingProtocols
+	r.wroteHeader = true
+
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped http.ResponseWriter, for http.ResponseController.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// validHeaderValue reports whether value, received from a client, is short
+// and printable, so that it cannot forge or bloat the entries.
+func validHeaderValue(value string) bool {
+	if value == "" || len(value) > maxHeaderValueLen {
+		return false
+	}
+
+	for i := range len(value) {
+		if value[i] < ' ' || value[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// crockford is the Crockford base32 alphabet of ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewULID returns a new ULID, a 26 character ID made of the current time in
+// milliseconds and 80 random bits, in Crockford base32, e.g.
+// "01HV6Z8Q6M3W5X0K2N7B9C4D1E". ULIDs sort by the time they were generated,
+// so the request IDs of the entries sort with them.
+func NewULID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[6:])
+
+	ms := uint64(time.Now().UnixMilli())
+	for i := 0; i < 6; i++ {
+		b[i] = byte(ms >> (40 - 8*i))
+	}
+
+	// encode the 128 bits 5 at a time from the end, the first character
+	// holding the top 3 bits
+	hi, lo := binary.BigEndian.Uint64(b[:8]), binary.BigEndian.Uint64(b[8:])
+
+	var id [26]byte
+	for i := len(id) - 1; i >= 0; i-- {
+		id[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+
+	return string(id[:])
+}
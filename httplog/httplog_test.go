@@ -0,0 +1,418 @@
+package httplog
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/sinktest"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		golog.FromContext(r.Context()).With("order_id", 42).Info("order created")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("created"))
+	}), Fields(map[string]any{"server": "api"}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?token=secret", nil)
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	entries := recorder.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	id := resp.Header().Get(DefaultRequestIDHeader)
+	if len(id) != 26 {
+		t.Errorf("a ULID is generated: len(id) = %d, want 26", len(id))
+	}
+
+	handled := entries[0]
+	if handled.Message != "order created" {
+		t.Errorf("handled.Message = %q, want %q", handled.Message, "order created")
+	}
+	want := map[string]any{FieldRequestID: id, "server": "api", "order_id": 42}
+	if !reflect.DeepEqual(handled.Fields, want) {
+		t.Errorf("handled.Fields = %v, want %v", handled.Fields, want)
+	}
+
+	completed := entries[1]
+	if completed.Level != golog.LevelInfo {
+		t.Errorf("completed.Level = %v, want %v", completed.Level, golog.LevelInfo)
+	}
+	if completed.Message != "request completed" {
+		t.Errorf("completed.Message = %q, want %q", completed.Message, "request completed")
+	}
+	if completed.Fields[FieldRequestID] != id {
+		t.Errorf("completed.Fields[FieldRequestID] = %q, want %q", completed.Fields[FieldRequestID], id)
+	}
+	if completed.Fields[FieldMethod] != http.MethodPost {
+		t.Errorf("completed.Fields[FieldMethod] = %q, want %q", completed.Fields[FieldMethod], http.MethodPost)
+	}
+	if completed.Fields[FieldPath] != "/orders" {
+		t.Errorf("the query is not logged: completed.Fields[FieldPath] = %q, want %q", completed.Fields[FieldPath], "/orders")
+	}
+	if completed.Fields[FieldStatus] != http.StatusCreated {
+		t.Errorf("completed.Fields[FieldStatus] = %v, want %v", completed.Fields[FieldStatus], http.StatusCreated)
+	}
+	if completed.Fields[FieldBytes] != int64(7) {
+		t.Errorf("completed.Fields[FieldBytes] = %v, want %v", completed.Fields[FieldBytes], int64(7))
+	}
+	if completed.Fields[FieldRemoteAddr] != req.RemoteAddr {
+		t.Errorf("completed.Fields[FieldRemoteAddr] = %q, want %q", completed.Fields[FieldRemoteAddr], req.RemoteAddr)
+	}
+	if got, _ := completed.Fields[FieldLatency].(string); got == "" {
+		t.Error("completed.Fields[FieldLatency] is empty")
+	}
+}
+
+func TestMiddleware_Status(t *testing.T) {
+	tests := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantCode  int
+		wantLevel int
+	}{
+		{
+			name:      "implicit-ok",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { w.Write([]byte("ok")) },
+			wantCode:  http.StatusOK,
+			wantLevel: golog.LevelInfo,
+		},
+		{
+			name:      "client-error",
+			handler:   func(w http.ResponseWriter, _ *http.Request) { http.NotFound(w, nil) },
+			wantCode:  http.StatusNotFound,
+			wantLevel: golog.LevelInfo,
+		},
+		{
+			name: "server-error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.WriteHeader(http.StatusOK)
+			},
+			wantCode:  http.StatusBadGateway,
+			wantLevel: golog.LevelError,
+		},
+		{
+			name: "informational-first",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusAccepted)
+			},
+			wantCode:  http.StatusAccepted,
+			wantLevel: golog.LevelInfo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+
+			Middleware(tt.handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			entries := recorder.Entries()
+			if len(entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1", len(entries))
+			}
+			if entries[0].Fields[FieldStatus] != tt.wantCode {
+				t.Errorf("entries[0].Fields[FieldStatus] = %v, want %v", entries[0].Fields[FieldStatus], tt.wantCode)
+			}
+			if entries[0].Level != tt.wantLevel {
+				t.Errorf("entries[0].Level = %v, want %v", entries[0].Level, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestMiddleware_RequestIDHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   string
+	}{
+		{name: "propagated", header: "abc-123", want: "abc-123"},
+		{name: "control-characters", header: "abc\n123"},
+		{name: "too-long", header: strings.Repeat("a", maxHeaderValueLen+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header["Trace-Id"] = []string{tt.header}
+			resp := httptest.NewRecorder()
+			Middleware(http.NotFoundHandler(), RequestIDHeader("Trace-Id")).ServeHTTP(resp, req)
+
+			id := recorder.Entries()[0].Fields[FieldRequestID]
+			if tt.want != "" {
+				if id != tt.want {
+					t.Errorf("id = %q, want %q", id, tt.want)
+				}
+			} else {
+				if got, _ := id.(string); len(got) != 26 {
+					t.Errorf("an invalid ID is replaced: id = %q, want 26 characters", got)
+				}
+			}
+			if got := resp.Header().Get("Trace-Id"); got != id {
+				t.Errorf(`resp.Header().Get("Trace-Id") = %q, want %q`, got, id)
+			}
+		})
+	}
+}
+
+func TestMiddleware_RequestIDGenerator(t *testing.T) {
+	recorder := sinktest.UseRecorder(t)
+
+	resp := httptest.NewRecorder()
+	Middleware(http.NotFoundHandler(), RequestIDGenerator(func() string { return "req-1" })).
+		ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := recorder.Entries()[0].Fields[FieldRequestID]; got != "req-1" {
+		t.Errorf("recorder.Entries()[0].Fields[FieldRequestID] = %q, want %q", got, "req-1")
+	}
+	if got := resp.Header().Get(DefaultRequestIDHeader); got != "req-1" {
+		t.Errorf("resp.Header().Get(DefaultRequestIDHeader) = %q, want %q", got, "req-1")
+	}
+}
+
+func TestNewULID(t *testing.T) {
+	first := NewULID()
+	time.Sleep(2 * time.Millisecond)
+	second := NewULID()
+
+	for _, id := range []string{first, second} {
+		if len(id) != 26 {
+			t.Fatalf("len(id) = %d, want 26", len(id))
+		}
+		for _, c := range id {
+			if !strings.ContainsRune(crockford, c) {
+				t.Errorf("%q is in the Crockford alphabet", c)
+			}
+		}
+	}
+
+	if first >= second {
+		t.Errorf("ULIDs sort by time: first = %v, want less than %v", first, second)
+	}
+	want := NewULID()
+	if got := NewULID(); got == want {
+		t.Errorf("NewULID() = %q, want a different value", got)
+	}
+
+	var ms int64
+	for _, c := range first[:10] {
+		ms = ms<<5 | int64(strings.IndexRune(crockford, c))
+	}
+	if d := time.Since(time.UnixMilli(ms)).Abs(); d > time.Second {
+		t.Errorf("the first 10 characters hold the time: off by %v", d)
+	}
+}
+
+func TestMiddleware_Routes(t *testing.T) {
+	rules := Routes(
+		RouteRule{Pattern: "/healthz", Skip: true},
+		RouteRule{Pattern: "/metrics", SampleRate: 1e-12},
+		RouteRule{Pattern: "/debug/*", Level: "debug", BodyBytes: 8},
+		RouteRule{Pattern: "/debug/quiet", Skip: true},
+	)
+
+	tests := []struct {
+		name      string
+		path      string
+		status    int
+		wantLevel int
+		wantBody  string
+		skipped   bool
+	}{
+		{name: "unmatched", path: "/orders", status: http.StatusOK, wantLevel: golog.LevelInfo},
+		{name: "skipped", path: "/healthz", status: http.StatusOK, skipped: true},
+		{name: "skipped-prefix-only", path: "/healthz/db", status: http.StatusOK, wantLevel: golog.LevelInfo},
+		{name: "skipped-server-error", path: "/healthz", status: http.StatusServiceUnavailable, wantLevel: golog.LevelError},
+		{name: "sampled-out", path: "/metrics", status: http.StatusOK, skipped: true},
+		{name: "prefix", path: "/debug/vars", status: http.StatusOK, wantLevel: golog.LevelDebug, wantBody: "debug=tr"},
+		{name: "prefix-root", path: "/debug", status: http.StatusOK, wantLevel: golog.LevelDebug, wantBody: "debug=tr"},
+		{name: "first-rule-wins", path: "/debug/quiet", status: http.StatusOK, wantLevel: golog.LevelDebug, wantBody: "debug=tr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+			golog.SetLevel(golog.LevelTrace)
+
+			handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(body) != "debug=true&verbose=1" {
+					t.Errorf("the handler reads the whole body: string(body) = %q, want %q", string(body), "debug=true&verbose=1")
+				}
+				w.WriteHeader(tt.status)
+			}), rules)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader("debug=true&verbose=1"))
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			entries := recorder.Entries()
+			if tt.skipped {
+				if len(entries) != 0 {
+					t.Errorf("entries = %v, want empty", entries)
+				}
+				return
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1", len(entries))
+			}
+			if entries[0].Level != tt.wantLevel {
+				t.Errorf("entries[0].Level = %v, want %v", entries[0].Level, tt.wantLevel)
+			}
+			if tt.wantBody != "" {
+				if entries[0].Fields[FieldRequestBody] != tt.wantBody {
+					t.Errorf("entries[0].Fields[FieldRequestBody] = %q, want %q", entries[0].Fields[FieldRequestBody], tt.wantBody)
+				}
+			} else {
+				if _, ok := entries[0].Fields[FieldRequestBody]; ok {
+					t.Errorf("entries[0].Fields has key %q, want none", FieldRequestBody)
+				}
+			}
+		})
+	}
+}
+
+// recovered calls f and returns the value it panics with, or nil.
+func recovered(f func()) (r any) {
+	defer func() { r = recover() }()
+	f()
+
+	return nil
+}
+
+func TestMiddleware_RoutesInvalidLevel(t *testing.T) {
+	r := recovered(func() {
+		Middleware(http.NotFoundHandler(), Routes(RouteRule{Pattern: "/", Level: "fatal"}))
+	})
+	if want := `httplog: invalid level "fatal" of route "/"`; r != want {
+		t.Errorf("recovered = %v, want %q", r, want)
+	}
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		opts       []Option
+		wantPanic  any
+		wantCode   int
+		wantStatus int
+		notLogged  bool
+	}{
+		{
+			name:       "recovered",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("nil map") },
+			wantCode:   http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name: "after-header",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusAccepted)
+				panic("nil map")
+			},
+			wantCode:   http.StatusAccepted,
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:       "repanic",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("nil map") },
+			opts:       []Option{Repanic()},
+			wantPanic:  "nil map",
+			wantCode:   http.StatusOK,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:      "abort-handler",
+			handler:   func(http.ResponseWriter, *http.Request) { panic(http.ErrAbortHandler) },
+			wantPanic: http.ErrAbortHandler,
+			wantCode:  http.StatusOK,
+			notLogged: true,
+		},
+		{
+			name:       "skipped-route",
+			handler:    func(http.ResponseWriter, *http.Request) { panic("nil map") },
+			opts:       []Option{Routes(RouteRule{Pattern: "/orders", Skip: true})},
+			wantCode:   http.StatusInternalServerError,
+			wantStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+
+			resp := httptest.NewRecorder()
+			serve := func() {
+				Middleware(tt.handler, tt.opts...).ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/orders", nil))
+			}
+
+			if r := recovered(serve); r != tt.wantPanic {
+				t.Errorf("recovered = %v, want %v", r, tt.wantPanic)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("resp.Code = %v, want %v", resp.Code, tt.wantCode)
+			}
+
+			entries := recorder.Entries()
+			if tt.notLogged {
+				if len(entries) != 0 {
+					t.Errorf("entries = %v, want empty", entries)
+				}
+				return
+			}
+
+			if len(entries) != 1 {
+				t.Fatalf("len(entries) = %d, want 1", len(entries))
+			}
+			if entries[0].Level != golog.LevelError {
+				t.Errorf("entries[0].Level = %v, want %v", entries[0].Level, golog.LevelError)
+			}
+			if entries[0].Message != "request completed" {
+				t.Errorf("entries[0].Message = %q, want %q", entries[0].Message, "request completed")
+			}
+			if entries[0].Fields[FieldStatus] != tt.wantStatus {
+				t.Errorf("entries[0].Fields[FieldStatus] = %v, want %v", entries[0].Fields[FieldStatus], tt.wantStatus)
+			}
+			if entries[0].Fields[FieldPanic] != "nil map" {
+				t.Errorf("entries[0].Fields[FieldPanic] = %q, want %q", entries[0].Fields[FieldPanic], "nil map")
+			}
+			if got, _ := entries[0].Fields[FieldStack].(string); !strings.Contains(got, "httplog_test.go") {
+				t.Errorf("entries[0].Fields[FieldStack] = %q, want it to contain %q", got, "httplog_test.go")
+			}
+			want := resp.Header().Get(DefaultRequestIDHeader)
+			if entries[0].Fields[FieldRequestID] != want {
+				t.Errorf("entries[0].Fields[FieldRequestID] = %q, want %q", entries[0].Fields[FieldRequestID], want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Flusher(t *testing.T) {
+	sinktest.UseRecorder(t)
+
+	Middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Fatalf("http.NewResponseController(w).Flush(): %v", err)
+		}
+	})).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/events", nil))
+}
@@ -0,0 +1,135 @@
+package httplog
+
+import (
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/jkaveri/golog"
+)
+
+// FieldRequestBody is the key of the request body logged for the routes
+// with RouteRule.BodyBytes set.
+const FieldRequestBody = "request_body"
+
+// RouteRule configures the "request completed" entries of the requests
+// whose path matches its pattern.
+type RouteRule struct {
+	// Pattern matches the URL path of a request: an exact path, such as
+	// "/healthz", or, ending with "/*", every path under a prefix, such as
+	// "/debug/*"
+	Pattern string
+	// Skip drops the entries of matching requests
+	Skip bool
+	// SampleRate is the fraction of matching requests that are logged, such
+	// as 0.01 for 1%. Zero logs them all.
+	SampleRate float64
+	// Level is the level of the entries of matching requests, as accepted by
+	// golog.ParseLevel, from "trace" to "error". Empty means "info".
+	Level string
+	// BodyBytes is the number of bytes of the request body logged, as read
+	// by the handler, in the request_body field. Zero logs no body.
+	BodyBytes int
+}
+
+// Routes sets rules for the requests whose path matches their pattern, such
+// as skipping health checks or sampling metrics scrapes. The first matching
+// rule applies. Server errors, with a status of 500 or above, are always
+// logged at error level, whatever the rule.
+//
+// Only log request bodies for debugging routes, with a small BodyBytes,
+// since bodies may hold credentials or personal data.
+//
+// Middleware panics if the level of a rule is invalid.
+//
+// Example:
+//
+//	handler := httplog.Middleware(mux, httplog.Routes(
+//	    httplog.RouteRule{Pattern: "/healthz", Skip: true},
+//	    httplog.RouteRule{Pattern: "/metrics", SampleRate: 0.01},
+//	    httplog.RouteRule{Pattern: "/debug/*", Level: "debug", BodyBytes: 512},
+//	))
+func Routes(rules ...RouteRule) Option {
+	return func(o *options) {
+		o.routes = append(o.routes, rules...)
+	}
+}
+
+// route is a RouteRule with its level parsed.
+type route struct {
+	RouteRule
+	level int
+}
+
+// newRoutes parses the levels of rules.
+func newRoutes(rules []RouteRule) []route {
+	routes := make([]route, len(rules))
+	for i, rule := range rules {
+		level := golog.LevelInfo
+		if rule.Level != "" {
+			level = golog.ParseLevel(rule.Level)
+		}
+
+		if level < golog.LevelTrace || level > golog.LevelError {
+			panic(fmt.Sprintf("httplog: invalid level %q of route %q", rule.Level, rule.Pattern))
+		}
+
+		routes[i] = route{RouteRule: rule, level: level}
+	}
+
+	return routes
+}
+
+// matchRoute returns the first route matching path, or nil.
+func matchRoute(routes []route, path string) *route {
+	for i := range routes {
+		pattern := routes[i].Pattern
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasSuffix(prefix, "/") {
+			if strings.HasPrefix(path, prefix) || path == strings.TrimSuffix(prefix, "/") {
+				return &routes[i]
+			}
+
+			continue
+		}
+
+		if path == pattern {
+			return &routes[i]
+		}
+	}
+
+	return nil
+}
+
+// logged reports whether a request of the route with a status below 500 is
+// logged.
+func (r *route) logged() bool {
+	if r.Skip {
+		return false
+	}
+
+	return r.SampleRate <= 0 || rand.Float64() < r.SampleRate
+}
+
+// bodyRecorder is a request body keeping the first bytes read from it.
+type bodyRecorder struct {
+	io.ReadCloser
+
+	limit int
+	body  []byte
+}
+
+// Read implements io.Reader, keeping the bytes read up to the limit.
+func (b *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if keep := min(n, b.limit-len(b.body)); keep > 0 {
+		b.body = append(b.body, p[:keep]...)
+	}
+
+	return n, err
+}
+
+// String returns the bytes kept as valid UTF-8.
+func (b *bodyRecorder) String() string {
+	return strings.ToValidUTF8(string(b.body), "\uFFFD")
+}
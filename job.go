@@ -0,0 +1,94 @@
+package golog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Field names used by RunJob
+const (
+	// FieldJob is the key of the job name
+	FieldJob = "job"
+	// FieldRunID is the key of the generated run ID
+	FieldRunID = "run_id"
+)
+
+// Job outcomes reported in the "outcome" field of RunJob's summary entry
+const (
+	// OutcomeSuccess means the job returned nil
+	OutcomeSuccess = "success"
+	// OutcomeFailure means the job returned an error
+	OutcomeFailure = "failure"
+	// OutcomePanic means the job panicked
+	OutcomePanic = "panic"
+)
+
+// RunJob runs fn as one run of the scheduled job name and logs it in a
+// standard way, so every cron job is observable the same way:
+//
+//   - "job started" (info) when the run begins
+//   - a single summary entry when it ends, with the "duration" and
+//     "outcome" (OutcomeSuccess, OutcomeFailure, or OutcomePanic) fields:
+//     "job completed" (info) on success, "job failed" (error) otherwise,
+//     with the "error" field, plus "stack" for a panic
+//
+// Every entry carries the job name and a generated run ID. fn receives a
+// context from which FromContext returns a scope with the same fields, for
+// its intermediate entries.
+//
+// A panic in fn is recovered and returned as an error, so a scheduler keeps
+// running other jobs. RunJob returns the error returned by fn.
+//
+// Example:
+//
+//	err := golog.RunJob(ctx, "cleanup-sessions", func(ctx context.Context) error {
+//	    n, err := store.DeleteExpiredSessions(ctx)
+//	    golog.FromContext(ctx).With("deleted", n).Info("expired sessions deleted")
+//	    return err
+//	})
+func RunJob(ctx context.Context, name string, fn func(ctx context.Context) error) (err error) {
+	ctx = IntoContext(ctx, FromContext(ctx).WithFields(map[string]any{
+		FieldJob:   name,
+		FieldRunID: newRunID(),
+	}))
+
+	FromContext(ctx).Info("job started")
+	start := time.Now()
+
+	defer func() {
+		scope := FromContext(ctx).With("duration", time.Since(start).String())
+
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %s panicked: %v", name, r)
+			_ = scope.WithFields(map[string]any{
+				"outcome": OutcomePanic,
+				"error":   fmt.Sprint(r),
+				"stack":   string(debug.Stack()),
+			}).Error("job failed")
+
+			return
+		}
+
+		if err != nil {
+			_ = scope.WithError(err).With("outcome", OutcomeFailure).Error("job failed")
+
+			return
+		}
+
+		scope.With("outcome", OutcomeSuccess).Info("job completed")
+	}()
+
+	return fn(ctx)
+}
+
+// newRunID returns a random 16 character hex run ID.
+func newRunID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
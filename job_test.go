@@ -0,0 +1,185 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRunJob(t *testing.T) {
+	errCleanup := errors.New("database unavailable")
+
+	tests := []struct {
+		name    string
+		fn      func(ctx context.Context) error
+		err     string
+		level   int
+		message string
+		outcome string
+	}{
+		{
+			name: "success",
+			fn: func(ctx context.Context) error {
+				FromContext(ctx).With("deleted", 3).Info("expired sessions deleted")
+				return nil
+			},
+			level:   LevelInfo,
+			message: "job completed",
+			outcome: OutcomeSuccess,
+		},
+		{
+			name: "failure",
+			fn: func(ctx context.Context) error {
+				FromContext(ctx).Info("expired sessions deleted")
+				return errCleanup
+			},
+			err:     "database unavailable",
+			level:   LevelError,
+			message: "job failed",
+			outcome: OutcomeFailure,
+		},
+		{
+			name: "panic",
+			fn: func(ctx context.Context) error {
+				FromContext(ctx).Info("expired sessions deleted")
+				panic("nil session store")
+			},
+			err:     "job cleanup-sessions panicked: nil session store",
+			level:   LevelError,
+			message: "job failed",
+			outcome: OutcomePanic,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			useWriter(t, w)
+
+			err := RunJob(context.Background(), "cleanup-sessions", tt.fn)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("err = %v, want %q", err, tt.err)
+				}
+			}
+
+			if len(w.entries) != 3 {
+				t.Fatalf("len(w.entries) = %d, want 3", len(w.entries))
+			}
+			if w.entries[0].msg != "job started" {
+				t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "job started")
+			}
+			if w.entries[1].msg != "expired sessions deleted" {
+				t.Errorf("w.entries[1].msg = %q, want %q", w.entries[1].msg, "expired sessions deleted")
+			}
+
+			runID := w.entries[0].fields[FieldRunID]
+			if id, _ := runID.(string); len(id) != 16 {
+				t.Errorf("runID = %q, want 16 characters", id)
+			}
+			for _, entry := range w.entries {
+				if entry.fields[FieldJob] != "cleanup-sessions" {
+					t.Errorf("entry.fields[FieldJob] = %q, want %q", entry.fields[FieldJob], "cleanup-sessions")
+				}
+				if !reflect.DeepEqual(entry.fields[FieldRunID], runID) {
+					t.Errorf("entry.fields[FieldRunID] = %v, want %v", entry.fields[FieldRunID], runID)
+				}
+			}
+
+			summary := w.last()
+			if summary.level != tt.level {
+				t.Errorf("summary.level = %v, want %v", summary.level, tt.level)
+			}
+			if summary.msg != tt.message {
+				t.Errorf("summary.msg = %q, want %q", summary.msg, tt.message)
+			}
+			if summary.fields["outcome"] != tt.outcome {
+				t.Errorf(`summary.fields["outcome"] = %q, want %q`, summary.fields["outcome"], tt.outcome)
+			}
+			if got, _ := summary.fields["duration"].(string); got == "" {
+				t.Error(`summary.fields["duration"] is empty`)
+			}
+			if tt.outcome == OutcomePanic {
+				if got, _ := summary.fields["stack"].(string); !strings.Contains(got, "TestRunJob") {
+					t.Errorf(`summary.fields["stack"] = %q, want it to contain %q`, got, "TestRunJob")
+				}
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	scope := With("request_id", "abc")
+	ctx := IntoContext(context.Background(), scope)
+	scope.With("later", true)
+
+	FromContext(ctx).With("step", 1).Info("first")
+	FromContext(ctx).Info("second")
+	FromContext(context.Background()).Info("no fields")
+
+	if len(w.entries) != 3 {
+		t.Fatalf("len(w.entries) = %d, want 3", len(w.entries))
+	}
+	want := map[string]any{"request_id": "abc", "step": 1}
+	if !reflect.DeepEqual(w.entries[0].fields, want) {
+		t.Errorf("w.entries[0].fields = %v, want %v", w.entries[0].fields, want)
+	}
+	if want := map[string]any{"request_id": "abc"}; !reflect.DeepEqual(w.entries[1].fields, want) {
+		t.Errorf("scopes from the context are independent: w.entries[1].fields = %v, want %v", w.entries[1].fields, want)
+	}
+	if len(w.entries[2].fields) != 0 {
+		t.Errorf("w.entries[2].fields = %v, want empty", w.entries[2].fields)
+	}
+}
+
+func TestIntoContext_Inherited(t *testing.T) {
+	w := &captureWriter{}
+	lg := New(LoggerWriter(w))
+
+	var last Entry
+	lg.AddHook(HookFunc(func(entry Entry) { last = entry }))
+
+	cause := errors.New("card declined")
+	ctx := IntoContext(context.Background(), lg.WithError(cause).With("request_id", "abc"))
+	ctx = IntoContext(ctx, FromContext(ctx).With("order_id", 7))
+
+	err := RunJob(ctx, "charge", func(ctx context.Context) error {
+		_ = FromContext(ctx).Error("charge failed")
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(w.entries) != 3 {
+		t.Fatalf("the entries go to the Logger of the stored scope: len(w.entries) = %d, want 3", len(w.entries))
+	}
+	failed := w.entries[1]
+	if failed.msg != "charge failed" {
+		t.Errorf("failed.msg = %q, want %q", failed.msg, "charge failed")
+	}
+	if failed.fields["request_id"] != "abc" {
+		t.Errorf(`failed.fields["request_id"] = %q, want %q`, failed.fields["request_id"], "abc")
+	}
+	if failed.fields["order_id"] != 7 {
+		t.Errorf(`failed.fields["order_id"] = %v, want 7`, failed.fields["order_id"])
+	}
+	if failed.fields[FieldJob] != "charge" {
+		t.Errorf("failed.fields[FieldJob] = %q, want %q", failed.fields[FieldJob], "charge")
+	}
+	if failed.fields["error"] != "card declined" {
+		t.Errorf(`failed.fields["error"] = %q, want %q`, failed.fields["error"], "card declined")
+	}
+	if last.Err != cause {
+		t.Errorf("last.Err = %p, want %p", last.Err, cause)
+	}
+}
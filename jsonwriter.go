@@ -4,68 +4,194 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"maps"
 	"time"
-
-	"github.com/bytedance/sonic"
 )
 
+// standardFields lists the fields every JSON entry starts with, in output order.
+var standardFields = []string{FieldTime, FieldLevel, FieldMessage, FieldCaller}
+
 type jsonWriter struct {
 	writer *bufio.Writer
 	output io.Writer
+	opts   writerOptions
 }
 
 // NewJSONWriter creates a new JSON logger that writes machine-readable logs to the given io.Writer.
 // Each log entry is a single JSON object with keys: time, level, msg, caller, plus any custom fields.
 // Ideal for production environments and log aggregation tools (e.g., ELK, Datadog).
+// Pass WriterOption values such as SortKeys to customize the output.
 //
 // Example output:
 //
 //	{"time":"2024-03-30T12:34:56Z","level":"INFO","msg":"User logged in","caller":"main.go:42","user_id":123}
-func NewJSONWriter(output io.Writer) *jsonWriter {
+func NewJSONWriter(output io.Writer, opts ...WriterOption) *jsonWriter {
 	return &jsonWriter{
 		writer: bufio.NewWriterSize(output, defaultBufferSize),
 		output: output,
+		opts:   newWriterOptions(opts),
 	}
 }
 
-// Write implements LogWriter interface
+// Write implements LogWriter interface.
+// If a field cannot be marshaled, the entry is written without custom fields
+// and with an "error" field describing the failure instead, and the failure is
+// reported to the error handler (see OnError).
+// Custom fields named like a standard field are handled by the
+// ReservedFieldPolicy set with OnReservedField.
 func (l *jsonWriter) Write(level int, msg string, fields map[string]any) {
 	// Get caller information (skip 2 frames to get the actual logging call)
 	file, line := getCallerInfo(skipFrames)
+	l.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter. It writes entry in the same format as
+// Write, using entry.Time as the "time" field. With StructuredErrors,
+// entry.Err replaces the message in the "error" field.
+func (l *jsonWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	l.write(entry, file, line)
+}
+
+// write encodes one log entry with the given caller location.
+func (l *jsonWriter) write(e Entry, file string, line int) {
+	fields := e.Fields
+	if _, ok := fields["error"]; ok && l.opts.structuredErrors && e.Err != nil {
+		// the fields belong to the caller, so set the error in a copy
+		fields = maps.Clone(fields)
+		fields["error"] = e.Err
+	}
 
 	// Create the base log entry
 	entry := map[string]any{
-		FieldTime:    time.Now().Format(time.RFC3339),
-		FieldLevel:   LevelString(level),
-		FieldMessage: msg,
+		FieldTime:    l.opts.timestamp(e.Time),
+		FieldLevel:   LevelString(e.Level),
+		FieldMessage: e.Message,
 		FieldCaller:  fmt.Sprintf("%s:%d", file, line),
 	}
 
 	// Add all fields to the entry
 	for k, v := range fields {
-		switch v := v.(type) {
+		if isStandardField(k) {
+			switch l.opts.reservedPolicy {
+			case ReservedFieldPrefix:
+				k = reservedFieldPrefix + k
+			case ReservedFieldError:
+				l.opts.handleError(fmt.Errorf("%w: %q", ErrReservedField, k))
+				continue
+			}
+		}
+
+		switch v := encodeField(l.opts.classifiedValue(v)).(type) {
 		case error:
-			entry[k] = fmt.Sprintf("%+v", v)
+			if l.opts.structuredErrors {
+				entry[k] = l.opts.structuredError(v)
+			} else {
+				entry[k] = fmt.Sprintf("%+v", v)
+			}
 		default:
-			entry[k] = v
+			entry[k] = l.opts.fieldValue(v)
 		}
 	}
 
-	// Marshal to JSON using sonic
-	data, err := sonic.Marshal(entry)
+	data, err := l.encode(entry)
 	if err != nil {
-		panic(err)
+		l.opts.handleError(fmt.Errorf("golog: failed to marshal log entry: %w", err))
+
+		for k := range entry {
+			if !isStandardField(k) {
+				delete(entry, k)
+			}
+		}
+
+		entry["error"] = fmt.Sprintf("failed to marshal log entry: %v", err)
+
+		if data, err = l.encode(entry); err != nil {
+			panic(err)
+		}
 	}
 
-	// Write the JSON entry with a newline
-	data = append(data, '\n')
+	// Write the JSON entry with its record delimiters
+	start := l.opts.recordStart()
+	l.writer.WriteString(start)
+	data = append(data, l.opts.recordEnd()...)
 	l.writer.Write(data)
+
+	if report := l.opts.metrics.record(e.Level, e.Message, len(start)+len(data)); report != nil {
+		l.write(Entry{Time: time.Now(), Level: LevelInfo, Message: SelfMetricsMessage, Fields: report}, file, line)
+		l.opts.metrics.reported()
+	}
 }
 
-// Flush implements LogWriter interface
+// encode marshals entry as a JSON object. The standard fields come first in a
+// fixed order, followed by custom fields in map order, or sorted by key when
+// the SortKeys option is set.
+func (l *jsonWriter) encode(entry map[string]any) ([]byte, error) {
+	custom := make(map[string]any, len(entry))
+	for k, v := range entry {
+		if !isStandardField(k) {
+			custom[k] = v
+		}
+	}
+
+	keys := make([]string, 0, len(entry))
+	for _, k := range standardFields {
+		if _, ok := entry[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+
+	keys = append(keys, l.opts.fieldKeys(custom)...)
+
+	data := []byte{'{'}
+	for i, k := range keys {
+		if i > 0 {
+			data = append(data, ',')
+		}
+
+		key, err := l.opts.marshal(k)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := l.opts.marshal(entry[k])
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, key...)
+		data = append(data, ':')
+		data = append(data, value...)
+	}
+
+	return append(data, '}'), nil
+}
+
+// isStandardField reports whether key is one of the fields the writer sets itself.
+func isStandardField(key string) bool {
+	for _, k := range standardFields {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// flushBuffer writes buffered data to the output without closing it.
+func (l *jsonWriter) flushBuffer() error {
+	return l.writer.Flush()
+}
+
+// flushBuffers implements bufferFlusher.
+func (l *jsonWriter) flushBuffers() {
+	l.writer.Flush()
+}
+
+// Flush implements LogWriter interface. It closes the output if it
+// implements io.Closer, unless it is stdout or stderr.
 func (l *jsonWriter) Flush() {
 	l.writer.Flush()
-	if flusher, ok := l.output.(io.Closer); ok {
-		flusher.Close()
-	}
+	closeOutput(l.output)
 }
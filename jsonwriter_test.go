@@ -3,17 +3,23 @@ package golog
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
-
-	"github.com/stretchr/testify/assert"
 )
 
 func TestNewJSONWriter(t *testing.T) {
 	buf := &bytes.Buffer{}
 	writer := NewJSONWriter(buf)
-	assert.NotNil(t, writer, "NewJSONWriter should not return nil")
+	if writer == nil {
+		t.Error("NewJSONWriter should not return nil: writer is nil")
+	}
 }
 
 func TestJSONWriter_Write(t *testing.T) {
@@ -32,11 +38,35 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "test message", entry[FieldMessage])
-				assert.Equal(t, "INFO", entry[FieldLevel])
-				assert.Contains(t, entry, FieldTime)
-				assert.Contains(t, entry, FieldCaller)
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry[FieldMessage] != "test message" {
+					t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "test message")
+				}
+				if entry[FieldLevel] != "INFO" {
+					t.Errorf("entry[FieldLevel] = %q, want %q", entry[FieldLevel], "INFO")
+				}
+				if _, ok := entry[FieldTime]; !ok {
+					t.Errorf("entry has no key %q", FieldTime)
+				}
+				if _, ok := entry[FieldCaller]; !ok {
+					t.Errorf("entry has no key %q", FieldCaller)
+				}
+			},
+		},
+		{
+			name:    "warn-entry",
+			level:   LevelWarn,
+			message: "retrying",
+			validate: func(t *testing.T, output string) {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(output), &entry); err != nil {
+					t.Errorf("json.Unmarshal([]byte(output), &entry): %v", err)
+				}
+				if entry[FieldLevel] != "WARN" {
+					t.Errorf("entry[FieldLevel] = %q, want %q", entry[FieldLevel], "WARN")
+				}
 			},
 		},
 		{
@@ -50,11 +80,21 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "user action", entry[FieldMessage])
-				assert.Equal(t, "DEBUG", entry[FieldLevel])
-				assert.Equal(t, float64(123), entry["user_id"])
-				assert.Equal(t, "login", entry["action"])
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry[FieldMessage] != "user action" {
+					t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "user action")
+				}
+				if entry[FieldLevel] != "DEBUG" {
+					t.Errorf("entry[FieldLevel] = %q, want %q", entry[FieldLevel], "DEBUG")
+				}
+				if entry["user_id"] != float64(123) {
+					t.Errorf(`entry["user_id"] = %v, want %v`, entry["user_id"], float64(123))
+				}
+				if entry["action"] != "login" {
+					t.Errorf(`entry["action"] = %q, want %q`, entry["action"], "login")
+				}
 			},
 		},
 		{
@@ -65,12 +105,18 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
 
 				timestamp, ok := entry[FieldTime].(string)
-				assert.True(t, ok, "Timestamp should be a string")
+				if !ok {
+					t.Error("Timestamp should be a string")
+				}
 				_, err = time.Parse(time.RFC3339, timestamp)
-				assert.NoError(t, err, "Timestamp should be in RFC3339 format")
+				if err != nil {
+					t.Errorf("Timestamp should be in RFC3339 format: unexpected error: %v", err)
+				}
 			},
 		},
 		{
@@ -81,8 +127,12 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "DEBUG", entry[FieldLevel])
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry[FieldLevel] != "DEBUG" {
+					t.Errorf("entry[FieldLevel] = %q, want %q", entry[FieldLevel], "DEBUG")
+				}
 			},
 		},
 		{
@@ -95,9 +145,15 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "test with special chars: \n\t\"'{}[]", entry[FieldMessage])
-				assert.Equal(t, "value with \n\t\"'{}[]", entry["special_field"])
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry[FieldMessage] != "test with special chars: \n\t\"'{}[]" {
+					t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "test with special chars: \n\t\"'{}[]")
+				}
+				if entry["special_field"] != "value with \n\t\"'{}[]" {
+					t.Errorf(`entry["special_field"] = %q, want %q`, entry["special_field"], "value with \n\t\"'{}[]")
+				}
 			},
 		},
 		{
@@ -115,13 +171,23 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
 				user, ok := entry["user"].(map[string]any)
-				assert.True(t, ok, "User should be a map")
-				assert.Equal(t, "John Doe", user["name"])
+				if !ok {
+					t.Error("User should be a map")
+				}
+				if user["name"] != "John Doe" {
+					t.Errorf(`user["name"] = %q, want %q`, user["name"], "John Doe")
+				}
 				address, ok := user["address"].(map[string]any)
-				assert.True(t, ok, "Address should be a map")
-				assert.Equal(t, "New York", address["city"])
+				if !ok {
+					t.Error("Address should be a map")
+				}
+				if address["city"] != "New York" {
+					t.Errorf(`address["city"] = %q, want %q`, address["city"], "New York")
+				}
 			},
 		},
 		{
@@ -134,9 +200,15 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "", entry[FieldMessage])
-				assert.Equal(t, true, entry["empty"])
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry[FieldMessage] != "" {
+					t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "")
+				}
+				if entry["empty"] != true {
+					t.Errorf(`entry["empty"] = %v, want true`, entry["empty"])
+				}
 			},
 		},
 		{
@@ -150,9 +222,15 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, float64(999999999999999), entry["large_number"])
-				assert.Len(t, entry["large_string"].(string), 1000)
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry["large_number"] != float64(999999999999999) {
+					t.Errorf(`entry["large_number"] = %v, want %v`, entry["large_number"], float64(999999999999999))
+				}
+				if got := entry["large_string"].(string); len(got) != 1000 {
+					t.Errorf(`len(entry["large_string"].(string)) = %d, want 1000`, len(got))
+				}
 			},
 		},
 		{
@@ -167,10 +245,18 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Equal(t, "value", entry["valid_field"])
-				assert.Equal(t, "value", entry["field.with.dots"])
-				assert.Equal(t, "value", entry["field-with-dashes"])
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if entry["valid_field"] != "value" {
+					t.Errorf(`entry["valid_field"] = %q, want %q`, entry["valid_field"], "value")
+				}
+				if entry["field.with.dots"] != "value" {
+					t.Errorf(`entry["field.with.dots"] = %q, want %q`, entry["field.with.dots"], "value")
+				}
+				if entry["field-with-dashes"] != "value" {
+					t.Errorf(`entry["field-with-dashes"] = %q, want %q`, entry["field-with-dashes"], "value")
+				}
 			},
 		},
 		{
@@ -183,8 +269,12 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Contains(t, entry["error"], "failed to marshal log entry")
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if got, _ := entry["error"].(string); !strings.Contains(got, "failed to marshal log entry") {
+					t.Errorf(`entry["error"] = %q, want it to contain %q`, got, "failed to marshal log entry")
+				}
 			},
 		},
 		{
@@ -197,8 +287,12 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Contains(t, entry["error"], "failed to marshal log entry")
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if got, _ := entry["error"].(string); !strings.Contains(got, "failed to marshal log entry") {
+					t.Errorf(`entry["error"] = %q, want it to contain %q`, got, "failed to marshal log entry")
+				}
 			},
 		},
 		{
@@ -211,8 +305,12 @@ func TestJSONWriter_Write(t *testing.T) {
 			validate: func(t *testing.T, output string) {
 				var entry map[string]any
 				err := json.Unmarshal([]byte(output), &entry)
-				assert.NoError(t, err, "Output should be valid JSON")
-				assert.Contains(t, entry["error"], "failed to marshal log entry")
+				if err != nil {
+					t.Errorf("Output should be valid JSON: unexpected error: %v", err)
+				}
+				if got, _ := entry["error"].(string); !strings.Contains(got, "failed to marshal log entry") {
+					t.Errorf(`entry["error"] = %q, want it to contain %q`, got, "failed to marshal log entry")
+				}
 			},
 		},
 	}
@@ -253,9 +351,474 @@ func TestJSONWriter_Flush(t *testing.T) {
 
 			writer.Write(tt.level, tt.message, tt.fields)
 
-			assert.NotPanics(t, func() {
-				writer.Flush()
-			}, "Flush should not panic")
+			writer.Flush()
+		})
+	}
+}
+
+func TestJSONWriter_SortKeys(t *testing.T) {
+	fields := map[string]any{
+		"zeta":  1,
+		"alpha": "a",
+		"mid":   map[string]any{"y": 2, "x": 1},
+	}
+
+	for i := 0; i < 10; i++ {
+		buf := &bytes.Buffer{}
+		writer := NewJSONWriter(buf, SortKeys())
+
+		writer.Write(LevelInfo, "sorted", fields)
+		writer.Flush()
+
+		output := strings.TrimSpace(buf.String())
+		if !strings.HasPrefix(output, `{"time":`) {
+			t.Errorf("standard fields should come first: %s", output)
+		}
+		if got, limit := strings.Index(output, `"level":`), strings.Index(output, `"msg":`); got >= limit {
+			t.Errorf("got %v, want less than %v", got, limit)
+		}
+		if got, limit := strings.Index(output, `"msg":`), strings.Index(output, `"caller":`); got >= limit {
+			t.Errorf("got %v, want less than %v", got, limit)
+		}
+		if !strings.HasSuffix(output, `"alpha":"a","mid":{"x":1,"y":2},"zeta":1}`) {
+			t.Errorf("%s", output)
+		}
+	}
+}
+
+func TestJSONWriter_TimeZone(t *testing.T) {
+	at := time.Date(2026, 10, 17, 23, 30, 0, 0, time.FixedZone("PDT", -7*3600))
+
+	tests := []struct {
+		name string
+		opts []WriterOption
+		want string
+	}{
+		{name: "default-utc", want: "2026-10-18T06:30:00Z"},
+		{name: "configured", opts: []WriterOption{TimeZone(time.FixedZone("JST", 9*3600))}, want: "2026-10-18T15:30:00+09:00"},
+		{name: "nil-utc", opts: []WriterOption{TimeZone(nil)}, want: "2026-10-18T06:30:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, tt.opts...)
+
+			writer.WriteEntry(Entry{Time: at, Level: LevelInfo, Message: "order shipped"})
+			writer.Flush()
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			if entry[FieldTime] != tt.want {
+				t.Errorf("entry[FieldTime] = %q, want %q", entry[FieldTime], tt.want)
+			}
+		})
+	}
+}
+
+// pkgFrame and pkgError mimic the stack traces of github.com/pkg/errors,
+// whose frames are program counters of a named type.
+type pkgFrame uintptr
+
+type pkgError struct {
+	stack []pkgFrame
+}
+
+func (e *pkgError) Error() string { return "pkg failure" }
+
+func (e *pkgError) StackTrace() []pkgFrame { return e.stack }
+
+func newPkgError() error {
+	var pcs [8]uintptr
+	n := runtime.Callers(1, pcs[:])
+
+	err := &pkgError{}
+	for _, pc := range pcs[:n] {
+		err.stack = append(err.stack, pkgFrame(pc))
+	}
+
+	return err
+}
+
+func TestJSONWriter_StructuredErrors(t *testing.T) {
+	pathErr := &fs.PathError{Op: "open", Path: "app.yaml", Err: fs.ErrNotExist}
+
+	tests := []struct {
+		name      string
+		err       error
+		wantType  string
+		wantChain []any
+		wantStack string
+	}{
+		{
+			name:     "wrapped",
+			err:      fmt.Errorf("load config: %w", pathErr),
+			wantType: "*fmt.wrapError",
+			wantChain: []any{
+				map[string]any{"message": pathErr.Error(), "type": "*fs.PathError"},
+				map[string]any{"message": "file does not exist", "type": "*errors.errorString"},
+			},
+		},
+		{
+			name:     "joined",
+			err:      errors.Join(errors.New("a"), errors.New("b")),
+			wantType: "*errors.joinError",
+			wantChain: []any{
+				map[string]any{"message": "a", "type": "*errors.errorString"},
+				map[string]any{"message": "b", "type": "*errors.errorString"},
+			},
+		},
+		{
+			name:      "stack-errors",
+			err:       StackErrors().Wrap(errors.New("timeout"), "query"),
+			wantType:  "*golog.stackError",
+			wantChain: []any{map[string]any{"message": "timeout", "type": "*errors.errorString"}},
+			wantStack: "TestJSONWriter_StructuredErrors",
+		},
+		{
+			name:      "pkg-errors",
+			err:       newPkgError(),
+			wantType:  "*golog.pkgError",
+			wantStack: "newPkgError",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, StructuredErrors())
+
+			fields := map[string]any{"error": tt.err.Error(), "cause": tt.err}
+			writer.WriteEntry(Entry{Level: LevelError, Message: "failed", Fields: fields, Err: tt.err})
+			writer.Flush()
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			want := tt.err.Error()
+			if fields["error"] != want {
+				t.Errorf(`the fields of the caller are not modified: fields["error"] = %q, want %q`, fields["error"], want)
+			}
+			if !reflect.DeepEqual(entry["cause"], entry["error"]) {
+				t.Errorf(`error values of fields are rendered the same way: entry["cause"] = %v, want %v`, entry["cause"], entry["error"])
+			}
+
+			rendered, ok := entry["error"].(map[string]any)
+			if !ok {
+				t.Fatal("the error is rendered as an object")
+			}
+			if want := tt.err.Error(); rendered["message"] != want {
+				t.Errorf(`rendered["message"] = %q, want %q`, rendered["message"], want)
+			}
+			if rendered["type"] != tt.wantType {
+				t.Errorf(`rendered["type"] = %q, want %q`, rendered["type"], tt.wantType)
+			}
+			if tt.wantChain != nil {
+				if !reflect.DeepEqual(rendered["chain"], tt.wantChain) {
+					t.Errorf(`rendered["chain"] = %v, want %v`, rendered["chain"], tt.wantChain)
+				}
+			} else {
+				if _, ok := rendered["chain"]; ok {
+					t.Errorf("rendered has key %q, want none", "chain")
+				}
+			}
+
+			if tt.wantStack == "" {
+				if _, ok := rendered["stack"]; ok {
+					t.Errorf("rendered has key %q, want none", "stack")
+				}
+				return
+			}
+
+			stack, ok := rendered["stack"].([]any)
+			if !ok {
+				t.Fatal("ok = false, want true")
+			}
+			if len(stack) == 0 {
+				t.Fatal("stack is empty")
+			}
+			if got, _ := stack[0].(string); !strings.Contains(got, tt.wantStack) {
+				t.Errorf("stack[0] = %q, want it to contain %q", got, tt.wantStack)
+			}
+			if got, _ := stack[0].(string); !strings.Contains(got, "jsonwriter_test.go:") {
+				t.Errorf("stack[0] = %q, want it to contain %q", got, "jsonwriter_test.go:")
+			}
+		})
+	}
+}
+
+func TestJSONWriter_StructuredErrors_Chain(t *testing.T) {
+	wrapper := StackErrors()
+	// a cause wrapped by three layers, each recording its stack
+	err := wrapper.Wrap(wrapper.Wrap(wrapper.Wrap(errors.New("connection refused"), "query"), "load order"), "handle request")
+
+	tests := []struct {
+		name          string
+		opts          []WriterOption
+		wantChain     []any
+		wantTruncated bool
+	}{
+		{
+			name: "default",
+			wantChain: []any{
+				map[string]any{"type": "*golog.stackError", "message": "load order: query: connection refused"},
+				map[string]any{"type": "*golog.stackError", "message": "query: connection refused"},
+				map[string]any{"type": "*errors.errorString", "message": "connection refused"},
+			},
+		},
+		{
+			name: "depth",
+			opts: []WriterOption{ErrorChainDepth(2)},
+			wantChain: []any{
+				map[string]any{"type": "*golog.stackError", "message": "load order: query: connection refused"},
+				map[string]any{"type": "*golog.stackError", "message": "query: connection refused"},
+			},
+			wantTruncated: true,
+		},
+		{
+			name:          "no-chain",
+			opts:          []WriterOption{ErrorChainDepth(0)},
+			wantTruncated: true,
+		},
+		{
+			name: "type-only",
+			opts: []WriterOption{ErrorCauseFields(CauseType)},
+			wantChain: []any{
+				map[string]any{"type": "*golog.stackError"},
+				map[string]any{"type": "*golog.stackError"},
+				map[string]any{"type": "*errors.errorString"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, append([]WriterOption{StructuredErrors()}, tt.opts...)...)
+
+			writer.Write(LevelError, "failed", map[string]any{"cause": err})
+			writer.Flush()
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+
+			rendered := entry["cause"].(map[string]any)
+			want := "handle request: load order: query: connection refused"
+			if rendered["message"] != want {
+				t.Errorf(`rendered["message"] = %q, want %q`, rendered["message"], want)
+			}
+			if stack, _ := rendered["stack"].([]any); len(stack) == 0 {
+				t.Errorf(`rendered["stack"] = %v, want non-empty`, rendered["stack"])
+			}
+			if tt.wantChain != nil {
+				if !reflect.DeepEqual(rendered["chain"], tt.wantChain) {
+					t.Errorf(`rendered["chain"] = %v, want %v`, rendered["chain"], tt.wantChain)
+				}
+			} else {
+				if _, ok := rendered["chain"]; ok {
+					t.Errorf("rendered has key %q, want none", "chain")
+				}
+			}
+
+			if tt.wantTruncated {
+				if rendered["chain_truncated"] != true {
+					t.Errorf(`rendered["chain_truncated"] = %v, want true`, rendered["chain_truncated"])
+				}
+			} else {
+				if _, ok := rendered["chain_truncated"]; ok {
+					t.Errorf("rendered has key %q, want none", "chain_truncated")
+				}
+			}
+		})
+	}
+}
+
+func TestJSONWriter_StructuredErrors_CauseFrame(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewJSONWriter(buf, StructuredErrors(), ErrorCauseFields(CauseType, CauseFrame))
+
+	err := fmt.Errorf("handle request: %w", StackErrors().Wrap(errors.New("connection refused"), "query"))
+	writer.Write(LevelError, "failed", map[string]any{"cause": err})
+	writer.Flush()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+	}
+
+	chain := entry["cause"].(map[string]any)["chain"].([]any)
+	if len(chain) != 2 {
+		t.Fatalf("len(chain) = %d, want 2", len(chain))
+	}
+
+	wrapped := chain[0].(map[string]any)
+	if wrapped["type"] != "*golog.stackError" {
+		t.Errorf(`wrapped["type"] = %q, want %q`, wrapped["type"], "*golog.stackError")
+	}
+	if _, ok := wrapped["message"]; ok {
+		t.Errorf("wrapped has key %q, want none", "message")
+	}
+	frame := regexp.MustCompile(`^github\.com/jkaveri/golog\.TestJSONWriter_StructuredErrors_CauseFrame .*jsonwriter_test\.go:\d+$`)
+	if got, _ := wrapped["frame"].(string); !frame.MatchString(got) {
+		t.Errorf(`wrapped["frame"] = %q, want a match of %q`, got, frame)
+	}
+
+	want := map[string]any{"type": "*errors.errorString"}
+	if !reflect.DeepEqual(chain[1], want) {
+		t.Errorf("the frame of a cause without a stack is omitted: chain[1] = %v, want %v", chain[1], want)
+	}
+}
+
+func TestJSONWriter_StructuredErrors_Disabled(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewJSONWriter(buf)
+
+	err := errors.New("boom")
+	writer.WriteEntry(Entry{Level: LevelError, Message: "failed", Fields: map[string]any{"error": err.Error()}, Err: err})
+	writer.Flush()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+	}
+	if entry["error"] != "boom" {
+		t.Errorf(`entry["error"] = %q, want %q`, entry["error"], "boom")
+	}
+}
+
+func TestJSONWriter_Int64Precision(t *testing.T) {
+	fields := map[string]any{
+		"int64":  int64(9007199254740993),
+		"uint64": uint64(18446744073709551615),
+		"int":    42,
+		"number": json.Number("12345678901234567890"),
+		"nested": map[string]any{"id": int64(-9007199254740993)},
+	}
+
+	tests := []struct {
+		name     string
+		opts     []WriterOption
+		contains []string
+	}{
+		{
+			name: "exact-numbers-by-default",
+			contains: []string{
+				`"int64":9007199254740993`,
+				`"uint64":18446744073709551615`,
+				`"int":42`,
+				`"number":12345678901234567890`,
+				`"nested":{"id":-9007199254740993}`,
+			},
+		},
+		{
+			name: "int64-as-string",
+			opts: []WriterOption{Int64AsString()},
+			contains: []string{
+				`"int64":"9007199254740993"`,
+				`"uint64":"18446744073709551615"`,
+				`"int":42`,
+				`"number":12345678901234567890`,
+				`"nested":{"id":"-9007199254740993"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, tt.opts...)
+
+			writer.Write(LevelInfo, "numbers", fields)
+			writer.Flush()
+
+			for _, contain := range tt.contains {
+				if got := buf.String(); !strings.Contains(got, contain) {
+					t.Errorf("buf.String() = %q, want it to contain %q", got, contain)
+				}
+			}
+		})
+	}
+}
+
+func TestJSONWriter_ReservedFieldPolicy(t *testing.T) {
+	fields := map[string]any{
+		FieldMessage: "user message",
+		"user_id":    1,
+	}
+
+	tests := []struct {
+		name       string
+		policy     ReservedFieldPolicy
+		wantMsg    string
+		wantFields map[string]any
+		wantErr    bool
+	}{
+		{
+			name:       "override",
+			policy:     ReservedFieldOverride,
+			wantMsg:    "user message",
+			wantFields: map[string]any{"user_id": float64(1)},
+		},
+		{
+			name:       "prefix",
+			policy:     ReservedFieldPrefix,
+			wantMsg:    "log message",
+			wantFields: map[string]any{"user_id": float64(1), "fields_msg": "user message"},
+		},
+		{
+			name:       "error",
+			policy:     ReservedFieldError,
+			wantMsg:    "log message",
+			wantFields: map[string]any{"user_id": float64(1)},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(
+				buf,
+				OnReservedField(tt.policy),
+				OnError(func(err error) { errs = append(errs, err) }),
+			)
+
+			writer.Write(LevelInfo, "log message", fields)
+			writer.Flush()
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Errorf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			if entry[FieldMessage] != tt.wantMsg {
+				t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], tt.wantMsg)
+			}
+
+			for _, k := range standardFields {
+				delete(entry, k)
+			}
+			if !reflect.DeepEqual(entry, tt.wantFields) {
+				t.Errorf("entry = %v, want %v", entry, tt.wantFields)
+			}
+
+			if tt.wantErr {
+				if len(errs) != 1 {
+					t.Errorf("len(errs) = %d, want 1", len(errs))
+				}
+				if !errors.Is(errs[0], ErrReservedField) {
+					t.Errorf("errs[0] = %v, want %v", errs[0], ErrReservedField)
+				}
+			} else {
+				if len(errs) != 0 {
+					t.Errorf("errs = %v, want empty", errs)
+				}
+			}
 		})
 	}
 }
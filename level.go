@@ -5,28 +5,37 @@ import "strings"
 // Log level constants. Lower values are less severe; only messages with
 // level >= the minimum (set via SetLevel) are logged.
 const (
-	LevelDebug = iota // 0 - detailed debugging information
-	LevelInfo         // 1 - general operational information (default minimum)
-	LevelError        // 2 - error conditions
+	LevelTrace = iota // 0 - very verbose diagnostics, e.g. of libraries
+	LevelDebug        // 1 - detailed debugging information
+	LevelInfo         // 2 - general operational information (default minimum)
+	LevelWarn         // 3 - recoverable conditions that may need attention
+	LevelError        // 4 - error conditions
+	LevelPanic        // 5 - unrecoverable conditions; the goroutine panics
+	LevelFatal        // 6 - unrecoverable conditions; the process exits
 )
 
 // levelNames maps level integers to their string representations
 var levelNames = map[int]string{
-	0: "DEBUG",
-	1: "INFO",
-	2: "ERROR",
+	LevelTrace: "TRACE",
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+	LevelPanic: "PANIC",
+	LevelFatal: "FATAL",
 }
 
 // levelValues maps string level names to their integer values
 var levelValues = map[string]int{
-	"DEBUG": 0,
-	"INFO":  1,
-	"ERROR": 2,
+	"TRACE": LevelTrace,
+	"DEBUG": LevelDebug,
+	"INFO":  LevelInfo,
+	"WARN":  LevelWarn,
+	"ERROR": LevelError,
+	"PANIC": LevelPanic,
+	"FATAL": LevelFatal,
 }
 
-// minLevel is the minimum level that should be logged
-var minLevel = LevelInfo
-
 // ParseLevel converts a string level name to its integer value.
 // The parsing is case-insensitive (e.g., "debug", "DEBUG", "Debug" all map to LevelDebug).
 // Returns -1 if the level name is invalid.
@@ -48,22 +57,11 @@ func LevelString(level int) string {
 	return "UNKNOWN"
 }
 
-// SetLevel sets the minimum log level that should be logged.
-// Only messages with severity >= minLevel will be logged.
-// Use LevelDebug, LevelInfo, or LevelError, or ParseLevel for string-based config.
+// SetLevel sets the minimum log level that should be logged, the level of the
+// default Logger.
+// Only messages with severity >= the minimum level will be logged.
+// Levels removed at compile time with build tags stay removed.
+// Use LevelTrace, LevelDebug, LevelInfo, LevelWarn, or LevelError, or ParseLevel for string-based config.
 func SetLevel(level int) {
-	if _, ok := levelNames[level]; ok {
-		minLevel = level
-	}
-}
-
-// shouldLog checks if a message with the given level should be logged
-// based on the current minimum level setting
-func shouldLog(level int) bool {
-	_, ok := levelNames[level]
-	if !ok {
-		return false
-	}
-
-	return level >= minLevel
+	std.SetLevel(level)
 }
@@ -0,0 +1,80 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultPreStopTimeout is the flush timeout of KubernetesPreStopHandler by
+// default, well within the default termination grace period of 30 seconds
+const defaultPreStopTimeout = 10 * time.Second
+
+// FlushContext is like Flush, but returns ctx.Err() if ctx is done before
+// the writer is flushed, e.g. because a remote sink hangs. The flush goes on
+// in the background.
+func (lg *Logger) FlushContext(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lg.Flush()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// KubernetesPreStopHandler returns a function that flushes the writers of
+// loggers, or of the default Logger when there are none, including async
+// and batching writers, within timeout (10 seconds when it is zero or
+// less). Call it from the preStop hook, or the shutdown path on SIGTERM, so
+// the last seconds of logs are not lost when the pod terminates. It returns
+// context.DeadlineExceeded if the writers are not flushed in time.
+//
+// golog does not import net/http; to serve the hook over HTTP, wrap the
+// function in a handler:
+//
+//	preStop := golog.KubernetesPreStopHandler(5 * time.Second)
+//	http.HandleFunc("/prestop", func(w http.ResponseWriter, r *http.Request) {
+//	    if err := preStop(); err != nil {
+//	        http.Error(w, err.Error(), http.StatusServiceUnavailable)
+//	    }
+//	})
+func KubernetesPreStopHandler(timeout time.Duration, loggers ...*Logger) func() error {
+	if timeout <= 0 {
+		timeout = defaultPreStopTimeout
+	}
+
+	if len(loggers) == 0 {
+		loggers = []*Logger{std}
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		errs := make([]error, len(loggers))
+
+		var wg sync.WaitGroup
+		for i, lg := range loggers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = lg.FlushContext(ctx)
+			}()
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
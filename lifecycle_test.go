@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// hangingWriter is a captureWriter whose Flush waits until release is
+// closed.
+type hangingWriter struct {
+	captureWriter
+	release chan struct{}
+}
+
+func (w *hangingWriter) Flush() {
+	<-w.release
+	w.captureWriter.Flush()
+}
+
+func TestKubernetesPreStopHandler(t *testing.T) {
+	t.Run("default-logger", func(t *testing.T) {
+		w := &captureWriter{}
+		useWriter(t, w)
+
+		if err := KubernetesPreStopHandler(time.Second)(); err != nil {
+			t.Errorf("KubernetesPreStopHandler(time.Second)(): %v", err)
+		}
+		if w.flushes != 1 {
+			t.Errorf("w.flushes = %v, want 1", w.flushes)
+		}
+	})
+
+	t.Run("loggers", func(t *testing.T) {
+		w := &captureWriter{}
+		hanging := &hangingWriter{release: make(chan struct{})}
+		defer close(hanging.release)
+
+		preStop := KubernetesPreStopHandler(50*time.Millisecond,
+			New(LoggerWriter(w)),
+			New(LoggerWriter(hanging)),
+		)
+
+		start := time.Now()
+		if err := preStop(); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("preStop() = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if got := time.Since(start); got >= time.Second {
+			t.Errorf("the flush is bounded by the timeout: time.Since(start) = %v, want less than %v", got, time.Second)
+		}
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		if w.flushes != 1 {
+			t.Errorf("the other loggers are flushed: w.flushes = %v, want 1", w.flushes)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package golog
 import (
 	"context"
 	"os"
+	"time"
 )
 
 const (
@@ -22,38 +23,43 @@ const (
 	FieldCaller = "caller"
 )
 
-var (
-	// instance is the global log writer instance
-	instance LogWriter = &defaultWriter{
-		output: os.Stdout,
-	}
-	// enrichers contains all registered log enrichers
-	enrichers []Enricher
-)
-
 // LogWriter defines the interface for log output writers.
 // Implementations should handle the actual writing of log entries.
 type LogWriter interface {
-	// Write writes a log entry with the given level, message, and fields
+	// Write writes a log entry with the given level, message, and fields.
+	// The fields must not be retained after Write returns: writers such as
+	// the async writer reuse the map for later entries. Copy the fields to
+	// keep them.
 	Write(level int, msg string, fields map[string]any)
 	// Flush ensures all buffered log entries are written
 	Flush()
 }
 
-// SetWriter sets the global log writer instance.
+// SetWriter sets the global log writer instance, the writer of the default
+// Logger.
 // This function should be called at application startup to configure logging.
+// Before it is called, entries are written unbuffered to os.Stderr in the text
+// format, so failures logged during early startup are never lost.
+// Existing scopes write to the new writer from their next entry on; flush the
+// previous writer yourself if it buffers output.
 func SetWriter(logger LogWriter) {
-	instance = logger
+	std.SetWriter(logger)
 }
 
-// RegisterEnricher adds a new enricher to the global enrichers list.
+// RegisterEnricher adds a new enricher to the global enrichers list, the
+// enrichers of the default Logger.
 // Enrichers are called in the order they are registered.
+//
+// RegisterEnricher is safe to call concurrently with logging: each LogScope
+// takes a snapshot of the registered enrichers when it is created, so an
+// enricher registered later only applies to scopes created afterwards.
+// Register enrichers at startup, before scopes are created, to have them
+// apply everywhere.
 func RegisterEnricher(enricher Enricher) {
-	enrichers = append(enrichers, enricher)
+	std.RegisterEnricher(enricher)
 }
 
 // With creates a new LogScope with a single key-value field.
-// LogScope is not thread-safe; create a new scope per goroutine.
 func With(key string, value any) *LogScope {
 	return newScope().With(key, value)
 }
@@ -97,28 +103,216 @@ func WithError(err error) *LogScope {
 	return newScope().WithError(err)
 }
 
+// WithReturnedError creates a new LogScope with the fields carried by err, as
+// returned by Error, and the error field. Use it to log, further up the call
+// chain, an error returned by Error with the context of the failure:
+//
+//	if err := repo.Save(ctx, order); err != nil {
+//	    golog.WithReturnedError(err).Warn("order not saved, retrying")
+//	}
+func WithReturnedError(err error) *LogScope {
+	return newScope().WithReturnedError(err)
+}
+
+// WithTime creates a new LogScope whose entries carry t as their time.
+// It is a convenience function for logging events that happened earlier.
+func WithTime(t time.Time) *LogScope {
+	return newScope().WithTime(t)
+}
+
+// WithLevel creates a new LogScope whose entries are written at or above
+// level, in place of the global level (see LogScope.WithLevel).
+func WithLevel(level int) *LogScope {
+	return newScope().WithLevel(level)
+}
+
+// WithWriter creates a new LogScope whose entries go to w instead of the
+// global writer. It is a convenience function for diverting a subsystem's
+// entries to a sink of its own.
+func WithWriter(w LogWriter) *LogScope {
+	return newScope().WithWriter(w)
+}
+
+// Trace logs a message at the trace level, for very verbose diagnostics, such
+// as the internals of a library, that would pollute debug output.
+// The message is not formatted (see Tracef).
+func Trace(msg string) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	newScope().Trace(msg)
+}
+
+// Tracef logs a message at the trace level.
+// Args are passed to fmt.Sprintf for message formatting.
+func Tracef(format string, args ...any) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	newScope().Tracef(format, args...)
+}
+
 // Debug logs a message at the debug level.
+// The message is not formatted (see Debugf).
+func Debug(msg string) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	newScope().Debug(msg)
+}
+
+// Debugf logs a message at the debug level.
 // Args are passed to fmt.Sprintf for message formatting.
-func Debug(msg string, args ...any) {
-	newScope().Debug(msg, args...)
+func Debugf(format string, args ...any) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	newScope().Debugf(format, args...)
 }
 
 // Info logs a message at the info level.
+// The message is not formatted (see Infof).
+func Info(msg string) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	newScope().Info(msg)
+}
+
+// Infof logs a message at the info level.
+// Args are passed to fmt.Sprintf for message formatting.
+func Infof(format string, args ...any) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	newScope().Infof(format, args...)
+}
+
+// Warn logs a message at the warn level, for recoverable conditions that do
+// not prevent the operation from completing.
+// The message is not formatted (see Warnf).
+func Warn(msg string) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	newScope().Warn(msg)
+}
+
+// Warnf logs a message at the warn level.
 // Args are passed to fmt.Sprintf for message formatting.
-func Info(msg string, args ...any) {
-	newScope().Info(msg, args...)
+func Warnf(format string, args ...any) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	newScope().Warnf(format, args...)
 }
 
 // Error logs a message at the error level and returns an error for propagation.
+// The message is not formatted (see Errorf).
+func Error(msg string) error {
+	return newScope().Error(msg)
+}
+
+// Errorf logs a message at the error level and returns an error for
+// propagation.
 // Args are passed to fmt.Sprintf for message formatting.
-func Error(msg string, args ...any) error {
-	return newScope().Error(msg, args...)
+func Errorf(format string, args ...any) error {
+	return newScope().Errorf(format, args...)
+}
+
+// ErrorIf logs a message at the error level, with err in the error field, and
+// returns an error wrapping err, when err is not nil. It does nothing and
+// returns nil when err is nil.
+// The message is not formatted.
+func ErrorIf(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return newScope().ErrorIf(err, msg)
+}
+
+// DebugIf logs a message at the debug level when cond is true.
+// The message is not formatted.
+func DebugIf(cond bool, msg string) {
+	if maxLevel > LevelDebug || !cond {
+		return
+	}
+
+	newScope().Debug(msg)
+}
+
+// InfoIf logs a message at the info level when cond is true.
+// The message is not formatted.
+func InfoIf(cond bool, msg string) {
+	if maxLevel > LevelInfo || !cond {
+		return
+	}
+
+	newScope().Info(msg)
+}
+
+// Panic logs a message at the panic level, flushes the writer, and panics
+// with the message.
+// The message is not formatted (see Panicf).
+func Panic(msg string) {
+	newScope().Panic(msg)
+}
+
+// Panicf logs a message at the panic level, flushes the writer, and panics
+// with the formatted message.
+// Args are passed to fmt.Sprintf for message formatting.
+func Panicf(format string, args ...any) {
+	newScope().Panicf(format, args...)
+}
+
+// Fatal logs a message at the fatal level, flushes the writer, and exits the
+// process with status 1 (see SetExitFunc). Deferred functions do not run.
+// The message is not formatted (see Fatalf).
+func Fatal(msg string) {
+	newScope().Fatal(msg)
+}
+
+// Fatalf logs a message at the fatal level, flushes the writer, and exits
+// the process with status 1.
+// Args are passed to fmt.Sprintf for message formatting.
+func Fatalf(format string, args ...any) {
+	newScope().Fatalf(format, args...)
+}
+
+// exitFunc terminates the process after a fatal entry.
+var exitFunc = os.Exit
+
+// SetExitFunc sets the function Fatal calls to terminate the process after
+// the entry is flushed. The default is os.Exit; nil restores it. Tests use it
+// to intercept termination.
+//
+// Example:
+//
+//	golog.SetExitFunc(func(code int) { exited = code })
+//	defer golog.SetExitFunc(nil)
+func SetExitFunc(exit func(code int)) {
+	if exit == nil {
+		exit = os.Exit
+	}
+
+	exitFunc = exit
 }
 
 // Flush ensures all buffered log entries are written.
-// It calls Flush on the global log writer instance.
+// It calls Flush on the global log writer instance, after writing the report
+// of dropped entries if any were recorded (see RecordDropped) and the
+// summaries of closed silence windows (see SetSilenceWindows).
 func Flush() {
-	instance.Flush()
+	std.Flush()
 }
 
 // skipFrames is the number of frames to skip when logging.
@@ -127,6 +321,8 @@ var skipFrames = 1
 
 // SetSkipFrames sets the number of frames to skip when logging.
 // This is useful for logging from functions that are called by other functions.
+// It applies to the Loggers without a setting of their own (see
+// LoggerSkipFrames).
 func SetSkipFrames(skip int) {
 	skipFrames = skip
 }
@@ -3,17 +3,290 @@ package golog
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
-
-	"github.com/stretchr/testify/assert"
+	"time"
 )
 
 func TestLevelConstants(t *testing.T) {
-	assert.Equal(t, 0, LevelDebug)
-	assert.Equal(t, 1, LevelInfo)
-	assert.Equal(t, 2, LevelError)
+	if LevelTrace != 0 {
+		t.Errorf("LevelTrace = %v, want 0", LevelTrace)
+	}
+	if LevelDebug != 1 {
+		t.Errorf("LevelDebug = %v, want 1", LevelDebug)
+	}
+	if LevelInfo != 2 {
+		t.Errorf("LevelInfo = %v, want 2", LevelInfo)
+	}
+	if LevelWarn != 3 {
+		t.Errorf("LevelWarn = %v, want 3", LevelWarn)
+	}
+	if LevelError != 4 {
+		t.Errorf("LevelError = %v, want 4", LevelError)
+	}
+	if LevelPanic != 5 {
+		t.Errorf("LevelPanic = %v, want 5", LevelPanic)
+	}
+	if LevelFatal != 6 {
+		t.Errorf("LevelFatal = %v, want 6", LevelFatal)
+	}
+}
+
+func TestFatal(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	exitCode := -1
+	SetExitFunc(func(code int) { exitCode = code })
+	t.Cleanup(func() { SetExitFunc(nil) })
+
+	With("config", "app.yaml").Fatalf("cannot load %s", "config")
+
+	if exitCode != 1 {
+		t.Errorf("exitCode = %v, want 1", exitCode)
+	}
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if got := w.last().level; got != LevelFatal {
+		t.Errorf("w.last().level = %v, want %v", got, LevelFatal)
+	}
+	if got := w.last().msg; got != "cannot load config" {
+		t.Errorf("w.last().msg = %q, want %q", got, "cannot load config")
+	}
+	if got := w.last().fields["config"]; got != "app.yaml" {
+		t.Errorf(`w.last().fields["config"] = %q, want %q`, got, "app.yaml")
+	}
+	if w.flushes != 1 {
+		t.Errorf("flushed before exiting: w.flushes = %v, want 1", w.flushes)
+	}
+}
+
+func TestPanic(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	r := recovered(func() { Panicf("invariant violated: %d > %d", 3, 2) })
+	if r != "invariant violated: 3 > 2" {
+		t.Errorf("recovered = %v, want %q", r, "invariant violated: 3 > 2")
+	}
+
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if got := w.last().level; got != LevelPanic {
+		t.Errorf("w.last().level = %v, want %v", got, LevelPanic)
+	}
+	if got := LevelString(w.last().level); got != "PANIC" {
+		t.Errorf("LevelString(w.last().level) = %q, want %q", got, "PANIC")
+	}
+	if w.flushes != 1 {
+		t.Errorf("flushed before panicking: w.flushes = %v, want 1", w.flushes)
+	}
+}
+
+func TestErrorIf(t *testing.T) {
+	cause := errors.New("disk full")
+
+	tests := []struct {
+		name    string
+		err     error
+		entries int
+	}{
+		{name: "nil-error", err: nil, entries: 0},
+		{name: "error", err: cause, entries: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			useWriter(t, w)
+
+			err := With("path", "/tmp/out").ErrorIf(tt.err, "write failed")
+
+			if len(w.entries) != tt.entries {
+				t.Fatalf("len(w.entries) = %d, want %d", len(w.entries), tt.entries)
+			}
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if err := ErrorIf(nil, "unused"); err != nil {
+					t.Errorf(`ErrorIf(nil, "unused"): %v`, err)
+				}
+				if len(w.entries) != 0 {
+					t.Errorf("w.entries = %v, want empty", w.entries)
+				}
+				return
+			}
+
+			if !errors.Is(err, cause) {
+				t.Errorf("err = %v, want %v", err, cause)
+			}
+			if got := w.last().level; got != LevelError {
+				t.Errorf("w.last().level = %v, want %v", got, LevelError)
+			}
+			if got := w.last().msg; got != "write failed" {
+				t.Errorf("w.last().msg = %q, want %q", got, "write failed")
+			}
+			if got := w.last().fields["path"]; got != "/tmp/out" {
+				t.Errorf(`w.last().fields["path"] = %q, want %q`, got, "/tmp/out")
+			}
+			want := cause.Error()
+			if got := w.last().fields["error"]; got != want {
+				t.Errorf(`the error field is set as by WithError: w.last().fields["error"] = %q, want %q`, got, want)
+			}
+		})
+	}
+}
+
+func TestLogIf(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	old := std.Level()
+	std.SetLevel(LevelDebug)
+	t.Cleanup(func() { std.SetLevel(old) })
+
+	DebugIf(false, "skipped")
+	InfoIf(false, "skipped")
+	With("attempt", 2).InfoIf(false, "skipped")
+
+	DebugIf(true, "cache miss")
+	InfoIf(true, "retrying")
+	With("attempt", 2).DebugIf(true, "backoff")
+
+	if len(w.entries) != 3 {
+		t.Fatalf("len(w.entries) = %d, want 3", len(w.entries))
+	}
+	if w.entries[0].level != LevelDebug {
+		t.Errorf("w.entries[0].level = %v, want %v", w.entries[0].level, LevelDebug)
+	}
+	if w.entries[0].msg != "cache miss" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "cache miss")
+	}
+	if w.entries[1].level != LevelInfo {
+		t.Errorf("w.entries[1].level = %v, want %v", w.entries[1].level, LevelInfo)
+	}
+	if w.entries[1].msg != "retrying" {
+		t.Errorf("w.entries[1].msg = %q, want %q", w.entries[1].msg, "retrying")
+	}
+	if w.entries[2].fields["attempt"] != 2 {
+		t.Errorf(`w.entries[2].fields["attempt"] = %v, want 2`, w.entries[2].fields["attempt"])
+	}
+}
+
+func TestLogScope_Log(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	exited := false
+	SetExitFunc(func(int) { exited = true })
+	t.Cleanup(func() { SetExitFunc(nil) })
+
+	With("source", "agent").Logf(LevelPanic, "forwarded %s", "panic")
+	With("source", "agent").Log(LevelFatal, "forwarded fatal")
+	With("source", "agent").Log(LevelDebug, "below the level")
+
+	if exited {
+		t.Error("exited = true, want false")
+	}
+	if len(w.entries) != 2 {
+		t.Fatalf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	if w.entries[0].level != LevelPanic {
+		t.Errorf("w.entries[0].level = %v, want %v", w.entries[0].level, LevelPanic)
+	}
+	if w.entries[0].msg != "forwarded panic" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "forwarded panic")
+	}
+	if w.entries[1].level != LevelFatal {
+		t.Errorf("w.entries[1].level = %v, want %v", w.entries[1].level, LevelFatal)
+	}
+	if w.flushes != 0 {
+		t.Errorf("w.flushes = %v, want zero", w.flushes)
+	}
+}
+
+func TestFormattedVariants(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func() error
+		want string
+	}{
+		{name: "plain", log: func() error { Info("disk 95% full"); return nil }, want: "disk 95% full"},
+		{name: "plain-scope", log: func() error { With("disk", "sda").Warn("100%s"); return nil }, want: "100%s"},
+		{name: "plain-logger", log: func() error { std.Debug("%d%%"); return nil }, want: "%d%%"},
+		{name: "formatted", log: func() error { Infof("disk %d%% full", 95); return nil }, want: "disk 95% full"},
+		{name: "formatted-no-args", log: func() error { With("disk", "sda").Warnf("100%%"); return nil }, want: "100%"},
+		{name: "formatted-logger", log: func() error { std.Debugf("%s", "ok"); return nil }, want: "ok"},
+		{name: "plain-error", log: func() error { return Error("50% of the shards failed") }, want: "50% of the shards failed"},
+		{name: "formatted-error", log: func() error { return Errorf("%d shards failed", 2) }, want: "2 shards failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			useWriter(t, w)
+			std.SetLevel(LevelDebug)
+			t.Cleanup(func() { std.SetLevel(LevelInfo) })
+
+			err := tt.log()
+
+			if len(w.entries) != 1 {
+				t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+			}
+			if got := w.last().msg; got != tt.want {
+				t.Errorf("w.last().msg = %q, want %q", got, tt.want)
+			}
+			if err != nil {
+				if err == nil || err.Error() != tt.want {
+					t.Errorf("the returned error has the same message: err = %v, want %q", err, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// formatCounter counts the times it is formatted.
+type formatCounter struct{ n int }
+
+func (c *formatCounter) String() string {
+	c.n++
+	return "shard"
+}
+
+func TestFormattedVariants_Disabled(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	std.SetLevel(LevelFatal)
+	t.Cleanup(func() { std.SetLevel(LevelInfo) })
+
+	arg := &formatCounter{}
+	err := Errorf("%s failed", arg)
+
+	if err == nil || err.Error() != "shard failed" {
+		t.Errorf("err = %v, want %q", err, "shard failed")
+	}
+	if arg.n != 1 {
+		t.Errorf("the message is formatted once: arg.n = %v, want 1", arg.n)
+	}
+
+	if r := recovered(func() { Panicf("%s failed", arg) }); r != "shard failed" {
+		t.Errorf("recovered = %v, want %q", r, "shard failed")
+	}
+	if arg.n != 2 {
+		t.Errorf("the message is formatted once: arg.n = %v, want 2", arg.n)
+	}
+	if len(w.entries) != 0 {
+		t.Errorf("w.entries = %v, want empty", w.entries)
+	}
 }
 
 func TestParseLevel(t *testing.T) {
@@ -22,6 +295,11 @@ func TestParseLevel(t *testing.T) {
 		input    string
 		expected int
 	}{
+		{
+			name:     "parse trace level",
+			input:    "Trace",
+			expected: LevelTrace,
+		},
 		{
 			name:     "parse debug level",
 			input:    "debug",
@@ -32,6 +310,11 @@ func TestParseLevel(t *testing.T) {
 			input:    "INFO",
 			expected: LevelInfo,
 		},
+		{
+			name:     "parse warn level",
+			input:    "warn",
+			expected: LevelWarn,
+		},
 		{
 			name:     "parse error level",
 			input:    "Error",
@@ -52,7 +335,9 @@ func TestParseLevel(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := ParseLevel(tt.input)
-			assert.Equal(t, tt.expected, result)
+			if result != tt.expected {
+				t.Errorf("result = %v, want %v", result, tt.expected)
+			}
 		})
 	}
 }
@@ -63,6 +348,11 @@ func TestLevelString(t *testing.T) {
 		input    int
 		expected string
 	}{
+		{
+			name:     "trace level string",
+			input:    LevelTrace,
+			expected: "TRACE",
+		},
 		{
 			name:     "debug level string",
 			input:    LevelDebug,
@@ -73,6 +363,11 @@ func TestLevelString(t *testing.T) {
 			input:    LevelInfo,
 			expected: "INFO",
 		},
+		{
+			name:     "warn level string",
+			input:    LevelWarn,
+			expected: "WARN",
+		},
 		{
 			name:     "error level string",
 			input:    LevelError,
@@ -88,36 +383,46 @@ func TestLevelString(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			result := LevelString(tt.input)
-			assert.Equal(t, tt.expected, result)
+			if result != tt.expected {
+				t.Errorf("result = %q, want %q", result, tt.expected)
+			}
 		})
 	}
 }
 
 func TestSetMinLevel(t *testing.T) {
-	// Save original minLevel
-	originalMinLevel := minLevel
+	// Save the original minimum level
+	originalMinLevel := std.Level()
 
 	// Test valid levels
 	SetLevel(LevelDebug)
-	assert.Equal(t, LevelDebug, minLevel)
+	if got := std.Level(); got != LevelDebug {
+		t.Errorf("std.Level() = %v, want %v", got, LevelDebug)
+	}
 
 	SetLevel(LevelInfo)
-	assert.Equal(t, LevelInfo, minLevel)
+	if got := std.Level(); got != LevelInfo {
+		t.Errorf("std.Level() = %v, want %v", got, LevelInfo)
+	}
 
 	SetLevel(LevelError)
-	assert.Equal(t, LevelError, minLevel)
+	if got := std.Level(); got != LevelError {
+		t.Errorf("std.Level() = %v, want %v", got, LevelError)
+	}
 
 	// Test invalid level
 	SetLevel(999)
-	assert.Equal(t, LevelError, minLevel) // Should not change
+	if got := std.Level(); got != LevelError {
+		t.Errorf("std.Level() = %v, want %v", got, LevelError)
+	} // Should not change
 
-	// Restore original minLevel
-	minLevel = originalMinLevel
+	// Restore the original minimum level
+	std.SetLevel(originalMinLevel)
 }
 
 func TestShouldLog(t *testing.T) {
-	// Save original minLevel
-	originalMinLevel := minLevel
+	// Save the original minimum level
+	originalMinLevel := std.Level()
 
 	tests := []struct {
 		name     string
@@ -125,6 +430,18 @@ func TestShouldLog(t *testing.T) {
 		level    int
 		expected bool
 	}{
+		{
+			name:     "trace level with debug min",
+			minLevel: LevelDebug,
+			level:    LevelTrace,
+			expected: false,
+		},
+		{
+			name:     "trace level with trace min",
+			minLevel: LevelTrace,
+			level:    LevelTrace,
+			expected: true,
+		},
 		{
 			name:     "debug level with debug min",
 			minLevel: LevelDebug,
@@ -143,6 +460,18 @@ func TestShouldLog(t *testing.T) {
 			level:    LevelInfo,
 			expected: true,
 		},
+		{
+			name:     "info level with warn min",
+			minLevel: LevelWarn,
+			level:    LevelInfo,
+			expected: false,
+		},
+		{
+			name:     "warn level with info min",
+			minLevel: LevelInfo,
+			level:    LevelWarn,
+			expected: true,
+		},
 		{
 			name:     "error level with info min",
 			minLevel: LevelInfo,
@@ -159,14 +488,514 @@ func TestShouldLog(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			minLevel = tt.minLevel
-			result := shouldLog(tt.level)
-			assert.Equal(t, tt.expected, result)
+			std.SetLevel(tt.minLevel)
+			result := std.Enabled(tt.level)
+			// levels removed with golog_max_level_* build tags are never logged
+			want := tt.expected && tt.level >= maxLevel
+			if result != want {
+				t.Errorf("result = %v, want %v", result, want)
+			}
+		})
+	}
+
+	// Restore the original minimum level
+	std.SetLevel(originalMinLevel)
+}
+
+func TestMaxLevel(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	originalMinLevel := std.Level()
+	SetLevel(LevelTrace)
+	t.Cleanup(func() { std.SetLevel(originalMinLevel) })
+
+	Trace("trace")
+	With("k", "v").Trace("scoped trace")
+	Debug("debug")
+	With("k", "v").Debug("scoped debug")
+	Info("info")
+	With("k", "v").Info("scoped info")
+	Warn("warn")
+	With("k", "v").Warn("scoped warn")
+	_ = Error("error")
+
+	var messages []string
+	for _, entry := range w.entries {
+		messages = append(messages, entry.msg)
+	}
+
+	expected := map[int][]string{
+		LevelTrace: {"trace", "scoped trace", "debug", "scoped debug", "info", "scoped info", "warn", "scoped warn", "error"},
+		LevelDebug: {"debug", "scoped debug", "info", "scoped info", "warn", "scoped warn", "error"},
+		LevelInfo:  {"info", "scoped info", "warn", "scoped warn", "error"},
+		LevelWarn:  {"warn", "scoped warn", "error"},
+		LevelError: {"error"},
+	}
+	if !reflect.DeepEqual(messages, expected[maxLevel]) {
+		t.Errorf("messages = %v, want %v", messages, expected[maxLevel])
+	}
+}
+
+// captureWriter is a LogWriter that records every entry it receives.
+type captureWriter struct {
+	mu      sync.Mutex
+	entries []capturedEntry
+	flushes int
+}
+
+type capturedEntry struct {
+	level  int
+	msg    string
+	fields map[string]any
+}
+
+func (w *captureWriter) Write(level int, msg string, fields map[string]any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	copied := make(map[string]any, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+
+	w.entries = append(w.entries, capturedEntry{level: level, msg: msg, fields: copied})
+}
+
+func (w *captureWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.flushes++
+}
+
+func (w *captureWriter) last() capturedEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.entries[len(w.entries)-1]
+}
+
+// useWriter installs w as the global writer for the duration of the test.
+func useWriter(t *testing.T, w LogWriter) {
+	t.Helper()
+
+	old := std.Writer()
+	std.SetWriter(w)
+	t.Cleanup(func() { std.SetWriter(old) })
+}
+
+// recovered calls f and returns the value it panics with, or nil.
+func recovered(f func()) (r any) {
+	defer func() { r = recover() }()
+	f()
+
+	return nil
+}
+
+// eventually polls cond until it holds, failing the test after a second.
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// resetEnrichers clears the registered enrichers for the duration of the test.
+func resetEnrichers(t *testing.T) {
+	t.Helper()
+
+	old := std.registeredEnrichers()
+	std.update(func(cfg *loggerConfig) { cfg.enrichers = nil })
+	t.Cleanup(func() { std.update(func(cfg *loggerConfig) { cfg.enrichers = old }) })
+}
+
+func TestRegisterEnricher(t *testing.T) {
+	resetEnrichers(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	before := With("scope", "old")
+
+	RegisterEnricher(EnricherFunc(func(ctx context.Context, level, msg string, fields map[string]any) {
+		fields["first"] = true
+	}))
+	RegisterEnricher(EnricherFunc(func(ctx context.Context, level, msg string, fields map[string]any) {
+		fields["second"] = fields["first"]
+	}))
+
+	Info("after registration")
+	want := map[string]any{"first": true, "second": true}
+	if got := w.last().fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("w.last().fields = %v, want %v", got, want)
+	}
+
+	before.Info("scope created before registration")
+	if got, want := w.last().fields, map[string]any{"scope": "old"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("w.last().fields = %v, want %v", got, want)
+	}
+}
+
+func TestRegisterEnricher_Concurrent(t *testing.T) {
+	resetEnrichers(t)
+	useWriter(t, &captureWriter{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterEnricher(EnricherFunc(func(ctx context.Context, level, msg string, fields map[string]any) {}))
+		}()
+		go func() {
+			defer wg.Done()
+			Info("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	if got := std.registeredEnrichers(); len(got) != 8 {
+		t.Errorf("len(std.registeredEnrichers()) = %d, want 8", len(got))
+	}
+}
+
+func TestLogScope_ChildScopes(t *testing.T) {
+	resetEnrichers(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	RegisterEnricher(EnricherFunc(func(ctx context.Context, level, msg string, fields map[string]any) {
+		fields["enriched"] = true
+	}))
+
+	parent := With("request_id", "abc")
+
+	tests := []struct {
+		name  string
+		child *LogScope
+		want  map[string]any
+	}{
+		{
+			name:  "with",
+			child: parent.With("user_id", 1),
+			want:  map[string]any{"request_id": "abc", "user_id": 1, "enriched": true},
+		},
+		{
+			name:  "with-fields",
+			child: parent.WithFields(map[string]any{"request_id": "def", "step": 2}),
+			want:  map[string]any{"request_id": "def", "step": 2, "enriched": true},
+		},
+		{
+			name:  "with-error",
+			child: parent.WithError(errors.New("boom")),
+			want:  map[string]any{"request_id": "abc", "error": "boom", "enriched": true},
+		},
+		{
+			name:  "with-retention",
+			child: parent.WithRetention("audit"),
+			want:  map[string]any{"request_id": "abc", FieldRetention: "audit", "enriched": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.child.Info("child")
+			if got := w.last().fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("w.last().fields = %v, want %v", got, tt.want)
+			}
+
+			parent.Info("parent")
+			if got, want := w.last().fields, map[string]any{"request_id": "abc", "enriched": true}; !reflect.DeepEqual(got, want) {
+				t.Errorf("parent unchanged: w.last().fields = %v, want %v", got, want)
+			}
 		})
 	}
+}
+
+func TestLogScope_SharedAcrossGoroutines(t *testing.T) {
+	resetEnrichers(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	RegisterEnricher(EnricherFunc(func(ctx context.Context, level, msg string, fields map[string]any) {
+		fields["enriched"] = true
+	}))
+
+	shared := With("request_id", "abc")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			shared.With("worker", i).Info("working")
+			shared.Info("shared")
+		}()
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.entries) != 16 {
+		t.Fatalf("len(w.entries) = %d, want 16", len(w.entries))
+	}
+	for _, entry := range w.entries {
+		if entry.msg == "shared" {
+			if _, ok := entry.fields["worker"]; ok {
+				t.Errorf("entry.fields has key %q, want none", "worker")
+			}
+		}
+	}
+}
+
+func TestScope_ResolvesWriterAtWriteTime(t *testing.T) {
+	first := &captureWriter{}
+	useWriter(t, first)
+
+	scope := With("request_id", "abc")
+
+	second := &captureWriter{}
+	SetWriter(second)
+
+	scope.Info("after SetWriter")
+	Flush()
+
+	if len(first.entries) != 0 {
+		t.Errorf("first.entries = %v, want empty", first.entries)
+	}
+	if len(second.entries) != 1 {
+		t.Errorf("len(second.entries) = %d, want 1", len(second.entries))
+	}
+	if got := second.last().fields["request_id"]; got != "abc" {
+		t.Errorf(`second.last().fields["request_id"] = %q, want %q`, got, "abc")
+	}
+	if second.flushes != 1 {
+		t.Errorf("second.flushes = %v, want 1", second.flushes)
+	}
+}
+
+func TestWithWriter(t *testing.T) {
+	global := &captureWriter{}
+	useWriter(t, global)
+
+	tenant := &captureWriter{}
+	scope := WithWriter(tenant).With("tenant", "acme")
+	scope.Info("diverted")
+
+	ctx := NewContext(context.Background(), scope)
+	FromContext(ctx).Debug("below the level")
+	FromContext(ctx).Error("from context")
+
+	Info("global")
+
+	if len(tenant.entries) != 2 {
+		t.Fatalf("len(tenant.entries) = %d, want 2", len(tenant.entries))
+	}
+	if tenant.entries[0].msg != "diverted" {
+		t.Errorf("tenant.entries[0].msg = %q, want %q", tenant.entries[0].msg, "diverted")
+	}
+	if tenant.entries[1].msg != "from context" {
+		t.Errorf("tenant.entries[1].msg = %q, want %q", tenant.entries[1].msg, "from context")
+	}
+	if tenant.entries[1].fields["tenant"] != "acme" {
+		t.Errorf(`tenant.entries[1].fields["tenant"] = %q, want %q`, tenant.entries[1].fields["tenant"], "acme")
+	}
+
+	if len(global.entries) != 1 {
+		t.Fatalf("len(global.entries) = %d, want 1", len(global.entries))
+	}
+	if got := global.last().msg; got != "global" {
+		t.Errorf("global.last().msg = %q, want %q", got, "global")
+	}
+}
+
+func TestWithLevel(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	WithLevel(LevelDebug).With("request_id", "abc").Debug("debugging one request")
+	Debug("other requests")
+	std.WithLevel(LevelError).Info("quieted")
+	WithLevel(42).Debug("unknown level ignored")
+
+	ctx := IntoContext(context.Background(), WithLevel(LevelTrace))
+	FromContext(ctx).Trace("from context")
+
+	filtered := &captureWriter{}
+	WithWriter(LevelFilter(filtered, LevelInfo)).WithLevel(LevelDebug).Debug("below the level of the writer")
+
+	if len(w.entries) != 2 {
+		t.Fatalf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	if w.entries[0].msg != "debugging one request" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "debugging one request")
+	}
+	if w.entries[1].msg != "from context" {
+		t.Errorf("the level is kept in the context: w.entries[1].msg = %q, want %q", w.entries[1].msg, "from context")
+	}
+	if len(filtered.entries) != 0 {
+		t.Errorf("the writer keeps its level: filtered.entries = %v, want empty", filtered.entries)
+	}
+}
+
+func TestFlushOnDone(t *testing.T) {
+	global := &captureWriter{}
+	useWriter(t, global)
+
+	tenant := &captureWriter{}
+	ctx, cancel := context.WithCancel(context.Background())
+	WithContext(ctx).WithWriter(tenant).FlushOnDone().Info("handled")
+	WithContext(context.Background()).FlushOnDone().Info("never done")
+
+	global.mu.Lock()
+	if global.flushes != 0 {
+		t.Errorf("global.flushes = %v, want zero", global.flushes)
+	}
+	global.mu.Unlock()
+
+	cancel()
+
+	eventually(t, func() bool {
+		global.mu.Lock()
+		defer global.mu.Unlock()
+		tenant.mu.Lock()
+		defer tenant.mu.Unlock()
+
+		return global.flushes == 1 && tenant.flushes == 1
+	})
+}
+
+// closableOutput is an output that, like a file, fails writes once closed.
+// It is safe for concurrent use.
+type closableOutput struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (o *closableOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.closed {
+		return 0, os.ErrClosed
+	}
+
+	return o.buf.Write(p)
+}
+
+func (o *closableOutput) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.closed = true
+	return nil
+}
 
-	// Restore original minLevel
-	minLevel = originalMinLevel
+func (o *closableOutput) String() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.buf.String()
+}
+
+// lockedWriter serializes the calls to a writer that is not safe for
+// concurrent use, such as the JSON writer flushed from FlushOnDone.
+type lockedWriter struct {
+	mu     sync.Mutex
+	writer LogWriter
+}
+
+func (w *lockedWriter) Write(level int, msg string, fields map[string]any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writer.Write(level, msg, fields)
+}
+
+func (w *lockedWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.writer.Flush()
+}
+
+func (w *lockedWriter) flushBuffers() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	flushBuffers(w.writer)
+}
+
+func TestFlushOnDone_KeepsOutputOpen(t *testing.T) {
+	useWriter(t, &captureWriter{})
+
+	out := &closableOutput{}
+	writer := &lockedWriter{writer: NewJSONWriter(out)}
+	for _, msg := range []string{"first request", "second request"} {
+		ctx, cancel := context.WithCancel(context.Background())
+		WithContext(ctx).WithWriter(writer).FlushOnDone().Info(msg)
+		cancel()
+
+		eventually(t, func() bool { return strings.Contains(out.String(), msg) })
+	}
+
+	out.mu.Lock()
+	defer out.mu.Unlock()
+	if out.closed {
+		t.Error("out.closed = true, want false")
+	}
+}
+
+func TestWithTime(t *testing.T) {
+	eventTime := time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		writer func(buf *bytes.Buffer) LogWriter
+		verify func(t *testing.T, output string)
+	}{
+		{
+			name:   "json-writer",
+			writer: func(buf *bytes.Buffer) LogWriter { return NewJSONWriter(buf) },
+			verify: func(t *testing.T, output string) {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(output), &entry); err != nil {
+					t.Errorf("json.Unmarshal([]byte(output), &entry): %v", err)
+				}
+				if entry[FieldTime] != "2024-03-30T12:34:56Z" {
+					t.Errorf("entry[FieldTime] = %q, want %q", entry[FieldTime], "2024-03-30T12:34:56Z")
+				}
+			},
+		},
+		{
+			name:   "default-writer",
+			writer: func(buf *bytes.Buffer) LogWriter { return NewDefaultWriter(buf) },
+			verify: func(t *testing.T, output string) {
+				if !strings.Contains(output, "[INFO][2024-03-30T12:34:56Z] replayed") {
+					t.Errorf("output = %q, want it to contain %q", output, "[INFO][2024-03-30T12:34:56Z] replayed")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.writer(buf)
+			useWriter(t, w)
+
+			WithTime(eventTime).With("source", "queue").Info("replayed")
+			w.Flush()
+
+			tt.verify(t, strings.TrimSpace(buf.String()))
+		})
+	}
 }
 
 func ExampleParseLevel() {
@@ -174,19 +1003,19 @@ func ExampleParseLevel() {
 	fmt.Println(ParseLevel("INFO"))
 	fmt.Println(ParseLevel("invalid"))
 	// Output:
-	// 0
 	// 1
+	// 2
 	// -1
 }
 
 func ExampleWithPairs() {
 	buf := &bytes.Buffer{}
-	oldWriter := instance
-	instance = NewDefaultWriter(buf)
-	defer func() { instance = oldWriter }()
+	oldWriter := std.Writer()
+	std.SetWriter(NewDefaultWriter(buf))
+	defer func() { std.SetWriter(oldWriter) }()
 
 	WithPairs("user_id", 123, "action", "login").Info("User logged in")
-	instance.Flush()
+	std.Writer().Flush()
 
 	output := buf.String()
 	if strings.Contains(output, "User logged in") && strings.Contains(output, "user_id") {
@@ -0,0 +1,466 @@
+package golog
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Logger is a logging pipeline: a writer, a minimum level, and enrichers.
+// The package-level functions use the default Logger, configured with
+// SetWriter, SetLevel, and RegisterEnricher; create more Loggers with New to
+// run differently configured pipelines in one process, e.g. an audit log
+// next to the application log.
+//
+// A Logger is safe for concurrent use, including SetWriter, SetLevel, and
+// RegisterEnricher: its configuration is an immutable snapshot replaced
+// atomically, so an entry being written sees either the old or the new
+// configuration, never a mix of both.
+//
+// The number of frames skipped to report the caller is the package setting
+// (see SetSkipFrames) unless the Logger has its own (see LoggerSkipFrames).
+type Logger struct {
+	// config holds the current configuration. It is never modified in
+	// place: the setters replace it with an updated copy (copy-on-write),
+	// so readers can use a loaded snapshot without locking.
+	config atomic.Pointer[loggerConfig]
+	// flags holds the settings overridden by feature flags (see BindFlags)
+	flags atomic.Pointer[flagSettings]
+}
+
+// loggerConfig is a snapshot of the configuration of a Logger.
+type loggerConfig struct {
+	// writer receives the entries
+	writer LogWriter
+	// level is the minimum level written
+	level int
+	// enrichers holds the registered enrichers
+	enrichers []Enricher
+	// hooks holds the hooks added with AddHook, in order
+	hooks []registeredHook
+	// skipFrames is the number of frames skipped to report the caller, or a
+	// negative number to use the package setting
+	skipFrames int
+	// generation counts the changes of the configuration
+	generation uint64
+}
+
+// LoggerOption configures a Logger created by New.
+type LoggerOption func(*Logger)
+
+// LoggerWriter sets the writer of the Logger. The default is a text writer
+// to os.Stderr.
+func LoggerWriter(w LogWriter) LoggerOption {
+	return func(lg *Logger) {
+		lg.SetWriter(w)
+	}
+}
+
+// LoggerLevel sets the minimum level of the Logger. The default is
+// LevelInfo.
+func LoggerLevel(level int) LoggerOption {
+	return func(lg *Logger) {
+		lg.SetLevel(level)
+	}
+}
+
+// LoggerSkipFrames sets the number of frames skipped to report the caller
+// of the entries of the Logger, in place of the package setting (see
+// SetSkipFrames), e.g. for a Logger used through a wrapper of its own. It
+// applies to the built-in writers, which report the caller.
+func LoggerSkipFrames(skip int) LoggerOption {
+	return func(lg *Logger) {
+		lg.SetSkipFrames(skip)
+	}
+}
+
+// LoggerEnrichers registers enrichers with the Logger, in order.
+func LoggerEnrichers(enrichers ...Enricher) LoggerOption {
+	return func(lg *Logger) {
+		for _, enricher := range enrichers {
+			lg.RegisterEnricher(enricher)
+		}
+	}
+}
+
+// std is the default Logger, used by the package-level functions. Until
+// SetWriter is called its writer is the bootstrap writer, which writes
+// synchronously to os.Stderr.
+var std = newLogger(newBootstrapWriter(os.Stderr))
+
+// newLogger returns a Logger writing to w at LevelInfo.
+func newLogger(w LogWriter) *Logger {
+	lg := &Logger{}
+	lg.config.Store(&loggerConfig{writer: w, level: LevelInfo, skipFrames: -1})
+
+	return lg
+}
+
+// New creates a Logger independent of the default Logger and of the other
+// Loggers.
+//
+// Example:
+//
+//	audit := golog.New(
+//	    golog.LoggerWriter(auditWriter),
+//	    golog.LoggerLevel(golog.LevelInfo),
+//	)
+//	audit.With("actor", user.ID).Info("role granted")
+func New(opts ...LoggerOption) *Logger {
+	lg := newLogger(nil)
+	for _, opt := range opts {
+		if opt != nil {
+			opt(lg)
+		}
+	}
+
+	if lg.Writer() == nil {
+		lg.SetWriter(NewDefaultWriter(os.Stderr))
+	}
+
+	return lg
+}
+
+// Default returns the default Logger, used by the package-level functions.
+func Default() *Logger {
+	return std
+}
+
+// Generation returns the number of changes of the configuration of the
+// Logger: its writer, level, enrichers, and hooks. It increases with every change,
+// so tests can tell whether a configuration swap happened.
+func (lg *Logger) Generation() uint64 {
+	return lg.load().generation
+}
+
+// load returns the current configuration of the Logger.
+func (lg *Logger) load() *loggerConfig {
+	return lg.config.Load()
+}
+
+// update replaces the configuration of the Logger with a copy modified by
+// change, incrementing its generation.
+func (lg *Logger) update(change func(cfg *loggerConfig)) {
+	for {
+		old := lg.config.Load()
+
+		next := *old
+		change(&next)
+		next.generation++
+
+		if lg.config.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// Writer returns the writer of the Logger.
+func (lg *Logger) Writer() LogWriter {
+	return lg.load().writer
+}
+
+// SetWriter sets the writer of the Logger. Existing scopes write to the new
+// writer from their next entry on; flush the previous writer yourself if it
+// buffers output.
+func (lg *Logger) SetWriter(w LogWriter) {
+	lg.update(func(cfg *loggerConfig) {
+		cfg.writer = w
+	})
+}
+
+// SkipFrames returns the number of frames skipped to report the caller of
+// the entries of the Logger.
+func (lg *Logger) SkipFrames() int {
+	return lg.load().skip()
+}
+
+// SetSkipFrames sets the number of frames skipped to report the caller of
+// the entries of the Logger, in place of the package setting (see
+// LoggerSkipFrames). A negative skip restores the package setting.
+func (lg *Logger) SetSkipFrames(skip int) {
+	lg.update(func(cfg *loggerConfig) {
+		cfg.skipFrames = max(skip, -1)
+	})
+}
+
+// skip returns the number of frames skipped to report the caller with the
+// configuration cfg.
+func (cfg *loggerConfig) skip() int {
+	if cfg.skipFrames < 0 {
+		return skipFrames
+	}
+
+	return cfg.skipFrames
+}
+
+// Level returns the minimum level of the Logger, the level of the flag
+// bound with FlagLevel while it is set.
+func (lg *Logger) Level() int {
+	return lg.levelOf(lg.load())
+}
+
+// levelOf returns the minimum level of cfg, or of the flag bound with
+// FlagLevel while it is set.
+func (lg *Logger) levelOf(cfg *loggerConfig) int {
+	if s := lg.flags.Load(); s != nil && s.level >= 0 {
+		return s.level
+	}
+
+	return cfg.level
+}
+
+// SetLevel sets the minimum level of the Logger. Unknown levels are
+// ignored. Levels removed at compile time with build tags stay removed.
+func (lg *Logger) SetLevel(level int) {
+	if _, ok := levelNames[level]; ok {
+		lg.update(func(cfg *loggerConfig) {
+			cfg.level = level
+		})
+	}
+}
+
+// Enabled reports whether entries at level are written to at least one of
+// the writers of the Logger, with the level of the Logger or of the writers
+// (see LevelFilter), e.g. to skip building expensive fields, or for bridges
+// from other logging libraries.
+func (lg *Logger) Enabled(level int) bool {
+	return lg.enabledIn(lg.load(), level)
+}
+
+// enabledIn reports whether entries at level are written with the
+// configuration cfg.
+func (lg *Logger) enabledIn(cfg *loggerConfig, level int) bool {
+	return enabledAt(level, writerMinLevel(cfg.writer, lg.levelOf(cfg)))
+}
+
+// enabledAt reports whether entries at level are written when the minimum
+// level is minLevel.
+func enabledAt(level, minLevel int) bool {
+	if _, ok := levelNames[level]; !ok {
+		return false
+	}
+
+	return level >= maxLevel && level >= minLevel
+}
+
+// RegisterEnricher adds an enricher to the Logger. Enrichers are called in
+// the order they are registered. It is safe to call concurrently with
+// logging: each LogScope takes a snapshot of the enrichers when it is
+// created, so an enricher registered later only applies to scopes created
+// afterwards.
+func (lg *Logger) RegisterEnricher(enricher Enricher) {
+	lg.update(func(cfg *loggerConfig) {
+		next := make([]Enricher, 0, len(cfg.enrichers)+1)
+		next = append(next, cfg.enrichers...)
+		cfg.enrichers = append(next, enricher)
+	})
+}
+
+// registeredEnrichers returns the current snapshot of registered enrichers.
+// The returned slice must not be modified.
+func (lg *Logger) registeredEnrichers() []Enricher {
+	return lg.load().enrichers
+}
+
+// newScope creates a new LogScope writing through the Logger.
+// It uses a snapshot of the registered enrichers and an empty fields map.
+func (lg *Logger) newScope() *LogScope {
+	return &LogScope{
+		logger:    lg,
+		enrichers: lg.registeredEnrichers(),
+		fields:    make(map[string]any),
+		ctx:       context.Background(),
+	}
+}
+
+// With creates a new LogScope with a single key-value field.
+func (lg *Logger) With(key string, value any) *LogScope {
+	return lg.newScope().With(key, value)
+}
+
+// WithFields creates a new LogScope with multiple fields.
+func (lg *Logger) WithFields(fields map[string]any) *LogScope {
+	return lg.newScope().WithFields(fields)
+}
+
+// WithContext creates a new LogScope with the given context.
+func (lg *Logger) WithContext(ctx context.Context) *LogScope {
+	return lg.newScope().WithContext(ctx)
+}
+
+// WithError creates a new LogScope with an error field.
+func (lg *Logger) WithError(err error) *LogScope {
+	return lg.newScope().WithError(err)
+}
+
+// WithReturnedError creates a new LogScope with the fields carried by err,
+// as returned by Error, and the error field (see LogScope.WithReturnedError).
+func (lg *Logger) WithReturnedError(err error) *LogScope {
+	return lg.newScope().WithReturnedError(err)
+}
+
+// WithWriter creates a new LogScope whose entries go to w instead of the
+// writer of the Logger.
+func (lg *Logger) WithWriter(w LogWriter) *LogScope {
+	return lg.newScope().WithWriter(w)
+}
+
+// WithTime creates a new LogScope whose entries carry t as their time.
+func (lg *Logger) WithTime(t time.Time) *LogScope {
+	return lg.newScope().WithTime(t)
+}
+
+// WithLevel creates a new LogScope whose entries are written at or above
+// level, in place of the level of the Logger (see LogScope.WithLevel).
+func (lg *Logger) WithLevel(level int) *LogScope {
+	return lg.newScope().WithLevel(level)
+}
+
+// Trace logs a message at the trace level. The message is not formatted.
+func (lg *Logger) Trace(msg string) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	lg.newScope().Trace(msg)
+}
+
+// Tracef logs a message at the trace level, formatted with fmt.Sprintf.
+func (lg *Logger) Tracef(format string, args ...any) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	lg.newScope().Tracef(format, args...)
+}
+
+// Debug logs a message at the debug level. The message is not formatted.
+func (lg *Logger) Debug(msg string) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	lg.newScope().Debug(msg)
+}
+
+// Debugf logs a message at the debug level, formatted with fmt.Sprintf.
+func (lg *Logger) Debugf(format string, args ...any) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	lg.newScope().Debugf(format, args...)
+}
+
+// Info logs a message at the info level. The message is not formatted.
+func (lg *Logger) Info(msg string) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	lg.newScope().Info(msg)
+}
+
+// Infof logs a message at the info level, formatted with fmt.Sprintf.
+func (lg *Logger) Infof(format string, args ...any) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	lg.newScope().Infof(format, args...)
+}
+
+// Warn logs a message at the warn level. The message is not formatted.
+func (lg *Logger) Warn(msg string) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	lg.newScope().Warn(msg)
+}
+
+// Warnf logs a message at the warn level, formatted with fmt.Sprintf.
+func (lg *Logger) Warnf(format string, args ...any) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	lg.newScope().Warnf(format, args...)
+}
+
+// Error logs a message at the error level and returns an error for
+// propagation (see LogScope.Error). The message is not formatted.
+func (lg *Logger) Error(msg string) error {
+	return lg.newScope().Error(msg)
+}
+
+// Errorf logs a message at the error level, formatted with fmt.Sprintf, and
+// returns an error for propagation (see LogScope.Error).
+func (lg *Logger) Errorf(format string, args ...any) error {
+	return lg.newScope().Errorf(format, args...)
+}
+
+// ErrorIf logs a message at the error level, with err in the error field, and
+// returns an error wrapping err, when err is not nil. It does nothing and
+// returns nil when err is nil. The message is not formatted.
+func (lg *Logger) ErrorIf(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return lg.newScope().ErrorIf(err, msg)
+}
+
+// Panic logs a message at the panic level, flushes the writer, and panics
+// with the message. The message is not formatted.
+func (lg *Logger) Panic(msg string) {
+	lg.newScope().Panic(msg)
+}
+
+// Panicf logs a message at the panic level, formatted with fmt.Sprintf,
+// flushes the writer, and panics with the formatted message.
+func (lg *Logger) Panicf(format string, args ...any) {
+	lg.newScope().Panicf(format, args...)
+}
+
+// Fatal logs a message at the fatal level, flushes the writer, and exits the
+// process with status 1 (see SetExitFunc). The message is not formatted.
+func (lg *Logger) Fatal(msg string) {
+	lg.newScope().Fatal(msg)
+}
+
+// Fatalf logs a message at the fatal level, formatted with fmt.Sprintf,
+// flushes the writer, and exits the process with status 1.
+func (lg *Logger) Fatalf(format string, args ...any) {
+	lg.newScope().Fatalf(format, args...)
+}
+
+// Flush writes the report of dropped entries and the summaries of closed
+// silence windows, if any, and flushes the writer of the Logger, and the
+// writer of the degradation profile (see SetDegradationProfile).
+func (lg *Logger) Flush() {
+	lg.flush(LogWriter.Flush)
+}
+
+// flushBuffers is like Flush but keeps the outputs of the console writers
+// open.
+func (lg *Logger) flushBuffers() {
+	lg.flush(flushBuffers)
+}
+
+// flush writes the reports of Flush and flushes the writer of the Logger
+// with flush.
+func (lg *Logger) flush(flush func(LogWriter)) {
+	writer := lg.Writer()
+
+	reports := writer
+	if fallback := degradationWriter(); fallback != nil {
+		reports = fallback
+		defer flush(fallback)
+	}
+
+	reportDropped(reports, true)
+	reportSilenced(reports)
+	flush(writer)
+}
@@ -0,0 +1,223 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	global := &captureWriter{}
+	useWriter(t, global)
+
+	audit := &captureWriter{}
+	lg := New(
+		LoggerWriter(audit),
+		LoggerLevel(LevelDebug),
+		LoggerEnrichers(EnricherFunc(func(_ context.Context, _, _ string, fields map[string]any) {
+			fields["log"] = "audit"
+		})),
+	)
+
+	lg.Debug("debug entry")
+	lg.With("actor", "u-1").Info("role granted")
+	err := lg.WithFields(map[string]any{"role": "admin"}).Error("grant failed")
+	Debug("global debug entry")
+	Info("global entry")
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if len(audit.entries) != 3 {
+		t.Fatalf("len(audit.entries) = %d, want 3", len(audit.entries))
+	}
+	if audit.entries[0].msg != "debug entry" {
+		t.Errorf("audit.entries[0].msg = %q, want %q", audit.entries[0].msg, "debug entry")
+	}
+	want := map[string]any{"actor": "u-1", "log": "audit"}
+	if !reflect.DeepEqual(audit.entries[1].fields, want) {
+		t.Errorf("audit.entries[1].fields = %v, want %v", audit.entries[1].fields, want)
+	}
+	if got := audit.last().level; got != LevelError {
+		t.Errorf("audit.last().level = %v, want %v", got, LevelError)
+	}
+
+	if len(global.entries) != 1 {
+		t.Fatalf("the default Logger is unaffected: len(global.entries) = %d, want 1", len(global.entries))
+	}
+	if got := global.last().msg; got != "global entry" {
+		t.Errorf("global.last().msg = %q, want %q", got, "global entry")
+	}
+	if _, ok := global.last().fields["log"]; ok {
+		t.Errorf("global.last().fields has key %q, want none", "log")
+	}
+
+	lg.Flush()
+	if audit.flushes != 1 {
+		t.Errorf("audit.flushes = %v, want 1", audit.flushes)
+	}
+	if global.flushes != 0 {
+		t.Errorf("global.flushes = %v, want 0", global.flushes)
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	lg := New()
+
+	if got := lg.Level(); got != LevelInfo {
+		t.Errorf("lg.Level() = %v, want %v", got, LevelInfo)
+	}
+	if _, ok := lg.Writer().(*defaultWriter); !ok {
+		t.Errorf("lg.Writer() = %T, want *defaultWriter", lg.Writer())
+	}
+	if got := Default(); got != std {
+		t.Errorf("Default() = %p, want %p", got, std)
+	}
+
+	lg.SetLevel(999)
+	if got := lg.Level(); got != LevelInfo {
+		t.Errorf("unknown levels are ignored: lg.Level() = %v, want %v", got, LevelInfo)
+	}
+}
+
+func TestLogger_Generation(t *testing.T) {
+	lg := New()
+	start := lg.Generation()
+
+	lg.SetLevel(LevelDebug)
+	want := start + 1
+	if got := lg.Generation(); got != want {
+		t.Errorf("lg.Generation() = %v, want %v", got, want)
+	}
+
+	lg.SetLevel(999)
+	if got, want := lg.Generation(), start+1; got != want {
+		t.Errorf("ignored changes keep the generation: lg.Generation() = %v, want %v", got, want)
+	}
+
+	lg.SetWriter(&captureWriter{})
+	lg.RegisterEnricher(EnricherFunc(func(context.Context, string, string, map[string]any) {}))
+	if got, want := lg.Generation(), start+3; got != want {
+		t.Errorf("lg.Generation() = %v, want %v", got, want)
+	}
+}
+
+func TestLogger_ConsistentSnapshot(t *testing.T) {
+	verbose, quiet := &captureWriter{}, &captureWriter{}
+	lg := New(LoggerWriter(verbose), LoggerLevel(LevelDebug))
+	start := lg.Generation()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for i := range 1000 {
+			// the quiet writer only ever runs with the error level
+			lg.update(func(cfg *loggerConfig) {
+				cfg.writer, cfg.level = quiet, LevelError
+				if i%2 == 1 {
+					cfg.writer, cfg.level = verbose, LevelDebug
+				}
+			})
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+					lg.Debug("debug entry")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	quiet.mu.Lock()
+	defer quiet.mu.Unlock()
+
+	if len(quiet.entries) != 0 {
+		t.Errorf("no entry mixes the quiet writer with the debug level: quiet.entries = %v, want empty", quiet.entries)
+	}
+	want := start + 1000
+	if got := lg.Generation(); got != want {
+		t.Errorf("one generation per swap: lg.Generation() = %v, want %v", got, want)
+	}
+}
+
+func TestLogger_SkipFrames(t *testing.T) {
+	own, shared := &bytes.Buffer{}, &bytes.Buffer{}
+	// skip getCallerInfo's caller, scope.write, LogScope.Info, and
+	// Logger.Info, to report this test
+	lg := New(LoggerWriter(NewJSONWriter(own)), LoggerSkipFrames(4))
+	other := New(LoggerWriter(NewJSONWriter(shared)))
+
+	lg.Info("own skip frames")
+	other.Info("package skip frames")
+	lg.Flush()
+	other.Flush()
+
+	if got := lg.SkipFrames(); got != 4 {
+		t.Errorf("lg.SkipFrames() = %v, want 4", got)
+	}
+	want := GetSkipFrames()
+	if got := other.SkipFrames(); got != want {
+		t.Errorf("other.SkipFrames() = %v, want %v", got, want)
+	}
+	if got := own.String(); !strings.Contains(got, `"caller":"logger_test.go:`) {
+		t.Errorf("own.String() = %q, want it to contain %q", got, `"caller":"logger_test.go:`)
+	}
+	if got := shared.String(); !strings.Contains(got, `"caller":"scope.go:`) {
+		t.Errorf("other Loggers keep the package setting: shared.String() = %q, want it to contain %q", got, `"caller":"scope.go:`)
+	}
+
+	lg.SetSkipFrames(-1)
+	if got, want := lg.SkipFrames(), GetSkipFrames(); got != want {
+		t.Errorf("a negative skip restores the package setting: lg.SkipFrames() = %v, want %v", got, want)
+	}
+}
+
+func TestLogger_ScopeShortcuts(t *testing.T) {
+	w, diverted := &captureWriter{}, &captureWriter{}
+	lg := New(LoggerWriter(w))
+
+	if err := lg.ErrorIf(nil, "not logged"); err != nil {
+		t.Errorf(`lg.ErrorIf(nil, "not logged"): %v`, err)
+	}
+
+	cause := errors.New("connection refused")
+	err := lg.With("order_id", "o-1").ErrorIf(cause, "order not saved")
+	if !errors.Is(err, cause) {
+		t.Fatalf("err = %v, want %v", err, cause)
+	}
+
+	lg.WithReturnedError(err).Warn("retrying")
+	lg.WithWriter(diverted).Info("diverted")
+
+	if len(w.entries) != 2 {
+		t.Fatalf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	if w.entries[0].msg != "order not saved" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "order not saved")
+	}
+	if got := w.last().fields["order_id"]; got != "o-1" {
+		t.Errorf(`the fields carried by the error are logged: w.last().fields["order_id"] = %q, want %q`, got, "o-1")
+	}
+	if len(diverted.entries) != 1 {
+		t.Fatalf("len(diverted.entries) = %d, want 1", len(diverted.entries))
+	}
+	if got := diverted.last().msg; got != "diverted" {
+		t.Errorf("diverted.last().msg = %q, want %q", got, "diverted")
+	}
+}
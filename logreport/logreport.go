@@ -0,0 +1,268 @@
+// Package logreport analyzes archives of JSON log lines, as written by
+// golog.NewJSONWriter, to guide log volume reduction. A report lists the
+// level distribution and the top message templates, callers, and loggers by
+// number of entries and bytes, so the noisiest call sites are easy to find.
+//
+// The golog command runs it on files:
+//
+//	golog report -top 20 app.log app.log.1.gz
+//
+// Example:
+//
+//	report, err := logreport.Analyze(file, logreport.Top(20))
+//	if err != nil {
+//	    return err
+//	}
+//
+//	report.WriteText(os.Stdout)
+package logreport
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/jkaveri/golog"
+)
+
+const (
+	// defaultTop is the number of templates, callers, and loggers reported
+	defaultTop = 10
+	// DefaultLoggerField is the field naming the logger of an entry.
+	DefaultLoggerField = "logger"
+)
+
+// Option configures Analyze.
+type Option func(*options)
+
+// options holds the settings of Analyze.
+type options struct {
+	// top is the number of templates, callers, and loggers reported
+	top int
+	// loggerField is the field naming the logger of an entry
+	loggerField string
+}
+
+// Top sets how many message templates, callers, and loggers are reported.
+// The default is 10; zero or less reports all of them.
+func Top(n int) Option {
+	return func(o *options) {
+		o.top = n
+	}
+}
+
+// LoggerField sets the field that names the logger (component, module, ...)
+// of an entry. The default is "logger".
+func LoggerField(key string) Option {
+	return func(o *options) {
+		o.loggerField = key
+	}
+}
+
+// Count is the number of entries and bytes of one level, template, caller,
+// or logger. Bytes include the line terminator.
+type Count struct {
+	Key     string `json:"key"`
+	Entries int    `json:"entries"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// Report summarizes a log archive.
+type Report struct {
+	// Entries is the number of entries analyzed
+	Entries int `json:"entries"`
+	// Bytes is the size of the entries analyzed
+	Bytes int64 `json:"bytes"`
+	// Invalid is the number of non-empty lines that are not JSON objects
+	Invalid int `json:"invalid"`
+	// Levels counts the entries per level, from the lowest level
+	Levels []Count `json:"levels"`
+	// Templates are the most frequent message templates (see
+	// golog.MessageTemplate), most entries first
+	Templates []Count `json:"templates"`
+	// Callers are the most frequent callers, most entries first
+	Callers []Count `json:"callers"`
+	// Loggers are the loggers writing the most bytes, largest first;
+	// entries without a logger field are counted under an empty key
+	Loggers []Count `json:"loggers"`
+}
+
+// counter accumulates counts by key.
+type counter map[string]*Count
+
+// add counts one entry of size bytes for key.
+func (c counter) add(key string, size int64) {
+	count, ok := c[key]
+	if !ok {
+		count = &Count{Key: key}
+		c[key] = count
+	}
+
+	count.Entries++
+	count.Bytes += size
+}
+
+// sorted returns the counts ordered by less, keeping the first top of them
+// when top is positive.
+func (c counter) sorted(top int, less func(a, b Count) bool) []Count {
+	counts := make([]Count, 0, len(c))
+	for _, count := range c {
+		counts = append(counts, *count)
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if less(counts[i], counts[j]) {
+			return true
+		}
+		if less(counts[j], counts[i]) {
+			return false
+		}
+
+		return counts[i].Key < counts[j].Key
+	})
+
+	if top > 0 && len(counts) > top {
+		counts = counts[:top]
+	}
+
+	return counts
+}
+
+// byEntries orders counts by decreasing number of entries.
+func byEntries(a, b Count) bool { return a.Entries > b.Entries }
+
+// byBytes orders counts by decreasing size.
+func byBytes(a, b Count) bool { return a.Bytes > b.Bytes }
+
+// byLevel orders counts by increasing level, unknown levels last.
+func byLevel(a, b Count) bool {
+	return levelRank(a.Key) < levelRank(b.Key)
+}
+
+// levelRank returns the order of a level name; unknown levels sort last.
+func levelRank(name string) int {
+	if level := golog.ParseLevel(name); level >= 0 {
+		return level
+	}
+
+	return math.MaxInt
+}
+
+// Analyze reads JSON log lines from r and summarizes them. CRLF line endings
+// and JSON text sequences (see golog.FramingJSONSeq) are accepted. Lines that
+// are not JSON objects are counted as invalid and skipped. Only read errors are
+// returned.
+func Analyze(r io.Reader, opts ...Option) (*Report, error) {
+	o := options{top: defaultTop, loggerField: DefaultLoggerField}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	report := &Report{}
+	levels, templates, callers, loggers := counter{}, counter{}, counter{}, counter{}
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			size := int64(len(line))
+
+			// records of JSON text sequences (RFC 7464) start with 0x1E
+			record := bytes.TrimPrefix(line, []byte{0x1e})
+
+			var entry map[string]any
+			if jsonErr := json.Unmarshal(record, &entry); jsonErr != nil || entry == nil {
+				if len(bytes.TrimSpace(line)) > 0 {
+					report.Invalid++
+				}
+			} else {
+				report.Entries++
+				report.Bytes += size
+
+				msg, _ := entry[golog.FieldMessage].(string)
+				levels.add(stringField(entry, golog.FieldLevel), size)
+				templates.add(golog.MessageTemplate(msg), size)
+				callers.add(stringField(entry, golog.FieldCaller), size)
+				loggers.add(stringField(entry, o.loggerField), size)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("logreport: read log: %w", err)
+		}
+	}
+
+	report.Levels = levels.sorted(0, byLevel)
+	report.Templates = templates.sorted(o.top, byEntries)
+	report.Callers = callers.sorted(o.top, byEntries)
+	report.Loggers = loggers.sorted(o.top, byBytes)
+
+	return report, nil
+}
+
+// stringField returns the field key of entry as a string.
+func stringField(entry map[string]any, key string) string {
+	switch v := entry[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// WriteText writes the report as aligned tables.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "entries\t%d\n", r.Entries)
+	fmt.Fprintf(tw, "bytes\t%d\n", r.Bytes)
+	if r.Invalid > 0 {
+		fmt.Fprintf(tw, "invalid lines\t%d\n", r.Invalid)
+	}
+
+	sections := []struct {
+		title  string
+		counts []Count
+	}{
+		{title: "LEVEL", counts: r.Levels},
+		{title: "TEMPLATE", counts: r.Templates},
+		{title: "CALLER", counts: r.Callers},
+		{title: "LOGGER", counts: r.Loggers},
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(tw, "\n%s\tENTRIES\tBYTES\tSHARE\n", section.title)
+		for _, count := range section.counts {
+			key := count.Key
+			if key == "" {
+				key = "-"
+			}
+
+			fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\n", key, count.Entries, count.Bytes, r.share(count))
+		}
+	}
+
+	return tw.Flush()
+}
+
+// share returns the percentage of the report's bytes in count.
+func (r *Report) share(count Count) float64 {
+	if r.Bytes == 0 {
+		return 0
+	}
+
+	return float64(count.Bytes) * 100 / float64(r.Bytes)
+}
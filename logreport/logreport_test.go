@@ -0,0 +1,155 @@
+package logreport
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jkaveri/golog"
+)
+
+// archive writes entries with a JSON writer and returns its output.
+func archive(t *testing.T, entries func(w golog.LogWriter)) string {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	w := golog.NewJSONWriter(buf)
+	entries(w)
+	w.Flush()
+
+	return buf.String()
+}
+
+func TestAnalyze(t *testing.T) {
+	input := archive(t, func(w golog.LogWriter) {
+		for i := 0; i < 3; i++ {
+			w.Write(golog.LevelDebug, "cache miss for key 17", map[string]any{"logger": "cache"})
+		}
+		w.Write(golog.LevelInfo, "order 1 paid", map[string]any{"logger": "billing", "amount": 100})
+		w.Write(golog.LevelError, "order 2 failed", nil)
+	})
+	input += "not json\n\n"
+
+	report, err := Analyze(strings.NewReader(input), Top(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(input), "\n")
+	if report.Entries != 5 {
+		t.Errorf("report.Entries = %v, want 5", report.Entries)
+	}
+	want := int64(len(input) - len("not json\n\n"))
+	if report.Bytes != want {
+		t.Errorf("report.Bytes = %v, want %v", report.Bytes, want)
+	}
+	if report.Invalid != 1 {
+		t.Errorf("report.Invalid = %v, want 1", report.Invalid)
+	}
+
+	var keys []string
+	for _, count := range report.Levels {
+		keys = append(keys, count.Key)
+	}
+	if want := []string{"DEBUG", "INFO", "ERROR"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+
+	if len(report.Templates) != 2 {
+		t.Fatalf("len(report.Templates) = %d, want 2", len(report.Templates))
+	}
+	if want := (Count{Key: "cache miss for key <*>", Entries: 3, Bytes: int64(3 * (len(lines[0]) + 1))}); !reflect.DeepEqual(report.Templates[0], want) {
+		t.Errorf("report.Templates[0] = %v, want %v", report.Templates[0], want)
+	}
+	if report.Templates[1].Key != "order <*> failed" {
+		t.Errorf("ties are ordered by key: report.Templates[1].Key = %q, want %q", report.Templates[1].Key, "order <*> failed")
+	}
+
+	if len(report.Callers) != 2 {
+		t.Fatalf("len(report.Callers) = %d, want 2", len(report.Callers))
+	}
+	if report.Callers[0].Entries != 3 {
+		t.Errorf("entries written from one line share a caller: report.Callers[0].Entries = %v, want 3", report.Callers[0].Entries)
+	}
+	if !strings.Contains(report.Callers[0].Key, "logreport_test.go:") {
+		t.Errorf("report.Callers[0].Key = %q, want it to contain %q", report.Callers[0].Key, "logreport_test.go:")
+	}
+
+	if len(report.Loggers) != 2 {
+		t.Fatalf("len(report.Loggers) = %d, want 2", len(report.Loggers))
+	}
+	if report.Loggers[0].Key != "cache" {
+		t.Errorf("report.Loggers[0].Key = %q, want %q", report.Loggers[0].Key, "cache")
+	}
+	if report.Loggers[1].Key != "billing" {
+		t.Errorf("report.Loggers[1].Key = %q, want %q", report.Loggers[1].Key, "billing")
+	}
+}
+
+func TestAnalyze_LoggerField(t *testing.T) {
+	input := `{"msg":"a","component":"db"}` + "\n" + `{"msg":"b"}`
+
+	report, err := Analyze(strings.NewReader(input), LoggerField("component"), Top(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Entries != 2 {
+		t.Errorf("report.Entries = %v, want 2", report.Entries)
+	}
+	want := []Count{
+		{Key: "db", Entries: 1, Bytes: int64(len(`{"msg":"a","component":"db"}`) + 1)},
+		{Key: "", Entries: 1, Bytes: int64(len(`{"msg":"b"}`))},
+	}
+	if !reflect.DeepEqual(report.Loggers, want) {
+		t.Errorf("report.Loggers = %v, want %v", report.Loggers, want)
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	report := &Report{
+		Entries: 2,
+		Bytes:   200,
+		Levels:  []Count{{Key: "INFO", Entries: 2, Bytes: 200}},
+		Loggers: []Count{{Key: "", Entries: 2, Bytes: 200}},
+	}
+
+	buf := &bytes.Buffer{}
+	if err := report.WriteText(buf); err != nil {
+		t.Fatalf("report.WriteText(buf): %v", err)
+	}
+
+	output := buf.String()
+	if !strings.Contains(output, "entries  2\n") {
+		t.Errorf("output = %q, want it to contain %q", output, "entries  2\n")
+	}
+	if !strings.Contains(output, "INFO   2        200    100.0%") {
+		t.Errorf("output = %q, want it to contain %q", output, "INFO   2        200    100.0%")
+	}
+	if !strings.Contains(output, "-       2        200    100.0%") {
+		t.Errorf("output = %q, want it to contain %q", output, "-       2        200    100.0%")
+	}
+	if strings.Contains(output, "invalid") {
+		t.Errorf("output = %q, want it not to contain %q", output, "invalid")
+	}
+}
+
+func TestAnalyze_Framing(t *testing.T) {
+	input := "\x1e" + `{"msg":"seq"}` + "\n" + `{"msg":"crlf"}` + "\r\n"
+
+	report, err := Analyze(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if report.Entries != 2 {
+		t.Errorf("report.Entries = %v, want 2", report.Entries)
+	}
+	if report.Invalid != 0 {
+		t.Errorf("report.Invalid = %v, want zero", report.Invalid)
+	}
+	if report.Bytes != int64(len(input)) {
+		t.Errorf("report.Bytes = %v, want %v", report.Bytes, int64(len(input)))
+	}
+}
@@ -0,0 +1,274 @@
+// Package logschema documents the fields each log event carries. Events are
+// registered with their fields at startup and exported as a JSON Schema
+// document, served over HTTP or written to a file, so downstream consumers
+// and data teams know what to expect without reading the code.
+//
+// An event is identified by its message, the "msg" field of JSON entries.
+//
+// Example:
+//
+//	var orderPaid = logschema.Register("order paid", "An order was paid.",
+//	    logschema.String("order_id", "Order identifier").Require(),
+//	    logschema.Integer("amount", "Amount in cents"),
+//	)
+//
+//	http.Handle("/debug/log-schema", logschema.Handler())
+//
+//	golog.WithPairs("order_id", id, "amount", amount).Info(orderPaid.Name)
+package logschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/jkaveri/golog"
+)
+
+// schemaVersion is the JSON Schema dialect of exported documents.
+const schemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Type is the JSON type of a field value.
+type Type string
+
+// Field value types
+const (
+	TypeString  Type = "string"
+	TypeInteger Type = "integer"
+	TypeNumber  Type = "number"
+	TypeBoolean Type = "boolean"
+	TypeObject  Type = "object"
+	TypeArray   Type = "array"
+)
+
+// Field describes one field of an event.
+type Field struct {
+	// Name is the field key
+	Name string
+	// Type is the JSON type of the value
+	Type Type
+	// Format refines the type, e.g. "date-time" or "duration"
+	Format string
+	// Description explains the field to consumers
+	Description string
+	// Required is set when every entry of the event has the field
+	Required bool
+}
+
+// Require returns f marked as present in every entry of the event.
+func (f Field) Require() Field {
+	f.Required = true
+	return f
+}
+
+// String returns a string field.
+func String(name, description string) Field {
+	return Field{Name: name, Type: TypeString, Description: description}
+}
+
+// Integer returns an integer field.
+func Integer(name, description string) Field {
+	return Field{Name: name, Type: TypeInteger, Description: description}
+}
+
+// Number returns a floating-point number field.
+func Number(name, description string) Field {
+	return Field{Name: name, Type: TypeNumber, Description: description}
+}
+
+// Boolean returns a boolean field.
+func Boolean(name, description string) Field {
+	return Field{Name: name, Type: TypeBoolean, Description: description}
+}
+
+// Time returns a field holding a time.Time, written as an RFC 3339 string.
+func Time(name, description string) Field {
+	return Field{Name: name, Type: TypeString, Format: "date-time", Description: description}
+}
+
+// Object returns a field holding a map or struct.
+func Object(name, description string) Field {
+	return Field{Name: name, Type: TypeObject, Description: description}
+}
+
+// Array returns a field holding a slice.
+func Array(name, description string) Field {
+	return Field{Name: name, Type: TypeArray, Description: description}
+}
+
+// Event describes a log event and its fields.
+type Event struct {
+	// Name is the message of the event's entries
+	Name string
+	// Description explains when the event is logged
+	Description string
+	// Fields are the custom fields of the event's entries
+	Fields []Field
+}
+
+// Registry holds event schemas. It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	events map[string]Event
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{events: make(map[string]Event)}
+}
+
+// defaultRegistry is used by the package-level functions.
+var defaultRegistry = NewRegistry()
+
+// Register adds an event to the registry and returns it. Registering an
+// event again replaces it.
+func (r *Registry) Register(name, description string, fields ...Field) Event {
+	event := Event{Name: name, Description: description, Fields: append([]Field(nil), fields...)}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events[name] = event
+
+	return event
+}
+
+// Events returns the registered events sorted by name.
+func (r *Registry) Events() []Event {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]Event, 0, len(r.events))
+	for _, event := range r.events {
+		events = append(events, event)
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Name < events[j].Name })
+
+	return events
+}
+
+// Document returns the registry as a JSON Schema document. Each event is a
+// definition under "$defs" describing its entries: the standard fields
+// (time, level, msg, caller), with msg fixed to the event name, and the
+// event's fields. The document matches any registered event.
+func (r *Registry) Document() map[string]any {
+	defs := make(map[string]any)
+	var refs []any
+
+	for _, event := range r.Events() {
+		defs[event.Name] = event.schema()
+		refs = append(refs, map[string]any{"$ref": "#/$defs/" + jsonPointerEscape(event.Name)})
+	}
+
+	document := map[string]any{
+		"$schema": schemaVersion,
+		"title":   "Log events",
+		"$defs":   defs,
+	}
+	if len(refs) > 0 {
+		document["oneOf"] = refs
+	}
+
+	return document
+}
+
+// schema returns the JSON Schema of the event's entries.
+func (e Event) schema() map[string]any {
+	properties := map[string]any{
+		golog.FieldTime:    map[string]any{"type": TypeString, "format": "date-time"},
+		golog.FieldLevel:   map[string]any{"type": TypeString},
+		golog.FieldMessage: map[string]any{"const": e.Name},
+		golog.FieldCaller:  map[string]any{"type": TypeString},
+	}
+	required := []string{golog.FieldMessage}
+
+	for _, field := range e.Fields {
+		property := map[string]any{"type": field.Type}
+		if field.Format != "" {
+			property["format"] = field.Format
+		}
+		if field.Description != "" {
+			property["description"] = field.Description
+		}
+
+		properties[field.Name] = property
+		if field.Required {
+			required = append(required, field.Name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":       TypeObject,
+		"properties": properties,
+		"required":   required,
+	}
+	if e.Description != "" {
+		schema["description"] = e.Description
+	}
+
+	return schema
+}
+
+// jsonPointerEscape escapes name for use in a JSON Pointer.
+func jsonPointerEscape(name string) string {
+	var escaped []byte
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '~':
+			escaped = append(escaped, '~', '0')
+		case '/':
+			escaped = append(escaped, '~', '1')
+		default:
+			escaped = append(escaped, name[i])
+		}
+	}
+
+	return string(escaped)
+}
+
+// WriteTo writes the registry's JSON Schema document to w, indented.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(r.Document(), "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("logschema: encode schema: %w", err)
+	}
+
+	n, err := w.Write(append(data, '\n'))
+
+	return int64(n), err
+}
+
+// Handler returns an http.Handler serving the registry's JSON Schema
+// document.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/schema+json")
+		r.WriteTo(w)
+	})
+}
+
+// Register adds an event to the default registry and returns it.
+func Register(name, description string, fields ...Field) Event {
+	return defaultRegistry.Register(name, description, fields...)
+}
+
+// Events returns the events of the default registry sorted by name.
+func Events() []Event {
+	return defaultRegistry.Events()
+}
+
+// WriteTo writes the default registry's JSON Schema document to w, for
+// example from a command line flag that exports it at build time.
+func WriteTo(w io.Writer) (int64, error) {
+	return defaultRegistry.WriteTo(w)
+}
+
+// Handler returns an http.Handler serving the default registry's JSON Schema
+// document.
+func Handler() http.Handler {
+	return defaultRegistry.Handler()
+}
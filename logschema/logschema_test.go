@@ -0,0 +1,91 @@
+package logschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Document(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("order paid", "An order was paid.",
+		String("order_id", "Order identifier").Require(),
+		Integer("amount", "Amount in cents"),
+		Time("paid_at", ""),
+	)
+	registry.Register("cache/miss", "")
+
+	buf := &bytes.Buffer{}
+	_, err := registry.WriteTo(buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var document map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &document); err != nil {
+		t.Fatalf("json.Unmarshal(buf.Bytes(), &document): %v", err)
+	}
+
+	if document["$schema"] != schemaVersion {
+		t.Errorf(`document["$schema"] = %q, want %q`, document["$schema"], schemaVersion)
+	}
+	want := []any{
+		map[string]any{"$ref": "#/$defs/cache~1miss"},
+		map[string]any{"$ref": "#/$defs/order paid"},
+	}
+	if !reflect.DeepEqual(document["oneOf"], want) {
+		t.Errorf(`events are sorted by name: document["oneOf"] = %v, want %v`, document["oneOf"], want)
+	}
+
+	order := document["$defs"].(map[string]any)["order paid"].(map[string]any)
+	if order["description"] != "An order was paid." {
+		t.Errorf(`order["description"] = %q, want %q`, order["description"], "An order was paid.")
+	}
+	if want := []any{"msg", "order_id"}; !reflect.DeepEqual(order["required"], want) {
+		t.Errorf(`order["required"] = %v, want %v`, order["required"], want)
+	}
+
+	properties := order["properties"].(map[string]any)
+	if want := map[string]any{"const": "order paid"}; !reflect.DeepEqual(properties["msg"], want) {
+		t.Errorf(`properties["msg"] = %v, want %v`, properties["msg"], want)
+	}
+	if want := map[string]any{"type": "integer", "description": "Amount in cents"}; !reflect.DeepEqual(properties["amount"], want) {
+		t.Errorf(`properties["amount"] = %v, want %v`, properties["amount"], want)
+	}
+	if want := map[string]any{"type": "string", "format": "date-time"}; !reflect.DeepEqual(properties["paid_at"], want) {
+		t.Errorf(`properties["paid_at"] = %v, want %v`, properties["paid_at"], want)
+	}
+}
+
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("started", "first")
+	event := registry.Register("started", "second", Boolean("warm", ""))
+
+	want := []Event{event}
+	if got := registry.Events(); !reflect.DeepEqual(got, want) {
+		t.Errorf("registering again replaces the event: registry.Events() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("started", "")
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/log-schema", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("rec.Code = %v, want %v", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/schema+json" {
+		t.Errorf(`rec.Header().Get("Content-Type") = %q, want %q`, got, "application/schema+json")
+	}
+	if got := rec.Body.String(); !strings.Contains(got, `"#/$defs/started"`) {
+		t.Errorf("rec.Body.String() = %q, want it to contain %q", got, `"#/$defs/started"`)
+	}
+}
@@ -0,0 +1,217 @@
+package logtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+// update rewrites golden files with the recorded entries instead of
+// comparing them: go test ./... -logtest.update
+var update = flag.Bool("logtest.update", false, "rewrite logtest golden files")
+
+// Option configures AssertGolden.
+type Option func(*options)
+
+// options holds the settings of AssertGolden.
+type options struct {
+	// ignore lists the keys removed from every entry before comparing
+	ignore map[string]bool
+}
+
+// IgnoreFields leaves the given keys out of the comparison and the golden
+// file. Keys can name custom fields or the standard "time", "level", and
+// "msg" fields; ignore values that change between runs, such as timestamps
+// or generated IDs.
+func IgnoreFields(keys ...string) Option {
+	return func(o *options) {
+		for _, key := range keys {
+			o.ignore[key] = true
+		}
+	}
+}
+
+// AssertGolden compares the entries recorded by recorder with the golden file
+// at path, and fails t with a description of every difference.
+//
+// The golden file is a JSON array with one object per entry, holding the
+// "time", "level", and "msg" fields followed by the custom fields; field
+// values are compared after a JSON round trip. Run the tests with the
+// -logtest.update flag to create or rewrite golden files from the recorded
+// entries.
+//
+// It returns whether the entries match.
+func AssertGolden(t testing.TB, recorder *Recorder, path string, opts ...Option) bool {
+	t.Helper()
+
+	o := options{ignore: map[string]bool{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	got, err := normalize(recorder.Entries(), o)
+	if err != nil {
+		t.Errorf("logtest: encode recorded entries: %v", err)
+		return false
+	}
+
+	if *update {
+		if err := writeGolden(path, got); err != nil {
+			t.Errorf("logtest: update golden file: %v", err)
+			return false
+		}
+
+		return true
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Errorf("logtest: read golden file (run with -logtest.update to create it): %v", err)
+		return false
+	}
+
+	var want []map[string]any
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Errorf("logtest: parse golden file %s: %v", path, err)
+		return false
+	}
+
+	for _, entry := range want {
+		for key := range o.ignore {
+			delete(entry, key)
+		}
+	}
+
+	diffs := diff(got, want)
+	if len(diffs) > 0 {
+		t.Errorf("logtest: entries differ from golden file %s:\n\t%s", path, strings.Join(diffs, "\n\t"))
+		return false
+	}
+
+	return true
+}
+
+// normalize converts entries to JSON objects as stored in golden files,
+// without the ignored keys.
+func normalize(entries []golog.Entry, o options) ([]map[string]any, error) {
+	objects := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		object := make(map[string]any, len(entry.Fields)+3)
+		for k, v := range entry.Fields {
+			if err, ok := v.(error); ok {
+				v = err.Error()
+			}
+
+			object[k] = v
+		}
+
+		object[golog.FieldTime] = entry.Time.Format(time.RFC3339)
+		object[golog.FieldLevel] = golog.LevelString(entry.Level)
+		object[golog.FieldMessage] = entry.Message
+
+		for key := range o.ignore {
+			delete(object, key)
+		}
+
+		objects = append(objects, object)
+	}
+
+	// round-trip through JSON so values compare like the golden file's
+	data, err := json.Marshal(objects)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized []map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+
+	return normalized, nil
+}
+
+// writeGolden writes entries to path as indented JSON.
+func writeGolden(path string, entries []map[string]any) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// diff describes the differences between the recorded and golden entries.
+func diff(got, want []map[string]any) []string {
+	var diffs []string
+	if len(got) != len(want) {
+		diffs = append(diffs, fmt.Sprintf("got %d entries, want %d", len(got), len(want)))
+	}
+
+	for i := range min(len(got), len(want)) {
+		keys := map[string]bool{}
+		for k := range got[i] {
+			keys[k] = true
+		}
+		for k := range want[i] {
+			keys[k] = true
+		}
+
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			g, gok := got[i][k]
+			w, wok := want[i][k]
+			switch {
+			case !wok:
+				diffs = append(diffs, fmt.Sprintf("entry %d: unexpected field %q = %s", i, k, jsonString(g)))
+			case !gok:
+				diffs = append(diffs, fmt.Sprintf("entry %d: missing field %q = %s", i, k, jsonString(w)))
+			case !reflect.DeepEqual(g, w):
+				diffs = append(diffs, fmt.Sprintf("entry %d: field %q = %s, want %s", i, k, jsonString(g), jsonString(w)))
+			}
+		}
+	}
+
+	for i := len(want); i < len(got); i++ {
+		diffs = append(diffs, fmt.Sprintf("entry %d: unexpected entry %s", i, jsonString(got[i])))
+	}
+
+	for i := len(got); i < len(want); i++ {
+		diffs = append(diffs, fmt.Sprintf("entry %d: missing entry %s", i, jsonString(want[i])))
+	}
+
+	return diffs
+}
+
+// jsonString returns v encoded as JSON for diff messages.
+func jsonString(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return string(data)
+}
@@ -0,0 +1,137 @@
+package logtest
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jkaveri/golog"
+)
+
+// fakeT records the failures reported by AssertGolden.
+type fakeT struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGolden(t *testing.T) {
+	if *update {
+		t.Skip("the golden file of this test is maintained by hand")
+	}
+
+	tests := []struct {
+		name   string
+		userID int
+		extra  bool
+		diffs  []string
+	}{
+		{
+			name:   "match",
+			userID: 42,
+		},
+		{
+			name:   "changed-field",
+			userID: 7,
+			diffs: []string{
+				`entry 0: field "user_id" = 7, want 42`,
+				`entry 1: field "user_id" = 7, want 42`,
+			},
+		},
+		{
+			name:   "extra-entry",
+			userID: 42,
+			extra:  true,
+			diffs:  []string{"got 3 entries, want 2", `entry 2: unexpected entry {"level":"DEBUG","msg":"done"}`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := NewRecorder()
+			recorder.Write(golog.LevelInfo, "checkout started", map[string]any{"user_id": tt.userID, "items": 2})
+			recorder.Write(golog.LevelError, "payment failed", map[string]any{"user_id": tt.userID, "error": errors.New("card declined")})
+			if tt.extra {
+				recorder.Write(golog.LevelDebug, "done", nil)
+			}
+
+			ft := &fakeT{TB: t}
+			ok := AssertGolden(ft, recorder, "testdata/checkout.json", IgnoreFields(golog.FieldTime))
+
+			want := len(tt.diffs) == 0
+			if ok != want {
+				t.Errorf("ok = %v, want %v", ok, want)
+			}
+			if len(tt.diffs) == 0 {
+				if len(ft.errors) != 0 {
+					t.Errorf("ft.errors = %v, want empty", ft.errors)
+				}
+				return
+			}
+
+			if len(ft.errors) != 1 {
+				t.Fatalf("len(ft.errors) = %d, want 1", len(ft.errors))
+			}
+			for _, d := range tt.diffs {
+				if !strings.Contains(ft.errors[0], d) {
+					t.Errorf("ft.errors[0] = %q, want it to contain %q", ft.errors[0], d)
+				}
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	fields := map[string]any{"n": 1}
+
+	recorder.Write(golog.LevelInfo, "hello", fields)
+	fields["n"] = 2
+
+	entries := recorder.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(entries))
+	}
+	if entries[0].Message != "hello" {
+		t.Errorf("entries[0].Message = %q, want %q", entries[0].Message, "hello")
+	}
+	want := map[string]any{"n": 1}
+	if !reflect.DeepEqual(entries[0].Fields, want) {
+		t.Errorf("fields are copied: entries[0].Fields = %v, want %v", entries[0].Fields, want)
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("entries[0].Time.IsZero() = true, want false")
+	}
+
+	recorder.Reset()
+	if got := recorder.Entries(); len(got) != 0 {
+		t.Errorf("recorder.Entries() = %v, want empty", got)
+	}
+}
+
+func TestAssertGolden_Update(t *testing.T) {
+	*update = true
+	t.Cleanup(func() { *update = false })
+
+	recorder := NewRecorder()
+	recorder.Write(golog.LevelInfo, "checkout started", map[string]any{"user_id": 42, "items": 2})
+	recorder.Write(golog.LevelError, "payment failed", map[string]any{"user_id": 42, "error": errors.New("card declined")})
+
+	path := filepath.Join(t.TempDir(), "golden", "checkout.json")
+	if !AssertGolden(t, recorder, path, IgnoreFields(golog.FieldTime)) {
+		t.Fatal("got false, want true")
+	}
+
+	*update = false
+	if !AssertGolden(t, recorder, path, IgnoreFields(golog.FieldTime)) {
+		t.Error("an updated golden file matches")
+	}
+}
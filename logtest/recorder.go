@@ -0,0 +1,75 @@
+// Package logtest provides helpers for testing code that logs with golog:
+// a Recorder that captures entries in memory, and golden file assertions that
+// make logging behavior part of the regression test suite.
+//
+// Example:
+//
+//	func TestCheckout(t *testing.T) {
+//	    recorder := logtest.NewRecorder()
+//	    golog.SetWriter(recorder)
+//
+//	    checkout(cart)
+//
+//	    logtest.AssertGolden(t, recorder, "testdata/checkout.json", logtest.IgnoreFields("time"))
+//	}
+package logtest
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+// Recorder is a golog.LogWriter that keeps every entry in memory.
+// It is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	entries []golog.Entry
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Write implements golog.LogWriter.
+func (r *Recorder) Write(level int, msg string, fields map[string]any) {
+	r.WriteEntry(golog.Entry{Level: level, Message: msg, Fields: fields})
+}
+
+// WriteEntry implements golog.EntryWriter. The entry's fields are copied.
+func (r *Recorder) WriteEntry(entry golog.Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	entry.Fields = maps.Clone(entry.Fields)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = append(r.entries, entry)
+}
+
+// Flush implements golog.LogWriter. Entries are kept in memory, so there is
+// nothing to flush.
+func (r *Recorder) Flush() {}
+
+// Entries returns a copy of the recorded entries, in the order they were
+// written.
+func (r *Recorder) Entries() []golog.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]golog.Entry(nil), r.entries...)
+}
+
+// Reset discards the recorded entries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.entries = nil
+}
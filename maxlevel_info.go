@@ -0,0 +1,7 @@
+//go:build golog_max_level_info && !golog_max_level_warn && !golog_max_level_error
+
+package golog
+
+// maxLevel is the most verbose level compiled in (see "Compile-time levels"
+// in the package documentation).
+const maxLevel = LevelInfo
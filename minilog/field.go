@@ -0,0 +1,67 @@
+package minilog
+
+import "strconv"
+
+// fieldKind is the type of a field's value.
+type fieldKind uint8
+
+const (
+	kindString fieldKind = iota
+	kindInt
+	kindBool
+)
+
+// Field is a typed key-value pair attached to an entry. Create fields with
+// String, Int, Bool, and Err.
+type Field struct {
+	// Key is the name of the field
+	Key  string
+	kind fieldKind
+	str  string
+	num  int64
+}
+
+// String returns a string field.
+func String(key, value string) Field {
+	return Field{Key: key, kind: kindString, str: value}
+}
+
+// Int returns an integer field.
+func Int(key string, value int64) Field {
+	return Field{Key: key, kind: kindInt, num: value}
+}
+
+// Bool returns a boolean field.
+func Bool(key string, value bool) Field {
+	var num int64
+	if value {
+		num = 1
+	}
+
+	return Field{Key: key, kind: kindBool, num: num}
+}
+
+// Err returns an "error" field holding err's message, or "<nil>".
+func Err(err error) Field {
+	if err == nil {
+		return String("error", "<nil>")
+	}
+
+	return String("error", err.Error())
+}
+
+// appendValue appends the quoted value of the field to b.
+func (f Field) appendValue(b []byte) []byte {
+	switch f.kind {
+	case kindInt:
+		b = append(b, '"')
+		b = strconv.AppendInt(b, f.num, 10)
+		return append(b, '"')
+	case kindBool:
+		b = append(b, '"')
+		b = strconv.AppendBool(b, f.num != 0)
+		return append(b, '"')
+	default:
+		return strconv.AppendQuote(b, f.str)
+	}
+}
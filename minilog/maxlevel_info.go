@@ -0,0 +1,6 @@
+//go:build golog_max_level_info && !golog_max_level_error
+
+package minilog
+
+// maxLevel is the most verbose level compiled in.
+const maxLevel = LevelInfo
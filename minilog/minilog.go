@@ -0,0 +1,134 @@
+// Package minilog is a minimal logger for TinyGo, microcontrollers, and
+// size-constrained binaries, where the reflection, maps, and fmt formatting of
+// the main golog package are too expensive.
+//
+// It writes one text line per entry in the same shape as golog's default
+// writer, without the caller location and timestamp, which small targets
+// often cannot provide:
+//
+//	[INFO] sensor read sensor="bme280" temp="21"
+//
+// Fields are typed (String, Int, Bool, Err), so formatting uses no
+// reflection, and entries are formatted into a buffer owned by the Logger, so
+// logging does not allocate.
+//
+// Levels can be removed at compile time with build tags: golog_max_level_info
+// removes Debug calls, and golog_max_level_error removes Debug and Info calls.
+// Removed calls compile to nothing, and their messages are left out of the
+// binary.
+//
+// Example:
+//
+//	log := minilog.New(machine.Serial)
+//	log.Info("sensor read", minilog.String("sensor", "bme280"), minilog.Int("temp", 21))
+package minilog
+
+import (
+	"io"
+	"sync"
+)
+
+// Level is the severity of an entry.
+type Level uint8
+
+// Log levels, from the most to the least verbose
+const (
+	// LevelDebug is for detailed information useful during development
+	LevelDebug Level = iota
+	// LevelInfo is for general operational information
+	LevelInfo
+	// LevelError is for failures that need attention
+	LevelError
+)
+
+// String returns the name of the level as written in entries.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// bufferSize is the size of the line buffer; longer entries are truncated.
+const bufferSize = 256
+
+// Logger writes entries to an io.Writer. It is safe for concurrent use.
+type Logger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level Level
+	buf   [bufferSize]byte
+}
+
+// New creates a Logger that writes entries of LevelInfo and above to w.
+// Entries longer than 256 bytes are truncated.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w, level: LevelInfo}
+}
+
+// SetLevel sets the minimum level written at runtime. Levels removed at
+// compile time (see the package documentation) stay removed.
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	l.level = level
+	l.mu.Unlock()
+}
+
+// Debug writes an entry at the debug level.
+func (l *Logger) Debug(msg string, fields ...Field) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	l.log(LevelDebug, msg, fields)
+}
+
+// Info writes an entry at the info level.
+func (l *Logger) Info(msg string, fields ...Field) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	l.log(LevelInfo, msg, fields)
+}
+
+// Error writes an entry at the error level.
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(LevelError, msg, fields)
+}
+
+// log formats the entry into the buffer and writes it with a single call.
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level < l.level {
+		return
+	}
+
+	b := l.buf[:0]
+	b = append(b, '[')
+	b = append(b, level.String()...)
+	b = append(b, "] "...)
+	b = append(b, msg...)
+
+	for _, f := range fields {
+		b = append(b, ' ')
+		b = append(b, f.Key...)
+		b = append(b, '=')
+		b = f.appendValue(b)
+	}
+
+	if len(b) >= bufferSize {
+		b = b[:bufferSize-1]
+	}
+
+	b = append(b, '\n')
+	l.w.Write(b)
+}
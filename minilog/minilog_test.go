@@ -0,0 +1,103 @@
+package minilog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	tests := []struct {
+		name  string
+		level Level
+		// logged is the level of the entry, which may be removed at compile time
+		logged   Level
+		log      func(l *Logger)
+		expected string
+	}{
+		{
+			name:   "info-with-fields",
+			level:  LevelInfo,
+			logged: LevelInfo,
+			log: func(l *Logger) {
+				l.Info("sensor read", String("sensor", "bme 280"), Int("temp", -3), Bool("ok", true))
+			},
+			expected: "[INFO] sensor read sensor=\"bme 280\" temp=\"-3\" ok=\"true\"\n",
+		},
+		{
+			name:   "error-with-err",
+			level:  LevelInfo,
+			logged: LevelError,
+			log: func(l *Logger) {
+				l.Error("read failed", Err(errors.New("i2c timeout")))
+			},
+			expected: "[ERROR] read failed error=\"i2c timeout\"\n",
+		},
+		{
+			name:   "below-level",
+			level:  LevelError,
+			logged: LevelInfo,
+			log: func(l *Logger) {
+				l.Info("dropped")
+			},
+		},
+		{
+			name:   "debug",
+			level:  LevelDebug,
+			logged: LevelDebug,
+			log: func(l *Logger) {
+				l.Debug("raw value", Int("adc", 512))
+			},
+			expected: "[DEBUG] raw value adc=\"512\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.logged < maxLevel {
+				tt.expected = ""
+			}
+
+			var buf bytes.Buffer
+			l := New(&buf)
+			l.SetLevel(tt.level)
+
+			tt.log(l)
+
+			if got := buf.String(); got != tt.expected {
+				t.Errorf("buf.String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLogger_Truncates(t *testing.T) {
+	var buf bytes.Buffer
+	New(&buf).Error(strings.Repeat("x", 2*bufferSize))
+
+	if got := buf.String(); len(got) != bufferSize {
+		t.Errorf("len(buf.String()) = %d, want %d", len(got), bufferSize)
+	}
+	if !strings.HasSuffix(buf.String(), "x\n") {
+		t.Error("strings.HasSuffix(buf.String(), \"x\\n\") = false, want true")
+	}
+}
+
+func TestLogger_NoAllocations(t *testing.T) {
+	l := New(discard{})
+	err := errors.New("i2c timeout")
+
+	allocs := testing.AllocsPerRun(100, func() {
+		l.Error("sensor read failed", String("sensor", "bme280"), Int("temp", 21), Err(err))
+	})
+
+	if allocs != 0 {
+		t.Errorf("allocs = %v, want zero", allocs)
+	}
+}
+
+// discard is an io.Writer that drops everything without allocating.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
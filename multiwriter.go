@@ -0,0 +1,248 @@
+package golog
+
+import (
+	"fmt"
+	"time"
+)
+
+// multiWriter implements the LogWriter interface by writing every entry to
+// several writers.
+type multiWriter struct {
+	writers []LogWriter
+	errors  writerOptions
+}
+
+// NewMultiWriter creates a LogWriter that duplicates every entry to each of
+// writers, in order, e.g. JSON lines to a file and the text format to
+// stdout. Wrap a writer with LevelFilter to give it a minimum level of its
+// own; the others get the entries at or above the level of the Logger.
+//
+// Writers are isolated from each other: a writer that panics does not keep
+// the entry from the writers after it. The panic is reported to os.Stderr and
+// the entry is counted as dropped (see RecordDropped) with DropWriterFailed.
+//
+// Example:
+//
+//	golog.SetWriter(golog.NewMultiWriter(
+//	    golog.NewDefaultWriter(os.Stdout), // info and above
+//	    golog.LevelFilter(fileWriter, golog.LevelDebug),
+//	))
+func NewMultiWriter(writers ...LogWriter) LogWriter {
+	var nonNil []LogWriter
+	for _, w := range writers {
+		if w != nil {
+			nonNil = append(nonNil, w)
+		}
+	}
+
+	return &multiWriter{writers: nonNil}
+}
+
+// Write implements LogWriter.
+func (w *multiWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *multiWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write writes the entry to every writer.
+func (w *multiWriter) write(entry Entry, file string, line int) {
+	for _, writer := range w.writers {
+		w.isolate(writer, true, func() {
+			writeLocated(writer, entry, file, line)
+		})
+	}
+}
+
+// minLevel implements levelRouter.
+func (w *multiWriter) minLevel(fallback int) int {
+	if len(w.writers) == 0 {
+		return fallback
+	}
+
+	lowest := writerMinLevel(w.writers[0], fallback)
+	for _, writer := range w.writers[1:] {
+		lowest = min(lowest, writerMinLevel(writer, fallback))
+	}
+
+	return lowest
+}
+
+// route implements levelRouter.
+func (w *multiWriter) route(entry Entry, fallback int, file string, line int) {
+	for _, writer := range w.writers {
+		w.isolate(writer, true, func() {
+			writeRouted(writer, entry, fallback, file, line)
+		})
+	}
+}
+
+// Flush implements LogWriter by flushing every writer.
+func (w *multiWriter) Flush() {
+	for _, writer := range w.writers {
+		w.isolate(writer, false, writer.Flush)
+	}
+}
+
+// flushBuffers implements bufferFlusher by flushing the buffers of every
+// writer.
+func (w *multiWriter) flushBuffers() {
+	for _, writer := range w.writers {
+		w.isolate(writer, false, func() { flushBuffers(writer) })
+	}
+}
+
+// Close closes the writers that implement io.Closer, such as file writers.
+func (w *multiWriter) Close() error {
+	return closeWriters(w.writers)
+}
+
+// isolate calls fn, recovering and reporting a panic of writer, and
+// counting the entry as dropped when fn writes one.
+func (w *multiWriter) isolate(writer LogWriter, writing bool, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if writing {
+				RecordDropped(DropWriterFailed, 1)
+			}
+
+			w.errors.handleError(fmt.Errorf("golog: writer %T panicked: %v", writer, r))
+		}
+	}()
+
+	fn()
+}
+
+// levelFilter implements the LogWriter interface by writing the entries at
+// or above a minimum level to a writer.
+type levelFilter struct {
+	writer LogWriter
+	level  int
+}
+
+// LevelFilter returns a LogWriter that writes to w only the entries at or
+// above level, for writers with a minimum level of their own behind
+// NewMultiWriter, e.g. a file getting the debug entries while stdout gets
+// the info entries. The level of the writer replaces the level of the
+// Logger (see SetLevel and LogScope.WithLevel), which applies to the
+// writers without a level of their own. Levels are only seen through
+// NewMultiWriter and LevelFilter, not through other writers wrapping them,
+// such as NewAsyncWriter.
+func LevelFilter(w LogWriter, level int) LogWriter {
+	return &levelFilter{writer: w, level: level}
+}
+
+// Write implements LogWriter.
+func (w *levelFilter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *levelFilter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write writes the entry when it meets the level.
+func (w *levelFilter) write(entry Entry, file string, line int) {
+	if entry.Level >= w.level {
+		writeLocated(w.writer, entry, file, line)
+	}
+}
+
+// minLevel implements levelRouter.
+func (w *levelFilter) minLevel(int) int {
+	return w.level
+}
+
+// route implements levelRouter.
+func (w *levelFilter) route(entry Entry, _ int, file string, line int) {
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// Flush implements LogWriter.
+func (w *levelFilter) Flush() {
+	w.writer.Flush()
+}
+
+// flushBuffers implements bufferFlusher.
+func (w *levelFilter) flushBuffers() {
+	flushBuffers(w.writer)
+}
+
+// Close closes the writer if it implements io.Closer.
+func (w *levelFilter) Close() error {
+	return closeWriters([]LogWriter{w.writer})
+}
+
+// writeLocated writes entry, with its time set, to w, passing along the
+// caller location to the built-in writers.
+func writeLocated(w LogWriter, entry Entry, file string, line int) {
+	switch w := w.(type) {
+	case locatedWriter:
+		w.write(entry, file, line)
+	case EntryWriter:
+		w.WriteEntry(entry)
+	default:
+		w.Write(entry.Level, entry.Message, entry.Fields)
+	}
+}
+
+// levelRouter is implemented by the writers whose writers can have a level
+// of their own, replacing the level of the Logger.
+type levelRouter interface {
+	// minLevel returns the lowest level of the entries written to any
+	// writer, with fallback as the level of the writers without one
+	minLevel(fallback int) int
+	// route writes entry to the writers whose level it meets, with fallback
+	// as the level of the writers without one
+	route(entry Entry, fallback int, file string, line int)
+}
+
+// writerMinLevel returns the lowest level of the entries w writes, with
+// fallback as the level of the writers without one.
+func writerMinLevel(w LogWriter, fallback int) int {
+	if r, ok := w.(levelRouter); ok {
+		return r.minLevel(fallback)
+	}
+
+	return fallback
+}
+
+// writeEntry writes entry to w, or to the writers of w whose level it
+// meets, with fallback as the level of the writers without one. The
+// built-in writers report the caller skip frames above the caller of
+// writeEntry, as if it called their WriteEntry.
+func writeEntry(w LogWriter, entry Entry, fallback, skip int) {
+	switch ew := w.(type) {
+	case levelRouter, locatedWriter:
+		file, line := getCallerInfo(skip)
+		writeRouted(w, entry, fallback, file, line)
+	case EntryWriter:
+		ew.WriteEntry(entry)
+	default:
+		w.Write(entry.Level, entry.Message, entry.Fields)
+	}
+}
+
+// writeRouted writes entry to w, or to the writers of w whose level it
+// meets, passing along the caller location.
+func writeRouted(w LogWriter, entry Entry, fallback int, file string, line int) {
+	if r, ok := w.(levelRouter); ok {
+		r.route(entry, fallback, file, line)
+		return
+	}
+
+	if entry.Level >= fallback {
+		writeLocated(w, entry, file, line)
+	}
+}
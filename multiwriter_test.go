@@ -0,0 +1,248 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// panickingWriter is a LogWriter that panics on every call.
+type panickingWriter struct{}
+
+func (panickingWriter) Write(int, string, map[string]any) { panic("sink unavailable") }
+
+func (panickingWriter) Flush() { panic("sink unavailable") }
+
+func TestMultiWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	all := &captureWriter{}
+	errorsOnly := &captureWriter{}
+
+	writer := NewMultiWriter(NewJSONWriter(buf), all, nil, LevelFilter(errorsOnly, LevelError))
+	useWriter(t, writer)
+
+	Info("order placed")
+	_ = Error("payment failed")
+	Flush()
+
+	if len(all.entries) != 2 {
+		t.Errorf("len(all.entries) = %d, want 2", len(all.entries))
+	}
+	if len(errorsOnly.entries) != 1 {
+		t.Fatalf("len(errorsOnly.entries) = %d, want 1", len(errorsOnly.entries))
+	}
+	if got := errorsOnly.last().msg; got != "payment failed" {
+		t.Errorf("errorsOnly.last().msg = %q, want %q", got, "payment failed")
+	}
+	if errorsOnly.flushes != 1 {
+		t.Errorf("errorsOnly.flushes = %v, want 1", errorsOnly.flushes)
+	}
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("len(lines) = %d, want 2", len(lines))
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(lines[0], &entry); err != nil {
+		t.Fatalf("json.Unmarshal(lines[0], &entry): %v", err)
+	}
+	if entry[FieldMessage] != "order placed" {
+		t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "order placed")
+	}
+}
+
+func TestLevelFilter_OverridesLoggerLevel(t *testing.T) {
+	tests := []struct {
+		name        string
+		loggerLevel int
+		wantStdout  []string
+		wantFile    []string
+	}{
+		{
+			name:        "lower-than-logger",
+			loggerLevel: LevelInfo,
+			wantStdout:  []string{"info", "error"},
+			wantFile:    []string{"debug", "info", "error"},
+		},
+		{
+			name:        "quiet-logger",
+			loggerLevel: LevelError,
+			wantStdout:  []string{"error"},
+			wantFile:    []string{"debug", "info", "error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, file := &captureWriter{}, &captureWriter{}
+			lg := New(LoggerWriter(NewMultiWriter(stdout, LevelFilter(file, LevelDebug))), LoggerLevel(tt.loggerLevel))
+
+			lg.Trace("trace")
+			lg.Debug("debug")
+			lg.Info("info")
+			_ = lg.Error("error")
+
+			if got := messages(stdout); !reflect.DeepEqual(got, tt.wantStdout) {
+				t.Errorf("messages(stdout) = %v, want %v", got, tt.wantStdout)
+			}
+			if got := messages(file); !reflect.DeepEqual(got, tt.wantFile) {
+				t.Errorf("messages(file) = %v, want %v", got, tt.wantFile)
+			}
+		})
+	}
+}
+
+// entryRecorder is an EntryWriter that records the entries written to it.
+type entryRecorder struct {
+	entries []Entry
+}
+
+func (w *entryRecorder) Write(level int, msg string, fields map[string]any) {
+	w.WriteEntry(Entry{Level: level, Message: msg, Fields: fields})
+}
+
+func (w *entryRecorder) WriteEntry(entry Entry) {
+	w.entries = append(w.entries, entry)
+}
+
+func (w *entryRecorder) Flush() {}
+
+func TestLevelFilter_RoutesEntryUnchanged(t *testing.T) {
+	base := errors.New("connection reset")
+	stdout, file := &entryRecorder{}, &entryRecorder{}
+	lg := New(LoggerWriter(NewMultiWriter(stdout, LevelFilter(file, LevelDebug))), LoggerLevel(LevelInfo))
+
+	lg.WithError(base).Debug("retrying")
+	_ = lg.WithError(base).Error("giving up")
+
+	if len(stdout.entries) != 1 {
+		t.Fatalf("len(stdout.entries) = %d, want 1", len(stdout.entries))
+	}
+	if len(file.entries) != 2 {
+		t.Fatalf("len(file.entries) = %d, want 2", len(file.entries))
+	}
+	for _, entry := range append(stdout.entries, file.entries...) {
+		if !errors.Is(entry.Err, base) {
+			t.Errorf("the error of %q reaches the routed writer: entry.Err = %v, want %v", entry.Message, entry.Err, base)
+		}
+		if entry.Time.IsZero() {
+			t.Error("entry.Time.IsZero() = true, want false")
+		}
+	}
+}
+
+// messages returns the messages of the entries captured by w.
+func messages(w *captureWriter) []string {
+	var msgs []string
+	for _, entry := range w.entries {
+		msgs = append(msgs, entry.msg)
+	}
+
+	return msgs
+}
+
+func TestMultiWriter_StructuredErrors(t *testing.T) {
+	base := fmt.Errorf("payment declined: %w", errors.New("card expired"))
+
+	tests := []struct {
+		name string
+		wrap func(LogWriter) LogWriter
+	}{
+		{
+			name: "multi-writer",
+			wrap: func(w LogWriter) LogWriter { return NewMultiWriter(w) },
+		},
+		{
+			name: "level-filter",
+			wrap: func(w LogWriter) LogWriter { return LevelFilter(w, LevelDebug) },
+		},
+		{
+			name: "filtered-in-multi-writer",
+			wrap: func(w LogWriter) LogWriter { return NewMultiWriter(&captureWriter{}, LevelFilter(w, LevelDebug)) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			useWriter(t, tt.wrap(NewJSONWriter(buf, StructuredErrors())))
+
+			_ = WithError(base).Error("checkout failed")
+			Flush()
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+
+			rendered, ok := entry["error"].(map[string]any)
+			if !ok {
+				t.Fatal("the error is rendered as an object behind the wrapping writer")
+			}
+			want := base.Error()
+			if rendered["message"] != want {
+				t.Errorf(`rendered["message"] = %q, want %q`, rendered["message"], want)
+			}
+			if chain, _ := rendered["chain"].([]any); len(chain) != 1 {
+				t.Errorf(`len(rendered["chain"]) = %d, want 1`, len(chain))
+			}
+		})
+	}
+}
+
+func TestMultiWriter_Isolation(t *testing.T) {
+	resetDrops(t)
+
+	var reported []error
+	after := &captureWriter{}
+	writer := NewMultiWriter(panickingWriter{}, after).(*multiWriter)
+	writer.errors.errorHandler = func(err error) { reported = append(reported, err) }
+
+	writer.Write(LevelInfo, "entry", nil)
+	writer.Flush()
+
+	if len(after.entries) != 1 {
+		t.Errorf("len(after.entries) = %d, want 1", len(after.entries))
+	}
+	if after.flushes != 1 {
+		t.Errorf("after.flushes = %v, want 1", after.flushes)
+	}
+	if len(reported) != 2 {
+		t.Fatalf("len(reported) = %d, want 2", len(reported))
+	}
+	if got := reported[0].Error(); !strings.Contains(got, "sink unavailable") {
+		t.Errorf("reported[0].Error() = %q, want it to contain %q", got, "sink unavailable")
+	}
+
+	fields, ok := drops.take(true)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	want := map[string]int{DropWriterFailed: 1}
+	if !reflect.DeepEqual(fields["dropped_by_reason"], want) {
+		t.Errorf(`fields["dropped_by_reason"] = %v, want %v`, fields["dropped_by_reason"], want)
+	}
+}
+
+func TestMultiWriter_Describe(t *testing.T) {
+	writer := NewMultiWriter(NewJSONWriter(&bytes.Buffer{}), LevelFilter(&captureWriter{}, LevelWarn))
+
+	d := DescribeWriter(writer)
+	if d.Type != "multi" {
+		t.Errorf("d.Type = %q, want %q", d.Type, "multi")
+	}
+	if len(d.Writers) != 2 {
+		t.Fatalf("len(d.Writers) = %d, want 2", len(d.Writers))
+	}
+	if d.Writers[0].Type != FormatJSON {
+		t.Errorf("d.Writers[0].Type = %q, want %q", d.Writers[0].Type, FormatJSON)
+	}
+	if d.Writers[1].Level != "WARN" {
+		t.Errorf("d.Writers[1].Level = %q, want %q", d.Writers[1].Level, "WARN")
+	}
+}
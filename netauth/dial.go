@@ -0,0 +1,356 @@
+package netauth
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Dialer makes network connections. *net.Dialer implements it, and so do
+// the proxy dialers of this package, so that connections of network sinks
+// can be routed through a SOCKS5 or HTTP proxy, a sidecar, or a Unix socket.
+type Dialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// DialThrough is like Dial, connecting with dialer, e.g. a proxy dialer,
+// instead of a direct connection. A nil dialer connects directly. For
+// addresses without a host name, such as Unix sockets, set t.ServerName.
+func DialThrough(ctx context.Context, dialer Dialer, network, addr string, t TLS) (net.Conn, error) {
+	cfg, err := t.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ServerName == "" {
+		if host, _, err := net.SplitHostPort(addr); err == nil {
+			cfg.ServerName = host
+		}
+	}
+
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return tlsConn, nil
+}
+
+// HTTPClient returns an http.Client for HTTP sinks that connects with
+// dialer, or directly when it is nil, and uses the TLS settings t for
+// HTTPS. It does not use the proxy environment variables: pass a proxy
+// dialer instead.
+func HTTPClient(dialer Dialer, t TLS) (*http.Client, error) {
+	cfg, err := t.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	transport := &http.Transport{
+		DialContext:       dialer.DialContext,
+		TLSClientConfig:   cfg,
+		ForceAttemptHTTP2: true,
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// Proxy returns a Dialer connecting through the proxy at proxyURL, with the
+// scheme "socks5" or "http". Credentials in the URL authenticate with the
+// proxy. The connection to the proxy is made with forward, or directly when
+// it is nil.
+//
+// Example:
+//
+//	proxyURL, _ := url.Parse("socks5://proxy.internal:1080")
+//	dialer, err := netauth.Proxy(proxyURL, nil)
+//	if err != nil {
+//	    return err
+//	}
+//	conn, err := netauth.DialThrough(ctx, dialer, "tcp", "collector:7070", tlsSettings)
+func Proxy(proxyURL *url.URL, forward Dialer) (Dialer, error) {
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		return &socks5Dialer{addr: proxyURL.Host, user: proxyURL.User, forward: forward}, nil
+	case "http":
+		return &connectDialer{addr: proxyURL.Host, user: proxyURL.User, forward: forward}, nil
+	default:
+		return nil, fmt.Errorf("netauth: unsupported proxy scheme %q", proxyURL.Scheme)
+	}
+}
+
+// socks5Dialer connects through a SOCKS5 proxy (RFC 1928), authenticating
+// with a username and password (RFC 1929) when set.
+type socks5Dialer struct {
+	addr    string
+	user    *url.Userinfo
+	forward Dialer
+}
+
+// SOCKS5 protocol constants
+const (
+	socks5Version      = 5
+	socks5NoAuth       = 0
+	socks5PasswordAuth = 2
+	socks5NoAcceptable = 0xff
+	socks5Connect      = 1
+	socks5IPv4         = 1
+	socks5Domain       = 3
+	socks5IPv6         = 4
+)
+
+// DialContext implements Dialer.
+func (d *socks5Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("netauth: SOCKS5 proxy does not support network %q", network)
+	}
+
+	conn, err := d.forward.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return nil, fmt.Errorf("netauth: connect to proxy %s: %w", d.addr, err)
+	}
+
+	if err := withDeadline(ctx, conn, func() error { return d.connect(conn, addr) }); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("netauth: SOCKS5 proxy %s: %w", d.addr, err)
+	}
+
+	return conn, nil
+}
+
+// connect negotiates the authentication and the connection to addr.
+func (d *socks5Dialer) connect(conn net.Conn, addr string) error {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", portStr)
+	}
+
+	method := byte(socks5NoAuth)
+	if d.user != nil {
+		method = socks5PasswordAuth
+	}
+
+	if _, err := conn.Write([]byte{socks5Version, 1, method}); err != nil {
+		return err
+	}
+
+	var reply [2]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return err
+	}
+
+	if reply[0] != socks5Version || reply[1] == socks5NoAcceptable || reply[1] != method {
+		return errors.New("no acceptable authentication method")
+	}
+
+	if method == socks5PasswordAuth {
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	}
+
+	req := []byte{socks5Version, socks5Connect, 0}
+	if ip := net.ParseIP(host); ip == nil {
+		if len(host) > 255 {
+			return fmt.Errorf("host name %q is too long", host)
+		}
+		req = append(req, socks5Domain, byte(len(host)))
+		req = append(req, host...)
+	} else if ip4 := ip.To4(); ip4 != nil {
+		req = append(req, socks5IPv4)
+		req = append(req, ip4...)
+	} else {
+		req = append(req, socks5IPv6)
+		req = append(req, ip.To16()...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	var header [4]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return err
+	}
+
+	if header[1] != 0 {
+		return fmt.Errorf("connect to %s failed with code %d", addr, header[1])
+	}
+
+	// skip the bound address and port
+	var skip int
+	switch header[3] {
+	case socks5IPv4:
+		skip = net.IPv4len + 2
+	case socks5IPv6:
+		skip = net.IPv6len + 2
+	case socks5Domain:
+		var n [1]byte
+		if _, err := io.ReadFull(conn, n[:]); err != nil {
+			return err
+		}
+		skip = int(n[0]) + 2
+	default:
+		return fmt.Errorf("invalid address type %d", header[3])
+	}
+
+	_, err = io.CopyN(io.Discard, conn, int64(skip))
+
+	return err
+}
+
+// authenticate sends the username and password.
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	username := d.user.Username()
+	password, _ := d.user.Password()
+	if len(username) > 255 || len(password) > 255 {
+		return errors.New("username or password is too long")
+	}
+
+	req := []byte{1, byte(len(username))}
+	req = append(req, username...)
+	req = append(req, byte(len(password)))
+	req = append(req, password...)
+
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+
+	var reply [2]byte
+	if _, err := io.ReadFull(conn, reply[:]); err != nil {
+		return err
+	}
+
+	if reply[1] != 0 {
+		return errors.New("authentication failed")
+	}
+
+	return nil
+}
+
+// connectDialer connects through an HTTP proxy with the CONNECT method.
+type connectDialer struct {
+	addr    string
+	user    *url.Userinfo
+	forward Dialer
+}
+
+// DialContext implements Dialer.
+func (d *connectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("netauth: HTTP proxy does not support network %q", network)
+	}
+
+	conn, err := d.forward.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return nil, fmt.Errorf("netauth: connect to proxy %s: %w", d.addr, err)
+	}
+
+	var tunnel net.Conn
+	err = withDeadline(ctx, conn, func() error {
+		var err error
+		tunnel, err = d.connect(conn, addr)
+		return err
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("netauth: HTTP proxy %s: %w", d.addr, err)
+	}
+
+	return tunnel, nil
+}
+
+// connect asks the proxy for a tunnel to addr.
+func (d *connectDialer) connect(conn net.Conn, addr string) (net.Conn, error) {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+
+	if d.user != nil {
+		password, _ := d.user.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("CONNECT %s: %s", addr, resp.Status)
+	}
+
+	if br.Buffered() > 0 {
+		// keep the bytes the server sent after the response
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose first bytes were read into a buffer.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read implements net.Conn.
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// withDeadline runs the handshake fn with the deadline of ctx set on conn.
+func withDeadline(ctx context.Context, conn net.Conn, fn func() error) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	return fn()
+}
@@ -0,0 +1,347 @@
+package netauth
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// serve accepts connections on a local listener and handles them with
+// handle, until the test ends. It returns the address of the listener.
+func serve(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// echo writes back what it reads.
+func echo(conn net.Conn) {
+	io.Copy(conn, conn)
+}
+
+// tunnel dials addr and copies between conn and the new connection.
+func tunnel(conn net.Conn, r io.Reader, addr string) {
+	target, err := net.Dial("tcp", addr)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+
+	go io.Copy(target, r)
+	io.Copy(conn, target)
+}
+
+// socks5Proxy returns a SOCKS5 proxy handler, requiring the password
+// method when user is set.
+func socks5Proxy(user, password string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+
+		var greeting [2]byte
+		if _, err := io.ReadFull(r, greeting[:]); err != nil {
+			return
+		}
+		methods := make([]byte, greeting[1])
+		if _, err := io.ReadFull(r, methods); err != nil {
+			return
+		}
+
+		want := byte(socks5NoAuth)
+		if user != "" {
+			want = socks5PasswordAuth
+		}
+		if methods[0] != want {
+			conn.Write([]byte{socks5Version, socks5NoAcceptable})
+			return
+		}
+		conn.Write([]byte{socks5Version, want})
+
+		if user != "" {
+			read := func() string {
+				n, _ := r.ReadByte()
+				b := make([]byte, n)
+				io.ReadFull(r, b)
+				return string(b)
+			}
+			r.ReadByte()
+			if read() != user || read() != password {
+				conn.Write([]byte{1, 1})
+				return
+			}
+			conn.Write([]byte{1, 0})
+		}
+
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return
+		}
+
+		var host string
+		switch header[3] {
+		case socks5IPv4:
+			ip := make([]byte, net.IPv4len)
+			io.ReadFull(r, ip)
+			host = net.IP(ip).String()
+		case socks5Domain:
+			n, _ := r.ReadByte()
+			name := make([]byte, n)
+			io.ReadFull(r, name)
+			host = string(name)
+		}
+
+		var port [2]byte
+		io.ReadFull(r, port[:])
+		addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port[:]))))
+
+		conn.Write([]byte{socks5Version, 0, 0, socks5IPv4, 0, 0, 0, 0, 0, 0})
+		tunnel(conn, r, addr)
+	}
+}
+
+// connectProxy returns an HTTP CONNECT proxy handler, requiring the basic
+// credentials when user is set.
+func connectProxy(user, password string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+
+		req, err := http.ReadRequest(r)
+		if err != nil || req.Method != http.MethodConnect {
+			return
+		}
+
+		if user != "" {
+			check := &http.Request{Header: http.Header{"Authorization": req.Header["Proxy-Authorization"]}}
+			if u, p, ok := check.BasicAuth(); !ok || u != user || p != password {
+				io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+				return
+			}
+		}
+
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+		tunnel(conn, r, req.Host)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	target := serve(t, echo)
+
+	tests := []struct {
+		name     string
+		proxyURL string
+		handle   func(conn net.Conn)
+		ok       bool
+	}{
+		{name: "socks5", proxyURL: "socks5://%s", handle: socks5Proxy("", ""), ok: true},
+		{name: "socks5-password", proxyURL: "socks5://agent:s3cret@%s", handle: socks5Proxy("agent", "s3cret"), ok: true},
+		{name: "socks5-wrong-password", proxyURL: "socks5://agent:wrong@%s", handle: socks5Proxy("agent", "s3cret")},
+		{name: "socks5-missing-password", proxyURL: "socks5://%s", handle: socks5Proxy("agent", "s3cret")},
+		{name: "http", proxyURL: "http://%s", handle: connectProxy("", ""), ok: true},
+		{name: "http-password", proxyURL: "http://agent:s3cret@%s", handle: connectProxy("agent", "s3cret"), ok: true},
+		{name: "http-wrong-password", proxyURL: "http://agent:wrong@%s", handle: connectProxy("agent", "s3cret")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxyURL, err := url.Parse(fmt.Sprintf(tt.proxyURL, serve(t, tt.handle)))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			dialer, err := Proxy(proxyURL, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			conn, err := dialer.DialContext(ctx, "tcp", target)
+			if !tt.ok {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer conn.Close()
+
+			_, err = conn.Write([]byte("hello"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := make([]byte, 5)
+			_, err = io.ReadFull(conn, got)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(got) != "hello" {
+				t.Errorf("string(got) = %q, want %q", string(got), "hello")
+			}
+		})
+	}
+}
+
+func TestProxy_UnsupportedScheme(t *testing.T) {
+	_, err := Proxy(&url.URL{Scheme: "ftp", Host: "proxy:21"}, nil)
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	_, err = (&socks5Dialer{addr: "proxy:1080", forward: &net.Dialer{}}).DialContext(context.Background(), "udp", "collector:7070")
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDialThrough(t *testing.T) {
+	c := newCerts(t)
+	server := TLS{CertFile: c.serverCert, KeyFile: c.serverKey}
+
+	t.Run("socks5", func(t *testing.T) {
+		listener, err := Listen("tcp", "127.0.0.1:0", server)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer listener.Close()
+		go acceptEcho(listener)
+
+		proxyURL, err := url.Parse("socks5://" + serve(t, socks5Proxy("", "")))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		dialer, err := Proxy(proxyURL, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		conn, err := DialThrough(context.Background(), dialer, "tcp", listener.Addr().String(), TLS{CAFile: c.ca})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertEcho(t, conn)
+	})
+
+	t.Run("unix", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "collector.sock")
+		listener, err := Listen("unix", path, server)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer listener.Close()
+		go acceptEcho(listener)
+
+		conn, err := DialThrough(context.Background(), nil, "unix", path, TLS{CAFile: c.ca, ServerName: "collector"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		assertEcho(t, conn)
+	})
+}
+
+// acceptEcho echoes the connections accepted on l.
+func acceptEcho(l net.Listener) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			echo(conn)
+		}()
+	}
+}
+
+// assertEcho checks that conn echoes what is written to it, and closes it.
+func assertEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	defer conn.Close()
+
+	_, err := conn.Write([]byte("ping"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make([]byte, 4)
+	_, err = io.ReadFull(conn, got)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(got) != "ping" {
+		t.Errorf("string(got) = %q, want %q", string(got), "ping")
+	}
+}
+
+// dialerFunc implements Dialer with a function.
+type dialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (f dialerFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return f(ctx, network, addr)
+}
+
+func TestHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// route every request to the test server, whatever its host
+	var dials atomic.Int32
+	dialer := dialerFunc(func(ctx context.Context, network, _ string) (net.Conn, error) {
+		dials.Add(1)
+		return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+	})
+
+	client, err := HTTPClient(dialer, TLS{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := client.Post("http://collector.internal/ingest", "application/json", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("resp.StatusCode = %v, want %v", resp.StatusCode, http.StatusNoContent)
+	}
+	if got := dials.Load(); got != int32(1) {
+		t.Errorf("dials.Load() = %v, want %v", got, int32(1))
+	}
+}
@@ -0,0 +1,269 @@
+// Package netauth holds the TLS and authentication settings shared by the
+// writers shipping entries over the network and by the collector receiving
+// them (see golog.NewWireWriter and the collector package).
+//
+// TCP streams are protected with TLS, mutual when client certificates are
+// configured: dial with Dial and listen with Listen. Connections can go
+// through a SOCKS5 or HTTP proxy, or any Dialer (see Proxy and DialThrough),
+// and HTTPClient builds the client of HTTP sinks from the same settings.
+// HTTP requests are authenticated with a bearer token, basic credentials, or
+// an HMAC signature of the body: clients call Auth.Sign, servers Auth.Verify.
+//
+// Example, for a process streaming to a collector over mutual TLS:
+//
+//	conn, err := netauth.Dial(ctx, "tcp", "collector:7070", netauth.TLS{
+//	    CAFile:   "/etc/golog/ca.pem",
+//	    CertFile: "/etc/golog/client.pem",
+//	    KeyFile:  "/etc/golog/client-key.pem",
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	writer, err := golog.NewWireWriter(conn)
+package netauth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTP headers of HMAC signatures
+const (
+	// HeaderSignature holds the hex HMAC-SHA256 of the timestamp, a dot, and
+	// the body, prefixed with "sha256="
+	HeaderSignature = "X-Golog-Signature"
+	// HeaderTimestamp holds the Unix time of the signature, in seconds
+	HeaderTimestamp = "X-Golog-Timestamp"
+)
+
+// defaultMaxSkew is how old, or how far in the future, a signature may be.
+const defaultMaxSkew = 5 * time.Minute
+
+// ErrUnauthorized is returned by Auth.Verify when a request is not
+// authenticated.
+var ErrUnauthorized = errors.New("netauth: unauthorized")
+
+// TLS holds TLS settings. The zero value uses the system roots and no client
+// certificate.
+type TLS struct {
+	// CAFile is a PEM file of the certificate authorities trusted to sign
+	// the certificates of the peer: the server for clients, the clients for
+	// servers. Empty uses the system roots for clients and disables client
+	// certificate verification for servers.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the certificate presented to
+	// the peer: the client certificate of mutual TLS, or the server
+	// certificate.
+	CertFile string
+	KeyFile  string
+	// ServerName is the name verified against the server certificate (SNI).
+	// Empty uses the host of the dialed address.
+	ServerName string
+	// InsecureSkipVerify disables the verification of the server
+	// certificate. Use it for tests only.
+	InsecureSkipVerify bool
+}
+
+// Client returns the tls.Config of a client.
+func (t TLS) Client() (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+
+	if t.CAFile != "" {
+		pool, err := loadPool(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("netauth: load client certificate: %w", err)
+		}
+
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// Server returns the tls.Config of a server. CertFile and KeyFile are
+// required; with CAFile, clients must present a certificate signed by one of
+// its authorities (mutual TLS).
+func (t TLS) Server() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("netauth: load server certificate: %w", err)
+	}
+
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if t.CAFile != "" {
+		pool, err := loadPool(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg.ClientCAs = pool
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// Dial connects to addr over TLS configured by t. Use DialThrough to connect
+// through a proxy or a custom dialer.
+func Dial(ctx context.Context, network, addr string, t TLS) (net.Conn, error) {
+	return DialThrough(ctx, nil, network, addr, t)
+}
+
+// Listen listens on addr and accepts TLS connections configured by t, e.g.
+// for collector.Collector.Serve.
+func Listen(network, addr string, t TLS) (net.Listener, error) {
+	cfg, err := t.Server()
+	if err != nil {
+		return nil, err
+	}
+
+	return tls.Listen(network, addr, cfg)
+}
+
+// loadPool returns the certificate pool of a PEM file.
+func loadPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("netauth: read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("netauth: no certificate in CA file %q", path)
+	}
+
+	return pool, nil
+}
+
+// Auth holds the credentials of HTTP requests. Set one method; when several
+// are set, Sign applies all of them and Verify requires all of them. The
+// zero value does not authenticate.
+type Auth struct {
+	// BearerToken is sent in the Authorization header
+	BearerToken string
+	// Username and Password are sent as basic credentials
+	Username string
+	Password string
+	// HMACKey signs the body with HMAC-SHA256 (see HeaderSignature)
+	HMACKey []byte
+	// MaxSkew is how old, or how far in the future, an HMAC signature may be
+	// for Verify, to limit replays. The default is 5 minutes.
+	MaxSkew time.Duration
+}
+
+// Sign adds the credentials to r, whose body is body.
+func (a Auth) Sign(r *http.Request, body []byte) {
+	if a.BearerToken != "" {
+		r.Header.Set("Authorization", "Bearer "+a.BearerToken)
+	}
+
+	if a.Username != "" || a.Password != "" {
+		r.SetBasicAuth(a.Username, a.Password)
+	}
+
+	if len(a.HMACKey) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		r.Header.Set(HeaderTimestamp, timestamp)
+		r.Header.Set(HeaderSignature, "sha256="+a.signature(timestamp, body))
+	}
+}
+
+// Verify checks the credentials of r, whose body is body, and returns an
+// error wrapping ErrUnauthorized if they are missing or wrong. Credentials
+// are compared in constant time.
+func (a Auth) Verify(r *http.Request, body []byte) error {
+	if a.BearerToken != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !equal(token, a.BearerToken) {
+			return fmt.Errorf("%w: invalid bearer token", ErrUnauthorized)
+		}
+	}
+
+	if a.Username != "" || a.Password != "" {
+		username, password, ok := r.BasicAuth()
+		// evaluate both comparisons, so that the time taken does not tell
+		// which one failed
+		userOK, passwordOK := equal(username, a.Username), equal(password, a.Password)
+		if !ok || !userOK || !passwordOK {
+			return fmt.Errorf("%w: invalid basic credentials", ErrUnauthorized)
+		}
+	}
+
+	if len(a.HMACKey) > 0 {
+		if err := a.verifySignature(r, body); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifySignature checks the HMAC signature and its timestamp.
+func (a Auth) verifySignature(r *http.Request, body []byte) error {
+	timestamp := r.Header.Get(HeaderTimestamp)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: missing or invalid signature timestamp", ErrUnauthorized)
+	}
+
+	maxSkew := a.MaxSkew
+	if maxSkew <= 0 {
+		maxSkew = defaultMaxSkew
+	}
+
+	if skew := time.Since(time.Unix(seconds, 0)); skew > maxSkew || skew < -maxSkew {
+		return fmt.Errorf("%w: signature timestamp outside the allowed skew", ErrUnauthorized)
+	}
+
+	signature, ok := strings.CutPrefix(r.Header.Get(HeaderSignature), "sha256=")
+	if !ok || !equal(signature, a.signature(timestamp, body)) {
+		return fmt.Errorf("%w: invalid signature", ErrUnauthorized)
+	}
+
+	return nil
+}
+
+// signature returns the hex HMAC-SHA256 of the timestamp and the body.
+func (a Auth) signature(timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, a.HMACKey)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// equal compares secrets in constant time.
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,266 @@
+package netauth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuth(t *testing.T) {
+	body := []byte(`{"msg":"order placed"}`)
+
+	tests := []struct {
+		name   string
+		signer Auth
+		// tamper modifies the signed request
+		tamper func(r *http.Request)
+		ok     bool
+	}{
+		{name: "bearer", signer: Auth{BearerToken: "token"}, ok: true},
+		{name: "wrong-bearer", signer: Auth{BearerToken: "other"}},
+		{name: "basic", signer: Auth{Username: "agent", Password: "secret"}, ok: true},
+		{name: "wrong-password", signer: Auth{Username: "agent", Password: "guess"}},
+		{name: "hmac", signer: Auth{HMACKey: []byte("key")}, ok: true},
+		{name: "wrong-key", signer: Auth{HMACKey: []byte("other")}},
+		{name: "missing", signer: Auth{}},
+		{
+			name:   "tampered-timestamp",
+			signer: Auth{HMACKey: []byte("key")},
+			tamper: func(r *http.Request) {
+				r.Header.Set(HeaderTimestamp, strconv.FormatInt(time.Now().Unix()+1, 10))
+			},
+		},
+		{
+			name:   "expired-signature",
+			signer: Auth{HMACKey: []byte("key")},
+			tamper: func(r *http.Request) {
+				old := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+				r.Header.Set(HeaderTimestamp, old)
+				r.Header.Set(HeaderSignature, "sha256="+Auth{HMACKey: []byte("key")}.signature(old, body))
+			},
+		},
+	}
+
+	verifiers := map[string]Auth{
+		"bearer": {BearerToken: "token"},
+		"basic":  {Username: "agent", Password: "secret"},
+		"hmac":   {HMACKey: []byte("key")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for method, verifier := range verifiers {
+				r := httptest.NewRequest(http.MethodPost, "/ingest", nil)
+				tt.signer.Sign(r, body)
+				if tt.tamper != nil {
+					tt.tamper(r)
+				}
+
+				err := verifier.Verify(r, body)
+				if tt.ok && strings.HasPrefix(tt.name, method) {
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", method, err)
+					}
+					continue
+				}
+
+				if !errors.Is(err, ErrUnauthorized) {
+					t.Errorf("%s: err = %v, want %v", method, err, ErrUnauthorized)
+				}
+			}
+		})
+	}
+}
+
+func TestAuth_TamperedBody(t *testing.T) {
+	auth := Auth{HMACKey: []byte("key")}
+
+	r := httptest.NewRequest(http.MethodPost, "/ingest", nil)
+	auth.Sign(r, []byte(`{"msg":"a"}`))
+
+	if err := auth.Verify(r, []byte(`{"msg":"b"}`)); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("got %v, want %v", err, ErrUnauthorized)
+	}
+}
+
+// certs writes a CA and certificates signed by it to dir.
+type certs struct {
+	ca, serverCert, serverKey, clientCert, clientKey string
+}
+
+func newCerts(t *testing.T) certs {
+	t.Helper()
+
+	dir := t.TempDir()
+	write := func(name, kind string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return path
+	}
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "golog test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	issue := func(serial int64, name string, usage x509.ExtKeyUsage) (string, string) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: name},
+			DNSNames:     []string{name},
+			IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		return write(name+".pem", "CERTIFICATE", der), write(name+"-key.pem", "EC PRIVATE KEY", keyDER)
+	}
+
+	c := certs{ca: write("ca.pem", "CERTIFICATE", caDER)}
+	c.serverCert, c.serverKey = issue(2, "collector", x509.ExtKeyUsageServerAuth)
+	c.clientCert, c.clientKey = issue(3, "agent", x509.ExtKeyUsageClientAuth)
+
+	return c
+}
+
+func TestDialListen_MutualTLS(t *testing.T) {
+	c := newCerts(t)
+
+	listener, err := Listen("tcp", "127.0.0.1:0", TLS{CAFile: c.ca, CertFile: c.serverCert, KeyFile: c.serverKey})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				data, _ := io.ReadAll(conn)
+				received <- string(data)
+			}()
+		}
+	}()
+
+	ctx := context.Background()
+	addr := listener.Addr().String()
+
+	tests := []struct {
+		name string
+		tls  TLS
+		ok   bool
+	}{
+		{
+			name: "client-certificate",
+			tls:  TLS{CAFile: c.ca, CertFile: c.clientCert, KeyFile: c.clientKey, ServerName: "collector"},
+			ok:   true,
+		},
+		{name: "no-client-certificate", tls: TLS{CAFile: c.ca, ServerName: "collector"}},
+		{name: "wrong-server-name", tls: TLS{CAFile: c.ca, CertFile: c.clientCert, KeyFile: c.clientKey, ServerName: "other"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := Dial(ctx, "tcp", addr, tt.tls)
+			if err == nil {
+				_, err = conn.Write([]byte(tt.name))
+				conn.Close()
+			}
+
+			if !tt.ok {
+				// without a client certificate, the handshake completes on
+				// the client side and the server rejects it
+				if err == nil {
+					if got := <-received; got != "" {
+						t.Errorf("<-received = %q, want %q", got, "")
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := <-received; got != tt.name {
+				t.Errorf("<-received = %q, want %q", got, tt.name)
+			}
+		})
+	}
+}
+
+func TestTLS_Invalid(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	_, err := TLS{CAFile: missing}.Client()
+	if err == nil {
+		t.Error("expected an error")
+	}
+
+	_, err = TLS{}.Server()
+	if err == nil {
+		t.Error("servers need a certificate: expected an error")
+	}
+
+	_, err = TLS{CertFile: missing, KeyFile: missing}.Client()
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
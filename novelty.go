@@ -0,0 +1,103 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FieldNovel is the key of the field marking entries whose message template
+// was not seen recently (see NewNoveltyEnricher).
+const FieldNovel = "novel"
+
+// noveltyMaxTemplates is the maximum number of message templates remembered
+// by a NoveltyEnricher.
+const noveltyMaxTemplates = 10000
+
+// NoveltyEnricher adds novel=true to entries whose message template (see
+// MessageTemplate) has not been seen at the same level within its window.
+// Alerting rules can key on the field to page only for new failure modes
+// rather than for ongoing, known noise.
+//
+// A template is forgotten once it has not been logged for the window, so a
+// failure that comes back after a quiet period is novel again. At most
+// 10000 templates are remembered; when the limit is reached the least
+// recently seen are forgotten first.
+type NoveltyEnricher struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu sync.Mutex
+	// lastSeen holds when each level and template was last logged
+	lastSeen map[string]time.Time
+	// lastPrune is when templates older than the window were last forgotten
+	lastPrune time.Time
+}
+
+// NewNoveltyEnricher returns a NoveltyEnricher tagging entries whose message
+// template was not seen within window.
+//
+// Example:
+//
+//	golog.RegisterEnricher(golog.NewNoveltyEnricher(30 * time.Minute))
+func NewNoveltyEnricher(window time.Duration) *NoveltyEnricher {
+	return &NoveltyEnricher{
+		window:   window,
+		now:      time.Now,
+		lastSeen: make(map[string]time.Time),
+	}
+}
+
+// Enrich implements Enricher.
+func (e *NoveltyEnricher) Enrich(_ context.Context, level string, msg string, fields map[string]any) {
+	key := level + " " + MessageTemplate(msg)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.prune(now)
+
+	last, seen := e.lastSeen[key]
+	if !seen || now.Sub(last) >= e.window {
+		fields[FieldNovel] = true
+	}
+
+	if !seen && len(e.lastSeen) >= noveltyMaxTemplates {
+		e.forgetOldest()
+	}
+
+	e.lastSeen[key] = now
+}
+
+// prune forgets the templates not seen within the window, at most once per
+// window.
+func (e *NoveltyEnricher) prune(now time.Time) {
+	if now.Sub(e.lastPrune) < e.window {
+		return
+	}
+
+	for key, last := range e.lastSeen {
+		if now.Sub(last) >= e.window {
+			delete(e.lastSeen, key)
+		}
+	}
+
+	e.lastPrune = now
+}
+
+// forgetOldest forgets the least recently seen template.
+func (e *NoveltyEnricher) forgetOldest() {
+	var (
+		oldestKey string
+		oldest    time.Time
+	)
+
+	for key, last := range e.lastSeen {
+		if oldestKey == "" || last.Before(oldest) {
+			oldestKey, oldest = key, last
+		}
+	}
+
+	delete(e.lastSeen, oldestKey)
+}
@@ -0,0 +1,85 @@
+package golog
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestNoveltyEnricher(t *testing.T) {
+	type logged struct {
+		after time.Duration
+		level string
+		msg   string
+		novel bool
+	}
+
+	tests := []struct {
+		name    string
+		entries []logged
+	}{
+		{
+			name: "first-seen",
+			entries: []logged{
+				{level: "ERROR", msg: "payment 1234 failed", novel: true},
+				{after: time.Second, level: "ERROR", msg: "payment 5678 failed", novel: false},
+			},
+		},
+		{
+			name: "other-template",
+			entries: []logged{
+				{level: "ERROR", msg: "payment 1234 failed", novel: true},
+				{after: time.Second, level: "ERROR", msg: "refund 1234 failed", novel: true},
+			},
+		},
+		{
+			name: "other-level",
+			entries: []logged{
+				{level: "INFO", msg: "payment 1234 failed", novel: true},
+				{after: time.Second, level: "ERROR", msg: "payment 1234 failed", novel: true},
+			},
+		},
+		{
+			name: "seen-again-after-window",
+			entries: []logged{
+				{level: "ERROR", msg: "payment 1234 failed", novel: true},
+				{after: 10 * time.Minute, level: "ERROR", msg: "payment 1234 failed", novel: true},
+			},
+		},
+		{
+			name: "ongoing-noise",
+			entries: []logged{
+				{level: "ERROR", msg: "payment 1234 failed", novel: true},
+				{after: 4 * time.Minute, level: "ERROR", msg: "payment 1234 failed", novel: false},
+				{after: 4 * time.Minute, level: "ERROR", msg: "payment 1234 failed", novel: false},
+				{after: 4 * time.Minute, level: "ERROR", msg: "payment 1234 failed", novel: false},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 3, 30, 12, 0, 0, 0, time.UTC)
+
+			e := NewNoveltyEnricher(5 * time.Minute)
+			e.now = func() time.Time { return now }
+
+			for i, entry := range tt.entries {
+				now = now.Add(entry.after)
+
+				fields := map[string]any{}
+				e.Enrich(context.Background(), entry.level, entry.msg, fields)
+
+				if entry.novel {
+					if fields[FieldNovel] != true {
+						t.Errorf("entry %d: fields[FieldNovel] = %v, want true", i, fields[FieldNovel])
+					}
+				} else {
+					if _, ok := fields[FieldNovel]; ok {
+						t.Errorf("entry %d: fields has key %q, want none", i, FieldNovel)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// onceKeys holds an *atomic.Bool per key of Once, set once logged
+	onceKeys sync.Map
+	// everyKeys holds an *atomic.Int64 per key of Every, with the Unix
+	// nanoseconds of the last entry
+	everyKeys sync.Map
+)
+
+// Once creates a new LogScope that writes at most one entry per process for
+// key, replacing ad-hoc sync.Once wrappers around warnings in library code.
+// Entries filtered out by level do not count.
+//
+// Example:
+//
+//	golog.Once("config.legacy-format").Warn("legacy config format is deprecated")
+func Once(key string) *LogScope {
+	scope := newScope()
+	scope.gate = func() bool {
+		logged, _ := onceKeys.LoadOrStore(key, new(atomic.Bool))
+		return logged.(*atomic.Bool).CompareAndSwap(false, true)
+	}
+
+	return scope
+}
+
+// Every creates a new LogScope that writes at most one entry per interval
+// for key, e.g. to report a recurring condition without flooding the logs.
+// Entries filtered out by level do not count.
+//
+// Example:
+//
+//	golog.Every("cache.full", time.Minute).Warn("cache is full, evicting entries")
+func Every(key string, interval time.Duration) *LogScope {
+	scope := newScope()
+	scope.gate = func() bool {
+		v, _ := everyKeys.LoadOrStore(key, new(atomic.Int64))
+		last := v.(*atomic.Int64)
+
+		now := time.Now().UnixNano()
+		prev := last.Load()
+		if prev != 0 && now-prev < int64(interval) {
+			return false
+		}
+
+		return last.CompareAndSwap(prev, now)
+	}
+
+	return scope
+}
@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestOnce(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Once("test.once").Warn("deprecated option")
+		}()
+	}
+	wg.Wait()
+
+	Once("test.once-filtered").Debug("below the level")
+	Once("test.once-filtered").Info("after a filtered entry")
+	Once("test.once-other").With("k", "v").Warn("other key")
+
+	var messages []string
+	for _, entry := range w.entries {
+		messages = append(messages, entry.msg)
+	}
+
+	want := []string{"deprecated option", "after a filtered entry", "other key"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %v, want %v", messages, want)
+	}
+}
+
+func TestEvery(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	Every("test.every", time.Hour).Warn("cache full")
+	Every("test.every", time.Hour).Warn("cache full")
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+
+	v, _ := everyKeys.Load("test.every")
+	v.(*atomic.Int64).Add(-int64(2 * time.Hour))
+
+	Every("test.every", time.Hour).Warn("cache full")
+	if len(w.entries) != 2 {
+		t.Errorf("logged again once the interval has passed: len(w.entries) = %d, want 2", len(w.entries))
+	}
+}
@@ -0,0 +1,360 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// WriterOption configures the built-in writers created by NewDefaultWriter
+// and NewJSONWriter.
+type WriterOption func(*writerOptions)
+
+// writerOptions holds the settings shared by the built-in writers.
+type writerOptions struct {
+	// sortKeys orders custom fields by key instead of map iteration order
+	sortKeys bool
+	// int64AsString encodes int64 and uint64 values as JSON strings
+	int64AsString bool
+	// reservedPolicy handles custom fields named like standard fields
+	reservedPolicy ReservedFieldPolicy
+	// errorHandler receives errors the writer cannot return to the caller
+	errorHandler func(error)
+	// marshalFunc replaces encoding/json for field values when set
+	marshalFunc func(v any) ([]byte, error)
+	// tolerant renders values that cannot be encoded instead of failing
+	tolerant bool
+	// redactedHeaders holds the lowercase names of masked HTTP headers;
+	// nil uses defaultRedactedHeaders
+	redactedHeaders map[string]bool
+	// maskedClasses holds the masked classifications; nil masks
+	// ClassSensitive only
+	maskedClasses map[Classification]bool
+	// metrics accumulates the self metrics of the writer; nil disables them
+	metrics *selfMetrics
+	// framing delimits entries
+	framing Framing
+	// continuation starts the continuation lines of multi-line text in the
+	// default writer; empty uses defaultContinuationPrefix
+	continuation string
+	// location is the time zone of the rendered timestamps; nil renders
+	// them in UTC
+	location *time.Location
+	// structuredErrors renders errors as objects instead of strings in the
+	// JSON writer
+	structuredErrors bool
+	// errorChainDepth is the number of causes rendered in the chain of a
+	// structured error
+	errorChainDepth int
+	// causeFields are the fields of the causes of a structured error; nil
+	// uses defaultCauseFields
+	causeFields []CauseField
+}
+
+// ReservedFieldPolicy controls what the JSON writer does with a custom field
+// whose key collides with a standard field (time, level, msg, caller).
+type ReservedFieldPolicy int
+
+const (
+	// ReservedFieldOverride lets the custom field replace the standard field.
+	// This is the default.
+	ReservedFieldOverride ReservedFieldPolicy = iota
+	// ReservedFieldPrefix keeps the standard field and renames the custom
+	// field with the "fields_" prefix, e.g. "msg" becomes "fields_msg".
+	ReservedFieldPrefix
+	// ReservedFieldError keeps the standard field, drops the custom field, and
+	// reports ErrReservedField to the writer's error handler (see OnError).
+	ReservedFieldError
+)
+
+// reservedFieldPrefix is prepended to colliding keys by ReservedFieldPrefix.
+const reservedFieldPrefix = "fields_"
+
+// ErrReservedField is reported to the error handler when a custom field
+// collides with a standard field under the ReservedFieldError policy.
+var ErrReservedField = errors.New("golog: field collides with a reserved field")
+
+// SortKeys makes the writer emit custom fields in ascending key order,
+// including the keys of nested maps. Without it, fields follow Go's random
+// map iteration order, so the same entry can render differently between runs.
+// Use it for golden tests, diff-based assertions, or when humans compare
+// entries side by side.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.SortKeys())
+func SortKeys() WriterOption {
+	return func(o *writerOptions) {
+		o.sortKeys = true
+	}
+}
+
+// Int64AsString makes the JSON writer encode int64 and uint64 field values,
+// including those nested in map[string]any and []any, as decimal strings.
+// Values of these types can exceed 2^53, beyond which consumers that parse
+// JSON numbers as float64 (JavaScript, many log pipelines) silently round
+// them. Smaller integer types such as int and int32 are still encoded as
+// numbers, so choose int64 for identifiers that must stay exact.
+//
+// Values of type json.Number are always written verbatim as JSON numbers.
+func Int64AsString() WriterOption {
+	return func(o *writerOptions) {
+		o.int64AsString = true
+	}
+}
+
+// OnReservedField sets the policy the JSON writer applies when a custom field
+// uses the key of a standard field (time, level, msg, caller).
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.OnReservedField(golog.ReservedFieldPrefix))
+func OnReservedField(policy ReservedFieldPolicy) WriterOption {
+	return func(o *writerOptions) {
+		o.reservedPolicy = policy
+	}
+}
+
+// OnError sets the handler that receives errors a writer cannot return to the
+// caller, such as fields that fail to marshal or reserved field collisions.
+// By default these errors are printed to os.Stderr.
+// The handler may be called from multiple goroutines at once.
+func OnError(handler func(error)) WriterOption {
+	return func(o *writerOptions) {
+		o.errorHandler = handler
+	}
+}
+
+// TimeZone sets the time zone of the timestamps the writer renders. The
+// default is UTC, so that the entries of hosts in different zones sort and
+// interleave correctly once aggregated; pass time.Local for the zone of the
+// host, or a zone loaded with time.LoadLocation. The time values of fields
+// are rendered as they are.
+//
+// Example:
+//
+//	writer := golog.NewDefaultWriter(os.Stdout, golog.TimeZone(time.Local))
+func TimeZone(loc *time.Location) WriterOption {
+	return func(o *writerOptions) {
+		o.location = loc
+	}
+}
+
+// StructuredErrors makes the JSON writer render errors as objects rather
+// than flattening them to their message, so that log pipelines can index
+// the cause of a failure. The error of an entry (see LogScope.WithError) and
+// the error values of fields are rendered as:
+//
+//	"error": {
+//	    "message": "load config: open app.yaml: no such file or directory",
+//	    "type": "*golog.stackError",
+//	    "stack": ["main.load /app/main.go:42", "main.main /app/main.go:17"],
+//	    "chain": [{"message": "open app.yaml: no such file or directory", "type": "*fs.PathError"}]
+//	}
+//
+// The stack is the one recorded by StackErrors, or by github.com/pkg/errors
+// and other packages whose errors have a StackTrace method, and is omitted
+// when no error of the chain has one. The chain lists the errors wrapped by
+// the error, outermost first; see ErrorChainDepth and ErrorCauseFields to
+// bound it.
+//
+// The error of an entry is only available to the writer when the entry is
+// passed to its WriteEntry method, as the loggers do; writers wrapping the
+// JSON writer, such as NewAsyncWriter, pass the message of the error.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.StructuredErrors())
+func StructuredErrors() WriterOption {
+	return func(o *writerOptions) {
+		o.structuredErrors = true
+	}
+}
+
+// defaultErrorChainDepth is the number of causes rendered in the chain of a
+// structured error by default.
+const defaultErrorChainDepth = 32
+
+// ErrorChainDepth sets the number of causes the JSON writer renders in the
+// chain of a structured error (see StructuredErrors), so that the errors of
+// layered code, wrapped at every layer, stay bounded in size. The causes
+// past the depth are left out, and the error gets "chain_truncated": true.
+// The default is 32; zero or less renders no chain.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.StructuredErrors(), golog.ErrorChainDepth(5))
+func ErrorChainDepth(depth int) WriterOption {
+	return func(o *writerOptions) {
+		o.errorChainDepth = max(depth, 0)
+	}
+}
+
+// CauseField is a field of the causes in the chain of a structured error
+// (see ErrorCauseFields).
+type CauseField int
+
+const (
+	// CauseType is the Go type of the cause, e.g. "*fs.PathError"
+	CauseType CauseField = iota
+	// CauseMessage is the message of the cause, which repeats the end of
+	// the message of the error when each layer adds a prefix
+	CauseMessage
+	// CauseFrame is the first frame of the stack trace recorded in the
+	// cause, e.g. "repo.(*Orders).Get /app/repo/orders.go:42", where the
+	// cause was created or wrapped; it is omitted when the cause has none
+	CauseFrame
+)
+
+// defaultCauseFields are the fields of the causes of a structured error by
+// default.
+var defaultCauseFields = []CauseField{CauseType, CauseMessage}
+
+// ErrorCauseFields sets the fields the JSON writer renders for each cause in
+// the chain of a structured error (see StructuredErrors). The default is
+// CauseType and CauseMessage. Leaving out CauseMessage keeps the entries
+// small when every layer wraps the message of the error below it, while
+// CauseFrame locates the layer of each cause.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.StructuredErrors(),
+//	    golog.ErrorCauseFields(golog.CauseType, golog.CauseFrame))
+func ErrorCauseFields(fields ...CauseField) WriterOption {
+	return func(o *writerOptions) {
+		o.causeFields = append([]CauseField{}, fields...)
+	}
+}
+
+// timestamp renders the time of an entry in the time zone of the writer.
+func (o writerOptions) timestamp(t time.Time) string {
+	if o.location == nil {
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return t.In(o.location).Format(time.RFC3339)
+}
+
+// newWriterOptions applies opts on top of the default writer settings.
+func newWriterOptions(opts []WriterOption) writerOptions {
+	o := writerOptions{errorChainDepth: defaultErrorChainDepth}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return o
+}
+
+// handleError passes err to the configured error handler, or prints it to
+// os.Stderr when none is set.
+func (o writerOptions) handleError(err error) {
+	if o.errorHandler != nil {
+		o.errorHandler(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "golog: %v\n", err)
+}
+
+// MarshalFunc sets the function the writers use to encode field values as
+// JSON, in place of encoding/json. It lets applications plug in a faster
+// encoder without golog depending on it; the github.com/jkaveri/golog/sonic
+// module provides one based on Sonic. With SortKeys, golog still orders the
+// top-level fields, while the key order of nested maps is up to marshal.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, gologsonic.Marshal())
+func MarshalFunc(marshal func(v any) ([]byte, error)) WriterOption {
+	return func(o *writerOptions) {
+		o.marshalFunc = marshal
+	}
+}
+
+// marshal encodes v as JSON. String containers such as http.Header use a
+// fast path (see marshalContainer). With TolerantEncoding, values that fail to
+// encode are encoded again with unsupported parts replaced by placeholders.
+func (o writerOptions) marshal(v any) ([]byte, error) {
+	if data, ok := o.marshalContainer(v); ok {
+		return data, nil
+	}
+
+	data, err := o.marshalValue(v)
+	if err == nil || !o.tolerant {
+		return data, err
+	}
+
+	if data, err = o.marshalValue(tolerantValue(v, 0)); err == nil {
+		return data, nil
+	}
+
+	return o.marshalValue(fmt.Sprintf("<%T: %v>", v, err))
+}
+
+// marshalValue encodes v as JSON with the function set by MarshalFunc, or
+// with encoding/json, which sorts map keys, without escaping HTML characters.
+func (o writerOptions) marshalValue(v any) ([]byte, error) {
+	if o.marshalFunc != nil {
+		return o.marshalFunc(v)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
+
+// fieldKeys returns the keys of fields, sorted when SortKeys is set.
+func (o writerOptions) fieldKeys(fields map[string]any) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+
+	if o.sortKeys {
+		sort.Strings(keys)
+	}
+
+	return keys
+}
+
+// fieldValue converts a field value according to the numeric options.
+func (o writerOptions) fieldValue(v any) any {
+	if !o.int64AsString {
+		return v
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case uint64:
+		return strconv.FormatUint(v, 10)
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for k, item := range v {
+			converted[k] = o.fieldValue(item)
+		}
+
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = o.fieldValue(item)
+		}
+
+		return converted
+	default:
+		return v
+	}
+}
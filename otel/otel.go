@@ -0,0 +1,370 @@
+// Package otel writes golog entries as OpenTelemetry log records, so that
+// they land in any OTLP-compatible backend through the OpenTelemetry SDK.
+//
+// The writer converts each entry into a record: the level becomes the
+// severity, the message the body, and the fields the attributes. The trace
+// context of the record is the span of the context of the entry (see
+// golog.LogScope.WithContext), or, for entries written without one, the
+// trace and span IDs copied into the fields by trace correlation (see
+// golog.EnableTraceCorrelation). Importing the package registers the
+// extractor of OpenTelemetry spans with golog.RegisterTraceExtractor.
+//
+// Example, exporting over OTLP/gRPC:
+//
+//	import gologotel "github.com/jkaveri/golog/otel"
+//
+//	exporter, err := otlploggrpc.New(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)))
+//	defer provider.Shutdown(ctx)
+//
+//	golog.SetWriter(gologotel.NewWriter(provider))
+//	golog.WithContext(ctx).Info("order paid")
+package otel
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func init() {
+	golog.RegisterTraceExtractor(Extract)
+}
+
+const (
+	// defaultScopeName is the instrumentation scope of the records by
+	// default
+	defaultScopeName = "github.com/jkaveri/golog"
+	// defaultFlushTimeout bounds Flush by default
+	defaultFlushTimeout = 5 * time.Second
+)
+
+// Option configures a Writer.
+type Option func(*options)
+
+// options holds the settings of a Writer.
+type options struct {
+	// scopeName is the name of the instrumentation scope of the records
+	scopeName string
+	// flushTimeout bounds the ForceFlush call of Flush
+	flushTimeout time.Duration
+	// errorHandler receives flush errors
+	errorHandler func(error)
+}
+
+// ScopeName sets the name of the instrumentation scope of the records. The
+// default is "github.com/jkaveri/golog".
+func ScopeName(name string) Option {
+	return func(o *options) {
+		o.scopeName = name
+	}
+}
+
+// FlushTimeout bounds Flush. The default is 5 seconds.
+func FlushTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.flushTimeout = timeout
+	}
+}
+
+// OnError sets the handler that receives the errors of Flush. By default
+// they are printed to os.Stderr.
+func OnError(handler func(error)) Option {
+	return func(o *options) {
+		o.errorHandler = handler
+	}
+}
+
+// flusher is implemented by LoggerProviders that buffer records, such as
+// the SDK's.
+type flusher interface {
+	ForceFlush(ctx context.Context) error
+}
+
+// Writer implements golog.LogWriter by emitting entries as OpenTelemetry
+// log records. It is safe for concurrent use.
+type Writer struct {
+	provider log.LoggerProvider
+	logger   log.Logger
+	opts     options
+}
+
+// NewWriter creates a Writer emitting records to a logger of provider,
+// typically an SDK LoggerProvider (go.opentelemetry.io/otel/sdk/log) with
+// an OTLP exporter. Flush calls the provider's ForceFlush, if it has one.
+func NewWriter(provider log.LoggerProvider, opts ...Option) *Writer {
+	o := options{
+		scopeName:    defaultScopeName,
+		flushTimeout: defaultFlushTimeout,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return &Writer{
+		provider: provider,
+		logger:   provider.Logger(o.scopeName),
+		opts:     o,
+	}
+}
+
+// Write implements golog.LogWriter.
+func (w *Writer) Write(level int, msg string, fields map[string]any) {
+	w.emit(nil, time.Now(), level, msg, fields)
+}
+
+// WriteEntry implements golog.EntryWriter.
+func (w *Writer) WriteEntry(entry golog.Entry) {
+	t := entry.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	w.emit(entry.Context, t, entry.Level, entry.Message, entry.Fields)
+}
+
+// emit converts an entry into a record and emits it, with the trace
+// context of the span of entryCtx, if any, or of its trace fields.
+func (w *Writer) emit(entryCtx context.Context, t time.Time, level int, msg string, fields map[string]any) {
+	var record log.Record
+	record.SetTimestamp(t)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(Severity(level))
+	record.SetSeverityText(golog.LevelString(level))
+	record.SetBody(log.StringValue(msg))
+
+	fieldsSpan := spanContextOf(fields)
+	spanContext := fieldsSpan
+	if entryCtx != nil {
+		if sc := trace.SpanContextFromContext(entryCtx); sc.IsValid() {
+			spanContext = sc
+		}
+	}
+
+	// the span context is set on a background context, so that a canceled
+	// request context does not affect the export
+	ctx := context.Background()
+	if spanContext.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, spanContext)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		// the trace fields are carried by the trace context
+		if fieldsSpan.IsValid() && fieldsSpan.Equal(spanContext) && (key == golog.FieldTraceID || key == golog.FieldSpanID || key == golog.FieldTraceSampled) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		record.AddAttributes(log.KeyValue{Key: key, Value: Value(fields[key])})
+	}
+
+	w.logger.Emit(ctx, record)
+}
+
+// Flush implements golog.LogWriter by calling the ForceFlush method of the
+// provider, if it has one, bounded by the flush timeout.
+func (w *Writer) Flush() {
+	f, ok := w.provider.(flusher)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.opts.flushTimeout)
+	defer cancel()
+
+	if err := f.ForceFlush(ctx); err != nil {
+		w.handleError(fmt.Errorf("golog/otel: flush: %w", err))
+	}
+}
+
+// Describe implements golog.Describer.
+func (w *Writer) Describe() golog.WriterDescription {
+	return golog.WriterDescription{
+		Type: "otel",
+		Settings: map[string]string{
+			"provider": fmt.Sprintf("%T", w.provider),
+			"scope":    w.opts.scopeName,
+		},
+	}
+}
+
+// handleError reports err to the error handler.
+func (w *Writer) handleError(err error) {
+	if w.opts.errorHandler != nil {
+		w.opts.errorHandler(err)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "%v\n", err)
+}
+
+// Extract returns the span of the OpenTelemetry span context in ctx. It is
+// registered with golog.RegisterTraceExtractor when the package is imported.
+func Extract(ctx context.Context) (golog.Span, bool) {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return golog.Span{}, false
+	}
+
+	return golog.Span{
+		TraceID: spanContext.TraceID().String(),
+		SpanID:  spanContext.SpanID().String(),
+		Sampled: spanContext.IsSampled(),
+	}, true
+}
+
+// spanContextOf returns the span context of the trace fields, invalid when
+// they are missing or malformed.
+func spanContextOf(fields map[string]any) trace.SpanContext {
+	traceIDHex, _ := fields[golog.FieldTraceID].(string)
+	spanIDHex, _ := fields[golog.FieldSpanID].(string)
+	if traceIDHex == "" || spanIDHex == "" {
+		return trace.SpanContext{}
+	}
+
+	traceID, err := trace.TraceIDFromHex(traceIDHex)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+
+	spanID, err := trace.SpanIDFromHex(spanIDHex)
+	if err != nil {
+		return trace.SpanContext{}
+	}
+
+	var flags trace.TraceFlags
+	if sampled, _ := fields[golog.FieldTraceSampled].(bool); sampled {
+		flags = trace.FlagsSampled
+	}
+
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: flags,
+	})
+}
+
+// Severity returns the OpenTelemetry severity of a golog level. Panic and
+// fatal entries are both fatal, panic being the less severe.
+func Severity(level int) log.Severity {
+	switch level {
+	case golog.LevelTrace:
+		return log.SeverityTrace
+	case golog.LevelDebug:
+		return log.SeverityDebug
+	case golog.LevelInfo:
+		return log.SeverityInfo
+	case golog.LevelWarn:
+		return log.SeverityWarn
+	case golog.LevelError:
+		return log.SeverityError
+	case golog.LevelPanic:
+		return log.SeverityFatal
+	case golog.LevelFatal:
+		return log.SeverityFatal4
+	default:
+		return log.SeverityUndefined
+	}
+}
+
+// Value converts a field value into an attribute value. Strings, booleans,
+// numbers, and byte slices keep their type; maps with string keys and
+// slices are converted recursively; times are formatted as RFC 3339;
+// errors, durations, and other values are written as strings.
+func Value(v any) log.Value {
+	switch v := v.(type) {
+	case nil:
+		return log.Value{}
+	case string:
+		return log.StringValue(v)
+	case bool:
+		return log.BoolValue(v)
+	case int:
+		return log.IntValue(v)
+	case int8:
+		return log.Int64Value(int64(v))
+	case int16:
+		return log.Int64Value(int64(v))
+	case int32:
+		return log.Int64Value(int64(v))
+	case int64:
+		return log.Int64Value(v)
+	case uint8:
+		return log.Int64Value(int64(v))
+	case uint16:
+		return log.Int64Value(int64(v))
+	case uint32:
+		return log.Int64Value(int64(v))
+	case uint:
+		return uintValue(uint64(v))
+	case uint64:
+		return uintValue(v)
+	case float32:
+		return log.Float64Value(float64(v))
+	case float64:
+		return log.Float64Value(v)
+	case []byte:
+		return log.BytesValue(v)
+	case time.Time:
+		return log.StringValue(v.Format(time.RFC3339Nano))
+	case time.Duration:
+		return log.StringValue(v.String())
+	case error:
+		return log.StringValue(v.Error())
+	case fmt.Stringer:
+		return log.StringValue(v.String())
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		kvs := make([]log.KeyValue, 0, len(v))
+		for _, key := range keys {
+			kvs = append(kvs, log.KeyValue{Key: key, Value: Value(v[key])})
+		}
+
+		return log.MapValue(kvs...)
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		values := make([]log.Value, rv.Len())
+		for i := range values {
+			values[i] = Value(rv.Index(i).Interface())
+		}
+
+		return log.SliceValue(values...)
+	}
+
+	return log.StringValue(fmt.Sprint(v))
+}
+
+// uintValue converts an unsigned integer, written as a string when it
+// overflows int64.
+func uintValue(v uint64) log.Value {
+	if v > math.MaxInt64 {
+		return log.StringValue(fmt.Sprint(v))
+	}
+
+	return log.Int64Value(int64(v))
+}
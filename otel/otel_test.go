@@ -0,0 +1,192 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// memoryExporter is an sdklog.Exporter keeping the exported records.
+type memoryExporter struct {
+	mu       sync.Mutex
+	records  []sdklog.Record
+	flushes  int
+	flushErr error
+}
+
+func (e *memoryExporter) Export(_ context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, r := range records {
+		e.records = append(e.records, r.Clone())
+	}
+
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(context.Context) error { return nil }
+
+func (e *memoryExporter) ForceFlush(context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.flushes++
+
+	return e.flushErr
+}
+
+// newProvider returns an SDK provider exporting synchronously to a
+// memoryExporter.
+func newProvider(t *testing.T) (*sdklog.LoggerProvider, *memoryExporter) {
+	t.Helper()
+
+	exporter := &memoryExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(exporter)))
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+
+	return provider, exporter
+}
+
+// attributes returns the attributes of a record.
+func attributes(r sdklog.Record) map[string]log.Value {
+	attrs := map[string]log.Value{}
+	r.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+
+	return attrs
+}
+
+func TestWriter(t *testing.T) {
+	provider, exporter := newProvider(t)
+	writer := NewWriter(provider, ScopeName("checkout"))
+
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	writer.WriteEntry(golog.Entry{
+		Time:    at,
+		Level:   golog.LevelWarn,
+		Message: "payment retried",
+		Fields: map[string]any{
+			"attempt": 2,
+			"amount":  12.5,
+			"ok":      false,
+			"err":     errors.New("timeout"),
+			"tags":    []string{"a", "b"},
+			"order":   map[string]any{"id": "o-1"},
+		},
+	})
+
+	require.Len(t, exporter.records, 1)
+	r := exporter.records[0]
+
+	assert.Equal(t, at, r.Timestamp())
+	assert.Equal(t, log.SeverityWarn, r.Severity())
+	assert.Equal(t, "WARN", r.SeverityText())
+	assert.Equal(t, "payment retried", r.Body().AsString())
+	assert.Equal(t, "checkout", r.InstrumentationScope().Name)
+	assert.False(t, r.TraceID().IsValid())
+
+	attrs := attributes(r)
+	assert.Equal(t, int64(2), attrs["attempt"].AsInt64())
+	assert.Equal(t, 12.5, attrs["amount"].AsFloat64())
+	assert.False(t, attrs["ok"].AsBool())
+	assert.Equal(t, "timeout", attrs["err"].AsString())
+	assert.Equal(t, log.KindSlice, attrs["tags"].Kind())
+	assert.Equal(t, []log.KeyValue{log.String("id", "o-1")}, attrs["order"].AsMap())
+}
+
+func TestTraceCorrelation(t *testing.T) {
+	provider, exporter := newProvider(t)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	lg := golog.New(
+		golog.LoggerWriter(NewWriter(provider)),
+		golog.LoggerEnrichers(golog.TraceEnricher(nil)),
+	)
+	lg.WithContext(ctx).Info("traced")
+	lg.WithContext(context.Background()).Info("untraced")
+
+	require.Len(t, exporter.records, 2)
+
+	traced := exporter.records[0]
+	assert.Equal(t, traceID, traced.TraceID())
+	assert.Equal(t, spanID, traced.SpanID())
+	assert.Equal(t, trace.FlagsSampled, traced.TraceFlags())
+	assert.NotContains(t, attributes(traced), golog.FieldTraceID, "the trace fields become the trace context")
+
+	untraced := exporter.records[1]
+	assert.False(t, untraced.TraceID().IsValid())
+	assert.NotContains(t, attributes(untraced), golog.FieldTraceID)
+}
+
+func TestWriter_EntryContext(t *testing.T) {
+	provider, exporter := newProvider(t)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	lg := golog.New(golog.LoggerWriter(NewWriter(provider)))
+	lg.WithContext(ctx).Info("traced without correlation")
+
+	require.Len(t, exporter.records, 1)
+	r := exporter.records[0]
+	assert.Equal(t, traceID, r.TraceID(), "the span of the entry context is read")
+	assert.Equal(t, spanID, r.SpanID())
+	assert.NotContains(t, attributes(r), golog.FieldTraceID)
+}
+
+func TestWriter_Flush(t *testing.T) {
+	provider, exporter := newProvider(t)
+	exporter.flushErr = errors.New("collector unavailable")
+
+	var flushErr error
+	writer := NewWriter(provider, OnError(func(err error) { flushErr = err }))
+	writer.Flush()
+
+	assert.Equal(t, 1, exporter.flushes)
+	assert.ErrorIs(t, flushErr, exporter.flushErr)
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level int
+		want  log.Severity
+	}{
+		{golog.LevelTrace, log.SeverityTrace},
+		{golog.LevelDebug, log.SeverityDebug},
+		{golog.LevelInfo, log.SeverityInfo},
+		{golog.LevelWarn, log.SeverityWarn},
+		{golog.LevelError, log.SeverityError},
+		{golog.LevelPanic, log.SeverityFatal},
+		{golog.LevelFatal, log.SeverityFatal4},
+		{42, log.SeverityUndefined},
+	}
+
+	for _, tt := range tests {
+		t.Run(golog.LevelString(tt.level), func(t *testing.T) {
+			assert.Equal(t, tt.want, Severity(tt.level))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledFields is the number of fields above which a map is not reused,
+// so that a few large entries do not keep large maps alive.
+const maxPooledFields = 64
+
+// PoolStats reports how a writer reuses the field maps of queued entries.
+// A steady state has few misses compared to gets; many discards mean most
+// entries have more than 64 fields.
+type PoolStats struct {
+	// Gets is the number of field maps taken for queued entries
+	Gets uint64
+	// Misses is the number of gets that allocated a new map
+	Misses uint64
+	// Puts is the number of maps returned for reuse
+	Puts uint64
+	// Discarded is the number of maps too large to be reused
+	Discarded uint64
+}
+
+// fieldsPool reuses the field maps of entries queued by a writer.
+type fieldsPool struct {
+	pool      sync.Pool
+	gets      atomic.Uint64
+	misses    atomic.Uint64
+	puts      atomic.Uint64
+	discarded atomic.Uint64
+}
+
+// get returns an empty field map.
+func (p *fieldsPool) get() map[string]any {
+	p.gets.Add(1)
+	if fields, ok := p.pool.Get().(map[string]any); ok {
+		return fields
+	}
+
+	p.misses.Add(1)
+
+	return make(map[string]any)
+}
+
+// put clears fields and keeps it for reuse. The caller must not use fields
+// afterwards.
+func (p *fieldsPool) put(fields map[string]any) {
+	if fields == nil {
+		return
+	}
+
+	if len(fields) > maxPooledFields {
+		p.discarded.Add(1)
+		return
+	}
+
+	clear(fields)
+	p.puts.Add(1)
+	p.pool.Put(fields)
+}
+
+// stats returns the pool counters.
+func (p *fieldsPool) stats() PoolStats {
+	return PoolStats{
+		Gets:      p.gets.Load(),
+		Misses:    p.misses.Load(),
+		Puts:      p.puts.Load(),
+		Discarded: p.discarded.Load(),
+	}
+}
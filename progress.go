@@ -0,0 +1,123 @@
+package golog
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultProgressInterval is the minimum time between two progress entries.
+const defaultProgressInterval = 10 * time.Second
+
+// ProgressTracker logs the progress of a long-running task such as a batch
+// job or a migration. Create one with Progress and call Update as work is
+// done. It is safe for concurrent use.
+type ProgressTracker struct {
+	mu       sync.Mutex
+	name     string
+	total    int
+	interval time.Duration
+	start    time.Time
+	lastLog  time.Time
+	logged   bool
+	finished bool
+	// now returns the current time; replaced in tests
+	now func() time.Time
+}
+
+// Progress returns a ProgressTracker for a task with the given name and total
+// number of work items. Entries are logged at the info level with the fields
+// task, done, total, percent, rate (items per second), and eta.
+//
+// Example:
+//
+//	progress := golog.Progress("backfill users", len(users))
+//	for i, user := range users {
+//	    migrate(user)
+//	    progress.Update(i + 1)
+//	}
+func Progress(name string, total int) *ProgressTracker {
+	p := &ProgressTracker{
+		name:     name,
+		total:    total,
+		interval: defaultProgressInterval,
+		now:      time.Now,
+	}
+	p.start = p.now()
+
+	return p
+}
+
+// Throttle sets the minimum time between two progress entries (10 seconds by
+// default). The first update and the update that completes the task are always
+// logged. It returns the ProgressTracker for method chaining.
+func (p *ProgressTracker) Throttle(interval time.Duration) *ProgressTracker {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.interval = interval
+
+	return p
+}
+
+// Update records that done work items out of the total are complete and logs
+// a progress entry unless one was logged within the throttle interval.
+func (p *ProgressTracker) Update(done int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.finished {
+		return
+	}
+
+	now := p.now()
+	complete := p.total > 0 && done >= p.total
+	if p.logged && !complete && now.Sub(p.lastLog) < p.interval {
+		return
+	}
+
+	p.logged = true
+	p.lastLog = now
+	p.finished = complete
+
+	fields := p.fields(done, now.Sub(p.start))
+	if complete {
+		WithFields(fields).Infof("%s completed", p.name)
+		return
+	}
+
+	WithFields(fields).Infof("%s in progress", p.name)
+}
+
+// fields computes the progress fields for done items after elapsed time.
+func (p *ProgressTracker) fields(done int, elapsed time.Duration) map[string]any {
+	fields := map[string]any{
+		"task":  p.name,
+		"done":  done,
+		"total": p.total,
+	}
+
+	if p.total > 0 {
+		fields["percent"] = round(float64(done)*100/float64(p.total), 1)
+	}
+
+	if elapsed <= 0 || done <= 0 {
+		return fields
+	}
+
+	rate := float64(done) / elapsed.Seconds()
+	fields["rate"] = round(rate, 2)
+
+	if p.total > done {
+		eta := time.Duration(float64(p.total-done) / rate * float64(time.Second))
+		fields["eta"] = eta.Round(time.Second).String()
+	}
+
+	return fields
+}
+
+// round rounds v to the given number of decimal places.
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,71 @@
+package golog
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestProgressTracker_Update(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := start
+	progress := Progress("backfill", 100)
+	progress.now = func() time.Time { return now }
+	progress.start = start
+	progress.Throttle(time.Minute)
+
+	now = start.Add(10 * time.Second)
+	progress.Update(10)
+	if len(w.entries) != 1 {
+		t.Errorf("first update is always logged: len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if got := w.last().msg; got != "backfill in progress" {
+		t.Errorf("w.last().msg = %q, want %q", got, "backfill in progress")
+	}
+	want := map[string]any{
+		"task":    "backfill",
+		"done":    10,
+		"total":   100,
+		"percent": 10.0,
+		"rate":    1.0,
+		"eta":     "1m30s",
+	}
+	if got := w.last().fields; !reflect.DeepEqual(got, want) {
+		t.Errorf("w.last().fields = %v, want %v", got, want)
+	}
+
+	now = start.Add(30 * time.Second)
+	progress.Update(30)
+	if len(w.entries) != 1 {
+		t.Errorf("updates within the interval are throttled: len(w.entries) = %d, want 1", len(w.entries))
+	}
+
+	now = start.Add(71 * time.Second)
+	progress.Update(71)
+	if len(w.entries) != 2 {
+		t.Errorf("len(w.entries) = %d, want 2", len(w.entries))
+	}
+	if got := w.last().fields["percent"]; got != 71.0 {
+		t.Errorf(`w.last().fields["percent"] = %v, want 71.0`, got)
+	}
+
+	now = start.Add(80 * time.Second)
+	progress.Update(100)
+	if len(w.entries) != 3 {
+		t.Errorf("completion is always logged: len(w.entries) = %d, want 3", len(w.entries))
+	}
+	if got := w.last().msg; got != "backfill completed" {
+		t.Errorf("w.last().msg = %q, want %q", got, "backfill completed")
+	}
+	if _, ok := w.last().fields["eta"]; ok {
+		t.Errorf("w.last().fields has key %q, want none", "eta")
+	}
+
+	progress.Update(100)
+	if len(w.entries) != 3 {
+		t.Errorf("updates after completion are ignored: len(w.entries) = %d, want 3", len(w.entries))
+	}
+}
@@ -0,0 +1,211 @@
+// Package protobuf renders protocol buffer messages logged as fields with
+// protojson, the canonical JSON mapping, instead of the reflection-based
+// encoding that exposes generated structs' internal state, size cache, and
+// unknown fields.
+//
+// Example:
+//
+//	import gologproto "github.com/jkaveri/golog/protobuf"
+//
+//	gologproto.Register(gologproto.Omit("card.number", "password"))
+//
+//	golog.With("order", order).Info("order received")
+package protobuf
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jkaveri/golog"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// messageType is the reflect.Type of the proto.Message interface.
+var messageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+
+// Option configures Register and Encode.
+type Option func(*options)
+
+// options holds the settings of the encoder.
+type options struct {
+	// marshal encodes messages to JSON
+	marshal protojson.MarshalOptions
+	// keep lists the field paths to keep; nil keeps every field
+	keep []string
+	// omit lists the field paths to clear
+	omit []string
+}
+
+// MarshalOptions sets the protojson options, for example to use the field
+// names of the .proto file (UseProtoNames) or to write fields with default
+// values (EmitUnpopulated).
+func MarshalOptions(marshal protojson.MarshalOptions) Option {
+	return func(o *options) {
+		o.marshal = marshal
+	}
+}
+
+// Mask keeps only the fields at the given paths, using the FieldMask path
+// syntax of the .proto field names (e.g. "order.id"). Fields of repeated
+// and map message fields are selected for every element.
+func Mask(paths ...string) Option {
+	return func(o *options) {
+		o.keep = append(o.keep, paths...)
+	}
+}
+
+// Omit clears the fields at the given paths, in the syntax of Mask, so that
+// secrets and personal data carried by messages are not logged.
+func Omit(paths ...string) Option {
+	return func(o *options) {
+		o.omit = append(o.omit, paths...)
+	}
+}
+
+// Register makes the built-in writers encode field values implementing
+// proto.Message with Encode. Register it at startup.
+func Register(opts ...Option) {
+	encode := Encoder(opts...)
+	golog.RegisterFieldEncoder(messageType, func(v any) any {
+		return encode(v.(proto.Message))
+	})
+}
+
+// Encoder returns a function converting a message to a field value: its
+// protojson encoding as a json.RawMessage, so the JSON writer embeds it as an
+// object. Messages that cannot be encoded are replaced by a string describing
+// the error.
+func Encoder(opts ...Option) func(proto.Message) any {
+	var o options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	keep, omit := newPathTree(o.keep), newPathTree(o.omit)
+
+	return func(msg proto.Message) any {
+		if msg == nil || !msg.ProtoReflect().IsValid() {
+			return nil
+		}
+
+		if keep != nil || omit != nil {
+			msg = proto.Clone(msg)
+			m := msg.ProtoReflect()
+			if keep != nil {
+				keepPaths(m, keep)
+			}
+			if omit != nil {
+				omitPaths(m, omit)
+			}
+		}
+
+		data, err := o.marshal.Marshal(msg)
+		if err != nil {
+			return fmt.Sprintf("<%s: %v>", msg.ProtoReflect().Descriptor().FullName(), err)
+		}
+
+		return json.RawMessage(data)
+	}
+}
+
+// pathTree holds field paths by their first name; a nil subtree selects the
+// whole field.
+type pathTree map[protoreflect.Name]pathTree
+
+// newPathTree returns the tree of paths, or nil when paths is empty.
+func newPathTree(paths []string) pathTree {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	tree := pathTree{}
+	for _, path := range paths {
+		node := tree
+		names := strings.Split(path, ".")
+		for i, name := range names {
+			child, ok := node[protoreflect.Name(name)]
+			if ok && child == nil {
+				// a shorter path already selects the whole field
+				break
+			}
+
+			if i == len(names)-1 {
+				node[protoreflect.Name(name)] = nil
+				break
+			}
+
+			if !ok {
+				child = pathTree{}
+				node[protoreflect.Name(name)] = child
+			}
+
+			node = child
+		}
+	}
+
+	return tree
+}
+
+// keepPaths clears the fields of m that are not selected by tree.
+func keepPaths(m protoreflect.Message, tree pathTree) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := tree[fd.Name()]
+		switch {
+		case !ok:
+			m.Clear(fd)
+		case sub != nil:
+			eachMessage(fd, v, func(nested protoreflect.Message) { keepPaths(nested, sub) })
+		}
+
+		return true
+	})
+}
+
+// omitPaths clears the fields of m that are selected by tree.
+func omitPaths(m protoreflect.Message, tree pathTree) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := tree[fd.Name()]
+		switch {
+		case !ok:
+		case sub == nil:
+			m.Clear(fd)
+		default:
+			eachMessage(fd, v, func(nested protoreflect.Message) { omitPaths(nested, sub) })
+		}
+
+		return true
+	})
+}
+
+// eachMessage calls fn with the messages held by the field fd with value v:
+// the message itself, or the elements of a repeated or map field.
+func eachMessage(fd protoreflect.FieldDescriptor, v protoreflect.Value, fn func(protoreflect.Message)) {
+	switch {
+	case fd.IsList():
+		if fd.Message() == nil {
+			return
+		}
+
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			fn(list.Get(i).Message())
+		}
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return
+		}
+
+		v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+			fn(value.Message())
+			return true
+		})
+	case fd.Message() != nil:
+		fn(v.Message())
+	}
+}
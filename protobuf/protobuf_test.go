@@ -0,0 +1,87 @@
+package protobuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/apipb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+)
+
+// api returns a message with scalar, nested, and repeated fields.
+func api() *apipb.Api {
+	return &apipb.Api{
+		Name:          "orders.v1.Orders",
+		Version:       "v1",
+		SourceContext: &sourcecontextpb.SourceContext{FileName: "orders.proto"},
+		Methods: []*apipb.Method{
+			{Name: "Create", RequestTypeUrl: "type.googleapis.com/orders.v1.CreateRequest"},
+			{Name: "Get", RequestTypeUrl: "type.googleapis.com/orders.v1.GetRequest"},
+		},
+		Syntax: typepb.Syntax_SYNTAX_PROTO3,
+	}
+}
+
+func TestEncoder(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "protojson",
+			opts:     nil,
+			expected: `{"name":"orders.v1.Orders","methods":[{"name":"Create","requestTypeUrl":"type.googleapis.com/orders.v1.CreateRequest"},{"name":"Get","requestTypeUrl":"type.googleapis.com/orders.v1.GetRequest"}],"version":"v1","sourceContext":{"fileName":"orders.proto"},"syntax":"SYNTAX_PROTO3"}`,
+		},
+		{
+			name:     "mask",
+			opts:     []Option{Mask("name", "methods.name", "source_context")},
+			expected: `{"name":"orders.v1.Orders","methods":[{"name":"Create"},{"name":"Get"}],"sourceContext":{"fileName":"orders.proto"}}`,
+		},
+		{
+			name:     "omit",
+			opts:     []Option{Omit("version", "methods.request_type_url", "source_context.file_name", "syntax")},
+			expected: `{"name":"orders.v1.Orders","methods":[{"name":"Create"},{"name":"Get"}],"sourceContext":{}}`,
+		},
+		{
+			name:     "marshal-options",
+			opts:     []Option{MarshalOptions(protojson.MarshalOptions{UseProtoNames: true}), Mask("source_context")},
+			expected: `{"source_context":{"file_name":"orders.proto"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := api()
+			value := Encoder(tt.opts...)(msg)
+
+			require.IsType(t, json.RawMessage{}, value)
+			assert.JSONEq(t, tt.expected, string(value.(json.RawMessage)))
+			assert.Equal(t, "v1", msg.Version, "the logged message is not modified")
+		})
+	}
+}
+
+func TestEncoder_Nil(t *testing.T) {
+	var msg *apipb.Api
+	assert.Nil(t, Encoder()(msg))
+}
+
+func TestRegister(t *testing.T) {
+	Register(Mask("name"))
+
+	buf := &bytes.Buffer{}
+	writer := golog.NewJSONWriter(buf)
+	writer.Write(golog.LevelInfo, "api loaded", map[string]any{"api": api()})
+	writer.Flush()
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, map[string]any{"name": "orders.v1.Orders"}, entry["api"])
+}
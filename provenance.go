@@ -0,0 +1,124 @@
+package golog
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// FieldProvenance is the key of the field recording the stage each field of
+// an entry comes from (see SetFieldProvenance)
+const FieldProvenance = "_provenance"
+
+// Stages of the pipeline recorded by SetFieldProvenance
+const (
+	// ProvenanceScope marks the fields set on the scope, with With,
+	// WithFields, WithError, or through the context (see IntoContext)
+	ProvenanceScope = "scope"
+	// ProvenanceEnricher prefixes the name of the enricher that set a field,
+	// as in "enricher:*golog.traceEnricher"
+	ProvenanceEnricher = "enricher:"
+	// ProvenanceRetention marks the retention field added by a rule of
+	// SetRetentionRules
+	ProvenanceRetention = "retention"
+)
+
+// provenanceEnabled reports whether the entries carry the provenance field.
+var provenanceEnabled atomic.Bool
+
+// SetFieldProvenance enables the _provenance field, a debug mode to find
+// where a field of an entry comes from in a complex enrichment pipeline. The
+// field maps each other field to the last stage that set it: "scope" for the
+// fields of the scope, "enricher:" followed by the name of the enricher, the
+// type of the enricher or the function of an EnricherFunc, and "retention"
+// for the retention field added by SetRetentionRules. Recording the
+// provenance copies the fields around each enricher, so keep it disabled, the
+// default, in production.
+//
+// Example:
+//
+//	golog.SetFieldProvenance(true)
+//	golog.With("user_id", 42).Info("profile updated")
+//	// _provenance={user_id:scope trace_id:enricher:*golog.traceEnricher ...}
+func SetFieldProvenance(enabled bool) {
+	provenanceEnabled.Store(enabled)
+}
+
+// provenance maps the fields of an entry to the stage that set them.
+type provenance map[string]string
+
+// newProvenance returns the provenance of the fields of a scope, or nil when
+// it is disabled.
+func newProvenance(fields map[string]any) provenance {
+	if !provenanceEnabled.Load() {
+		return nil
+	}
+
+	p := make(provenance, len(fields))
+	for key := range fields {
+		p[key] = ProvenanceScope
+	}
+
+	return p
+}
+
+// enrich applies enricher to fields, recording the fields it sets.
+func (p provenance) enrich(enricher Enricher, ctx context.Context, level, msg string, fields map[string]any) {
+	before := maps.Clone(fields)
+	enricher.Enrich(ctx, level, msg, fields)
+	p.track(ProvenanceEnricher+enricherName(enricher), before, fields)
+}
+
+// track records stage as the provenance of the fields of after that are not
+// in before, or have another value, and forgets the removed fields.
+func (p provenance) track(stage string, before, after map[string]any) {
+	for key, value := range after {
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			p[key] = stage
+		}
+	}
+
+	p.keep(after)
+}
+
+// keep forgets the fields missing from fields, such as the debug fields
+// removed by BindFlags.
+func (p provenance) keep(fields map[string]any) {
+	for key := range p {
+		if _, ok := fields[key]; !ok {
+			delete(p, key)
+		}
+	}
+}
+
+// addTo returns fields with the provenance field.
+func (p provenance) addTo(fields map[string]any) map[string]any {
+	stages := make(map[string]any, len(p))
+	for key, stage := range p {
+		stages[key] = stage
+	}
+
+	withProvenance := make(map[string]any, len(fields)+1)
+	maps.Copy(withProvenance, fields)
+	withProvenance[FieldProvenance] = stages
+
+	return withProvenance
+}
+
+// enricherName returns the name of an enricher: the function of an
+// EnricherFunc, or else its type.
+func enricherName(enricher Enricher) string {
+	if f, ok := enricher.(EnricherFunc); ok && f != nil {
+		if fn := runtime.FuncForPC(reflect.ValueOf(f).Pointer()); fn != nil {
+			// keep the package name, not its path
+			name := fn.Name()
+			return name[strings.LastIndex(name, "/")+1:]
+		}
+	}
+
+	return fmt.Sprintf("%T", enricher)
+}
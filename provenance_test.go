@@ -0,0 +1,109 @@
+package golog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// tenantEnricher sets the tenant field, for provenance tests.
+type tenantEnricher struct{}
+
+func (tenantEnricher) Enrich(_ context.Context, _, _ string, fields map[string]any) {
+	fields["tenant"] = "acme"
+}
+
+func addRegion(_ context.Context, _, _ string, fields map[string]any) {
+	fields["region"] = "eu"
+}
+
+func TestSetFieldProvenance(t *testing.T) {
+	resetEnrichers(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	SetFieldProvenance(true)
+	t.Cleanup(func() { SetFieldProvenance(false) })
+
+	RegisterEnricher(tenantEnricher{})
+	RegisterEnricher(EnricherFunc(addRegion))
+	// leaves the fields it sees unchanged
+	RegisterEnricher(EnricherFunc(func(_ context.Context, _, _ string, fields map[string]any) {
+		if id, ok := fields["user_id"]; ok {
+			fields["user_id"] = id
+		}
+	}))
+
+	tests := []struct {
+		name  string
+		scope *LogScope
+		want  map[string]any
+	}{
+		{
+			name:  "scope-and-enrichers",
+			scope: With("user_id", 42),
+			want: map[string]any{
+				"user_id": ProvenanceScope,
+				"tenant":  ProvenanceEnricher + "golog.tenantEnricher",
+				"region":  ProvenanceEnricher + "golog.addRegion",
+			},
+		},
+		{
+			name:  "enricher-overrides-scope",
+			scope: With("tenant", "wrong"),
+			want: map[string]any{
+				"tenant": ProvenanceEnricher + "golog.tenantEnricher",
+				"region": ProvenanceEnricher + "golog.addRegion",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.scope.Info("entry")
+			if got := w.last().fields[FieldProvenance]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("w.last().fields[FieldProvenance] = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetFieldProvenance_RetentionAndFlags(t *testing.T) {
+	resetEnrichers(t)
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	SetFieldProvenance(true)
+	t.Cleanup(func() { SetFieldProvenance(false) })
+
+	SetRetentionRules(RetentionRule{Retention: "audit", Match: func(int, string, map[string]any) bool { return true }})
+	t.Cleanup(func() { SetRetentionRules() })
+
+	flags := &flagValues{values: map[string]any{}}
+	stop := BindFlags(context.Background(), flags.get, FlagDebugFields("debug", "body"))
+	t.Cleanup(stop)
+
+	With("body", "{}").With("order_id", 7).Info("order created")
+
+	entry := w.last()
+	if _, ok := entry.fields[FieldProvenance]; !ok {
+		t.Fatalf("entry.fields has no key %q", FieldProvenance)
+	}
+	want := map[string]any{
+		"order_id":     ProvenanceScope,
+		FieldRetention: ProvenanceRetention,
+	}
+	if !reflect.DeepEqual(entry.fields[FieldProvenance], want) {
+		t.Errorf("entry.fields[FieldProvenance] = %v, want %v", entry.fields[FieldProvenance], want)
+	}
+}
+
+func TestSetFieldProvenance_Disabled(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	With("user_id", 42).Info("entry")
+	if _, ok := w.last().fields[FieldProvenance]; ok {
+		t.Errorf("w.last().fields has key %q, want none", FieldProvenance)
+	}
+}
@@ -0,0 +1,111 @@
+package golog
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+)
+
+// pseudonymSize is the number of HMAC bytes kept in a token.
+const pseudonymSize = 16
+
+// Pseudonym is a token that replaces an identifier, in the form
+// "KEYID:HEX". It is written like a plain string.
+type Pseudonym string
+
+// Pseudonymizer is an Enricher that replaces the values of identifier fields,
+// such as user IDs or email addresses, with keyed HMAC-SHA256 tokens. The
+// same value always maps to the same token under the same key, so entries
+// about one data subject can still be correlated, but the value cannot be
+// recovered from the token.
+//
+// This lets logs be retained while honoring data subject erasure: without
+// the key, nobody can compute a subject's token to find their entries, so
+// destroying the key erases the link. Rotate the key periodically (each token
+// names the key that produced it) so destroying one key only affects the
+// entries of its period.
+//
+// A Pseudonymizer is safe for concurrent use.
+type Pseudonymizer struct {
+	mu     sync.RWMutex
+	keyID  string
+	key    []byte
+	fields map[string]bool
+}
+
+// NewPseudonymizer creates a Pseudonymizer that replaces the given fields
+// using key, identified in tokens as keyID. Register it after the enrichers
+// that add identifier fields, so it sees them:
+//
+//	pseudonymizer := golog.NewPseudonymizer("2024-06", key, "user_id", "email")
+//	golog.RegisterEnricher(pseudonymizer)
+//
+//	golog.With("user_id", 42).Info("profile updated") // user_id="2024-06:3f9c..."
+func NewPseudonymizer(keyID string, key []byte, fields ...string) *Pseudonymizer {
+	p := &Pseudonymizer{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		p.fields[field] = true
+	}
+
+	p.Rotate(keyID, key)
+
+	return p
+}
+
+// Rotate makes the Pseudonymizer use a new key, identified in tokens as
+// keyID, for the following entries. Keep the previous key for as long as
+// its entries must be searchable by subject, and destroy it to erase them.
+func (p *Pseudonymizer) Rotate(keyID string, key []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.keyID = keyID
+	p.key = append([]byte(nil), key...)
+}
+
+// Token returns the token of value under the current key, e.g. to search the
+// entries of a data subject.
+func (p *Pseudonymizer) Token(value any) Pseudonym {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return pseudonym(p.keyID, p.key, value)
+}
+
+// Enrich implements Enricher by replacing the configured fields with tokens.
+// Values that are already tokens are left as they are.
+func (p *Pseudonymizer) Enrich(_ context.Context, _, _ string, fields map[string]any) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for key, value := range fields {
+		if !p.fields[key] || value == nil {
+			continue
+		}
+
+		if _, ok := value.(Pseudonym); ok {
+			continue
+		}
+
+		fields[key] = pseudonym(p.keyID, p.key, value)
+	}
+}
+
+// PseudonymToken returns the token of value under the given key, e.g. to
+// search the entries of a data subject that were written with a previous
+// key.
+func PseudonymToken(keyID string, key []byte, value any) Pseudonym {
+	return pseudonym(keyID, key, value)
+}
+
+// pseudonym computes the token of value: the key ID and the truncated
+// HMAC-SHA256 of the value's string form.
+func pseudonym(keyID string, key []byte, value any) Pseudonym {
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprint(mac, value)
+
+	return Pseudonym(keyID + ":" + hex.EncodeToString(mac.Sum(nil)[:pseudonymSize]))
+}
@@ -0,0 +1,76 @@
+package golog
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	resetEnrichers(t)
+
+	pseudonymizer := NewPseudonymizer("k1", []byte("secret-key-1"), "user_id", "email")
+	RegisterEnricher(pseudonymizer)
+
+	scope := WithFields(map[string]any{"user_id": 42, "email": "ada@example.com", "plan": "pro"})
+	scope.Info("profile updated")
+	scope.Info("profile viewed")
+	With("user_id", 42).Info("logged out")
+
+	pseudonymizer.Rotate("k2", []byte("secret-key-2"))
+	With("user_id", 42).Info("logged in")
+
+	if len(w.entries) != 4 {
+		t.Fatalf("len(w.entries) = %d, want 4", len(w.entries))
+	}
+
+	token := w.entries[0].fields["user_id"]
+	if got, _ := token.(Pseudonym); !regexp.MustCompile(`^k1:[0-9a-f]{32}$`).MatchString(string(got)) {
+		t.Errorf("token = %q, want a match of %q", got, `^k1:[0-9a-f]{32}$`)
+	}
+	if w.entries[0].fields["email"] == "ada@example.com" {
+		t.Errorf(`w.entries[0].fields["email"] = %q, want a different value`, w.entries[0].fields["email"])
+	}
+	if w.entries[0].fields["plan"] != "pro" {
+		t.Errorf(`other fields are kept: w.entries[0].fields["plan"] = %q, want %q`, w.entries[0].fields["plan"], "pro")
+	}
+	if !reflect.DeepEqual(w.entries[1].fields["user_id"], token) {
+		t.Errorf(`tokens are not pseudonymized again: w.entries[1].fields["user_id"] = %v, want %v`, w.entries[1].fields["user_id"], token)
+	}
+	if !reflect.DeepEqual(w.entries[2].fields["user_id"], token) {
+		t.Errorf(`same value, same token: w.entries[2].fields["user_id"] = %v, want %v`, w.entries[2].fields["user_id"], token)
+	}
+	want := PseudonymToken("k1", []byte("secret-key-1"), 42)
+	if token != want {
+		t.Errorf("token = %q, want %q", token, want)
+	}
+
+	rotated := w.entries[3].fields["user_id"]
+	if got, _ := rotated.(Pseudonym); !strings.HasPrefix(string(got), "k2:") {
+		t.Errorf("rotated = %q, want the prefix %q", got, "k2:")
+	}
+	if want := pseudonymizer.Token(42); rotated != want {
+		t.Errorf("rotated = %q, want %q", rotated, want)
+	}
+	if reflect.DeepEqual(rotated, token) {
+		t.Errorf("rotated = %v, want a different value", rotated)
+	}
+}
+
+func TestPseudonym_DefaultWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewDefaultWriter(buf)
+
+	writer.Write(LevelInfo, "logged in", map[string]any{"user_id": Pseudonym("k1:abc")})
+	if err := writer.flushBuffer(); err != nil {
+		t.Fatalf("writer.flushBuffer(): %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, `user_id="k1:abc"`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `user_id="k1:abc"`)
+	}
+}
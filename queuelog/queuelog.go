@@ -0,0 +1,151 @@
+// Package queuelog logs message queue consumers. It wraps message handlers of
+// any broker (Kafka, SQS, RabbitMQ, ...) to log when a message is received,
+// how long it took to process, and whether it failed, will be retried, or was
+// dead-lettered, with a message-scoped logger carrying the message ID and
+// attempt number.
+//
+// Example with an SQS-style message:
+//
+//	handler := queuelog.Wrap(processOrder, func(m *sqs.Message) queuelog.Message {
+//	    attempt, _ := strconv.Atoi(m.Attributes["ApproximateReceiveCount"])
+//	    return queuelog.Message{ID: *m.MessageId, Attempt: attempt, Queue: "orders"}
+//	}, queuelog.MaxAttempts(5))
+//
+//	func processOrder(ctx context.Context, m *sqs.Message) error {
+//	    queuelog.Logger(ctx).Info("order accepted")
+//	    ...
+//	}
+package queuelog
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+// Field names used in message entries
+const (
+	// FieldMessageID is the key of the message ID
+	FieldMessageID = "message_id"
+	// FieldAttempt is the key of the delivery attempt, starting at 1
+	FieldAttempt = "attempt"
+	// FieldQueue is the key of the queue or topic name
+	FieldQueue = "queue"
+)
+
+// Message describes a message for logging.
+type Message struct {
+	// ID identifies the message in the broker
+	ID string
+	// Attempt is the delivery attempt, starting at 1; zero omits it
+	Attempt int
+	// Queue is the queue or topic the message came from; empty omits it
+	Queue string
+	// Fields are added to every entry of the message, e.g. a partition
+	Fields map[string]any
+}
+
+// fields returns the log fields of the message.
+func (m Message) fields() map[string]any {
+	fields := maps.Clone(m.Fields)
+	if fields == nil {
+		fields = make(map[string]any, 3)
+	}
+
+	fields[FieldMessageID] = m.ID
+	if m.Attempt > 0 {
+		fields[FieldAttempt] = m.Attempt
+	}
+	if m.Queue != "" {
+		fields[FieldQueue] = m.Queue
+	}
+
+	return fields
+}
+
+// Handler processes one message of type M.
+type Handler[M any] func(ctx context.Context, msg M) error
+
+// Option configures Wrap.
+type Option func(*options)
+
+// options holds the settings of Wrap.
+type options struct {
+	// maxAttempts is the attempt after which failed messages are dead-lettered
+	maxAttempts int
+}
+
+// MaxAttempts sets how many times the broker delivers a message before
+// dead-lettering it. With it, a failure is logged as a retry until the last
+// attempt and as dead-lettering on the last one. Without it, every failure
+// is logged as an error, since whether it will be retried is unknown.
+func MaxAttempts(attempts int) Option {
+	return func(o *options) {
+		o.maxAttempts = attempts
+	}
+}
+
+// contextKey is the context key of the message fields.
+type contextKey struct{}
+
+// Wrap returns a Handler that logs the processing of each message and calls
+// handler with a context from which Logger returns a message-scoped logger.
+// describe extracts the logged details from a message.
+//
+// It logs "message received" at debug level, then one of:
+//   - "message processed" (info) with the duration, when handler succeeds
+//   - "message processing failed" (info) with the error and will_retry=true,
+//     when handler fails and attempts remain
+//   - "message dead-lettered" (error) with the error, when handler fails on
+//     the last attempt
+//   - "message processing failed" (error) with the error, when handler fails
+//     and MaxAttempts is not set
+//
+// The error returned by handler is returned unchanged.
+func Wrap[M any](handler Handler[M], describe func(msg M) Message, opts ...Option) Handler[M] {
+	o := options{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return func(ctx context.Context, msg M) error {
+		m := describe(msg)
+		fields := m.fields()
+		ctx = context.WithValue(ctx, contextKey{}, fields)
+
+		Logger(ctx).Debug("message received")
+
+		start := time.Now()
+		err := handler(ctx, msg)
+
+		scope := Logger(ctx).With("duration", time.Since(start).String())
+		switch {
+		case err == nil:
+			scope.Info("message processed")
+		case o.maxAttempts > 0 && m.Attempt < o.maxAttempts:
+			scope.With("error", err.Error()).With("will_retry", true).Info("message processing failed")
+		case o.maxAttempts > 0:
+			_ = scope.With("error", err.Error()).Error("message dead-lettered")
+		default:
+			_ = scope.With("error", err.Error()).Error("message processing failed")
+		}
+
+		return err
+	}
+}
+
+// Logger returns a new scope with the fields of the message being processed
+// and ctx as its context. Outside a handler wrapped with Wrap, the scope has
+// no message fields.
+func Logger(ctx context.Context) *golog.LogScope {
+	scope := golog.WithContext(ctx)
+	if fields, ok := ctx.Value(contextKey{}).(map[string]any); ok {
+		scope = scope.WithFields(fields)
+	}
+
+	return scope
+}
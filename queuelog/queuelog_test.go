@@ -0,0 +1,129 @@
+package queuelog
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/sinktest"
+)
+
+// delivery stands in for a broker's message type.
+type delivery struct {
+	id       string
+	attempt  int
+	failWith error
+}
+
+func describe(d delivery) Message {
+	return Message{ID: d.id, Attempt: d.attempt, Queue: "orders", Fields: map[string]any{"partition": 3}}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		delivery delivery
+		level    int
+		message  string
+		retry    bool
+	}{
+		{
+			name:     "processed",
+			opts:     []Option{MaxAttempts(3)},
+			delivery: delivery{id: "m-1", attempt: 1},
+			level:    golog.LevelInfo,
+			message:  "message processed",
+		},
+		{
+			name:     "retry",
+			opts:     []Option{MaxAttempts(3)},
+			delivery: delivery{id: "m-2", attempt: 2, failWith: errors.New("db unavailable")},
+			level:    golog.LevelInfo,
+			message:  "message processing failed",
+			retry:    true,
+		},
+		{
+			name:     "dead-lettered",
+			opts:     []Option{MaxAttempts(3)},
+			delivery: delivery{id: "m-3", attempt: 3, failWith: errors.New("db unavailable")},
+			level:    golog.LevelError,
+			message:  "message dead-lettered",
+		},
+		{
+			name:     "failed-without-max-attempts",
+			delivery: delivery{id: "m-4", attempt: 1, failWith: errors.New("db unavailable")},
+			level:    golog.LevelError,
+			message:  "message processing failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := sinktest.UseRecorder(t)
+			golog.SetLevel(golog.LevelDebug)
+
+			handler := Wrap(func(ctx context.Context, d delivery) error {
+				Logger(ctx).Info("handling order")
+				return d.failWith
+			}, describe, tt.opts...)
+
+			err := handler(context.Background(), tt.delivery)
+			if !reflect.DeepEqual(err, tt.delivery.failWith) {
+				t.Errorf("err = %v, want %v", err, tt.delivery.failWith)
+			}
+
+			entries := recorder.Entries()
+			if len(entries) != 3 {
+				t.Fatalf("len(entries) = %d, want 3", len(entries))
+			}
+			if entries[0].Message != "message received" {
+				t.Errorf("entries[0].Message = %q, want %q", entries[0].Message, "message received")
+			}
+			if entries[0].Level != golog.LevelDebug {
+				t.Errorf("entries[0].Level = %v, want %v", entries[0].Level, golog.LevelDebug)
+			}
+			if entries[1].Message != "handling order" {
+				t.Errorf("entries[1].Message = %q, want %q", entries[1].Message, "handling order")
+			}
+
+			for _, entry := range entries {
+				if entry.Fields[FieldMessageID] != tt.delivery.id {
+					t.Errorf("entry.Fields[FieldMessageID] = %q, want %q", entry.Fields[FieldMessageID], tt.delivery.id)
+				}
+				if entry.Fields[FieldAttempt] != tt.delivery.attempt {
+					t.Errorf("entry.Fields[FieldAttempt] = %v, want %v", entry.Fields[FieldAttempt], tt.delivery.attempt)
+				}
+				if entry.Fields[FieldQueue] != "orders" {
+					t.Errorf("entry.Fields[FieldQueue] = %q, want %q", entry.Fields[FieldQueue], "orders")
+				}
+				if entry.Fields["partition"] != 3 {
+					t.Errorf(`entry.Fields["partition"] = %v, want 3`, entry.Fields["partition"])
+				}
+			}
+
+			last := entries[2]
+			if last.Level != tt.level {
+				t.Errorf("last.Level = %v, want %v", last.Level, tt.level)
+			}
+			if last.Message != tt.message {
+				t.Errorf("last.Message = %q, want %q", last.Message, tt.message)
+			}
+			if got, _ := last.Fields["duration"].(string); got == "" {
+				t.Error(`last.Fields["duration"] is empty`)
+			}
+			if tt.delivery.failWith != nil {
+				if last.Fields["error"] != "db unavailable" {
+					t.Errorf(`last.Fields["error"] = %q, want %q`, last.Fields["error"], "db unavailable")
+				}
+			}
+			if tt.retry {
+				if last.Fields["will_retry"] != true {
+					t.Errorf(`last.Fields["will_retry"] = %v, want true`, last.Fields["will_retry"])
+				}
+			}
+		})
+	}
+}
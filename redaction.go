@@ -0,0 +1,325 @@
+package golog
+
+import (
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// RedactTag is the value of the log struct tag marking the struct fields
+// whose values are redacted (see SetRedaction)
+const RedactTag = "redact"
+
+// RedactOption configures SetRedaction.
+type RedactOption func(*redactor)
+
+// redactor masks the sensitive values of entries.
+type redactor struct {
+	// fields holds the lower-cased names of the redacted fields
+	fields map[string]bool
+	// patterns match the sensitive parts of string values
+	patterns []*regexp.Regexp
+}
+
+// redaction holds the redactor set with SetRedaction, nil when redaction is
+// disabled.
+var redaction atomic.Pointer[redactor]
+
+// redactedTypes caches whether a type holds struct fields tagged
+// `log:"redact"`, by reflect.Type.
+var redactedTypes sync.Map
+
+// RedactFields redacts the values of the fields named names, compared
+// case-insensitively, at any depth: top-level fields, keys of nested
+// map[string]any values, and struct fields, by their JSON name.
+func RedactFields(names ...string) RedactOption {
+	return func(r *redactor) {
+		for _, name := range names {
+			r.fields[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// RedactPatterns redacts the parts of string values matching patterns, such
+// as card numbers or bearer tokens, at any depth.
+func RedactPatterns(patterns ...*regexp.Regexp) RedactOption {
+	return func(r *redactor) {
+		r.patterns = append(r.patterns, patterns...)
+	}
+}
+
+// SetRedaction enables the redaction of sensitive values: every entry of
+// every Logger is redacted before it reaches its writer, so the values are
+// masked as "[REDACTED]" whatever the writer, including third-party ones.
+// It replaces the fields and patterns set by a previous call; call it
+// without options to only honor struct tags, and DisableRedaction to turn
+// redaction off.
+//
+// While redaction is enabled, the struct fields tagged `log:"redact"` are
+// redacted too; structs holding such fields are written as maps, keyed by
+// the JSON names of their fields.
+//
+// Unlike an enricher, redaction runs after the enrichers, the retention
+// rules, and the feature flags, and sees the values nested in maps, slices,
+// and structs, so no sensitive value slips through.
+//
+// Example:
+//
+//	golog.SetRedaction(
+//	    golog.RedactFields("password", "authorization", "ssn"),
+//	    golog.RedactPatterns(regexp.MustCompile(`\b\d{13,16}\b`)),
+//	)
+//
+//	type User struct {
+//	    ID    int    `json:"id"`
+//	    Email string `json:"email" log:"redact"`
+//	}
+func SetRedaction(opts ...RedactOption) {
+	r := &redactor{fields: map[string]bool{}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(r)
+		}
+	}
+
+	redaction.Store(r)
+}
+
+// DisableRedaction disables the redaction enabled with SetRedaction, which
+// is the default.
+func DisableRedaction() {
+	redaction.Store(nil)
+}
+
+// applyRedaction returns fields with their sensitive values masked, or
+// fields itself when redaction is disabled or nothing is masked.
+func applyRedaction(fields map[string]any) map[string]any {
+	r := redaction.Load()
+	if r == nil || len(fields) == 0 {
+		return fields
+	}
+
+	redacted, changed := r.redactMap(fields, 0)
+	if !changed {
+		return fields
+	}
+
+	return redacted
+}
+
+// redactError returns err, or an error with its message redacted and the
+// same stack trace (see ErrorStack) when redaction changes its message. The
+// redacted error does not wrap err, whose chain holds the message.
+func redactError(err error) error {
+	r := redaction.Load()
+	if r == nil || err == nil {
+		return err
+	}
+
+	msg, changed := r.redactString(err.Error())
+	if !changed {
+		return err
+	}
+
+	return &stackError{msg: msg, stack: ErrorStack(err)}
+}
+
+// redactMap returns m with its sensitive values masked, and whether any was.
+func (r *redactor) redactMap(m map[string]any, depth int) (map[string]any, bool) {
+	var redacted map[string]any
+	for key, value := range m {
+		v, changed := r.redactField(key, value, depth)
+		if !changed {
+			continue
+		}
+
+		if redacted == nil {
+			redacted = maps.Clone(m)
+		}
+
+		redacted[key] = v
+	}
+
+	if redacted == nil {
+		return m, false
+	}
+
+	return redacted, true
+}
+
+// redactField returns the value of the field key masked, and whether it was.
+func (r *redactor) redactField(key string, v any, depth int) (any, bool) {
+	if r.fields[strings.ToLower(key)] {
+		return redactedValue, true
+	}
+
+	return r.redactValue(v, depth)
+}
+
+// redactValue returns v with its sensitive parts masked, and whether any was.
+func (r *redactor) redactValue(v any, depth int) (any, bool) {
+	if depth > maxTolerantDepth {
+		return v, false
+	}
+
+	switch v := v.(type) {
+	case nil, Classified:
+		return v, false
+	case string:
+		return r.redactString(v)
+	case error:
+		// the message of an error may carry a secret, e.g. a URL with a token
+		if msg, changed := r.redactString(v.Error()); changed {
+			return msg, true
+		}
+
+		return v, false
+	case map[string]any:
+		return r.redactMap(v, depth+1)
+	case []any:
+		var redacted []any
+		for i, item := range v {
+			item, changed := r.redactValue(item, depth+1)
+			if !changed {
+				continue
+			}
+
+			if redacted == nil {
+				redacted = slices.Clone(v)
+			}
+
+			redacted[i] = item
+		}
+
+		if redacted == nil {
+			return v, false
+		}
+
+		return redacted, true
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() == reflect.Struct && (hasRedactTags(rv.Type()) || r.matches() && hasJSONFields(rv.Type())) {
+		m, changed := r.redactStruct(rv, depth+1)
+		if changed {
+			return m, true
+		}
+	}
+
+	return v, false
+}
+
+// matches reports whether the redactor masks values by field name or
+// pattern, not only by struct tag.
+func (r *redactor) matches() bool {
+	return len(r.fields) > 0 || len(r.patterns) > 0
+}
+
+// redactString returns s with the parts matching the patterns masked, and
+// whether any did.
+func (r *redactor) redactString(s string) (string, bool) {
+	redacted := s
+	for _, pattern := range r.patterns {
+		redacted = pattern.ReplaceAllLiteralString(redacted, redactedValue)
+	}
+
+	return redacted, redacted != s
+}
+
+// redactStruct returns the struct v as a map keyed by the JSON names of its
+// fields, with the tagged and redacted fields masked, and whether any was.
+func (r *redactor) redactStruct(v reflect.Value, depth int) (map[string]any, bool) {
+	m := map[string]any{}
+	changed := false
+
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		if field.Tag.Get("log") == RedactTag {
+			m[name] = redactedValue
+			changed = true
+
+			continue
+		}
+
+		value, redacted := r.redactField(name, v.Field(i).Interface(), depth)
+		m[name] = value
+		changed = changed || redacted
+	}
+
+	return m, changed
+}
+
+// hasRedactTags reports whether t, or a struct type it holds, has fields
+// tagged `log:"redact"`.
+func hasRedactTags(t reflect.Type) bool {
+	if cached, ok := redactedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+
+	tagged := structHasRedactTags(t, map[reflect.Type]bool{})
+	redactedTypes.Store(t, tagged)
+
+	return tagged
+}
+
+// structHasRedactTags implements hasRedactTags, skipping the types in
+// visiting to stop at recursive types.
+func structHasRedactTags(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	visiting[t] = true
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Tag.Get("log") == RedactTag {
+			return true
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+
+		if ft.Kind() == reflect.Struct && !visiting[ft] && structHasRedactTags(ft, visiting) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasJSONFields reports whether the struct type t is written as an object
+// of its fields, rather than by a marshaler, so that its fields can be
+// redacted by name.
+func hasJSONFields(t reflect.Type) bool {
+	marshaler := reflect.TypeFor[interface{ MarshalJSON() ([]byte, error) }]()
+	textMarshaler := reflect.TypeFor[interface{ MarshalText() ([]byte, error) }]()
+
+	return !t.Implements(marshaler) && !reflect.PointerTo(t).Implements(marshaler) &&
+		!t.Implements(textMarshaler) && !reflect.PointerTo(t).Implements(textMarshaler)
+}
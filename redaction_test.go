@@ -0,0 +1,139 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type redactedAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street" log:"redact"`
+}
+
+type redactedUser struct {
+	ID       int              `json:"id"`
+	Email    string           `json:"email" log:"redact"`
+	Password string           `json:"-"`
+	Address  *redactedAddress `json:"address"`
+	Token    string
+}
+
+type plainOrder struct {
+	ID   string `json:"id"`
+	Card string `json:"card"`
+}
+
+func TestSetRedaction(t *testing.T) {
+	t.Cleanup(DisableRedaction)
+	SetRedaction(
+		RedactFields("Password", "token"),
+		RedactPatterns(regexp.MustCompile(`\b\d{16}\b`)),
+	)
+
+	at := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		fields map[string]any
+		want   map[string]any
+	}{
+		{
+			name:   "field-name",
+			fields: map[string]any{"password": "hunter2", "PASSWORD": "hunter2", "user": "ann"},
+			want:   map[string]any{"password": redactedValue, "PASSWORD": redactedValue, "user": "ann"},
+		},
+		{
+			name:   "pattern",
+			fields: map[string]any{"note": "card 4111111111111111 declined"},
+			want:   map[string]any{"note": "card [REDACTED] declined"},
+		},
+		{
+			name:   "error-message",
+			fields: map[string]any{"error": errors.New("charge 4111111111111111 failed")},
+			want:   map[string]any{"error": "charge [REDACTED] failed"},
+		},
+		{
+			name: "nested",
+			fields: map[string]any{"request": map[string]any{
+				"headers": map[string]any{"Token": "abc"},
+				"cards":   []any{"4111111111111111", 3},
+			}},
+			want: map[string]any{"request": map[string]any{
+				"headers": map[string]any{"Token": redactedValue},
+				"cards":   []any{redactedValue, 3},
+			}},
+		},
+		{
+			name:   "struct-tags",
+			fields: map[string]any{"user": redactedUser{ID: 7, Email: "ann@example.com", Password: "x", Address: &redactedAddress{City: "Oslo", Street: "Main 1"}, Token: "abc"}},
+			want: map[string]any{"user": map[string]any{
+				"id":      7,
+				"email":   redactedValue,
+				"address": map[string]any{"city": "Oslo", "street": redactedValue},
+				"Token":   redactedValue,
+			}},
+		},
+		{
+			name:   "untagged-struct",
+			fields: map[string]any{"order": plainOrder{ID: "o-1", Card: "4111111111111111"}},
+			want:   map[string]any{"order": map[string]any{"id": "o-1", "card": redactedValue}},
+		},
+		{
+			name:   "unchanged",
+			fields: map[string]any{"order": plainOrder{ID: "o-1"}, "at": at, "sensitive": Sensitive("email", "x")["email"]},
+			want:   map[string]any{"order": plainOrder{ID: "o-1"}, "at": at, "sensitive": Sensitive("email", "x")["email"]},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			useWriter(t, w)
+
+			WithFields(tt.fields).Info("entry")
+			if got := w.last().fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("w.last().fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetRedaction_AllWriters(t *testing.T) {
+	t.Cleanup(DisableRedaction)
+	SetRedaction(RedactFields("password"))
+
+	buf := &bytes.Buffer{}
+	useWriter(t, NewJSONWriter(buf))
+
+	fields := map[string]any{"password": "hunter2", "user": redactedUser{Email: "ann@example.com"}}
+	WithFields(fields).Info("login")
+	Flush()
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+	}
+	if entry["password"] != redactedValue {
+		t.Errorf(`entry["password"] = %q, want %q`, entry["password"], redactedValue)
+	}
+	if got := entry["user"].(map[string]any)["email"]; got != redactedValue {
+		t.Errorf(`entry["user"].(map[string]any)["email"] = %q, want %q`, got, redactedValue)
+	}
+	if fields["password"] != "hunter2" {
+		t.Errorf(`the fields of the caller are left unchanged: fields["password"] = %q, want %q`, fields["password"], "hunter2")
+	}
+
+	DisableRedaction()
+	buf.Reset()
+	WithFields(fields).Info("login")
+	Flush()
+	if got := buf.String(); !strings.Contains(got, "hunter2") {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, "hunter2")
+	}
+}
@@ -0,0 +1,69 @@
+package golog
+
+import "os"
+
+// ResetForTesting restores the global state of golog to that of a process
+// that has not configured it, so that the writer, level, enrichers, or rules
+// set by one test do not leak into the next ones. It restores:
+//
+//   - the default Logger: the bootstrap writer to os.Stderr, LevelInfo, and
+//     no enrichers, hooks, or flag overrides
+//   - redaction, retention rules, field provenance, field type checks,
+//     silence windows, the degradation profile, error kinds, the error
+//     wrapper, and unit conventions, to their defaults
+//   - the pending drop and silence reports, which are discarded, and the
+//     drop report interval
+//   - the keys of Once and Every, so that their entries are logged again,
+//     and the field types seen by SetFieldTypeChecks
+//
+// The writer being replaced is flushed, not closed. Registrations usually
+// made by init functions and imports, such as codecs, trace extractors, and
+// field encoders, are kept. Stop the bindings of BindFlags with their stop
+// function first, or they set their overrides again.
+//
+// Call it from TestMain, or from t.Cleanup of tests that configure golog,
+// when no other goroutine logs. Parallel tests should not configure the
+// global state at all, but create their own Logger with New.
+//
+// Example:
+//
+//	func TestMain(m *testing.M) {
+//	    code := m.Run()
+//	    golog.ResetForTesting()
+//	    os.Exit(code)
+//	}
+//
+//	func TestCheckout(t *testing.T) {
+//	    t.Cleanup(golog.ResetForTesting)
+//	    golog.SetWriter(recorder)
+//	    ...
+//	}
+func ResetForTesting() {
+	if w := std.Writer(); w != nil {
+		w.Flush()
+	}
+
+	std.update(func(cfg *loggerConfig) {
+		cfg.writer = newBootstrapWriter(os.Stderr)
+		cfg.level = LevelInfo
+		cfg.enrichers = nil
+		cfg.hooks = nil
+	})
+	std.flags.Store(nil)
+
+	DisableRedaction()
+	SetRetentionRules()
+	SetFieldProvenance(false)
+	SetFieldTypeChecks(false)
+	_ = SetDegradationProfile(ProfileFull)
+	SetErrorKinds(nil)
+	SetErrorWrapper(nil)
+	unitConventions.Store(nil)
+
+	silences.reset()
+	drops.reset()
+	fieldTypes.reset()
+
+	onceKeys.Clear()
+	everyKeys.Clear()
+}
@@ -0,0 +1,147 @@
+package golog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// initWriter is the type of the writer of the default Logger seen by init.
+var initWriter string
+
+// init uses the global APIs before any configuration, as applications and
+// libraries do from their init functions. The entries are below the default
+// level, so the test output stays clean.
+func init() {
+	initWriter = fmt.Sprintf("%T", Default().Writer())
+
+	Debug("from init")
+	With("phase", "init").Trace("from init")
+	WithContext(context.Background()).WithError(errors.New("boom")).Debug("from init")
+	FromContext(IntoContext(context.Background(), With("id", 1))).Debug("from init")
+	Once("init").Debug("from init")
+	Every("init", time.Minute).Debug("from init")
+	_ = Describe().String()
+	Flush()
+}
+
+func TestInit(t *testing.T) {
+	if initWriter != "*golog.bootstrapWriter" {
+		t.Errorf("entries logged from init are written synchronously to os.Stderr: initWriter = %q, want %q", initWriter, "*golog.bootstrapWriter")
+	}
+}
+
+func TestResetForTesting(t *testing.T) {
+	t.Cleanup(ResetForTesting)
+
+	w := &captureWriter{}
+	SetWriter(w)
+	SetLevel(LevelDebug)
+	RegisterEnricher(EnricherFunc(func(_ context.Context, _, _ string, fields map[string]any) {
+		fields["leaked"] = true
+	}))
+	AddHook(HookFunc(func(Entry) { t.Error("the hook leaked") }))
+	SetRedaction(RedactFields("user"))
+	SetRetentionRules(RetentionRule{Retention: "audit"})
+	SetFieldProvenance(true)
+	SetSilenceWindows(SilenceWindow{Name: "leaked"})
+	SetErrorKinds([]ErrorKind{{Kind: "leaked", Match: func(error) bool { return true }}})
+	SetUnitConventions(UnitConventions{DurationUnit: time.Second})
+	SetDropReportInterval(time.Hour)
+	RecordDropped(DropSampled, 3)
+	Once("reset").Info("logged once")
+
+	ResetForTesting()
+
+	if _, ok := std.Writer().(*bootstrapWriter); !ok {
+		t.Errorf("std.Writer() = %T, want *bootstrapWriter", std.Writer())
+	}
+	if got := std.Level(); got != LevelInfo {
+		t.Errorf("std.Level() = %v, want %v", got, LevelInfo)
+	}
+	if got := std.registeredEnrichers(); len(got) != 0 {
+		t.Errorf("std.registeredEnrichers() = %v, want empty", got)
+	}
+	if got := std.load().hooks; len(got) != 0 {
+		t.Errorf("std.load().hooks = %v, want empty", got)
+	}
+	if got := redaction.Load(); got != nil {
+		t.Errorf("redaction.Load() = %v, want nil", got)
+	}
+	if provenanceEnabled.Load() {
+		t.Error("provenanceEnabled.Load() = true, want false")
+	}
+	if silences.active.Load() {
+		t.Error("the pending summaries are discarded")
+	}
+	if got := errorKinds.Load(); got != nil {
+		t.Errorf("errorKinds.Load() = %v, want nil", got)
+	}
+	want := map[string]any{"wait_ms": int64(1500), "wait_h": "1.5s"}
+	if got := Dur("wait", 1500*time.Millisecond); !reflect.DeepEqual(got, want) {
+		t.Errorf(`Dur("wait", 1500*time.Millisecond) = %v, want %v`, got, want)
+	}
+	if drops.pending.Load() {
+		t.Error("the pending drops are discarded")
+	}
+	if drops.interval != defaultDropReportInterval {
+		t.Errorf("drops.interval = %v, want %v", drops.interval, defaultDropReportInterval)
+	}
+	if w.flushes != 1 {
+		t.Errorf("the replaced writer is flushed: w.flushes = %v, want 1", w.flushes)
+	}
+
+	w2 := &captureWriter{}
+	SetWriter(w2)
+	With("user", "alice").Info("after reset")
+	Once("reset").Info("logged once")
+
+	if len(w2.entries) != 2 {
+		t.Fatalf("len(w2.entries) = %d, want 2", len(w2.entries))
+	}
+	if want := map[string]any{"user": "alice"}; !reflect.DeepEqual(w2.entries[0].fields, want) {
+		t.Errorf("no enricher, redaction, or retention rule applies: w2.entries[0].fields = %v, want %v", w2.entries[0].fields, want)
+	}
+	if w2.entries[1].msg != "logged once" {
+		t.Errorf("the keys of Once are forgotten: w2.entries[1].msg = %q, want %q", w2.entries[1].msg, "logged once")
+	}
+}
+
+func TestGlobalAPIs_Parallel(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx := IntoContext(context.Background(), With("worker", i))
+			for range 50 {
+				FromContext(ctx).With("step", 1).Info("working")
+				Once("parallel").Info("once")
+				Every("parallel", time.Hour).Info("every")
+				_ = WithError(errors.New("boom")).Error("failed")
+				_ = Describe()
+			}
+		}()
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	counts := map[string]int{}
+	for _, entry := range w.entries {
+		counts[entry.msg]++
+	}
+	want := map[string]int{"working": 400, "once": 1, "every": 1, "failed": 400}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
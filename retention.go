@@ -0,0 +1,95 @@
+package golog
+
+import (
+	"maps"
+	"sync/atomic"
+)
+
+// FieldRetention is the key of the retention hint of an entry
+const FieldRetention = "retention"
+
+// Retention hints. Sinks map them to their storage tiers, e.g. an index with
+// a short lifecycle policy or a ClickHouse partition with a short TTL. Any
+// other value can be used as long as the sinks understand it.
+const (
+	// RetentionShort is for high-volume entries only useful for a few days
+	RetentionShort = "short"
+	// RetentionStandard is for regular entries
+	RetentionStandard = "standard"
+	// RetentionLong is for entries that must be kept, such as audit events
+	RetentionLong = "long"
+)
+
+// RetentionRule sets the retention hint of the entries it matches.
+type RetentionRule struct {
+	// Match reports whether the rule applies to an entry. The fields must
+	// not be modified.
+	Match func(level int, msg string, fields map[string]any) bool
+	// Retention is the hint set on matching entries
+	Retention string
+}
+
+// retentionRules holds the rules set with SetRetentionRules.
+var retentionRules atomic.Pointer[[]RetentionRule]
+
+// SetRetentionRules sets the rules that give entries a retention hint, in
+// the "retention" field, when their scope has none (see WithRetention). The
+// first matching rule wins. It replaces the previous rules; call it without
+// rules to remove them.
+//
+// Example:
+//
+//	golog.SetRetentionRules(golog.RetentionRule{
+//	    Match: func(level int, _ string, _ map[string]any) bool {
+//	        return level == golog.LevelDebug
+//	    },
+//	    Retention: golog.RetentionShort,
+//	})
+func SetRetentionRules(rules ...RetentionRule) {
+	rules = append([]RetentionRule(nil), rules...)
+	retentionRules.Store(&rules)
+}
+
+// WithRetention returns a child scope whose entries have the retention hint
+// retention, overriding the rules set with SetRetentionRules.
+func (l *LogScope) WithRetention(retention string) *LogScope {
+	return l.With(FieldRetention, retention)
+}
+
+// WithRetention creates a new LogScope whose entries carry the retention
+// hint, e.g. golog.WithRetention(golog.RetentionLong).Info("role granted").
+func WithRetention(retention string) *LogScope {
+	return newScope().WithRetention(retention)
+}
+
+// EntryRetention returns the retention hint of entry, or an empty string when
+// it has none. Sinks use it to choose where to store the entry.
+func EntryRetention(entry Entry) string {
+	retention, _ := entry.Fields[FieldRetention].(string)
+	return retention
+}
+
+// applyRetention returns fields with the retention hint of the first
+// matching rule, unless fields already has one. The scope's fields are
+// copied rather than modified, so the hint only applies to this entry.
+func applyRetention(level int, msg string, fields map[string]any) map[string]any {
+	rules := retentionRules.Load()
+	if rules == nil || len(*rules) == 0 {
+		return fields
+	}
+
+	if _, ok := fields[FieldRetention]; ok {
+		return fields
+	}
+
+	for _, rule := range *rules {
+		if rule.Match != nil && rule.Match(level, msg, fields) {
+			fields = maps.Clone(fields)
+			fields[FieldRetention] = rule.Retention
+
+			return fields
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,58 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestRetention(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	originalMinLevel := std.Level()
+	SetLevel(LevelDebug)
+	t.Cleanup(func() {
+		std.SetLevel(originalMinLevel)
+		SetRetentionRules()
+	})
+
+	SetRetentionRules(
+		RetentionRule{
+			Match:     func(level int, _ string, _ map[string]any) bool { return level == LevelDebug },
+			Retention: RetentionShort,
+		},
+		RetentionRule{
+			Match:     func(_ int, _ string, fields map[string]any) bool { return fields["audit"] == true },
+			Retention: RetentionLong,
+		},
+	)
+
+	scope := With("request_id", "abc")
+	scope.Debug("cache miss")
+	scope.Info("request handled")
+	With("audit", true).Info("role granted")
+	WithRetention(RetentionLong).Debug("explicit")
+
+	if len(w.entries) != 4 {
+		t.Fatalf("len(w.entries) = %d, want 4", len(w.entries))
+	}
+	if w.entries[0].fields[FieldRetention] != RetentionShort {
+		t.Errorf("first matching rule: w.entries[0].fields[FieldRetention] = %q, want %q", w.entries[0].fields[FieldRetention], RetentionShort)
+	}
+	if _, ok := w.entries[1].fields[FieldRetention]; ok {
+		t.Errorf("rules do not stick to the scope: w.entries[1].fields has key %q, want none", FieldRetention)
+	}
+	if w.entries[2].fields[FieldRetention] != RetentionLong {
+		t.Errorf("w.entries[2].fields[FieldRetention] = %q, want %q", w.entries[2].fields[FieldRetention], RetentionLong)
+	}
+	if w.entries[3].fields[FieldRetention] != RetentionLong {
+		t.Errorf("the scope overrides rules: w.entries[3].fields[FieldRetention] = %q, want %q", w.entries[3].fields[FieldRetention], RetentionLong)
+	}
+}
+
+func TestEntryRetention(t *testing.T) {
+	if got := EntryRetention(Entry{Fields: map[string]any{FieldRetention: RetentionShort}}); got != RetentionShort {
+		t.Errorf("got %q, want %q", got, RetentionShort)
+	}
+	if got := EntryRetention(Entry{}); len(got) != 0 {
+		t.Errorf("EntryRetention(Entry{}) = %q, want empty", got)
+	}
+}
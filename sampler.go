@@ -0,0 +1,206 @@
+package golog
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultSampleTick is the sampling window by default.
+const defaultSampleTick = time.Second
+
+// SamplerOption configures a writer created by NewSampler.
+type SamplerOption func(*samplerOptions)
+
+// samplerOptions holds the settings of a sampler.
+type samplerOptions struct {
+	// rules holds the sampling rule of each sampled level
+	rules map[int]sampleRule
+	// tick is the length of a sampling window
+	tick time.Duration
+	// perMessage counts the entries of each message template separately
+	perMessage bool
+}
+
+// sampleRule keeps the first entries of a window, then one in every
+// thereafter.
+type sampleRule struct {
+	first      uint64
+	thereafter uint64
+}
+
+// SampleLevel samples the entries at level: in every window (see
+// SampleTick), the first entries are written, then one in every thereafter.
+// A zero thereafter drops all the entries after the first ones. Levels
+// without a rule are never sampled.
+func SampleLevel(level, first, thereafter int) SamplerOption {
+	return func(o *samplerOptions) {
+		o.rules[level] = sampleRule{first: uint64(max(first, 0)), thereafter: uint64(max(thereafter, 0))}
+	}
+}
+
+// SampleTick sets the length of a sampling window, after which the counts
+// start over. The default is one second.
+func SampleTick(tick time.Duration) SamplerOption {
+	return func(o *samplerOptions) {
+		o.tick = tick
+	}
+}
+
+// SamplePerMessage counts the entries of each message template (see
+// MessageTemplate) separately, so that a burst of one message does not
+// crowd out the others, as zap's sampler does. By default the entries of a
+// level are counted together, which caps the rate of the level.
+func SamplePerMessage() SamplerOption {
+	return func(o *samplerOptions) {
+		o.perMessage = true
+	}
+}
+
+// SamplerStats holds the counters of a sampler.
+type SamplerStats struct {
+	// Written is the number of entries of sampled levels written
+	Written uint64
+	// Dropped is the number of entries dropped by sampling
+	Dropped uint64
+	// DroppedByLevel holds the number of entries dropped per level name
+	DroppedByLevel map[string]uint64
+}
+
+// samplerKey identifies a counter of a sampler.
+type samplerKey struct {
+	level    int
+	template string
+}
+
+// samplerWriter implements the LogWriter interface by sampling the entries
+// written to a writer.
+type samplerWriter struct {
+	writer LogWriter
+	opts   samplerOptions
+
+	mu sync.Mutex
+	// windowStart is the start of the current window
+	windowStart time.Time
+	// counts holds the entries counted in the current window
+	counts map[samplerKey]uint64
+	// written is the number of entries of sampled levels written
+	written uint64
+	// dropped holds the entries dropped per level
+	dropped map[int]uint64
+}
+
+// NewSampler returns a writer that samples the entries written to w, to
+// bound the volume of chatty levels under load, e.g. the first 100 Debug
+// entries per second, then 1 in 100. Sample the levels below LevelWarn only,
+// so that warnings and errors are never lost.
+//
+// Dropped entries are counted in Stats and reported with RecordDropped as
+// DropSampled.
+//
+// Example:
+//
+//	writer := golog.NewSampler(golog.NewJSONWriter(os.Stdout),
+//	    golog.SampleLevel(golog.LevelDebug, 100, 100),
+//	    golog.SampleLevel(golog.LevelInfo, 1000, 10),
+//	    golog.SamplePerMessage(),
+//	)
+//	golog.SetWriter(writer)
+func NewSampler(w LogWriter, opts ...SamplerOption) *samplerWriter {
+	o := samplerOptions{rules: map[int]sampleRule{}, tick: defaultSampleTick}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	if o.tick <= 0 {
+		o.tick = defaultSampleTick
+	}
+
+	return &samplerWriter{
+		writer:  w,
+		opts:    o,
+		counts:  map[samplerKey]uint64{},
+		dropped: map[int]uint64{},
+	}
+}
+
+// Write implements LogWriter.
+func (w *samplerWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *samplerWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write writes the entry when it is sampled in.
+func (w *samplerWriter) write(entry Entry, file string, line int) {
+	if !w.allow(time.Now(), entry.Level, entry.Message) {
+		RecordDropped(DropSampled, 1)
+		return
+	}
+
+	writeLocated(w.writer, entry, file, line)
+}
+
+// allow counts an entry and reports whether it is written.
+func (w *samplerWriter) allow(now time.Time, level int, msg string) bool {
+	rule, ok := w.opts.rules[level]
+	if !ok {
+		return true
+	}
+
+	key := samplerKey{level: level}
+	if w.opts.perMessage {
+		key.template = MessageTemplate(msg)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if now.Sub(w.windowStart) >= w.opts.tick {
+		clear(w.counts)
+		w.windowStart = now
+	}
+
+	w.counts[key]++
+	n := w.counts[key]
+
+	if n <= rule.first || (rule.thereafter > 0 && (n-rule.first)%rule.thereafter == 0) {
+		w.written++
+		return true
+	}
+
+	w.dropped[level]++
+
+	return false
+}
+
+// Stats returns the counters of the sampler since it was created.
+func (w *samplerWriter) Stats() SamplerStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	stats := SamplerStats{Written: w.written, DroppedByLevel: map[string]uint64{}}
+	for level, n := range w.dropped {
+		stats.Dropped += n
+		stats.DroppedByLevel[LevelString(level)] = n
+	}
+
+	return stats
+}
+
+// Flush implements LogWriter.
+func (w *samplerWriter) Flush() {
+	w.writer.Flush()
+}
+
+// flushBuffers implements bufferFlusher.
+func (w *samplerWriter) flushBuffers() {
+	flushBuffers(w.writer)
+}
@@ -0,0 +1,115 @@
+package golog
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSampler(t *testing.T) {
+	resetDrops(t)
+
+	inner := &captureWriter{}
+	w := NewSampler(inner, SampleLevel(LevelDebug, 2, 3))
+
+	for i := range 10 {
+		w.Write(LevelDebug, fmt.Sprintf("debug %d", i), nil)
+		w.Write(LevelInfo, "info", nil)
+	}
+
+	var debug []string
+	for _, entry := range inner.entries {
+		if entry.level == LevelDebug {
+			debug = append(debug, entry.msg)
+		}
+	}
+
+	want := []string{"debug 0", "debug 1", "debug 4", "debug 7"}
+	if !reflect.DeepEqual(debug, want) {
+		t.Errorf("first 2, then 1 in 3: debug = %v, want %v", debug, want)
+	}
+	if len(inner.entries) != 14 {
+		t.Errorf("unsampled levels are all written: len(inner.entries) = %d, want 14", len(inner.entries))
+	}
+	if got, want := w.Stats(), (SamplerStats{Written: 4, Dropped: 6, DroppedByLevel: map[string]uint64{"DEBUG": 6}}); !reflect.DeepEqual(got, want) {
+		t.Errorf("w.Stats() = %v, want %v", got, want)
+	}
+
+	report, ok := drops.take(true)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	if want := map[string]int{DropSampled: 6}; !reflect.DeepEqual(report["dropped_by_reason"], want) {
+		t.Errorf(`report["dropped_by_reason"] = %v, want %v`, report["dropped_by_reason"], want)
+	}
+}
+
+func TestSampler_Windows(t *testing.T) {
+	start := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		opts    []SamplerOption
+		entries []string
+		at      []time.Duration
+		want    []bool
+	}{
+		{
+			name:    "per-level",
+			opts:    []SamplerOption{SampleLevel(LevelDebug, 1, 0)},
+			entries: []string{"cache miss key=1", "job 42 started", "cache miss key=2"},
+			at:      []time.Duration{0, 0, 0},
+			want:    []bool{true, false, false},
+		},
+		{
+			name:    "per-message",
+			opts:    []SamplerOption{SampleLevel(LevelDebug, 1, 0), SamplePerMessage()},
+			entries: []string{"cache miss key=1", "job 42 started", "cache miss key=2"},
+			at:      []time.Duration{0, 0, 0},
+			want:    []bool{true, true, false},
+		},
+		{
+			name:    "new-window",
+			opts:    []SamplerOption{SampleLevel(LevelDebug, 1, 0), SampleTick(time.Minute)},
+			entries: []string{"a", "b", "c", "d"},
+			at:      []time.Duration{0, 30 * time.Second, time.Minute, time.Minute},
+			want:    []bool{true, false, true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := NewSampler(&captureWriter{}, tt.opts...)
+
+			if len(tt.at) != len(tt.entries) {
+				t.Fatalf("len(tt.at) = %d, want %d", len(tt.at), len(tt.entries))
+			}
+			for i, msg := range tt.entries {
+				if got := w.allow(start.Add(tt.at[i]), LevelDebug, msg); got != tt.want[i] {
+					t.Errorf("%s: w.allow(start.Add(tt.at[i]), LevelDebug, msg) = %v, want %v", msg, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSampler_Describe(t *testing.T) {
+	w := NewSampler(&captureWriter{}, SampleLevel(LevelDebug, 100, 100), SamplePerMessage())
+
+	d := w.Describe()
+	if d.Type != "sampler" {
+		t.Errorf("d.Type = %q, want %q", d.Type, "sampler")
+	}
+	want := map[string]string{
+		"tick":        "1s",
+		"per_message": "true",
+		"debug":       "first 100, thereafter 100",
+	}
+	if !reflect.DeepEqual(d.Settings, want) {
+		t.Errorf("d.Settings = %v, want %v", d.Settings, want)
+	}
+	if len(d.Writers) != 1 {
+		t.Fatalf("len(d.Writers) = %d, want 1", len(d.Writers))
+	}
+}
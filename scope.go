@@ -2,24 +2,57 @@ package golog
 
 import (
 	"context"
+	"errors"
 	"fmt"
-
-	"github.com/pkg/errors"
+	"maps"
+	"time"
 )
 
 // LogScope represents a logging context with associated fields and enrichers.
 // It supports method chaining (With, WithFields, WithContext, WithError) and is typically
 // used for request handlers or operations where fields should propagate to all log calls.
-// LogScope is not thread-safe; create a new scope per goroutine.
+//
+// The methods adding fields (With, WithFields, WithError, WithReturnedError,
+// and WithRetention) return a child scope carrying the fields of its parent
+// and the new ones, and leave the parent unchanged, so a long-lived scope,
+// such as a request logger, can be shared by goroutines and derived from
+// freely:
+//
+//	reqLog := golog.With("request_id", id)
+//	reqLog.With("user_agent", ua).Debug("request details") // reqLog has no user_agent
+//	go worker(reqLog.With("worker", 1))
+//
+// The methods configuring the scope itself (WithContext, WithTime,
+// WithWriter, WithLevel, and FlushOnDone) modify it in place; call them
+// before sharing the scope.
+//
+// By default a LogScope does not own a writer: entries go to the writer of its
+// Logger (the global writer installed with SetWriter for scopes created by
+// the package-level functions) at the time they are written, so scopes never
+// hold on to a writer that has since been replaced. WithWriter diverts a
+// scope's entries to a writer of its own.
 type LogScope struct {
-	// writer is the LogWriter instance used to write log entries
-	writer LogWriter
+	// logger is the Logger whose writer, level, and enrichers apply
+	logger *Logger
 	// enrichers contains the list of enrichers to apply to log entries
 	enrichers []Enricher
 	// fields contains the key-value pairs to include in log entries
 	fields map[string]any
 	// ctx contains the context associated with this scope
 	ctx context.Context
+	// time overrides the entry time when non-zero (see WithTime)
+	time time.Time
+	// writer replaces the global writer when non-nil (see WithWriter)
+	writer LogWriter
+	// gate, when set, reports whether an entry may be written (see Once and
+	// Every)
+	gate func() bool
+	// err is the error set with WithError
+	err error
+	// level replaces the level of the Logger when levelSet is true (see
+	// WithLevel)
+	level    int
+	levelSet bool
 }
 
 // Context returns the context associated with this LogScope.
@@ -27,73 +60,433 @@ func (l *LogScope) Context() context.Context {
 	return l.ctx
 }
 
-// Debug writes a log entry at the debug level.
-// The message and any additional arguments are formatted using fmt.Sprintf.
-func (l *LogScope) Debug(msg string, args ...any) {
-	l.write(LevelDebug, msg, args...)
+// Field returns the value of the field key of this LogScope, and whether it
+// has one, e.g. to propagate a request ID to a downstream service.
+func (l *LogScope) Field(key string) (any, bool) {
+	value, ok := l.fields[key]
+	return value, ok
+}
+
+// Trace writes a log entry at the trace level with the message msg, which
+// is not formatted (see Tracef).
+func (l *LogScope) Trace(msg string) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	l.write(LevelTrace, false, msg)
+}
+
+// Tracef writes a log entry at the trace level.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Tracef(format string, args ...any) {
+	if maxLevel > LevelTrace {
+		return
+	}
+
+	l.write(LevelTrace, true, format, args...)
+}
+
+// Debug writes a log entry at the debug level with the message msg, which
+// is not formatted (see Debugf).
+func (l *LogScope) Debug(msg string) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	l.write(LevelDebug, false, msg)
 }
 
-// Info writes a log entry at the info level.
-// The message and any additional arguments are formatted using fmt.Sprintf.
-func (l *LogScope) Info(msg string, args ...any) {
-	l.write(LevelInfo, msg, args...)
+// Debugf writes a log entry at the debug level.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Debugf(format string, args ...any) {
+	if maxLevel > LevelDebug {
+		return
+	}
+
+	l.write(LevelDebug, true, format, args...)
 }
 
-// Error writes a log entry at the error level and returns an error for propagation.
-// The message and any additional arguments are formatted using fmt.Sprintf.
-// If the scope has an error field (via WithError), returns errors.Wrap of that error;
-// otherwise returns a new error with the formatted message.
-func (l *LogScope) Error(msg string, args ...any) error {
-	l.write(LevelError, msg, args...)
+// Info writes a log entry at the info level with the message msg, which is
+// not formatted (see Infof).
+func (l *LogScope) Info(msg string) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	l.write(LevelInfo, false, msg)
+}
+
+// Infof writes a log entry at the info level.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Infof(format string, args ...any) {
+	if maxLevel > LevelInfo {
+		return
+	}
+
+	l.write(LevelInfo, true, format, args...)
+}
+
+// Warn writes a log entry at the warn level with the message msg, which is
+// not formatted (see Warnf).
+func (l *LogScope) Warn(msg string) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	l.write(LevelWarn, false, msg)
+}
+
+// Warnf writes a log entry at the warn level.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Warnf(format string, args ...any) {
+	if maxLevel > LevelWarn {
+		return
+	}
+
+	l.write(LevelWarn, true, format, args...)
+}
+
+// Error writes a log entry at the error level with the message msg, which
+// is not formatted (see Errorf), and returns an error for propagation.
+// If the scope has an error, set with WithError or WithReturnedError, or an
+// error field holding an error, the returned error wraps it; otherwise it is
+// a new error with the message. The errors are created by
+// the ErrorWrapper set with SetErrorWrapper.
+//
+// The returned error carries a snapshot of the scope's fields, so that a
+// caller logging it further up with WithReturnedError keeps the context of
+// the failure without it having to be logged twice.
+func (l *LogScope) Error(msg string) error {
+	l.write(LevelError, false, msg)
+
+	return l.newError(msg)
+}
 
-	if l.fields["error"] != nil {
-		err, ok := l.fields["error"].(error)
-		if ok {
-			return errors.Wrap(err, fmt.Sprintf(msg, args...))
+// Errorf writes a log entry at the error level and returns an error for
+// propagation, like Error.
+// The message is formatted with args using fmt.Sprintf, once: the returned
+// error carries it, so it is formatted even when the level is disabled, but
+// the entry is only built when the level is enabled.
+func (l *LogScope) Errorf(format string, args ...any) error {
+	msg := fmt.Sprintf(format, args...)
+	if l.enabled(LevelError) {
+		l.write(LevelError, false, msg)
+	}
+
+	return l.newError(msg)
+}
+
+// newError returns the error returned by Error for the message msg.
+func (l *LogScope) newError(msg string) error {
+	var err error
+	if l.err != nil {
+		err = errorWrapper.Wrap(l.err, msg)
+	} else if cause, ok := l.fields["error"].(error); ok {
+		err = errorWrapper.Wrap(cause, msg)
+	} else {
+		err = errorWrapper.New(msg)
+	}
+
+	return &scopedError{err: err, fields: maps.Clone(l.fields)}
+}
+
+// WithReturnedError returns a child scope with the fields carried by err, as
+// returned by Error, and the error field. Fields already set on the scope are
+// kept.
+func (l *LogScope) WithReturnedError(err error) *LogScope {
+	child := l.child(0)
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		scoped, ok := e.(*scopedError)
+		if !ok {
+			continue
+		}
+
+		for k, v := range scoped.fields {
+			if _, exists := child.fields[k]; !exists {
+				child.fields[k] = v
+			}
 		}
 	}
 
-	return errors.New(fmt.Sprintf(msg, args...))
+	child.setError(err)
+
+	return child
 }
 
-// With adds a key-value field to this LogScope.
-// It returns the LogScope for method chaining.
+// ErrorIf writes a log entry at the error level, with err in the error field,
+// and returns an error wrapping err, when err is not nil. It does nothing and
+// returns nil when err is nil, replacing the if err != nil boilerplate:
+//
+//	return golog.With("path", path).ErrorIf(f.Close(), "close failed")
+//
+// The message is not formatted.
+func (l *LogScope) ErrorIf(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+
+	return l.WithError(err).Error(msg)
+}
+
+// DebugIf writes a log entry at the debug level when cond is true.
+// The message is not formatted.
+func (l *LogScope) DebugIf(cond bool, msg string) {
+	if cond {
+		l.Debug(msg)
+	}
+}
+
+// InfoIf writes a log entry at the info level when cond is true.
+// The message is not formatted.
+func (l *LogScope) InfoIf(cond bool, msg string) {
+	if cond {
+		l.Info(msg)
+	}
+}
+
+// Panic writes a log entry at the panic level with the message msg, which
+// is not formatted (see Panicf), flushes the writer, and panics with the
+// message.
+func (l *LogScope) Panic(msg string) {
+	l.write(LevelPanic, false, msg)
+	l.flush()
+
+	panic(msg)
+}
+
+// Panicf writes a log entry at the panic level, flushes the writer, and
+// panics with the formatted message.
+// The message is formatted with args using fmt.Sprintf, once: the panic
+// value carries it, so it is formatted even when the level is disabled, but
+// the entry is only built when the level is enabled.
+func (l *LogScope) Panicf(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if l.enabled(LevelPanic) {
+		l.write(LevelPanic, false, msg)
+	}
+	l.flush()
+
+	panic(msg)
+}
+
+// Fatal writes a log entry at the fatal level with the message msg, which
+// is not formatted (see Fatalf), flushes the writer, and exits the process
+// with status 1 (see SetExitFunc). Deferred functions do not run.
+func (l *LogScope) Fatal(msg string) {
+	l.write(LevelFatal, false, msg)
+	l.flush()
+
+	exitFunc(1)
+}
+
+// Fatalf writes a log entry at the fatal level, flushes the writer, and
+// exits the process with status 1, like Fatal.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Fatalf(format string, args ...any) {
+	l.write(LevelFatal, true, format, args...)
+	l.flush()
+
+	exitFunc(1)
+}
+
+// Log writes a log entry at level with the message msg, which is not
+// formatted (see Logf), for code forwarding entries logged elsewhere, such
+// as a collector or a bridge from another logging library.
+// Unlike Error, Panic, and Fatal, it has no other effect: it returns no
+// error, does not panic, and does not exit.
+func (l *LogScope) Log(level int, msg string) {
+	l.write(level, false, msg)
+}
+
+// Logf writes a log entry at level, like Log.
+// The message is formatted with args using fmt.Sprintf.
+func (l *LogScope) Logf(level int, format string, args ...any) {
+	l.write(level, true, format, args...)
+}
+
+// flush flushes the writer of the scope's Logger and the scope's writer, if
+// any.
+func (l *LogScope) flush() {
+	l.logger.Flush()
+
+	if l.writer != nil {
+		l.writer.Flush()
+	}
+}
+
+// With returns a child scope with a key-value field added to the fields of
+// this LogScope, which is left unchanged.
 func (l *LogScope) With(key string, value any) *LogScope {
-	l.fields[key] = value
-	return l
+	child := l.child(1)
+	child.fields[key] = value
+
+	return child
+}
+
+// child returns a copy of the scope with its own fields map, with room for
+// extra more fields.
+func (l *LogScope) child(extra int) *LogScope {
+	child := *l
+	child.fields = make(map[string]any, len(l.fields)+extra)
+	maps.Copy(child.fields, l.fields)
+
+	return &child
+}
+
+// enabled reports whether an entry at level passes the level of the scope,
+// or of its Logger, and of its writer.
+func (l *LogScope) enabled(level int) bool {
+	cfg := l.logger.load()
+
+	writer := l.writer
+	if writer == nil {
+		writer = cfg.writer
+	}
+
+	return enabledAt(level, writerMinLevel(writer, l.levelIn(cfg)))
 }
 
 // write is an internal method that writes a log entry with the given level and message.
-// It applies all registered enrichers before writing.
-func (l *LogScope) write(level int, msg string, args ...any) {
-	// Check if we should log this level
-	if !shouldLog(level) {
+// It applies all registered enrichers before writing to the scope's writer, or
+// the current writer of its Logger. When format is true, the message is msg
+// formatted with args, which is only done once the entry is known to be
+// enabled; otherwise msg is the message as is.
+func (l *LogScope) write(level int, format bool, msg string, args ...any) {
+	// use one snapshot of the configuration of the Logger for the whole
+	// entry, even if it is swapped meanwhile
+	cfg := l.logger.load()
+
+	writer := l.writer
+	if writer == nil {
+		writer = cfg.writer
+	}
+
+	// Check if we should log this level: the level of the scope, or of its
+	// Logger, applies to the writers without a level of their own
+	minLevel := l.levelIn(cfg)
+	lowest := writerMinLevel(writer, minLevel)
+	if !enabledAt(level, lowest) {
+		return
+	}
+
+	if l.gate != nil && !l.gate() {
 		return
 	}
 
-	// Apply enrichers
-	for _, enricher := range l.enrichers {
-		enricher.Enrich(l.ctx, LevelString(level), fmt.Sprintf(msg, args...), l.fields)
+	message := msg
+	if format {
+		message = fmt.Sprintf(msg, args...)
+	}
+
+	// Apply enrichers to a copy of the fields, so that the scope, which may
+	// be shared, is left unchanged
+	fields := l.fields
+	origins := newProvenance(fields)
+	if len(l.enrichers) > 0 {
+		fields = maps.Clone(l.fields)
+		if fields == nil {
+			fields = map[string]any{}
+		}
+
+		for _, enricher := range l.enrichers {
+			if origins != nil {
+				origins.enrich(enricher, l.ctx, LevelString(level), message, fields)
+				continue
+			}
+
+			enricher.Enrich(l.ctx, LevelString(level), message, fields)
+		}
+	}
+
+	checkFieldTypes(cfg.writer, fields)
+
+	level, ok := silences.apply(time.Now(), level, message, fields)
+	if !ok || !enabledAt(level, lowest) {
+		return
+	}
+
+	fallback, ok := degrade(level)
+	if !ok {
+		return
+	}
+
+	retained := applyRetention(level, message, fields)
+	if origins != nil {
+		origins.track(ProvenanceRetention, fields, retained)
+	}
+
+	fields, ok = l.logger.applyFlags(level, retained)
+	if !ok {
+		return
+	}
+
+	fields = applyRedaction(fields)
+
+	if origins != nil {
+		origins.keep(fields)
+		fields = origins.addTo(fields)
+	}
+
+	// the reports go to the Logger's writer, or to the writer of the
+	// degradation profile, which replaces it
+	reports := cfg.writer
+	if fallback != nil {
+		writer, reports = fallback, fallback
+	}
+
+	reportDropped(reports, false)
+	reportSilenced(reports)
+
+	entry := Entry{
+		Time:    l.entryTime(),
+		Level:   level,
+		Message: message,
+		Fields:  fields,
+		Err:     redactError(l.err),
+		Context: l.ctx,
 	}
 
-	l.writer.Write(level, fmt.Sprintf(msg, args...), l.fields)
+	writeEntry(writer, entry, minLevel, cfg.skip())
+
+	fireHooks(cfg.hooks, entry)
 }
 
-// WithError adds an error field to this LogScope.
-// It returns the LogScope for method chaining.
+// entryTime returns the time override set with WithTime, or the current time.
+func (l *LogScope) entryTime() time.Time {
+	if !l.time.IsZero() {
+		return l.time
+	}
+
+	return time.Now()
+}
+
+// WithError returns a child scope with an error field, and an error_kind
+// field when error classification is enabled (see SetErrorKinds).
 func (l *LogScope) WithError(err error) *LogScope {
+	child := l.child(2)
+	child.setError(err)
+
+	return child
+}
+
+// setError sets the error fields of a scope not shared yet.
+func (l *LogScope) setError(err error) {
+	l.err = err
 	l.fields["error"] = err.Error()
-	return l
+
+	if kind, ok := errorKind(err); ok {
+		l.fields[FieldErrorKind] = kind
+	}
 }
 
-// WithFields adds multiple key-value fields to this LogScope.
-// It returns the LogScope for method chaining.
+// WithFields returns a child scope with multiple key-value fields added to
+// the fields of this LogScope, which is left unchanged.
 func (l *LogScope) WithFields(fields map[string]any) *LogScope {
-	for k, v := range fields {
-		l.fields[k] = v
-	}
+	child := l.child(len(fields))
+	maps.Copy(child.fields, fields)
 
-	return l
+	return child
 }
 
 // WithContext sets the context for this LogScope.
@@ -103,18 +496,97 @@ func (l *LogScope) WithContext(ctx context.Context) *LogScope {
 	return l
 }
 
-// newScope creates a new LogScope with default values.
-// It uses the global log writer instance and creates an empty fields map.
-func newScope() *LogScope {
-	return &LogScope{
-		writer: instance,
-		fields: make(map[string]any),
-		ctx:    context.Background(),
+// FlushOnDone flushes the writer of the scope's Logger, and the scope's writer
+// if any, when the scope's context is done, so that buffered entries of a
+// request are written as soon as the request ends or is canceled rather than
+// with the next flush. Unlike Flush, it keeps the outputs of the writers
+// open, so later requests can still write to them. Call it after
+// WithContext; it does nothing for contexts that are never done, such as
+// context.Background().
+// It returns the LogScope for method chaining.
+//
+// Example:
+//
+//	scope := golog.WithContext(r.Context()).With("request_id", id).FlushOnDone()
+func (l *LogScope) FlushOnDone() *LogScope {
+	if l.ctx.Done() == nil {
+		return l
+	}
+
+	logger, writer := l.logger, l.writer
+	context.AfterFunc(l.ctx, func() {
+		logger.flushBuffers()
+		if writer != nil {
+			flushBuffers(writer)
+		}
+	})
+
+	return l
+}
+
+// WithTime sets the time recorded for entries written by this LogScope,
+// instead of the time they are written. Use it for replayed events, imported
+// batches, or delayed queues so entries carry the original event time.
+// Writers that do not implement EntryWriter ignore the override.
+// It returns the LogScope for method chaining.
+func (l *LogScope) WithTime(t time.Time) *LogScope {
+	l.time = t
+	return l
+}
+
+// WithWriter diverts the entries of this LogScope to w instead of the global
+// writer, so a subsystem or a request can write to a sink of its own, e.g. a
+// per-tenant debug capture, without changing the global configuration. The
+// level set with SetLevel, or with WithLevel, still applies. Scopes derived from the context
+// (see IntoContext) keep the writer. Flushing and closing w is up to the
+// caller; the package-level Flush only flushes the global writer.
+// It returns the LogScope for method chaining.
+func (l *LogScope) WithWriter(w LogWriter) *LogScope {
+	l.writer = w
+	return l
+}
+
+// WithLevel sets the minimum level of the entries of this LogScope, in
+// place of the level of its Logger, e.g. to log the debug entries of a
+// single request, or to quiet a noisy component. Writers with a level of
+// their own (see LevelFilter) keep it, and the levels removed at compile
+// time stay removed. Scopes derived from the context (see IntoContext) keep
+// the level. Unknown levels are ignored.
+// It returns the LogScope for method chaining.
+//
+// Example:
+//
+//	scope := golog.WithContext(r.Context()).With("request_id", id)
+//	if r.Header.Get("X-Debug") == token {
+//	    scope.WithLevel(golog.LevelDebug)
+//	}
+func (l *LogScope) WithLevel(level int) *LogScope {
+	if _, ok := levelNames[level]; ok {
+		l.level, l.levelSet = level, true
+	}
+
+	return l
+}
+
+// levelIn returns the minimum level of the entries of the scope with the
+// configuration cfg of its Logger.
+func (l *LogScope) levelIn(cfg *loggerConfig) int {
+	if l.levelSet {
+		return l.level
 	}
+
+	return l.logger.levelOf(cfg)
+}
+
+// newScope creates a new LogScope of the default Logger.
+func newScope() *LogScope {
+	return std.newScope()
 }
 
 // Flush ensures all buffered log entries are written.
-// It calls Flush on the underlying log writer.
+// It flushes the global log writer, which is shared by all scopes.
+//
+// Deprecated: a LogScope does not own a writer; use the package-level Flush.
 func (l *LogScope) Flush() {
-	l.writer.Flush()
+	Flush()
 }
@@ -0,0 +1,208 @@
+package golog
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+const (
+	// SelfMetricsMessage is the message of the entries written by SelfMetrics.
+	SelfMetricsMessage = "golog self metrics"
+	// selfMetricsTopTemplates is the number of templates listed in a report
+	selfMetricsTopTemplates = 10
+	// selfMetricsMaxTemplates bounds the distinct templates counted per interval
+	selfMetricsMaxTemplates = 1000
+	// templatePlaceholder replaces the variable parts of a message template
+	templatePlaceholder = "<*>"
+)
+
+// SelfMetrics makes the JSON and default writers add a summary entry of their
+// own output every interval, so logging cost regressions show up in the logs
+// themselves. The entry has the message "golog self metrics" and the fields:
+//
+//   - interval: the time covered by the summary
+//   - entries: the number of entries written
+//   - bytes: the number of bytes written
+//   - avg_entry_bytes: the average entry size in bytes
+//   - entries_per_level: the number of entries per level name
+//   - top_templates: the 10 most frequent message templates (see
+//     MessageTemplate) with their counts
+//
+// The summary is written with the first entry logged after the interval has
+// elapsed, so an idle writer writes none. A zero interval disables it.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.SelfMetrics(time.Minute))
+func SelfMetrics(interval time.Duration) WriterOption {
+	return func(o *writerOptions) {
+		if interval <= 0 {
+			o.metrics = nil
+			return
+		}
+
+		o.metrics = &selfMetrics{interval: interval, start: time.Now()}
+	}
+}
+
+// TemplateCount is a message template and the number of entries using it.
+type TemplateCount struct {
+	Template string `json:"template"`
+	Count    int    `json:"count"`
+}
+
+// selfMetrics accumulates the output statistics of a writer over an interval.
+type selfMetrics struct {
+	mu       sync.Mutex
+	interval time.Duration
+	// start is when the current interval started
+	start     time.Time
+	entries   int
+	bytes     int
+	levels    map[int]int
+	templates map[string]int
+	// reporting is set while the summary entry itself is written
+	reporting bool
+}
+
+// record counts one written entry of size bytes. When the interval has
+// elapsed, it returns the fields of the summary entry and starts a new
+// interval; the caller writes the summary and then calls reported. Entries
+// recorded while the summary is written are not counted. It does nothing on
+// a nil receiver.
+func (m *selfMetrics) record(level int, msg string, size int) map[string]any {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.reporting {
+		return nil
+	}
+
+	if m.levels == nil {
+		m.levels = make(map[int]int)
+		m.templates = make(map[string]int)
+	}
+
+	m.entries++
+	m.bytes += size
+	m.levels[level]++
+
+	template := MessageTemplate(msg)
+	if _, ok := m.templates[template]; ok || len(m.templates) < selfMetricsMaxTemplates {
+		m.templates[template]++
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(m.start)
+	if elapsed < m.interval {
+		return nil
+	}
+
+	report := m.report(elapsed)
+	m.start = now
+	m.entries, m.bytes = 0, 0
+	m.levels = nil
+	m.templates = nil
+	m.reporting = true
+
+	return report
+}
+
+// reported marks the end of writing the summary entry.
+func (m *selfMetrics) reported() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reporting = false
+}
+
+// report returns the summary fields of the current interval.
+func (m *selfMetrics) report(elapsed time.Duration) map[string]any {
+	levels := make(map[string]int, len(m.levels))
+	for level, count := range m.levels {
+		levels[LevelString(level)] = count
+	}
+
+	top := make([]TemplateCount, 0, len(m.templates))
+	for template, count := range m.templates {
+		top = append(top, TemplateCount{Template: template, Count: count})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+
+		return top[i].Template < top[j].Template
+	})
+
+	if len(top) > selfMetricsTopTemplates {
+		top = top[:selfMetricsTopTemplates]
+	}
+
+	return map[string]any{
+		"interval":          elapsed.Round(time.Millisecond).String(),
+		"entries":           m.entries,
+		"bytes":             m.bytes,
+		"avg_entry_bytes":   m.bytes / m.entries,
+		"entries_per_level": levels,
+		"top_templates":     top,
+	}
+}
+
+// MessageTemplate returns msg with its variable parts, the words containing
+// digits such as IDs, counts, durations, and addresses, replaced by "<*>".
+// Words are delimited by spaces, punctuation other than "." "-" "_" ":", and
+// the "=" and "/" separators, so keys and paths are kept:
+//
+//	MessageTemplate("user=42 fetched /orders/9f3c1 in 12.5ms") // "user=<*> fetched /orders/<*> in <*>"
+func MessageTemplate(msg string) string {
+	var sb strings.Builder
+
+	start := -1
+	digit := false
+	flush := func(end int) {
+		if start < 0 {
+			return
+		}
+
+		if digit {
+			sb.WriteString(templatePlaceholder)
+		} else {
+			sb.WriteString(msg[start:end])
+		}
+
+		start, digit = -1, false
+	}
+
+	for i, r := range msg {
+		if isTemplateWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+
+			digit = digit || unicode.IsDigit(r)
+
+			continue
+		}
+
+		flush(i)
+		sb.WriteRune(r)
+	}
+
+	flush(len(msg))
+
+	return sb.String()
+}
+
+// isTemplateWordRune reports whether r is part of a word in MessageTemplate.
+func isTemplateWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' || r == ':'
+}
@@ -0,0 +1,152 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      string
+		expected string
+	}{
+		{
+			name:     "no variable parts",
+			msg:      "server started",
+			expected: "server started",
+		},
+		{
+			name:     "numbers and durations",
+			msg:      "fetched 42 rows in 12.5ms",
+			expected: "fetched <*> rows in <*>",
+		},
+		{
+			name:     "keys and paths are kept",
+			msg:      "user=42 fetched /orders/9f3c1",
+			expected: "user=<*> fetched /orders/<*>",
+		},
+		{
+			name:     "addresses and identifiers",
+			msg:      "dial 10.0.0.1:8080 failed, request_id 7c1e-41aa",
+			expected: "dial <*> failed, request_id <*>",
+		},
+		{
+			name:     "empty",
+			msg:      "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageTemplate(tt.msg); got != tt.expected {
+				t.Errorf("MessageTemplate(tt.msg) = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSelfMetrics(t *testing.T) {
+	tests := []struct {
+		name   string
+		writer func(buf *bytes.Buffer) LogWriter
+		// report verifies the summary line
+		report func(t *testing.T, lines []string)
+	}{
+		{
+			name:   "json-writer",
+			writer: func(buf *bytes.Buffer) LogWriter { return NewJSONWriter(buf, SelfMetrics(time.Hour)) },
+			report: func(t *testing.T, lines []string) {
+				var entry map[string]any
+				if err := json.Unmarshal([]byte(lines[3]), &entry); err != nil {
+					t.Fatalf("json.Unmarshal([]byte(lines[3]), &entry): %v", err)
+				}
+				if entry[FieldMessage] != SelfMetricsMessage {
+					t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], SelfMetricsMessage)
+				}
+				if entry["entries"] != float64(3) {
+					t.Errorf(`entry["entries"] = %v, want %v`, entry["entries"], float64(3))
+				}
+				want := float64(len(lines[0]) + len(lines[1]) + len(lines[2]) + 3)
+				if entry["bytes"] != want {
+					t.Errorf(`entry["bytes"] = %v, want %v`, entry["bytes"], want)
+				}
+				if want := map[string]any{"INFO": float64(2), "ERROR": float64(1)}; !reflect.DeepEqual(entry["entries_per_level"], want) {
+					t.Errorf(`entry["entries_per_level"] = %v, want %v`, entry["entries_per_level"], want)
+				}
+				if want := []any{
+					map[string]any{"template": "order <*> paid", "count": float64(2)},
+					map[string]any{"template": "order <*> failed", "count": float64(1)},
+				}; !reflect.DeepEqual(entry["top_templates"], want) {
+					t.Errorf(`entry["top_templates"] = %v, want %v`, entry["top_templates"], want)
+				}
+			},
+		},
+		{
+			name:   "default-writer",
+			writer: func(buf *bytes.Buffer) LogWriter { return NewDefaultWriter(buf, SelfMetrics(time.Hour), SortKeys()) },
+			report: func(t *testing.T, lines []string) {
+				if !strings.Contains(lines[3], SelfMetricsMessage) {
+					t.Errorf("lines[3] = %q, want it to contain %q", lines[3], SelfMetricsMessage)
+				}
+				if !strings.Contains(lines[3], `entries="3"`) {
+					t.Errorf("lines[3] = %q, want it to contain %q", lines[3], `entries="3"`)
+				}
+				want := `top_templates="[{"template":"order <*> paid","count":2}`
+				if !strings.Contains(lines[3], want) {
+					t.Errorf("lines[3] = %q, want it to contain %q", lines[3], want)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.writer(buf)
+
+			var metrics *selfMetrics
+			switch w := w.(type) {
+			case *jsonWriter:
+				metrics = w.opts.metrics
+			case *defaultWriter:
+				metrics = w.opts.metrics
+			}
+
+			w.Write(LevelInfo, "order 1 paid", nil)
+			w.Write(LevelError, "order 2 failed", nil)
+
+			// the interval elapses before the third entry
+			metrics.start = metrics.start.Add(-time.Hour)
+			w.Write(LevelInfo, "order 3 paid", nil)
+			w.Write(LevelInfo, "next interval", nil)
+			w.(entryEncoder).flushBuffer()
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if len(lines) != 5 {
+				t.Fatalf("the summary follows the entry that ends the interval: len(lines) = %d, want 5", len(lines))
+			}
+			tt.report(t, lines)
+			if !strings.Contains(lines[4], "next interval") {
+				t.Errorf("lines[4] = %q, want it to contain %q", lines[4], "next interval")
+			}
+			if metrics.entries != 1 {
+				t.Errorf("the summary itself is not counted: metrics.entries = %v, want 1", metrics.entries)
+			}
+		})
+	}
+}
+
+func TestSelfMetrics_Disabled(t *testing.T) {
+	if got := newWriterOptions([]WriterOption{SelfMetrics(0)}).metrics; got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+	if got := newWriterOptions(nil).metrics; got != nil {
+		t.Errorf("newWriterOptions(nil).metrics = %v, want nil", got)
+	}
+}
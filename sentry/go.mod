@@ -0,0 +1,19 @@
+module github.com/jkaveri/golog/sentry
+
+go 1.23.4
+
+replace github.com/jkaveri/golog => ../
+
+require (
+	github.com/getsentry/sentry-go v0.35.3
+	github.com/jkaveri/golog v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.2-0.20180830191138-d8f796af33cc // indirect
+	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
+	golang.org/x/sys v0.18.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
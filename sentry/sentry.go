@@ -0,0 +1,235 @@
+// Package sentry reports golog entries to Sentry as events, so that errors
+// logged by an application show up in Sentry with their fields and stack
+// traces.
+//
+// A Hook sends the entries at LevelError and above, by default, as events:
+// the level becomes the Sentry level, the message the event message, the
+// fields chosen with TagFields the tags, and the other fields the extra
+// data. The error passed to golog.WithError (see golog.Entry.Err) becomes the
+// exception of the event, with the stack trace recorded by
+// golog.StackErrors, or by any error type Sentry understands, such as those
+// of github.com/pkg/errors. The error is redacted like the fields when
+// golog.SetRedaction is enabled.
+//
+// Delivery is asynchronous: the Sentry SDK's default HTTP transport queues
+// the events and sends them from a goroutine of its own, so logging never
+// waits for Sentry. Flush drains the queue, bounded by the flush timeout;
+// call it, or golog.Flush when the hook is also a writer, before the process
+// exits.
+//
+// Example, as a hook of the default Logger:
+//
+//	import gologsentry "github.com/jkaveri/golog/sentry"
+//
+//	err := sentry.Init(sentry.ClientOptions{Dsn: dsn})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	hook := gologsentry.NewHook(sentry.CurrentHub(), gologsentry.TagFields("tenant", "region"))
+//	golog.AddHook(hook, golog.LevelError, golog.LevelPanic, golog.LevelFatal)
+//	defer hook.Flush()
+//
+//	golog.WithError(err).With("order_id", id).Error("charge failed")
+package sentry
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/jkaveri/golog"
+)
+
+const (
+	// defaultFlushTimeout bounds Flush by default
+	defaultFlushTimeout = 2 * time.Second
+	// defaultMaxErrorDepth bounds the error chains reported without a client
+	defaultMaxErrorDepth = 10
+	// loggerName is the logger of the events
+	loggerName = "golog"
+)
+
+// Option configures a Hook.
+type Option func(*options)
+
+// options holds the settings of a Hook.
+type options struct {
+	// minLevel is the lowest level of the entries sent
+	minLevel int
+	// tags holds the keys of the fields sent as tags
+	tags map[string]bool
+	// flushTimeout bounds Flush
+	flushTimeout time.Duration
+}
+
+// MinLevel sets the lowest level of the entries sent to Sentry. The default
+// is golog.LevelError.
+func MinLevel(level int) Option {
+	return func(o *options) {
+		o.minLevel = level
+	}
+}
+
+// TagFields sends the fields named keys as tags of the events, which Sentry
+// indexes for search and grouping, such as a tenant or a region. The other
+// fields are sent as extra data. Tags are strings; other values are
+// formatted with fmt.Sprint.
+func TagFields(keys ...string) Option {
+	return func(o *options) {
+		for _, key := range keys {
+			o.tags[key] = true
+		}
+	}
+}
+
+// FlushTimeout bounds Flush. The default is 2 seconds.
+func FlushTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.flushTimeout = timeout
+	}
+}
+
+// Hook sends golog entries to Sentry as events. It implements golog.Hook,
+// to be added to a Logger with golog.AddHook, and golog.LogWriter, to be
+// combined with other writers, e.g. with golog.NewMultiWriter. It is safe
+// for concurrent use.
+type Hook struct {
+	hub  *sentrygo.Hub
+	opts options
+}
+
+// NewHook creates a Hook sending events through hub, or through the current
+// hub (see sentrygo.CurrentHub) when hub is nil, so that the hook may be
+// created before sentry.Init is called.
+func NewHook(hub *sentrygo.Hub, opts ...Option) *Hook {
+	o := options{
+		minLevel:     golog.LevelError,
+		tags:         map[string]bool{},
+		flushTimeout: defaultFlushTimeout,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	return &Hook{hub: hub, opts: o}
+}
+
+// Fire implements golog.Hook.
+func (h *Hook) Fire(entry golog.Entry) {
+	if entry.Level < h.opts.minLevel {
+		return
+	}
+
+	h.currentHub().CaptureEvent(h.event(entry))
+}
+
+// Write implements golog.LogWriter.
+func (h *Hook) Write(level int, msg string, fields map[string]any) {
+	h.Fire(golog.Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields})
+}
+
+// WriteEntry implements golog.EntryWriter.
+func (h *Hook) WriteEntry(entry golog.Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	h.Fire(entry)
+}
+
+// Flush implements golog.LogWriter by waiting for the queued events to be
+// sent, bounded by the flush timeout.
+func (h *Hook) Flush() {
+	h.currentHub().Flush(h.opts.flushTimeout)
+}
+
+// Describe implements golog.Describer.
+func (h *Hook) Describe() golog.WriterDescription {
+	tags := make([]string, 0, len(h.opts.tags))
+	for key := range h.opts.tags {
+		tags = append(tags, key)
+	}
+	slices.Sort(tags)
+
+	return golog.WriterDescription{
+		Type: "sentry",
+		Settings: map[string]string{
+			"min_level":     golog.LevelString(h.opts.minLevel),
+			"tags":          fmt.Sprint(tags),
+			"flush_timeout": h.opts.flushTimeout.String(),
+		},
+	}
+}
+
+// currentHub returns the hub of the hook, or the current hub.
+func (h *Hook) currentHub() *sentrygo.Hub {
+	if h.hub != nil {
+		return h.hub
+	}
+
+	return sentrygo.CurrentHub()
+}
+
+// event converts an entry into a Sentry event. It copies the fields, which
+// the entry does not let the hook retain.
+func (h *Hook) event(entry golog.Entry) *sentrygo.Event {
+	event := sentrygo.NewEvent()
+	event.Level = Level(entry.Level)
+	event.Message = entry.Message
+	event.Timestamp = entry.Time
+	event.Logger = loggerName
+
+	for key, value := range entry.Fields {
+		if !h.opts.tags[key] {
+			event.Extra[key] = value
+			continue
+		}
+
+		if v, ok := value.(string); ok {
+			event.Tags[key] = v
+		} else {
+			event.Tags[key] = fmt.Sprint(value)
+		}
+	}
+
+	if entry.Err != nil {
+		// keep the error field, the message of the error, out of the extra
+		// data: it is the value of the exception
+		delete(event.Extra, "error")
+		event.SetException(entry.Err, h.maxErrorDepth())
+	}
+
+	return event
+}
+
+// maxErrorDepth returns the depth of the error chains reported by the
+// client of the hub.
+func (h *Hook) maxErrorDepth() int {
+	if client := h.currentHub().Client(); client != nil {
+		return client.Options().MaxErrorDepth
+	}
+
+	return defaultMaxErrorDepth
+}
+
+// Level returns the Sentry level of a golog level. Sentry has no trace and
+// panic levels: LevelTrace maps to the debug level and LevelPanic to the
+// fatal level.
+func Level(level int) sentrygo.Level {
+	switch {
+	case level >= golog.LevelPanic:
+		return sentrygo.LevelFatal
+	case level == golog.LevelError:
+		return sentrygo.LevelError
+	case level == golog.LevelWarn:
+		return sentrygo.LevelWarning
+	case level == golog.LevelInfo:
+		return sentrygo.LevelInfo
+	default:
+		return sentrygo.LevelDebug
+	}
+}
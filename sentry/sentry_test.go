@@ -0,0 +1,145 @@
+package sentry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	sentrygo "github.com/getsentry/sentry-go"
+	"github.com/jkaveri/golog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTransport is a sentrygo.Transport keeping the events sent.
+type memoryTransport struct {
+	mu      sync.Mutex
+	events  []*sentrygo.Event
+	flushes int
+}
+
+func (t *memoryTransport) Configure(sentrygo.ClientOptions) {}
+
+func (t *memoryTransport) SendEvent(event *sentrygo.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.events = append(t.events, event)
+}
+
+func (t *memoryTransport) Flush(time.Duration) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.flushes++
+
+	return true
+}
+
+func (t *memoryTransport) FlushWithContext(context.Context) bool {
+	return t.Flush(0)
+}
+
+func (t *memoryTransport) Close() {}
+
+// newHub returns a hub sending events to a memoryTransport.
+func newHub(t *testing.T) (*sentrygo.Hub, *memoryTransport) {
+	t.Helper()
+
+	transport := &memoryTransport{}
+	client, err := sentrygo.NewClient(sentrygo.ClientOptions{
+		Dsn:       "https://public@sentry.example.com/1",
+		Transport: transport,
+	})
+	require.NoError(t, err)
+
+	return sentrygo.NewHub(client, sentrygo.NewScope()), transport
+}
+
+func TestHook(t *testing.T) {
+	hub, transport := newHub(t)
+	hook := NewHook(hub, TagFields("tenant", "attempt"))
+
+	lg := golog.New(golog.LoggerWriter(golog.NewJSONWriter(io.Discard)))
+	lg.AddHook(hook)
+
+	cause := golog.StackErrors().New("connection refused")
+	lg.WithError(cause).
+		With("tenant", "acme").
+		With("attempt", 3).
+		With("order_id", 42).
+		Error("charge failed")
+	lg.Warn("slow response")
+
+	hook.Flush()
+
+	require.Len(t, transport.events, 1, "entries below the minimum level are not sent")
+	event := transport.events[0]
+	assert.Equal(t, "charge failed", event.Message)
+	assert.Equal(t, sentrygo.LevelError, event.Level)
+	assert.Equal(t, map[string]string{"tenant": "acme", "attempt": "3"}, event.Tags)
+	assert.Equal(t, 42, event.Extra["order_id"])
+	assert.NotContains(t, event.Extra, "error", "the error is the exception")
+
+	require.NotEmpty(t, event.Exception)
+	exception := event.Exception[len(event.Exception)-1]
+	assert.Equal(t, "connection refused", exception.Value)
+	require.NotNil(t, exception.Stacktrace)
+	require.NotEmpty(t, exception.Stacktrace.Frames)
+	assert.Equal(t, "TestHook", exception.Stacktrace.Frames[len(exception.Stacktrace.Frames)-1].Function,
+		"the stack trace is the one recorded by the error")
+
+	assert.Equal(t, 1, transport.flushes)
+}
+
+func TestHook_Writer(t *testing.T) {
+	hub, transport := newHub(t)
+	hook := NewHook(hub, MinLevel(golog.LevelWarn))
+
+	hook.Write(golog.LevelInfo, "started", nil)
+	hook.Write(golog.LevelWarn, "disk almost full", map[string]any{"free": "5%"})
+	hook.WriteEntry(golog.Entry{Level: golog.LevelFatal, Message: "out of memory", Err: errors.New("oom")})
+
+	require.Len(t, transport.events, 2)
+	assert.Equal(t, sentrygo.LevelWarning, transport.events[0].Level)
+	assert.Equal(t, "5%", transport.events[0].Extra["free"])
+	assert.Empty(t, transport.events[0].Exception)
+	assert.Equal(t, sentrygo.LevelFatal, transport.events[1].Level)
+	assert.False(t, transport.events[1].Timestamp.IsZero())
+	require.NotEmpty(t, transport.events[1].Exception)
+	assert.Equal(t, "oom", transport.events[1].Exception[0].Value)
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		level int
+		want  sentrygo.Level
+	}{
+		{golog.LevelTrace, sentrygo.LevelDebug},
+		{golog.LevelDebug, sentrygo.LevelDebug},
+		{golog.LevelInfo, sentrygo.LevelInfo},
+		{golog.LevelWarn, sentrygo.LevelWarning},
+		{golog.LevelError, sentrygo.LevelError},
+		{golog.LevelPanic, sentrygo.LevelFatal},
+		{golog.LevelFatal, sentrygo.LevelFatal},
+	}
+
+	for _, tt := range tests {
+		t.Run(golog.LevelString(tt.level), func(t *testing.T) {
+			assert.Equal(t, tt.want, Level(tt.level))
+		})
+	}
+}
+
+func TestHook_Describe(t *testing.T) {
+	d := NewHook(nil, TagFields("tenant", "region")).Describe()
+	assert.Equal(t, "sentry", d.Type)
+	assert.Equal(t, map[string]string{
+		"min_level":     "ERROR",
+		"tags":          "[region tenant]",
+		"flush_timeout": "2s",
+	}, d.Settings)
+}
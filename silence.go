@@ -0,0 +1,201 @@
+package golog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SilencedEntriesMessage is the message of the entries summarizing the
+// entries silenced during a silence window.
+const SilencedEntriesMessage = "golog silenced entries"
+
+// Silence actions
+const (
+	// SilenceSuppress drops matching entries
+	SilenceSuppress = "suppress"
+	// SilenceDowngrade writes matching entries one level lower, e.g. errors
+	// as warnings
+	SilenceDowngrade = "downgrade"
+)
+
+// SilenceWindow is a period during which matching entries are suppressed or
+// downgraded, such as the expected errors of a nightly maintenance.
+type SilenceWindow struct {
+	// Name identifies the window in its summary
+	Name string
+	// Start is when the window opens. Zero means it is open from the start.
+	Start time.Time
+	// End is when the window closes. Zero means it stays open until it is
+	// removed with SetSilenceWindows, which makes the window a toggle.
+	End time.Time
+	// Match reports whether an entry is silenced. The fields must not be
+	// modified. Nil matches every entry.
+	Match func(level int, msg string, fields map[string]any) bool
+	// Action is SilenceSuppress (or empty) or SilenceDowngrade.
+	Action string
+}
+
+// silences holds the windows set with SetSilenceWindows.
+var silences = &silencer{}
+
+// SetSilenceWindows sets the windows during which matching entries are
+// suppressed or downgraded. The first open window matching an entry applies.
+// It replaces the previous windows; call it without windows to remove them.
+//
+// Once a window has closed, or has been removed, the number of entries it
+// silenced is summarized in an Info entry with the message
+// "golog silenced entries", written to the current writer with the next
+// entry logged, or by Flush. Its fields are "window", "suppressed",
+// "downgraded", "start", and "end".
+//
+// Example:
+//
+//	golog.SetSilenceWindows(golog.SilenceWindow{
+//	    Name:  "nightly-maintenance",
+//	    Start: start,
+//	    End:   start.Add(30 * time.Minute),
+//	    Match: func(level int, _ string, fields map[string]any) bool {
+//	        return level == golog.LevelError && fields["component"] == "db"
+//	    },
+//	    Action: golog.SilenceDowngrade,
+//	})
+func SetSilenceWindows(windows ...SilenceWindow) {
+	silences.set(time.Now(), windows)
+}
+
+// silencer tracks the silence windows and the entries they silenced.
+type silencer struct {
+	// active is set when there are windows or pending summaries, so that the
+	// write path only pays for an atomic load otherwise
+	active atomic.Bool
+
+	mu      sync.Mutex
+	windows []*silenceWindow
+	// summaries holds the fields of the summaries of the closed windows
+	summaries []map[string]any
+}
+
+// silenceWindow is a SilenceWindow with the counts of silenced entries.
+type silenceWindow struct {
+	SilenceWindow
+	// opened is when the window was set, the start of a window without Start
+	opened     time.Time
+	suppressed int
+	downgraded int
+}
+
+// set replaces the windows, summarizing the removed ones.
+func (s *silencer) set(now time.Time, windows []SilenceWindow) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.windows {
+		s.summarize(w, now)
+	}
+
+	s.windows = make([]*silenceWindow, 0, len(windows))
+	for _, w := range windows {
+		s.windows = append(s.windows, &silenceWindow{SilenceWindow: w, opened: now})
+	}
+
+	s.active.Store(len(s.windows) > 0 || len(s.summaries) > 0)
+}
+
+// reset removes the windows and discards the pending summaries.
+func (s *silencer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.windows = nil
+	s.summaries = nil
+	s.active.Store(false)
+}
+
+// apply returns the level at which to write an entry, and false when the
+// entry is suppressed.
+func (s *silencer) apply(now time.Time, level int, msg string, fields map[string]any) (int, bool) {
+	if !s.active.Load() {
+		return level, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.windows {
+		if !w.open(now) || (w.Match != nil && !w.Match(level, msg, fields)) {
+			continue
+		}
+
+		if w.Action == SilenceDowngrade {
+			w.downgraded++
+			return max(level-1, LevelTrace), true
+		}
+
+		w.suppressed++
+
+		return level, false
+	}
+
+	return level, true
+}
+
+// take returns the summaries of the windows closed by now.
+func (s *silencer) take(now time.Time) []map[string]any {
+	if !s.active.Load() {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	open := s.windows[:0]
+	for _, w := range s.windows {
+		if !w.End.IsZero() && !now.Before(w.End) {
+			s.summarize(w, w.End)
+			continue
+		}
+
+		open = append(open, w)
+	}
+	s.windows = open
+
+	summaries := s.summaries
+	s.summaries = nil
+	s.active.Store(len(s.windows) > 0)
+
+	return summaries
+}
+
+// summarize queues the summary of w, closed at end, if it silenced entries.
+func (s *silencer) summarize(w *silenceWindow, end time.Time) {
+	if w.suppressed == 0 && w.downgraded == 0 {
+		return
+	}
+
+	start := w.Start
+	if start.IsZero() {
+		start = w.opened
+	}
+
+	s.summaries = append(s.summaries, map[string]any{
+		"window":     w.Name,
+		"suppressed": w.suppressed,
+		"downgraded": w.downgraded,
+		"start":      start.Format(time.RFC3339),
+		"end":        end.Format(time.RFC3339),
+	})
+}
+
+// open reports whether the window is open at now.
+func (w *silenceWindow) open(now time.Time) bool {
+	return (w.Start.IsZero() || !now.Before(w.Start)) && (w.End.IsZero() || now.Before(w.End))
+}
+
+// reportSilenced writes the summaries of the closed silence windows to
+// writer.
+func reportSilenced(writer LogWriter) {
+	for _, fields := range silences.take(time.Now()) {
+		writer.Write(LevelInfo, SilencedEntriesMessage, fields)
+	}
+}
@@ -0,0 +1,162 @@
+package golog
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSilenceWindows(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	t.Cleanup(func() {
+		SetSilenceWindows()
+		silences.take(time.Now())
+	})
+
+	now := time.Now()
+	SetSilenceWindows(
+		SilenceWindow{
+			Name: "db-maintenance",
+			End:  now.Add(time.Hour),
+			Match: func(level int, _ string, fields map[string]any) bool {
+				return level == LevelError && fields["component"] == "db"
+			},
+			Action: SilenceDowngrade,
+		},
+		SilenceWindow{
+			Name:  "cache-flush",
+			Match: func(_ int, msg string, _ map[string]any) bool { return msg == "cache miss" },
+		},
+		SilenceWindow{
+			Name:  "later",
+			Start: now.Add(time.Hour),
+		},
+	)
+
+	With("component", "db").Error("connection lost")
+	With("component", "api").Error("connection lost")
+	Info("cache miss")
+	Info("cache hit")
+
+	if len(w.entries) != 3 {
+		t.Fatalf("len(w.entries) = %d, want 3", len(w.entries))
+	}
+	if w.entries[0].level != LevelWarn {
+		t.Errorf("downgraded: w.entries[0].level = %v, want %v", w.entries[0].level, LevelWarn)
+	}
+	if w.entries[1].level != LevelError {
+		t.Errorf("w.entries[1].level = %v, want %v", w.entries[1].level, LevelError)
+	}
+	if w.entries[2].msg != "cache hit" {
+		t.Errorf("w.entries[2].msg = %q, want %q", w.entries[2].msg, "cache hit")
+	}
+
+	SetSilenceWindows()
+	Flush()
+
+	if len(w.entries) != 5 {
+		t.Fatalf("len(w.entries) = %d, want 5", len(w.entries))
+	}
+	summaries := map[any]map[string]any{}
+	for _, entry := range w.entries[3:] {
+		if entry.msg != SilencedEntriesMessage {
+			t.Errorf("entry.msg = %q, want %q", entry.msg, SilencedEntriesMessage)
+		}
+		if entry.level != LevelInfo {
+			t.Errorf("entry.level = %v, want %v", entry.level, LevelInfo)
+		}
+		summaries[entry.fields["window"]] = entry.fields
+	}
+
+	if summaries["db-maintenance"]["downgraded"] != 1 {
+		t.Errorf(`summaries["db-maintenance"]["downgraded"] = %v, want 1`, summaries["db-maintenance"]["downgraded"])
+	}
+	if summaries["db-maintenance"]["suppressed"] != 0 {
+		t.Errorf(`summaries["db-maintenance"]["suppressed"] = %v, want 0`, summaries["db-maintenance"]["suppressed"])
+	}
+	if summaries["cache-flush"]["suppressed"] != 1 {
+		t.Errorf(`summaries["cache-flush"]["suppressed"] = %v, want 1`, summaries["cache-flush"]["suppressed"])
+	}
+	if _, ok := summaries["later"]; ok {
+		t.Errorf("windows that silenced nothing are not summarized: summaries has key %v, want none", "later")
+	}
+}
+
+func TestSilenceWindows_Closed(t *testing.T) {
+	w := &captureWriter{}
+	useWriter(t, w)
+	t.Cleanup(func() { SetSilenceWindows() })
+
+	start := time.Date(2024, 3, 30, 2, 0, 0, 0, time.UTC)
+	end := start.Add(30 * time.Minute)
+	silences.set(start, []SilenceWindow{{Name: "nightly", Start: start, End: end}})
+
+	level, ok := silences.apply(start.Add(time.Minute), LevelError, "backup failed", nil)
+	if ok {
+		t.Error("ok = true, want false")
+	}
+	if level != LevelError {
+		t.Errorf("level = %v, want %v", level, LevelError)
+	}
+
+	_, ok = silences.apply(end, LevelError, "backup failed", nil)
+	if !ok {
+		t.Error("the window is closed at its end")
+	}
+
+	if got := silences.take(end.Add(-time.Second)); len(got) != 0 {
+		t.Errorf("no summary while the window is open: silences.take(end.Add(-time.Second)) = %v, want empty", got)
+	}
+
+	summaries := silences.take(end)
+	if len(summaries) != 1 {
+		t.Fatalf("len(summaries) = %d, want 1", len(summaries))
+	}
+	want := map[string]any{
+		"window":     "nightly",
+		"suppressed": 1,
+		"downgraded": 0,
+		"start":      "2024-03-30T02:00:00Z",
+		"end":        "2024-03-30T02:30:00Z",
+	}
+	if !reflect.DeepEqual(summaries[0], want) {
+		t.Errorf("summaries[0] = %v, want %v", summaries[0], want)
+	}
+	if silences.active.Load() {
+		t.Error("silences.active.Load() = true, want false")
+	}
+}
+
+func TestConfigure_Silence(t *testing.T) {
+	useWriter(t, std.Writer())
+	originalMinLevel := std.Level()
+	t.Cleanup(func() {
+		std.SetLevel(originalMinLevel)
+		SetSilenceWindows()
+		silences.take(time.Now())
+	})
+
+	path := filepath.Join(t.TempDir(), "app.json")
+	_, err := Configure(Config{
+		Outputs: []OutputConfig{{Path: path, Format: FormatJSON}},
+		Silence: []SilenceConfig{{Name: "deploy", Level: "error", Message: "upstream"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	Error("upstream unavailable")
+	Error("disk full")
+	Flush()
+
+	lines := readLines(t, path)
+	if len(lines) != 1 {
+		t.Fatalf("len(lines) = %d, want 1", len(lines))
+	}
+	if !strings.Contains(lines[0], `"msg":"disk full"`) {
+		t.Errorf("lines[0] = %q, want it to contain %q", lines[0], `"msg":"disk full"`)
+	}
+}
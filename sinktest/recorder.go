@@ -0,0 +1,40 @@
+package sinktest
+
+import (
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/logtest"
+)
+
+// UseRecorder installs a logtest.Recorder as the writer of the default
+// Logger for the duration of t, for testing code that logs with the
+// package-level functions, such as middleware. The previous writer and level
+// are restored when t ends, so tests may change the level freely. Tests
+// using it must not run in parallel.
+//
+// Example:
+//
+//	func TestHandler(t *testing.T) {
+//	    recorder := sinktest.UseRecorder(t)
+//	    golog.SetLevel(golog.LevelDebug)
+//
+//	    handler.ServeHTTP(resp, req)
+//
+//	    entries := recorder.Entries()
+//	    ...
+//	}
+func UseRecorder(t testing.TB) *logtest.Recorder {
+	t.Helper()
+
+	writer, level := golog.Default().Writer(), golog.Default().Level()
+	t.Cleanup(func() {
+		golog.SetWriter(writer)
+		golog.SetLevel(level)
+	})
+
+	recorder := logtest.NewRecorder()
+	golog.SetWriter(recorder)
+
+	return recorder
+}
@@ -0,0 +1,245 @@
+// Package sinktest provides conformance tests for golog.LogWriter
+// implementations, so third-party sinks can check that they behave like the
+// built-in writers: entries are delivered in order with their level, message,
+// and fields, Flush delivers everything written before it, concurrent writes
+// are not lost, and large entries are not truncated.
+//
+// A sink's test provides a Factory that creates the writer and reads back the
+// entries it delivered, for example by querying the backend:
+//
+//	func TestConformance(t *testing.T) {
+//	    sinktest.Run(t, func(t *testing.T) (golog.LogWriter, func() []golog.Entry) {
+//	        buf := &bytes.Buffer{}
+//	        return mysink.NewWriter(buf), func() []golog.Entry {
+//	            entries, err := sinktest.DecodeJSONLines(buf.Bytes())
+//	            if err != nil {
+//	                t.Fatal(err)
+//	            }
+//	            return entries
+//	        }
+//	    })
+//	}
+package sinktest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jkaveri/golog"
+)
+
+const (
+	// concurrentWriters is the number of goroutines writing concurrently
+	concurrentWriters = 8
+	// entriesPerWriter is the number of entries each goroutine writes
+	entriesPerWriter = 50
+	// largeEntrySize is the size of the field written by the large entry test
+	largeEntrySize = 1 << 20
+)
+
+// Factory creates the writer under test and returns a function reading back
+// the entries it delivered so far, in delivery order. Delivered entries must
+// carry the level, message, and fields that were written; field values may
+// come back as decoded JSON (strings, float64, ...). Resources are released
+// with t.Cleanup.
+type Factory func(t *testing.T) (w golog.LogWriter, delivered func() []golog.Entry)
+
+// Run runs the conformance tests as subtests of t, creating a new writer for
+// each of them.
+func Run(t *testing.T, factory Factory) {
+	t.Run("Ordering", func(t *testing.T) { testOrdering(t, factory) })
+	t.Run("Fields", func(t *testing.T) { testFields(t, factory) })
+	t.Run("FlushDelivers", func(t *testing.T) { testFlush(t, factory) })
+	t.Run("EntryTime", func(t *testing.T) { testEntryTime(t, factory) })
+	t.Run("Concurrency", func(t *testing.T) { testConcurrency(t, factory) })
+	t.Run("LargeEntry", func(t *testing.T) { testLargeEntry(t, factory) })
+}
+
+// testOrdering checks that entries are delivered in write order with their
+// level and message.
+func testOrdering(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	levels := []int{golog.LevelTrace, golog.LevelDebug, golog.LevelInfo, golog.LevelWarn, golog.LevelError}
+	for i := 0; i < 30; i++ {
+		w.Write(levels[i%len(levels)], fmt.Sprintf("entry %d", i), nil)
+	}
+	w.Flush()
+
+	entries := requireLen(t, delivered(), 30)
+	for i, entry := range entries {
+		if msg := fmt.Sprintf("entry %d", i); entry.Message != msg {
+			t.Errorf("sinktest: entry %d has message %q, want %q", i, entry.Message, msg)
+		}
+		if level := levels[i%len(levels)]; entry.Level != level {
+			t.Errorf("sinktest: entry %d has level %d, want %d", i, entry.Level, level)
+		}
+	}
+}
+
+// testFields checks that custom fields are delivered.
+func testFields(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	fields := map[string]any{
+		"user":    "ada",
+		"attempt": 3,
+		"ok":      true,
+		"unicode": "héllo   世界",
+		"quote":   `"quoted" \ back`,
+	}
+	w.Write(golog.LevelInfo, "with fields", fields)
+	w.Flush()
+
+	entries := requireLen(t, delivered(), 1)
+	for key, value := range fields {
+		got := entries[0].Fields[key]
+		if fmt.Sprint(got) != fmt.Sprint(value) {
+			t.Errorf("sinktest: field %q delivered as %#v, want %#v", key, got, value)
+		}
+	}
+}
+
+// testFlush checks that Flush delivers every entry written before it, and
+// that the writer keeps working after Flush.
+func testFlush(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	w.Write(golog.LevelInfo, "before flush", nil)
+	w.Flush()
+	requireLen(t, delivered(), 1)
+
+	w.Write(golog.LevelInfo, "after flush", nil)
+	w.Flush()
+
+	entries := requireLen(t, delivered(), 2)
+	if entries[1].Message != "after flush" {
+		t.Errorf("sinktest: entry written after Flush delivered as %q", entries[1].Message)
+	}
+}
+
+// testEntryTime checks that writers implementing golog.EntryWriter keep the
+// event time of the entry.
+func testEntryTime(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	entryWriter, ok := w.(golog.EntryWriter)
+	if !ok {
+		t.Skip("writer does not implement golog.EntryWriter")
+	}
+
+	eventTime := time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC)
+	entryWriter.WriteEntry(golog.Entry{Time: eventTime, Level: golog.LevelInfo, Message: "replayed"})
+	w.Flush()
+
+	entries := requireLen(t, delivered(), 1)
+	if !entries[0].Time.Equal(eventTime) {
+		t.Errorf("sinktest: event time %s delivered as %s", eventTime, entries[0].Time)
+	}
+}
+
+// testConcurrency checks that no entry is lost or duplicated when several
+// goroutines write at once.
+func testConcurrency(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentWriters; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < entriesPerWriter; j++ {
+				w.Write(golog.LevelInfo, fmt.Sprintf("writer %d entry %d", i, j), map[string]any{"writer": i})
+			}
+		}()
+	}
+	wg.Wait()
+	w.Flush()
+
+	entries := requireLen(t, delivered(), concurrentWriters*entriesPerWriter)
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if seen[entry.Message] {
+			t.Errorf("sinktest: %q delivered twice", entry.Message)
+		}
+		seen[entry.Message] = true
+	}
+}
+
+// testLargeEntry checks that an entry with a 1 MiB field is delivered whole.
+func testLargeEntry(t *testing.T, factory Factory) {
+	w, delivered := factory(t)
+
+	payload := strings.Repeat("x", largeEntrySize)
+	w.Write(golog.LevelInfo, "large", map[string]any{"payload": payload})
+	w.Write(golog.LevelInfo, "after large", nil)
+	w.Flush()
+
+	entries := requireLen(t, delivered(), 2)
+	if got, _ := entries[0].Fields["payload"].(string); got != payload {
+		t.Errorf("sinktest: large field delivered with %d bytes, want %d", len(got), len(payload))
+	}
+	if entries[1].Message != "after large" {
+		t.Errorf("sinktest: entry after the large one delivered as %q", entries[1].Message)
+	}
+}
+
+// requireLen stops the test unless n entries were delivered.
+func requireLen(t *testing.T, entries []golog.Entry, n int) []golog.Entry {
+	t.Helper()
+
+	if len(entries) != n {
+		t.Fatalf("sinktest: %d entries delivered, want %d", len(entries), n)
+	}
+
+	return entries
+}
+
+// DecodeJSONLines decodes the output of golog.NewJSONWriter (one JSON object
+// per line, in any golog.RecordFraming) into entries, for sinks that write
+// that format. The standard fields become the entry's Time, Level, and
+// Message; the caller is dropped and the other keys are the entry's fields.
+func DecodeJSONLines(data []byte) ([]golog.Entry, error) {
+	var entries []golog.Entry
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		// records of JSON text sequences (RFC 7464) start with 0x1E
+		line := bytes.TrimPrefix(scanner.Bytes(), []byte{0x1e})
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var fields map[string]any
+		if err := json.Unmarshal(line, &fields); err != nil {
+			return nil, fmt.Errorf("sinktest: decode line %d: %w", len(entries)+1, err)
+		}
+
+		entry := golog.Entry{Level: -1}
+		if level, ok := fields[golog.FieldLevel].(string); ok {
+			entry.Level = golog.ParseLevel(level)
+		}
+		entry.Message, _ = fields[golog.FieldMessage].(string)
+		if value, ok := fields[golog.FieldTime].(string); ok {
+			entry.Time, _ = time.Parse(time.RFC3339Nano, value)
+		}
+
+		delete(fields, golog.FieldLevel)
+		delete(fields, golog.FieldMessage)
+		delete(fields, golog.FieldTime)
+		delete(fields, golog.FieldCaller)
+		entry.Fields = fields
+
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
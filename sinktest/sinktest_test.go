@@ -0,0 +1,106 @@
+package sinktest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/jkaveri/golog/logtest"
+)
+
+func TestRun_Recorder(t *testing.T) {
+	Run(t, func(t *testing.T) (golog.LogWriter, func() []golog.Entry) {
+		recorder := logtest.NewRecorder()
+		return recorder, recorder.Entries
+	})
+}
+
+func TestRun_FileWriter(t *testing.T) {
+	Run(t, func(t *testing.T) (golog.LogWriter, func() []golog.Entry) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		writer, err := golog.NewFileWriter(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		t.Cleanup(func() { writer.Close() })
+
+		return writer, func() []golog.Entry {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			entries, err := DecodeJSONLines(data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			return entries
+		}
+	})
+}
+
+func TestDecodeJSONLines(t *testing.T) {
+	data := []byte(`{"time":"2024-03-30T12:34:56Z","level":"ERROR","msg":"failed","caller":"main.go:1","n":1}` + "\n\n" +
+		`{"msg":"no level"}` + "\n")
+
+	entries, err := DecodeJSONLines(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("len(entries) = %d, want 2", len(entries))
+	}
+
+	if entries[0].Level != golog.LevelError {
+		t.Errorf("entries[0].Level = %v, want %v", entries[0].Level, golog.LevelError)
+	}
+	if entries[0].Message != "failed" {
+		t.Errorf("entries[0].Message = %q, want %q", entries[0].Message, "failed")
+	}
+	if got := entries[0].Time.Format("2006-01-02T15:04:05Z07:00"); got != "2024-03-30T12:34:56Z" {
+		t.Errorf("got %q, want %q", got, "2024-03-30T12:34:56Z")
+	}
+	want := map[string]any{"n": float64(1)}
+	if !reflect.DeepEqual(entries[0].Fields, want) {
+		t.Errorf("entries[0].Fields = %v, want %v", entries[0].Fields, want)
+	}
+	if entries[1].Level != -1 {
+		t.Errorf("entries[1].Level = %v, want -1", entries[1].Level)
+	}
+
+	_, err = DecodeJSONLines([]byte("not json\n"))
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestUseRecorder(t *testing.T) {
+	previous := UseRecorder(t)
+
+	t.Run("recording", func(t *testing.T) {
+		recorder := UseRecorder(t)
+		golog.SetLevel(golog.LevelDebug)
+
+		golog.Debug("cache warmed")
+
+		if got := recorder.Entries(); len(got) != 1 {
+			t.Fatalf("len(recorder.Entries()) = %d, want 1", len(got))
+		}
+		if got := recorder.Entries()[0].Message; got != "cache warmed" {
+			t.Errorf("recorder.Entries()[0].Message = %q, want %q", got, "cache warmed")
+		}
+	})
+
+	if got := golog.Default().Writer(); got != previous {
+		t.Errorf("the previous writer is restored: golog.Default().Writer() = %p, want %p", got, previous)
+	}
+	if got := golog.Default().Level(); got != golog.LevelInfo {
+		t.Errorf("the previous level is restored: golog.Default().Level() = %v, want %v", got, golog.LevelInfo)
+	}
+	if got := previous.Entries(); len(got) != 0 {
+		t.Errorf("previous.Entries() = %v, want empty", got)
+	}
+}
@@ -0,0 +1,217 @@
+package golog
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Keys of the fields of the SLO tracking (see NewSLOEnricher)
+const (
+	// FieldSLOBreach marks the error entries logged while an error budget is
+	// exceeded
+	FieldSLOBreach = "slo_breach"
+	// FieldSLO holds the name of the threshold of an alert entry
+	FieldSLO = "slo"
+)
+
+// Messages of the alert entries of an SLOEnricher (see SLOAlerts)
+const (
+	// SLOExceededMessage is the message of the entry logged when an error
+	// budget is exceeded
+	SLOExceededMessage = "error budget exceeded"
+	// SLORecoveredMessage is the message of the entry logged when an error
+	// budget is no longer exceeded
+	SLORecoveredMessage = "error budget recovered"
+)
+
+// SLOThreshold is an error budget: the number of error entries allowed in a
+// sliding window.
+type SLOThreshold struct {
+	// Name identifies the threshold in the alert entries; empty uses the
+	// window, e.g. "5m0s"
+	Name string
+	// Window is the length of the sliding window
+	Window time.Duration
+	// MaxErrors is the number of entries at LevelError and above allowed in
+	// the window; one more exceeds the budget
+	MaxErrors int
+}
+
+// SLOOption configures an SLOEnricher.
+type SLOOption func(*SLOEnricher)
+
+// SLOAlerts makes the SLOEnricher log synthetic alert entries to lg: a
+// warning entry with the message SLOExceededMessage when a threshold is
+// exceeded, and an info entry with the message SLORecoveredMessage on the
+// first entry logged once it is no longer exceeded. Both carry the name of
+// the threshold in the slo field, and the window and the budget.
+func SLOAlerts(lg *Logger) SLOOption {
+	return func(e *SLOEnricher) {
+		e.alerts = lg
+	}
+}
+
+// SLOEnricher tracks the rate of the error entries of the Logger it is
+// registered with against error budgets, for teams without a metrics stack.
+// While a budget is exceeded, it adds slo_breach=true to the entries at
+// LevelError and above, so that alerting rules and dashboards built on the
+// logs can key on the field. See SLOAlerts to also log an entry when a
+// budget is exceeded and when it recovers.
+//
+// Register an SLOEnricher with a single Logger, since it counts every error
+// entry it sees.
+type SLOEnricher struct {
+	now    func() time.Time
+	alerts *Logger
+
+	mu      sync.Mutex
+	windows []*sloWindow
+}
+
+// sloWindow tracks the error entries of a threshold.
+type sloWindow struct {
+	threshold SLOThreshold
+	// times holds the times of the last MaxErrors+1 error entries, as a ring
+	times []time.Time
+	// next is the index of the oldest time in times, once it is full
+	next int
+	// breached reports whether the budget was exceeded at the last entry
+	breached bool
+}
+
+// sloAlert is an alert entry to log.
+type sloAlert struct {
+	threshold SLOThreshold
+	exceeded  bool
+}
+
+// NewSLOEnricher returns an SLOEnricher tracking the error entries against
+// thresholds, e.g. a fast burn over five minutes and a slow burn over an
+// hour. Thresholds without a window are ignored.
+//
+// Example:
+//
+//	golog.RegisterEnricher(golog.NewSLOEnricher([]golog.SLOThreshold{
+//	    {Name: "fast-burn", Window: 5 * time.Minute, MaxErrors: 50},
+//	    {Name: "slow-burn", Window: time.Hour, MaxErrors: 200},
+//	}, golog.SLOAlerts(golog.Default())))
+func NewSLOEnricher(thresholds []SLOThreshold, opts ...SLOOption) *SLOEnricher {
+	e := &SLOEnricher{now: time.Now}
+	for _, threshold := range thresholds {
+		if threshold.Window <= 0 {
+			continue
+		}
+
+		threshold.MaxErrors = max(threshold.MaxErrors, 0)
+		if threshold.Name == "" {
+			threshold.Name = threshold.Window.String()
+		}
+
+		e.windows = append(e.windows, &sloWindow{
+			threshold: threshold,
+			times:     make([]time.Time, 0, threshold.MaxErrors+1),
+		})
+	}
+
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+
+	return e
+}
+
+// Enrich implements Enricher.
+func (e *SLOEnricher) Enrich(_ context.Context, level string, _ string, fields map[string]any) {
+	isError := ParseLevel(level) >= LevelError
+
+	e.mu.Lock()
+
+	now := e.now()
+	breached := false
+
+	var alerts []sloAlert
+	for _, w := range e.windows {
+		if isError {
+			w.record(now)
+		}
+
+		exceeded := w.exceeded(now)
+		if exceeded != w.breached {
+			w.breached = exceeded
+			alerts = append(alerts, sloAlert{threshold: w.threshold, exceeded: exceeded})
+		}
+
+		breached = breached || exceeded
+	}
+
+	e.mu.Unlock()
+
+	if breached && isError {
+		fields[FieldSLOBreach] = true
+	}
+
+	// the alert entries go through the enrichers again, so log them without
+	// holding the lock
+	for _, alert := range alerts {
+		e.alert(alert)
+	}
+}
+
+// Breached reports whether an error budget is exceeded, e.g. for a health
+// check, as of the last entry.
+func (e *SLOEnricher) Breached() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, w := range e.windows {
+		if w.breached {
+			return true
+		}
+	}
+
+	return false
+}
+
+// alert logs the alert entry of a threshold, when alerts are enabled.
+func (e *SLOEnricher) alert(alert sloAlert) {
+	if e.alerts == nil {
+		return
+	}
+
+	scope := e.alerts.WithFields(map[string]any{
+		FieldSLO:     alert.threshold.Name,
+		"window":     alert.threshold.Window.String(),
+		"max_errors": alert.threshold.MaxErrors,
+	})
+
+	if alert.exceeded {
+		scope.Warn(SLOExceededMessage)
+		return
+	}
+
+	scope.Info(SLORecoveredMessage)
+}
+
+// record adds an error entry logged at now.
+func (w *sloWindow) record(now time.Time) {
+	if len(w.times) < cap(w.times) {
+		w.times = append(w.times, now)
+		return
+	}
+
+	w.times[w.next] = now
+	w.next = (w.next + 1) % len(w.times)
+}
+
+// exceeded reports whether more than MaxErrors error entries were logged
+// within the window before now.
+func (w *sloWindow) exceeded(now time.Time) bool {
+	if len(w.times) < cap(w.times) {
+		return false
+	}
+
+	return now.Sub(w.times[w.next]) < w.threshold.Window
+}
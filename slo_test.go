@@ -0,0 +1,142 @@
+package golog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSLOEnricher(t *testing.T) {
+	type logged struct {
+		after  time.Duration
+		level  string
+		breach bool
+	}
+
+	tests := []struct {
+		name    string
+		entries []logged
+	}{
+		{
+			name: "within-budget",
+			entries: []logged{
+				{level: "ERROR"},
+				{after: time.Second, level: "ERROR"},
+				{after: time.Second, level: "INFO"},
+			},
+		},
+		{
+			name: "exceeded",
+			entries: []logged{
+				{level: "ERROR"},
+				{after: time.Second, level: "ERROR"},
+				{after: time.Second, level: "FATAL", breach: true},
+				{after: time.Second, level: "INFO"},
+				{after: time.Second, level: "ERROR", breach: true},
+			},
+		},
+		{
+			name: "slides-out",
+			entries: []logged{
+				{level: "ERROR"},
+				{after: time.Second, level: "ERROR"},
+				{after: 59 * time.Second, level: "ERROR"},
+				{after: 500 * time.Millisecond, level: "ERROR", breach: true},
+			},
+		},
+		{
+			name: "warnings-not-counted",
+			entries: []logged{
+				{level: "WARN"},
+				{level: "WARN"},
+				{level: "WARN"},
+				{level: "ERROR"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewSLOEnricher([]SLOThreshold{{Window: time.Minute, MaxErrors: 2}})
+			now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+			e.now = func() time.Time { return now }
+
+			for i, entry := range tt.entries {
+				now = now.Add(entry.after)
+
+				fields := map[string]any{}
+				e.Enrich(context.Background(), entry.level, "payment failed", fields)
+
+				if entry.breach {
+					if fields[FieldSLOBreach] != true {
+						t.Errorf("entry %d: fields[FieldSLOBreach] = %v, want true", i, fields[FieldSLOBreach])
+					}
+				} else {
+					if _, ok := fields[FieldSLOBreach]; ok {
+						t.Errorf("entry %d: fields has key %q, want none", i, FieldSLOBreach)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSLOEnricher_Alerts(t *testing.T) {
+	w := &captureWriter{}
+	lg := New(LoggerWriter(w))
+
+	e := NewSLOEnricher([]SLOThreshold{
+		{Name: "fast-burn", Window: time.Minute, MaxErrors: 1},
+		{Window: time.Hour, MaxErrors: 10},
+		{Name: "no-window", MaxErrors: 1},
+	}, SLOAlerts(lg))
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	e.now = func() time.Time { return now }
+	lg.RegisterEnricher(e)
+
+	_ = lg.Error("payment failed")
+	_ = lg.Error("payment failed")
+	if !e.Breached() {
+		t.Error("e.Breached() = false, want true")
+	}
+
+	now = now.Add(2 * time.Minute)
+	lg.Info("payment succeeded")
+	if e.Breached() {
+		t.Error("e.Breached() = true, want false")
+	}
+
+	var messages []string
+	for _, entry := range w.entries {
+		messages = append(messages, entry.msg)
+	}
+	want := []string{
+		"payment failed",
+		SLOExceededMessage,
+		"payment failed",
+		SLORecoveredMessage,
+		"payment succeeded",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("alerts are logged while the entry that triggers them is enriched: messages = %v, want %v", messages, want)
+	}
+
+	exceeded := w.entries[1]
+	if exceeded.level != LevelWarn {
+		t.Errorf("exceeded.level = %v, want %v", exceeded.level, LevelWarn)
+	}
+	if want := map[string]any{FieldSLO: "fast-burn", "window": "1m0s", "max_errors": 1}; !reflect.DeepEqual(exceeded.fields, want) {
+		t.Errorf("exceeded.fields = %v, want %v", exceeded.fields, want)
+	}
+	if w.entries[2].fields[FieldSLOBreach] != true {
+		t.Errorf("w.entries[2].fields[FieldSLOBreach] = %v, want true", w.entries[2].fields[FieldSLOBreach])
+	}
+
+	if len(e.windows) != 2 {
+		t.Fatalf("thresholds without a window are ignored: len(e.windows) = %d, want 2", len(e.windows))
+	}
+	if e.windows[1].threshold.Name != "1h0m0s" {
+		t.Errorf("e.windows[1].threshold.Name = %q, want %q", e.windows[1].threshold.Name, "1h0m0s")
+	}
+}
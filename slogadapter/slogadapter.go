@@ -0,0 +1,160 @@
+// Package slogadapter routes log/slog records into golog.
+//
+// Applications and libraries using the standard library's slog write through
+// a golog Logger, its writer, enrichers, and level, once the handler is set as
+// the default slog handler:
+//
+//	slog.SetDefault(slog.New(slogadapter.New(nil)))
+//
+// Attributes become fields; the attributes of groups become fields whose keys
+// are prefixed with the group names, separated by dots, e.g. "http.method".
+package slogadapter
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jkaveri/golog"
+)
+
+// Handler is a slog.Handler writing records through a golog Logger.
+type Handler struct {
+	logger *golog.Logger
+	// attrs holds the fields added with WithAttrs, keyed with their groups
+	attrs map[string]any
+	// prefix is the key prefix of the open groups, e.g. "http."
+	prefix string
+}
+
+// New creates a Handler writing through lg, or through the default Logger
+// when lg is nil. To write to a golog.LogWriter alone, pass
+// golog.New(golog.LoggerWriter(w)).
+func New(lg *golog.Logger) *Handler {
+	if lg == nil {
+		lg = golog.Default()
+	}
+
+	return &Handler{logger: lg, attrs: map[string]any{}}
+}
+
+// Enabled implements slog.Handler. It reports whether the Logger writes
+// entries at the golog level of level (see golog.Logger.Enabled).
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Enabled(Level(level))
+}
+
+// Handle implements slog.Handler. The record is written with its time, its
+// context, which the enrichers of the Logger receive, and its attributes as
+// fields.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	fields := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for k, v := range h.attrs {
+		fields[k] = v
+	}
+
+	r.Attrs(func(attr slog.Attr) bool {
+		addAttr(fields, h.prefix, attr)
+		return true
+	})
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	scope := h.logger.WithContext(ctx).WithFields(fields)
+	if !r.Time.IsZero() {
+		scope.WithTime(r.Time)
+	}
+
+	switch Level(r.Level) {
+	case golog.LevelTrace:
+		scope.Trace(r.Message)
+	case golog.LevelDebug:
+		scope.Debug(r.Message)
+	case golog.LevelInfo:
+		scope.Info(r.Message)
+	case golog.LevelWarn:
+		scope.Warn(r.Message)
+	default:
+		_ = scope.Error(r.Message)
+	}
+
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	next := h.clone()
+	for _, attr := range attrs {
+		addAttr(next.attrs, next.prefix, attr)
+	}
+
+	return next
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	next := h.clone()
+	next.prefix += name + "."
+
+	return next
+}
+
+// clone returns a copy of the handler that can be modified.
+func (h *Handler) clone() *Handler {
+	attrs := make(map[string]any, len(h.attrs))
+	for k, v := range h.attrs {
+		attrs[k] = v
+	}
+
+	return &Handler{logger: h.logger, attrs: attrs, prefix: h.prefix}
+}
+
+// Level returns the golog level of a slog level: levels below slog.LevelDebug
+// map to golog.LevelTrace, and levels from slog.LevelError up to
+// golog.LevelError.
+func Level(level slog.Level) int {
+	switch {
+	case level < slog.LevelDebug:
+		return golog.LevelTrace
+	case level < slog.LevelInfo:
+		return golog.LevelDebug
+	case level < slog.LevelWarn:
+		return golog.LevelInfo
+	case level < slog.LevelError:
+		return golog.LevelWarn
+	default:
+		return golog.LevelError
+	}
+}
+
+// addAttr adds attr to fields under prefix, flattening groups. Empty
+// attributes are ignored, and the attributes of groups without a key are
+// added under prefix, as slog handlers do.
+func addAttr(fields map[string]any, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() != slog.KindGroup {
+		fields[prefix+attr.Key] = attr.Value.Any()
+		return
+	}
+
+	if attr.Key != "" {
+		prefix += attr.Key + "."
+	}
+
+	for _, a := range attr.Value.Group() {
+		addAttr(fields, prefix, a)
+	}
+}
@@ -0,0 +1,162 @@
+package slogadapter
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jkaveri/golog"
+)
+
+// captureWriter records the entries written to it.
+type captureWriter struct {
+	mu      sync.Mutex
+	entries []capturedEntry
+}
+
+type capturedEntry struct {
+	level  int
+	msg    string
+	fields map[string]any
+}
+
+func (w *captureWriter) Write(level int, msg string, fields map[string]any) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	copied := make(map[string]any, len(fields))
+	for k, v := range fields {
+		copied[k] = v
+	}
+
+	w.entries = append(w.entries, capturedEntry{level: level, msg: msg, fields: copied})
+}
+
+func (w *captureWriter) Flush() {}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		log    func(l *slog.Logger)
+		level  int
+		msg    string
+		fields map[string]any
+	}{
+		{
+			name:   "attrs",
+			log:    func(l *slog.Logger) { l.Info("order placed", "order_id", 42, "total", 9.5) },
+			level:  golog.LevelInfo,
+			msg:    "order placed",
+			fields: map[string]any{"order_id": int64(42), "total": 9.5},
+		},
+		{
+			name:   "message-is-not-a-format",
+			log:    func(l *slog.Logger) { l.Warn("disk 95% full") },
+			level:  golog.LevelWarn,
+			msg:    "disk 95% full",
+			fields: map[string]any{},
+		},
+		{
+			name: "groups",
+			log: func(l *slog.Logger) {
+				l.With("service", "api").WithGroup("http").Error("request failed",
+					"method", "GET", slog.Group("response", "status", 502))
+			},
+			level: golog.LevelError,
+			msg:   "request failed",
+			fields: map[string]any{
+				"service":              "api",
+				"http.method":          "GET",
+				"http.response.status": int64(502),
+			},
+		},
+		{
+			name: "empty-attrs-and-inline-groups",
+			log: func(l *slog.Logger) {
+				l.Debug("cache miss", slog.Attr{}, slog.Group("", "key", "user:1"), slog.Group("empty"))
+			},
+			level:  golog.LevelDebug,
+			msg:    "cache miss",
+			fields: map[string]any{"key": "user:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &captureWriter{}
+			lg := golog.New(golog.LoggerWriter(w), golog.LoggerLevel(golog.LevelDebug))
+
+			tt.log(slog.New(New(lg)))
+
+			if len(w.entries) != 1 {
+				t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+			}
+			if w.entries[0].level != tt.level {
+				t.Errorf("w.entries[0].level = %v, want %v", w.entries[0].level, tt.level)
+			}
+			if w.entries[0].msg != tt.msg {
+				t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, tt.msg)
+			}
+			if !reflect.DeepEqual(w.entries[0].fields, tt.fields) {
+				t.Errorf("w.entries[0].fields = %v, want %v", w.entries[0].fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestHandler_LevelAndEnrichers(t *testing.T) {
+	type requestIDKey struct{}
+
+	w := &captureWriter{}
+	lg := golog.New(
+		golog.LoggerWriter(w),
+		golog.LoggerEnrichers(golog.EnricherFunc(func(ctx context.Context, _ string, _ string, fields map[string]any) {
+			if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+				fields["request_id"] = id
+			}
+		})),
+	)
+	logger := slog.New(New(lg))
+
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	logger.DebugContext(ctx, "below the level")
+	logger.InfoContext(ctx, "handled")
+
+	if len(w.entries) != 1 {
+		t.Fatalf("len(w.entries) = %d, want 1", len(w.entries))
+	}
+	if w.entries[0].msg != "handled" {
+		t.Errorf("w.entries[0].msg = %q, want %q", w.entries[0].msg, "handled")
+	}
+	if w.entries[0].fields["request_id"] != "abc" {
+		t.Errorf(`w.entries[0].fields["request_id"] = %q, want %q`, w.entries[0].fields["request_id"], "abc")
+	}
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		t.Error("logger.Enabled(ctx, slog.LevelDebug) = true, want false")
+	}
+}
+
+func TestLevel(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{slog.LevelDebug - 4, golog.LevelTrace},
+		{slog.LevelDebug, golog.LevelDebug},
+		{slog.LevelInfo, golog.LevelInfo},
+		{slog.LevelInfo + 2, golog.LevelInfo},
+		{slog.LevelWarn, golog.LevelWarn},
+		{slog.LevelError, golog.LevelError},
+		{slog.LevelError + 4, golog.LevelError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := Level(tt.level); got != tt.want {
+				t.Errorf("Level(tt.level) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package golog
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+)
+
+// slogWriter writes entries as slog records to a slog.Handler.
+type slogWriter struct {
+	handler slog.Handler
+}
+
+// NewSlogWriter creates a LogWriter that writes entries as records to h, so
+// that golog's API can be used in front of any slog backend. Fields become
+// attributes, sorted by key; levels map to the slog level of the same name,
+// with LevelTrace at slog.LevelDebug-4, LevelPanic at slog.LevelError+4, and
+// LevelFatal at slog.LevelError+8. Entries that h fails to handle are counted
+// as dropped (see RecordDropped). Flush calls the Flush method of h, if it
+// has one.
+//
+// Example:
+//
+//	golog.SetWriter(golog.NewSlogWriter(slog.NewJSONHandler(os.Stdout, nil)))
+func NewSlogWriter(h slog.Handler) LogWriter {
+	return &slogWriter{handler: h}
+}
+
+// Write implements LogWriter.
+func (w *slogWriter) Write(level int, msg string, fields map[string]any) {
+	w.WriteEntry(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields})
+}
+
+// WriteEntry implements EntryWriter.
+func (w *slogWriter) WriteEntry(entry Entry) {
+	ctx := context.Background()
+	level := slogLevel(entry.Level)
+	if !w.handler.Enabled(ctx, level) {
+		return
+	}
+
+	record := slog.NewRecord(entryTime(entry), level, entry.Message, 0)
+	for _, key := range slices.Sorted(maps.Keys(entry.Fields)) {
+		record.AddAttrs(slog.Any(key, entry.Fields[key]))
+	}
+
+	if err := w.handler.Handle(ctx, record); err != nil {
+		RecordDropped(DropHandlerFailed, 1)
+	}
+}
+
+// Flush implements LogWriter.
+func (w *slogWriter) Flush() {
+	if f, ok := w.handler.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+}
+
+// slogLevel returns the slog level of a golog level.
+func slogLevel(level int) slog.Level {
+	switch level {
+	case LevelTrace:
+		return slog.LevelDebug - 4
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelInfo:
+		return slog.LevelInfo
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelPanic:
+		return slog.LevelError + 4
+	case LevelFatal:
+		return slog.LevelError + 8
+	default:
+		return slog.LevelError
+	}
+}
@@ -0,0 +1,107 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingHandler is a slog.Handler that fails to handle every record.
+type failingHandler struct {
+	slog.Handler
+}
+
+func (failingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (failingHandler) Handle(context.Context, slog.Record) error {
+	return errors.New("backend unavailable")
+}
+
+func TestSlogWriter(t *testing.T) {
+	eventTime := time.Date(2024, 3, 30, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		level int
+		want  string
+	}{
+		{name: "trace", level: LevelTrace, want: "DEBUG-4"},
+		{name: "debug", level: LevelDebug, want: "DEBUG"},
+		{name: "info", level: LevelInfo, want: "INFO"},
+		{name: "warn", level: LevelWarn, want: "WARN"},
+		{name: "error", level: LevelError, want: "ERROR"},
+		{name: "fatal", level: LevelFatal, want: "ERROR+8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := NewSlogWriter(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug - 4}))
+
+			w.(EntryWriter).WriteEntry(Entry{
+				Time:    eventTime,
+				Level:   tt.level,
+				Message: "order placed",
+				Fields:  map[string]any{"order_id": 42, "user": "alice"},
+			})
+
+			var record map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &record): %v", err)
+			}
+			if record["level"] != tt.want {
+				t.Errorf(`record["level"] = %q, want %q`, record["level"], tt.want)
+			}
+			if record["msg"] != "order placed" {
+				t.Errorf(`record["msg"] = %q, want %q`, record["msg"], "order placed")
+			}
+			if record["time"] != "2024-03-30T12:34:56Z" {
+				t.Errorf(`record["time"] = %q, want %q`, record["time"], "2024-03-30T12:34:56Z")
+			}
+			if record["order_id"] != float64(42) {
+				t.Errorf(`record["order_id"] = %v, want %v`, record["order_id"], float64(42))
+			}
+			if record["user"] != "alice" {
+				t.Errorf(`record["user"] = %q, want %q`, record["user"], "alice")
+			}
+		})
+	}
+}
+
+func TestSlogWriter_Filtering(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w := NewSlogWriter(slog.NewTextHandler(buf, nil))
+
+	w.Write(LevelDebug, "below the handler level", nil)
+	w.Write(LevelInfo, "kept", map[string]any{"b": 2, "a": 1})
+	w.Flush()
+
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("strings.Count(buf.String(), \"\\n\") = %v, want 1", got)
+	}
+	if got := buf.String(); !strings.Contains(got, `msg=kept a=1 b=2`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `msg=kept a=1 b=2`)
+	}
+}
+
+func TestSlogWriter_HandlerFailure(t *testing.T) {
+	resetDrops(t)
+
+	w := NewSlogWriter(failingHandler{})
+	w.Write(LevelInfo, "lost", nil)
+
+	fields, ok := drops.take(true)
+	if !ok {
+		t.Fatal("ok = false, want true")
+	}
+	want := map[string]int{DropHandlerFailed: 1}
+	if !reflect.DeepEqual(fields["dropped_by_reason"], want) {
+		t.Errorf(`fields["dropped_by_reason"] = %v, want %v`, fields["dropped_by_reason"], want)
+	}
+}
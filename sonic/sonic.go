@@ -0,0 +1,30 @@
+// Package sonic plugs the Sonic JSON encoder (github.com/bytedance/sonic)
+// into golog's built-in writers.
+//
+// Example:
+//
+//	import gologsonic "github.com/jkaveri/golog/sonic"
+//
+//	golog.SetWriter(golog.NewJSONWriter(os.Stdout, gologsonic.Marshal()))
+package sonic
+
+import (
+	"github.com/bytedance/sonic"
+	"github.com/jkaveri/golog"
+)
+
+// sorted is the Sonic configuration used by MarshalSorted.
+var sorted = sonic.Config{SortMapKeys: true}.Froze()
+
+// Marshal returns a golog.WriterOption that encodes field values with Sonic's
+// default configuration, which favours speed: map keys are not sorted and
+// HTML characters are not escaped.
+func Marshal() golog.WriterOption {
+	return golog.MarshalFunc(sonic.Marshal)
+}
+
+// MarshalSorted is like Marshal, but also sorts the keys of nested maps. Use
+// it together with golog.SortKeys for fully deterministic output.
+func MarshalSorted() golog.WriterOption {
+	return golog.MarshalFunc(sorted.Marshal)
+}
@@ -0,0 +1,41 @@
+package sonic
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jkaveri/golog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []golog.WriterOption
+		expected string
+	}{
+		{
+			name:     "marshal",
+			opts:     []golog.WriterOption{Marshal()},
+			expected: `"name":"gopher"`,
+		},
+		{
+			name:     "marshal-sorted",
+			opts:     []golog.WriterOption{golog.SortKeys(), MarshalSorted()},
+			expected: `"user":{"id":1,"name":"gopher"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer := golog.NewJSONWriter(&buf, tt.opts...)
+			user := map[string]any{"name": "gopher", "id": 1}
+
+			writer.Write(golog.LevelInfo, "hello", map[string]any{"user": user})
+			writer.Flush()
+
+			assert.Contains(t, buf.String(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package golog
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// maxTolerantDepth bounds how deep tolerantValue descends into nested values,
+// which also stops cycles through pointers, maps, and slices.
+const maxTolerantDepth = 32
+
+// TolerantEncoding makes the writers render field values that cannot be
+// encoded as JSON instead of failing: channels, functions, and unsafe
+// pointers become a placeholder naming their type (e.g. "<chan int>"),
+// complex numbers and non-finite floats become strings such as "(1+2i)" and
+// "NaN", and values that still fail, such as a json.Marshaler returning an
+// error, become "<TYPE: error>". The rest of the value is kept, so a struct
+// with one unsupported field still shows its other fields.
+//
+// With this option, the JSON writer never replaces an entry's fields with an
+// "error" field and the default writer never panics on a field value.
+// Unsupported values are only walked when regular encoding fails, so entries
+// without them are encoded as fast as before.
+//
+// Example:
+//
+//	writer := golog.NewJSONWriter(os.Stdout, golog.TolerantEncoding())
+func TolerantEncoding() WriterOption {
+	return func(o *writerOptions) {
+		o.tolerant = true
+	}
+}
+
+// tolerantValue returns a copy of v in which every value that encoding/json
+// cannot encode is replaced by a string describing it.
+func tolerantValue(v any, depth int) any {
+	if v == nil {
+		return nil
+	}
+
+	return tolerantReflect(reflect.ValueOf(v), depth)
+}
+
+// tolerantReflect implements tolerantValue for a reflect.Value.
+func tolerantReflect(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+
+	if depth > maxTolerantDepth {
+		return fmt.Sprintf("<%s: too deep>", v.Type())
+	}
+
+	if v.CanInterface() {
+		switch m := v.Interface().(type) {
+		case json.Marshaler:
+			if isNilPointer(v) {
+				return nil
+			}
+
+			if _, err := m.MarshalJSON(); err != nil {
+				return fmt.Sprintf("<%s: %v>", v.Type(), err)
+			}
+
+			return m
+		case encoding.TextMarshaler:
+			if isNilPointer(v) {
+				return nil
+			}
+
+			if _, err := m.MarshalText(); err != nil {
+				return fmt.Sprintf("<%s: %v>", v.Type(), err)
+			}
+
+			return m
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		return fmt.Sprintf("<%s>", v.Type())
+	case reflect.Complex64, reflect.Complex128:
+		return fmt.Sprint(v.Complex())
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprint(f)
+		}
+
+		return f
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+
+		return tolerantReflect(v.Elem(), depth+1)
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = tolerantReflect(iter.Value(), depth+1)
+		}
+
+		return m
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Bytes()
+		}
+
+		fallthrough
+	case reflect.Array:
+		s := make([]any, v.Len())
+		for i := range s {
+			s[i] = tolerantReflect(v.Index(i), depth+1)
+		}
+
+		return s
+	case reflect.Struct:
+		m := map[string]any{}
+		tolerantStruct(v, m, depth)
+
+		return m
+	default:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+
+		return fmt.Sprintf("<%s>", v.Type())
+	}
+}
+
+// tolerantStruct adds the exported fields of the struct v to m, named and
+// skipped according to their json tags, with embedded structs flattened.
+func tolerantStruct(v reflect.Value, m map[string]any, depth int) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		fv := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+
+				fv = fv.Elem()
+			}
+
+			if fv.Kind() == reflect.Struct {
+				tolerantStruct(fv, m, depth+1)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		m[name] = tolerantReflect(fv, depth+1)
+	}
+}
+
+// isNilPointer reports whether v is a nil pointer.
+func isNilPointer(v reflect.Value) bool {
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,165 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// failingMarshaler is a json.Marshaler that always fails.
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) { return nil, errors.New("boom") }
+
+// node is a linked list node used to build cycles.
+type node struct {
+	Name string `json:"name"`
+	Next *node  `json:"next"`
+}
+
+func TestTolerantEncoding_JSONWriter(t *testing.T) {
+	cycle := &node{Name: "a"}
+	cycle.Next = cycle
+
+	type job struct {
+		ID       int `json:"id"`
+		Callback func()
+		Done     chan struct{} `json:"done"`
+		Skipped  string        `json:"-"`
+		internal int
+	}
+
+	tests := []struct {
+		name     string
+		value    any
+		expected any
+	}{
+		{
+			name:     "chan",
+			value:    make(chan int),
+			expected: "<chan int>",
+		},
+		{
+			name:     "func",
+			value:    func(int) error { return nil },
+			expected: "<func(int) error>",
+		},
+		{
+			name:     "complex",
+			value:    complex(1, 2),
+			expected: "(1+2i)",
+		},
+		{
+			name:     "nan",
+			value:    math.NaN(),
+			expected: "NaN",
+		},
+		{
+			name:     "struct-with-unsupported-fields",
+			value:    job{ID: 7, Done: make(chan struct{}), Skipped: "x", internal: 1},
+			expected: map[string]any{"id": float64(7), "Callback": nil, "done": "<chan struct {}>"},
+		},
+		{
+			name:     "nested",
+			value:    map[string]any{"ok": 1, "values": []any{1.5, math.Inf(-1)}},
+			expected: map[string]any{"ok": float64(1), "values": []any{1.5, "-Inf"}},
+		},
+		{
+			name:     "failing-marshaler",
+			value:    failingMarshaler{},
+			expected: "<golog.failingMarshaler: boom>",
+		},
+		{
+			name:  "cycle",
+			value: cycle,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs []error
+			buf := &bytes.Buffer{}
+			writer := NewJSONWriter(buf, TolerantEncoding(), OnError(func(err error) { errs = append(errs, err) }))
+
+			writer.Write(LevelInfo, "tolerant", map[string]any{"value": tt.value, "kept": "yes"})
+			if err := writer.flushBuffer(); err != nil {
+				t.Fatalf("writer.flushBuffer(): %v", err)
+			}
+
+			var entry map[string]any
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("json.Unmarshal(buf.Bytes(), &entry): %v", err)
+			}
+			if len(errs) != 0 {
+				t.Errorf("errs = %v, want empty", errs)
+			}
+			if entry["kept"] != "yes" {
+				t.Errorf(`entry["kept"] = %q, want %q`, entry["kept"], "yes")
+			}
+			if _, ok := entry["error"]; ok {
+				t.Errorf("entry has key %q, want none", "error")
+			}
+			if tt.expected != nil {
+				if !reflect.DeepEqual(entry["value"], tt.expected) {
+					t.Errorf(`entry["value"] = %v, want %v`, entry["value"], tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestTolerantEncoding_DefaultWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := NewDefaultWriter(buf, TolerantEncoding(), SortKeys())
+
+	writer.Write(LevelInfo, "tolerant", map[string]any{
+		"complex": complex64(1 + 2i),
+		"chan":    make(chan int),
+	})
+	if err := writer.flushBuffer(); err != nil {
+		t.Fatalf("writer.flushBuffer(): %v", err)
+	}
+
+	if got := buf.String(); !strings.Contains(got, `chan=""<chan int>"" complex="(1+2i)"`) {
+		t.Errorf("buf.String() = %q, want it to contain %q", got, `chan=""<chan int>"" complex="(1+2i)"`)
+	}
+}
+
+func FuzzTolerantEncoding(f *testing.F) {
+	f.Add("key", "value", int64(1), 1.5, uint8(0))
+	f.Add("msg", "", int64(-1), math.NaN(), uint8(3))
+	f.Add("", "\xff", int64(math.MaxInt64), math.Inf(1), uint8(7))
+
+	f.Fuzz(func(t *testing.T, key, s string, n int64, fl float64, kind uint8) {
+		values := []any{
+			s, n, fl, complex(fl, float64(n)), make(chan string), func() {},
+			[]any{s, fl, map[int]any{int(n): fl}}, map[string]any{s: []float64{fl}},
+			struct {
+				S string
+				C chan int
+				F float64
+			}{S: s, F: fl},
+		}
+
+		fields := map[string]any{key: values[int(kind)%len(values)], "all": values}
+
+		buf := &bytes.Buffer{}
+		writer := NewJSONWriter(buf, TolerantEncoding(), SortKeys(), OnError(func(err error) {
+			t.Errorf("unexpected error: %v", err)
+		}))
+		writer.Write(LevelInfo, s, fields)
+		if err := writer.flushBuffer(); err != nil {
+			t.Fatalf("writer.flushBuffer(): %v", err)
+		}
+		if !json.Valid(buf.Bytes()) {
+			t.Errorf("output is valid JSON: %s", buf.String())
+		}
+
+		text := NewDefaultWriter(&bytes.Buffer{}, TolerantEncoding())
+		text.Write(LevelInfo, s, fields)
+	})
+}
@@ -0,0 +1,141 @@
+package golog
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Keys of the trace correlation fields (see EnableTraceCorrelation)
+const (
+	// FieldTraceID holds the hex trace ID of the span of the entry
+	FieldTraceID = "trace_id"
+	// FieldSpanID holds the hex span ID of the span of the entry
+	FieldSpanID = "span_id"
+	// FieldTraceSampled reports whether the trace of the entry is sampled
+	FieldTraceSampled = "trace_sampled"
+)
+
+// Span identifies the span an entry is logged in.
+type Span struct {
+	// TraceID is the hex trace ID
+	TraceID string
+	// SpanID is the hex span ID
+	SpanID string
+	// Sampled reports whether the trace is sampled
+	Sampled bool
+}
+
+// TraceExtractor returns the span carried by ctx, and false when there is
+// none, e.g. the active span of a tracing library.
+type TraceExtractor func(ctx context.Context) (Span, bool)
+
+// spanKey is the context key of the span stored by ContextWithSpan.
+type spanKey struct{}
+
+// traceExtractors holds the extractors registered with
+// RegisterTraceExtractor, as an immutable slice replaced on registration.
+var traceExtractors atomic.Pointer[[]TraceExtractor]
+
+// ContextWithSpan returns a copy of ctx that carries span, for tracers that
+// do not register a TraceExtractor, e.g. a span parsed from a traceparent
+// header.
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// RegisterTraceExtractor adds an extractor used by EnableTraceCorrelation to
+// find the span of an entry. Extractors are tried in the order they are
+// registered, after the span stored by ContextWithSpan. The otel module
+// (github.com/jkaveri/golog/otel) registers the OpenTelemetry extractor
+// when it is imported.
+func RegisterTraceExtractor(extract TraceExtractor) {
+	for {
+		old := traceExtractors.Load()
+
+		var next []TraceExtractor
+		if old != nil {
+			next = append(next, *old...)
+		}
+
+		next = append(next, extract)
+
+		if traceExtractors.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// EnableTraceCorrelation registers with the default Logger an enricher that
+// adds the trace ID, span ID, and sampled flag of the span in the context of
+// each entry, in the "trace_id", "span_id", and "trace_sampled" fields. The
+// span is found by the extractors registered with RegisterTraceExtractor.
+// Entries logged without a span in their context are left unchanged. It does
+// nothing if the default Logger already has a trace enricher; use
+// TraceEnricher for other Loggers.
+//
+// Example:
+//
+//	import _ "github.com/jkaveri/golog/otel"
+//
+//	golog.EnableTraceCorrelation()
+//	golog.WithContext(ctx).Info("order paid") // trace_id=... span_id=...
+func EnableTraceCorrelation() {
+	for _, enricher := range std.registeredEnrichers() {
+		if _, ok := enricher.(*traceEnricher); ok {
+			return
+		}
+	}
+
+	std.RegisterEnricher(TraceEnricher(nil))
+}
+
+// TraceEnricher returns an Enricher adding the trace fields of the span
+// returned by extract, or found by the registered extractors when extract is
+// nil.
+func TraceEnricher(extract TraceExtractor) Enricher {
+	if extract == nil {
+		extract = SpanFromContext
+	}
+
+	return &traceEnricher{extract: extract}
+}
+
+// traceEnricher adds the trace fields of the span of an entry.
+type traceEnricher struct {
+	extract TraceExtractor
+}
+
+// Enrich implements Enricher.
+func (e *traceEnricher) Enrich(ctx context.Context, _ string, _ string, fields map[string]any) {
+	if ctx == nil {
+		return
+	}
+
+	span, ok := e.extract(ctx)
+	if !ok || span.TraceID == "" {
+		return
+	}
+
+	fields[FieldTraceID] = span.TraceID
+	fields[FieldSpanID] = span.SpanID
+	fields[FieldTraceSampled] = span.Sampled
+}
+
+// SpanFromContext returns the span carried by ctx: the span stored by
+// ContextWithSpan, or else the first span returned by the registered
+// extractors.
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	if span, ok := ctx.Value(spanKey{}).(Span); ok {
+		return span, true
+	}
+
+	if extractors := traceExtractors.Load(); extractors != nil {
+		for _, extract := range *extractors {
+			if span, ok := extract(ctx); ok {
+				return span, true
+			}
+		}
+	}
+
+	return Span{}, false
+}
@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// spanFromValue is a context key of a span set by a fake tracer.
+type spanFromValue struct{}
+
+func TestEnableTraceCorrelation(t *testing.T) {
+	resetEnrichers(t)
+
+	old := traceExtractors.Load()
+	t.Cleanup(func() { traceExtractors.Store(old) })
+	RegisterTraceExtractor(func(ctx context.Context) (Span, bool) {
+		span, ok := ctx.Value(spanFromValue{}).(Span)
+		return span, ok
+	})
+
+	w := &captureWriter{}
+	useWriter(t, w)
+
+	EnableTraceCorrelation()
+	EnableTraceCorrelation()
+	if got := std.registeredEnrichers(); len(got) != 1 {
+		t.Errorf("enabled once: len(std.registeredEnrichers()) = %d, want 1", len(got))
+	}
+
+	stored := Span{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}
+	extracted := Span{TraceID: "0af7651916cd43dd8448eb211c80319c", SpanID: "b7ad6b7169203331"}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]any
+	}{
+		{
+			name: "context-with-span",
+			ctx:  ContextWithSpan(context.Background(), stored),
+			want: map[string]any{FieldTraceID: stored.TraceID, FieldSpanID: stored.SpanID, FieldTraceSampled: true},
+		},
+		{
+			name: "extractor",
+			ctx:  context.WithValue(context.Background(), spanFromValue{}, extracted),
+			want: map[string]any{FieldTraceID: extracted.TraceID, FieldSpanID: extracted.SpanID, FieldTraceSampled: false},
+		},
+		{
+			name: "no-span",
+			ctx:  context.Background(),
+			want: map[string]any{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			WithContext(tt.ctx).Info("entry")
+			if got := w.last().fields; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("w.last().fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
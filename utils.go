@@ -1,6 +1,8 @@
 package golog
 
 import (
+	"io"
+	"os"
 	"path/filepath"
 	"runtime"
 )
@@ -16,3 +18,34 @@ func getCallerInfo(skip int) (file string, line int) {
 	file = filepath.Base(file)
 	return file, line
 }
+
+// bufferFlusher is implemented by writers that can write their buffered
+// entries and keep their output open, which Flush of the console writers
+// closes.
+type bufferFlusher interface {
+	flushBuffers()
+}
+
+// flushBuffers writes the entries buffered by w without closing its output
+// when w supports it, and calls Flush otherwise.
+func flushBuffers(w LogWriter) {
+	if f, ok := w.(bufferFlusher); ok {
+		f.flushBuffers()
+		return
+	}
+
+	w.Flush()
+}
+
+// closeOutput closes output if it implements io.Closer, unless it is stdout
+// or stderr, which are shared with the rest of the process: closing them
+// would silently lose every later entry.
+func closeOutput(output io.Writer) {
+	if output == os.Stdout || output == os.Stderr {
+		return
+	}
+
+	if closer, ok := output.(io.Closer); ok {
+		closer.Close()
+	}
+}
@@ -0,0 +1,325 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"sync"
+	"time"
+)
+
+// The wire protocol streams entries from a process to a log agent as a
+// sequence of frames. Each frame holds a block of JSON lines compressed with
+// a codec:
+//
+//	magic       4 bytes  "GLOG"
+//	version     1 byte   WireVersion
+//	codec       1 byte   length n of the codec name, then n bytes, e.g. "gzip"
+//	length      4 bytes  length of the payload, big-endian
+//	checksum    4 bytes  CRC-32C of the payload, big-endian
+//	payload     length bytes, the compressed JSON lines
+const (
+	// WireVersion is the version of the wire protocol written by the wire
+	// writer and read by WireReader.
+	WireVersion = 1
+	// CodecZstd is the name of the zstd codec, which compresses better than
+	// gzip. golog does not include it; register it with RegisterCodec and
+	// select it with WireCodec.
+	CodecZstd = "zstd"
+	// MaxWireFrameSize is the largest payload, compressed or not, that
+	// WireReader accepts, to bound the memory used by a corrupt or hostile
+	// stream.
+	MaxWireFrameSize = 16 << 20
+
+	// defaultWireFrameSize is the amount of entries compressed into a frame.
+	defaultWireFrameSize = 64 * 1024
+)
+
+// wireMagic starts every frame.
+var wireMagic = [4]byte{'G', 'L', 'O', 'G'}
+
+// crc32c is the table of the frame checksum.
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+var (
+	// ErrWireFormat is returned by WireReader when the stream is not a valid
+	// sequence of frames, e.g. the magic or the version does not match.
+	ErrWireFormat = errors.New("golog: invalid wire frame")
+	// ErrWireChecksum is returned by WireReader when the checksum of a frame
+	// does not match its payload.
+	ErrWireChecksum = errors.New("golog: wire frame checksum mismatch")
+)
+
+// WireOption configures the writer created by NewWireWriter.
+type WireOption func(*wireOptions)
+
+// wireOptions holds the settings of the wire writer.
+type wireOptions struct {
+	// codec is the name of the compression codec
+	codec string
+	// frameSize is the amount of entries that triggers a frame
+	frameSize int
+	// writerOptions configure the entry format
+	writerOptions []WriterOption
+}
+
+// WireCodec sets the codec compressing the frames, by its registered name
+// (see RegisterCodec). The default is CodecGzip.
+func WireCodec(name string) WireOption {
+	return func(o *wireOptions) {
+		o.codec = name
+	}
+}
+
+// WireFrameSize sets the amount of entries, in bytes before compression,
+// buffered into a frame before it is sent. The default is 64 KiB.
+func WireFrameSize(size int) WireOption {
+	return func(o *wireOptions) {
+		o.frameSize = size
+	}
+}
+
+// WireWriterOptions passes WriterOption values (SortKeys, OnError, ...) to
+// the JSON format of the entries.
+func WireWriterOptions(opts ...WriterOption) WireOption {
+	return func(o *wireOptions) {
+		o.writerOptions = append(o.writerOptions, opts...)
+	}
+}
+
+// wireWriter implements the LogWriter interface by sending entries as wire
+// protocol frames.
+type wireWriter struct {
+	mu      sync.Mutex
+	output  io.Writer
+	opts    wireOptions
+	codec   Codec
+	encoder entryEncoder
+	errors  writerOptions
+	// pending holds the entry being formatted
+	pending bytes.Buffer
+	// block holds the entries of the next frame
+	block bytes.Buffer
+}
+
+// NewWireWriter creates a LogWriter that streams entries to output, such as
+// a connection to a log agent, in the wire protocol: blocks of JSON lines,
+// compressed with gzip unless WireCodec is set, in frames with a checksum.
+// A frame is sent when 64 KiB of entries are buffered (see WireFrameSize)
+// and by Flush. Entries still buffered are lost if the process exits
+// without flushing.
+//
+// The agent reads the frames with WireReader. Errors writing to output are
+// reported to the error handler set with WireWriterOptions(OnError(...)).
+//
+// NewWireWriter returns ErrUnknownCodec if the codec is not registered.
+//
+// Example:
+//
+//	conn, err := net.Dial("tcp", "log-agent:7070")
+//	if err != nil {
+//	    return err
+//	}
+//	writer, err := golog.NewWireWriter(conn)
+//	if err != nil {
+//	    return err
+//	}
+//	golog.SetWriter(writer)
+func NewWireWriter(output io.Writer, opts ...WireOption) (*wireWriter, error) {
+	o := wireOptions{codec: CodecGzip, frameSize: defaultWireFrameSize}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	if len(o.codec) > 255 {
+		return nil, fmt.Errorf("golog: wire codec name %q is longer than 255 bytes", o.codec)
+	}
+
+	codec, err := lookupCodec(o.codec)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &wireWriter{
+		output: output,
+		opts:   o,
+		codec:  codec,
+		errors: newWriterOptions(o.writerOptions),
+	}
+	w.encoder = NewJSONWriter(&w.pending, o.writerOptions...)
+
+	return w, nil
+}
+
+// Write implements LogWriter.
+func (w *wireWriter) Write(level int, msg string, fields map[string]any) {
+	file, line := getCallerInfo(skipFrames)
+	w.write(Entry{Time: time.Now(), Level: level, Message: msg, Fields: fields}, file, line)
+}
+
+// WriteEntry implements EntryWriter.
+func (w *wireWriter) WriteEntry(entry Entry) {
+	file, line := getCallerInfo(skipFrames)
+	entry.Time = entryTime(entry)
+	w.write(entry, file, line)
+}
+
+// write formats the entry into the block and sends the block once it
+// reaches the frame size.
+func (w *wireWriter) write(entry Entry, file string, line int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending.Reset()
+	w.encoder.write(entry, file, line)
+	if err := w.encoder.flushBuffer(); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: format log entry: %w", err))
+		return
+	}
+
+	w.block.Write(w.pending.Bytes())
+	if w.block.Len() >= w.opts.frameSize {
+		w.sendFrame()
+	}
+}
+
+// Flush implements LogWriter. It sends the buffered entries as a frame.
+func (w *wireWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.sendFrame()
+}
+
+// sendFrame compresses the block and writes it to the output as one frame.
+func (w *wireWriter) sendFrame() {
+	if w.block.Len() == 0 {
+		return
+	}
+
+	payload, err := compress(w.codec, w.block.Bytes())
+	w.block.Reset()
+	if err != nil {
+		w.errors.handleError(fmt.Errorf("golog: compress wire frame: %w", err))
+		return
+	}
+
+	frame := make([]byte, 0, len(wireMagic)+2+len(w.opts.codec)+8+len(payload))
+	frame = append(frame, wireMagic[:]...)
+	frame = append(frame, WireVersion, byte(len(w.opts.codec)))
+	frame = append(frame, w.opts.codec...)
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = binary.BigEndian.AppendUint32(frame, crc32.Checksum(payload, crc32c))
+	frame = append(frame, payload...)
+
+	// a single write call, so that frames are never interleaved
+	if _, err := w.output.Write(frame); err != nil {
+		w.errors.handleError(fmt.Errorf("golog: write wire frame: %w", err))
+	}
+}
+
+// WireReader reads the frames written by the wire writer (see NewWireWriter),
+// for log agents receiving entries over the wire protocol.
+//
+// Example:
+//
+//	reader := golog.NewWireReader(conn)
+//	for {
+//	    lines, err := reader.ReadFrame()
+//	    if err != nil {
+//	        return err // io.EOF when the client closed the connection
+//	    }
+//	    forward(lines)
+//	}
+type WireReader struct {
+	r io.Reader
+}
+
+// NewWireReader creates a WireReader reading frames from r.
+func NewWireReader(r io.Reader) *WireReader {
+	return &WireReader{r: r}
+}
+
+// ReadFrame reads the next frame and returns its entries, decompressed, as
+// JSON lines. It returns io.EOF at the end of the stream,
+// io.ErrUnexpectedEOF if the stream ends inside a frame, ErrWireFormat or
+// ErrWireChecksum for a corrupt frame, and ErrUnknownCodec if the codec of
+// the frame is not registered. After an error the stream cannot be resumed.
+func (r *WireReader) ReadFrame() ([]byte, error) {
+	var header [6]byte
+	if _, err := io.ReadFull(r.r, header[:]); err != nil {
+		return nil, err
+	}
+
+	if [4]byte(header[:4]) != wireMagic {
+		return nil, fmt.Errorf("%w: bad magic %q", ErrWireFormat, header[:4])
+	}
+
+	if header[4] != WireVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrWireFormat, header[4])
+	}
+
+	rest := make([]byte, int(header[5])+8)
+	if _, err := io.ReadFull(r.r, rest); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	name := string(rest[:header[5]])
+	length := binary.BigEndian.Uint32(rest[header[5]:])
+	checksum := binary.BigEndian.Uint32(rest[int(header[5])+4:])
+
+	if length > MaxWireFrameSize {
+		return nil, fmt.Errorf("%w: payload of %d bytes exceeds %d", ErrWireFormat, length, MaxWireFrameSize)
+	}
+
+	codec, err := lookupCodec(name)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.r, payload); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	if crc32.Checksum(payload, crc32c) != checksum {
+		return nil, ErrWireChecksum
+	}
+
+	return decompress(codec, payload)
+}
+
+// decompress returns the decompressed data of a frame payload, limited to
+// MaxWireFrameSize.
+func decompress(codec Codec, payload []byte) ([]byte, error) {
+	zr, err := codec.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWireFormat, err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, MaxWireFrameSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrWireFormat, err)
+	}
+
+	if len(data) > MaxWireFrameSize {
+		return nil, fmt.Errorf("%w: decompressed payload exceeds %d bytes", ErrWireFormat, MaxWireFrameSize)
+	}
+
+	return data, nil
+}
+
+// unexpectedEOF turns io.EOF, read inside a frame, into io.ErrUnexpectedEOF.
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
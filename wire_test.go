@@ -0,0 +1,132 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestWireWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWireWriter(buf, WireCodec(CodecGzip), WireFrameSize(200))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		writer.Write(LevelInfo, "entry", map[string]any{"n": i})
+	}
+	writer.Flush()
+	writer.Flush()
+
+	reader := NewWireReader(buf)
+
+	var entries []map[string]any
+	frames := 0
+	for {
+		lines, err := reader.ReadFrame()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		frames++
+		for _, line := range strings.Split(strings.TrimSpace(string(lines)), "\n") {
+			var entry map[string]any
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatalf("json.Unmarshal([]byte(line), &entry): %v", err)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	if frames <= 1 {
+		t.Errorf("frames are sent once the frame size is reached: frames = %v, want greater than 1", frames)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("len(entries) = %d, want 4", len(entries))
+	}
+	for i, entry := range entries {
+		if entry[FieldMessage] != "entry" {
+			t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "entry")
+		}
+		if entry["n"] != float64(i) {
+			t.Errorf(`entry["n"] = %v, want %v`, entry["n"], float64(i))
+		}
+	}
+}
+
+func TestNewWireWriter_Defaults(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWireWriter(buf)
+	if err != nil {
+		t.Fatalf("the default codec is registered: unexpected error: %v", err)
+	}
+
+	writer.Write(LevelInfo, "entry", nil)
+	writer.Flush()
+
+	lines, err := NewWireReader(buf).ReadFrame()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal(bytes.TrimSpace(lines), &entry); err != nil {
+		t.Fatalf("json.Unmarshal(bytes.TrimSpace(lines), &entry): %v", err)
+	}
+	if entry[FieldMessage] != "entry" {
+		t.Errorf("entry[FieldMessage] = %q, want %q", entry[FieldMessage], "entry")
+	}
+}
+
+func TestNewWireWriter_UnknownCodec(t *testing.T) {
+	_, err := NewWireWriter(io.Discard, WireCodec(CodecZstd))
+	if !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("zstd is not registered by default: err = %v, want %v", err, ErrUnknownCodec)
+	}
+}
+
+func TestWireReader_Invalid(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer, err := NewWireWriter(buf, WireCodec(CodecGzip))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writer.Write(LevelInfo, "entry", nil)
+	writer.Flush()
+	frame := buf.Bytes()
+
+	corrupt := func(i int) []byte {
+		data := bytes.Clone(frame)
+		data[i] ^= 0xff
+		return data
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{name: "bad-magic", data: corrupt(0), want: ErrWireFormat},
+		{name: "bad-version", data: corrupt(4), want: ErrWireFormat},
+		{name: "bad-checksum", data: corrupt(len(frame) - 1), want: ErrWireChecksum},
+		{name: "truncated", data: frame[:len(frame)-3], want: io.ErrUnexpectedEOF},
+		{name: "empty", data: nil, want: io.EOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewWireReader(bytes.NewReader(tt.data)).ReadFrame()
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}